@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/netbirdio/netbird/client/internal"
+	"github.com/netbirdio/netbird/client/proto"
+	"github.com/netbirdio/netbird/util"
+)
+
+var (
+	debugConnectTimeout time.Duration
+	debugConnectJSON    bool
+)
+
+type traceEventOutput struct {
+	Type    string            `json:"type"`
+	Time    time.Time         `json:"time"`
+	Elapsed string            `json:"elapsed"`
+	Message string            `json:"message"`
+	Fields  map[string]string `json:"fields,omitempty"`
+}
+
+var debugCmd = &cobra.Command{
+	Use:   "debug",
+	Short: "debugging commands for the Netbird Service",
+}
+
+var debugConnectCmd = &cobra.Command{
+	Use:   "connect <peer>",
+	Short: "makes a new connection attempt to a peer and traces its steps",
+	Long: "Makes the daemon drop the connection to the given peer and connect again, printing the steps of the attempt: " +
+		"the gathered local and received remote ICE candidates, the checked candidate pairs, the selected pair " +
+		"and the proxy type chosen for the connection. " +
+		"Peers can be addressed by their public key, NetBird domain name or IP address.",
+	Args: cobra.ExactArgs(1),
+	RunE: debugConnectFunc,
+}
+
+func init() {
+	debugCmd.AddCommand(debugConnectCmd)
+	debugConnectCmd.Flags().DurationVar(&debugConnectTimeout, "timeout", 30*time.Second, "time to wait for the connection to be established")
+	debugConnectCmd.Flags().BoolVar(&debugConnectJSON, "json", false, "print every step as a json object per line")
+}
+
+func debugConnectFunc(cmd *cobra.Command, args []string) error {
+	SetFlagsFromEnvVars(rootCmd)
+	SetFlagsFromEnvVars(cmd)
+
+	cmd.SetOut(cmd.OutOrStdout())
+
+	err := util.InitLog(logLevel, "console")
+	if err != nil {
+		return fmt.Errorf("failed initializing log %v", err)
+	}
+
+	ctx := internal.CtxInitState(cmd.Context())
+
+	conn, err := DialClientGRPCServer(ctx, daemonAddr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to daemon error: %v\n"+
+			"If the daemon is not running please run: "+
+			"\nnetbird service install \nnetbird service start\n", err)
+	}
+	defer conn.Close()
+
+	// leave the daemon some time to report the timeout
+	streamCtx, cancel := context.WithTimeout(ctx, debugConnectTimeout+5*time.Second)
+	defer cancel()
+
+	stream, err := proto.NewDaemonServiceClient(conn).DebugConnect(streamCtx, &proto.DebugConnectRequest{
+		Peer:    args[0],
+		Timeout: durationpb.New(debugConnectTimeout),
+	})
+	if err != nil {
+		return fmt.Errorf("debug connect failed: %v", status.Convert(err).Message())
+	}
+
+	for {
+		event, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("debug connect failed: %v", status.Convert(err).Message())
+		}
+
+		line, err := formatTraceEvent(event, debugConnectJSON)
+		if err != nil {
+			return err
+		}
+		cmd.Println(line)
+	}
+}
+
+// formatTraceEvent formats a connection trace event as a single line, e.g.
+// [+1.234s] selected_pair: selected candidate pair local=... remote=...
+func formatTraceEvent(event *proto.ConnectionTraceEvent, asJSON bool) (string, error) {
+	if asJSON {
+		jsonBytes, err := json.Marshal(traceEventOutput{
+			Type:    event.GetType(),
+			Time:    event.GetTime().AsTime().Local(),
+			Elapsed: event.GetElapsed().AsDuration().String(),
+			Message: event.GetMessage(),
+			Fields:  event.GetFields(),
+		})
+		if err != nil {
+			return "", fmt.Errorf("json marshal failed: %v", err)
+		}
+		return string(jsonBytes), nil
+	}
+
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "[+%.3fs] %s: %s", event.GetElapsed().AsDuration().Seconds(), event.GetType(), event.GetMessage())
+
+	keys := make([]string, 0, len(event.GetFields()))
+	for key := range event.GetFields() {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := event.GetFields()[key]
+		if strings.ContainsAny(value, " \t") {
+			value = fmt.Sprintf("%q", value)
+		}
+		fmt.Fprintf(&builder, " %s=%s", key, value)
+	}
+
+	return builder.String(), nil
+}
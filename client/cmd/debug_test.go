@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/netbirdio/netbird/client/proto"
+)
+
+func TestFormatTraceEvent(t *testing.T) {
+	event := &proto.ConnectionTraceEvent{
+		Type:    "local_candidate",
+		Time:    timestamppb.New(time.Date(2023, time.February, 27, 10, 0, 0, 0, time.UTC)),
+		Elapsed: durationpb.New(1234 * time.Millisecond),
+		Message: "gathered local candidate",
+		Fields: map[string]string{
+			"type":      "host",
+			"id":        "candidate:1",
+			"candidate": "udp4 host 192.168.1.2:51820",
+		},
+	}
+
+	line, err := formatTraceEvent(event, false)
+	assert.NoError(t, err)
+	assert.Equal(t, `[+1.234s] local_candidate: gathered local candidate candidate="udp4 host 192.168.1.2:51820" id=candidate:1 type=host`, line)
+
+	line, err = formatTraceEvent(event, true)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"type":"local_candidate","time":"`+event.GetTime().AsTime().Local().Format(time.RFC3339Nano)+`","elapsed":"1.234s",`+
+		`"message":"gathered local candidate","fields":{"candidate":"udp4 host 192.168.1.2:51820","id":"candidate:1","type":"host"}}`, line)
+}
@@ -25,8 +25,9 @@ import (
 )
 
 const (
-	externalIPMapFlag  = "external-ip-map"
-	dnsResolverAddress = "dns-resolver-address"
+	externalIPMapFlag    = "external-ip-map"
+	dnsResolverAddress   = "dns-resolver-address"
+	sshRecordingsDirFlag = "ssh-recordings-dir"
 )
 
 var (
@@ -48,6 +49,8 @@ var (
 	preSharedKey            string
 	natExternalIPs          []string
 	customDNSAddress        string
+	sshRecordingsDir        string
+	metricsAddr             string
 	rootCmd                 = &cobra.Command{
 		Use:          "netbird",
 		Short:        "",
@@ -92,6 +95,7 @@ func init() {
 	rootCmd.PersistentFlags().StringVarP(&configPath, "config", "c", defaultConfigPath, "Netbird config file location")
 	rootCmd.PersistentFlags().StringVarP(&logLevel, "log-level", "l", "info", "sets Netbird log level")
 	rootCmd.PersistentFlags().StringVar(&logFile, "log-file", defaultLogFile, "sets Netbird log path. If console is specified the the log will be output to stdout")
+	rootCmd.PersistentFlags().StringVar(&metricsAddr, "metrics-addr", "", "Exposes the daemon metrics in the Prometheus format on the given address under /metrics, e.g. 127.0.0.1:9090. Disabled if empty")
 	rootCmd.PersistentFlags().StringVarP(&setupKey, "setup-key", "k", "", "Setup key obtained from the Management Service Dashboard (used to register peer)")
 	rootCmd.PersistentFlags().StringVar(&preSharedKey, "preshared-key", "", "Sets Wireguard PreSharedKey property. If set, then only peers that have the same key can communicate.")
 	rootCmd.AddCommand(serviceCmd)
@@ -101,6 +105,7 @@ func init() {
 	rootCmd.AddCommand(loginCmd)
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(sshCmd)
+	rootCmd.AddCommand(debugCmd)
 	serviceCmd.AddCommand(runCmd, startCmd, stopCmd, restartCmd) // service control commands are subcommands of service
 	serviceCmd.AddCommand(installCmd, uninstallCmd)              // service installer commands are subcommands of service
 	upCmd.PersistentFlags().StringSliceVar(&natExternalIPs, externalIPMapFlag, nil,
@@ -116,6 +121,12 @@ func init() {
 			`An empty string "" clears the previous configuration. `+
 			`E.g. --dns-resolver-address 127.0.0.1:5053 or --dns-resolver-address ""`,
 	)
+	upCmd.PersistentFlags().StringVar(&sshRecordingsDir, sshRecordingsDirFlag, "",
+		`Records sessions of the embedded SSH server in the asciicast format to the given directory. `+
+			`The oldest recordings are removed when the directory grows too large. `+
+			`An empty string "" disables recording. `+
+			`E.g. --ssh-recordings-dir /var/lib/netbird/ssh-recordings or --ssh-recordings-dir ""`,
+	)
 }
 
 // SetupCloseHandler handles SIGTERM signal and exits with success
@@ -10,12 +10,14 @@ import (
 	"google.golang.org/grpc"
 
 	"github.com/netbirdio/netbird/client/internal"
+	"github.com/netbirdio/netbird/client/internal/telemetry"
 )
 
 type program struct {
-	ctx    context.Context
-	cancel context.CancelFunc
-	serv   *grpc.Server
+	ctx     context.Context
+	cancel  context.CancelFunc
+	serv    *grpc.Server
+	metrics *telemetry.ClientMetrics
 }
 
 func newProgram(ctx context.Context, cancel context.CancelFunc) *program {
@@ -11,6 +11,7 @@ import (
 	"github.com/kardianos/service"
 	log "github.com/sirupsen/logrus"
 
+	"github.com/netbirdio/netbird/client/internal/telemetry"
 	"github.com/netbirdio/netbird/client/proto"
 	"github.com/netbirdio/netbird/client/server"
 	"github.com/netbirdio/netbird/util"
@@ -43,6 +44,18 @@ func (p *program) Start(svc service.Service) error {
 	if err != nil {
 		return fmt.Errorf("failed to listen daemon interface: %w", err)
 	}
+
+	if metricsAddr != "" {
+		p.metrics, err = telemetry.NewClientMetrics(p.ctx)
+		if err != nil {
+			_ = listen.Close()
+			return fmt.Errorf("failed creating metrics: %w", err)
+		}
+		if err := p.metrics.Expose(metricsAddr); err != nil {
+			_ = listen.Close()
+			return fmt.Errorf("failed exposing metrics: %w", err)
+		}
+	}
 	go func() {
 		defer listen.Close()
 
@@ -55,6 +68,7 @@ func (p *program) Start(svc service.Service) error {
 		}
 
 		serverInstance := server.New(p.ctx, configPath, logFile)
+		serverInstance.SetMetrics(p.metrics)
 		if err := serverInstance.Start(); err != nil {
 			log.Fatalf("failed to start daemon: %v", err)
 		}
@@ -75,6 +89,10 @@ func (p *program) Stop(srv service.Service) error {
 		p.serv.Stop()
 	}
 
+	if err := p.metrics.Close(); err != nil {
+		log.Warnf("failed closing metrics: %v", err)
+	}
+
 	time.Sleep(time.Second * 2)
 	log.Info("stopped Netbird service") //nolint
 	return nil
@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/netbirdio/netbird/client/internal/telemetry"
+)
+
+func TestProgram_StopClosesMetrics(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	metrics, err := telemetry.NewClientMetrics(ctx)
+	require.NoError(t, err)
+	require.NoError(t, metrics.Expose("127.0.0.1:0"))
+	addr := metrics.Address().String()
+
+	p := newProgram(ctx, cancel)
+	p.metrics = metrics
+	require.NoError(t, p.Stop(nil))
+
+	assert.Nil(t, metrics.Address(), "metrics shouldn't be exposed after the daemon stopped")
+	_, err = net.Dial("tcp", addr)
+	assert.Error(t, err, "the metrics address should be released after the daemon stopped")
+}
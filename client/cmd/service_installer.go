@@ -41,6 +41,10 @@ var installCmd = &cobra.Command{
 			svcConfig.Arguments = append(svcConfig.Arguments, "--log-file", logFile)
 		}
 
+		if metricsAddr != "" {
+			svcConfig.Arguments = append(svcConfig.Arguments, "--metrics-addr", metricsAddr)
+		}
+
 		if runtime.GOOS == "linux" {
 			// Respected only by systemd systems
 			svcConfig.Dependencies = []string{"After=network.target syslog.target"}
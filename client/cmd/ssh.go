@@ -18,13 +18,14 @@ import (
 )
 
 var (
-	port int
-	user = "root"
-	host string
+	port    int
+	user    = "root"
+	host    string
+	command string
 )
 
 var sshCmd = &cobra.Command{
-	Use: "ssh",
+	Use: "ssh [user@]host [-- command]",
 	Args: func(cmd *cobra.Command, args []string) error {
 		if len(args) < 1 {
 			return errors.New("requires a host argument")
@@ -38,6 +39,8 @@ var sshCmd = &cobra.Command{
 			host = args[0]
 		}
 
+		command = strings.Join(args[1:], " ")
+
 		return nil
 	},
 	Short: "connect to a remote SSH server",
@@ -70,10 +73,14 @@ var sshCmd = &cobra.Command{
 		signal.Notify(sig, syscall.SIGTERM, syscall.SIGINT)
 		sshctx, cancel := context.WithCancel(ctx)
 
+		var exitCode int
 		go func() {
 			// blocking
-			if err := runSSH(sshctx, host, []byte(config.SSHKey), cmd); err != nil {
+			var err error
+			exitCode, err = runSSH(sshctx, host, []byte(config.SSHKey), cmd)
+			if err != nil {
 				log.Print(err)
+				exitCode = 1
 			}
 			cancel()
 		}()
@@ -84,11 +91,26 @@ var sshCmd = &cobra.Command{
 		case <-sshctx.Done():
 		}
 
+		// propagate the exit code of the remote command so that it can be used in scripts
+		if command != "" && exitCode != 0 {
+			os.Exit(exitCode)
+		}
+
 		return nil
 	},
 }
 
-func runSSH(ctx context.Context, addr string, pemKey []byte, cmd *cobra.Command) error {
+var sshSFTPServerCmd = &cobra.Command{
+	Use:    "sftp-server",
+	Short:  "serves SFTP on the standard input and output for the NetBird SSH server",
+	Hidden: true,
+	Args:   cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return nbssh.ServeSFTP(os.Stdin, os.Stdout)
+	},
+}
+
+func runSSH(ctx context.Context, addr string, pemKey []byte, cmd *cobra.Command) (int, error) {
 	c, err := nbssh.DialWithKey(fmt.Sprintf("%s:%d", addr, port), user, pemKey)
 	if err != nil {
 		cmd.Printf("Error: %v\n", err)
@@ -97,7 +119,7 @@ func runSSH(ctx context.Context, addr string, pemKey []byte, cmd *cobra.Command)
 			"Run the status command: \n\n" +
 			" netbird status\n\n" +
 			"It might also be that the SSH server is disabled on the agent you are trying to connect to.\n")
-		return nil
+		return 255, nil
 	}
 	go func() {
 		<-ctx.Done()
@@ -107,14 +129,19 @@ func runSSH(ctx context.Context, addr string, pemKey []byte, cmd *cobra.Command)
 		}
 	}()
 
+	if command != "" {
+		return c.ExecuteCommand(command)
+	}
+
 	err = c.OpenTerminal()
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	return nil
+	return 0, nil
 }
 
 func init() {
+	sshCmd.AddCommand(sshSFTPServerCmd, sshProxyCmd, sshConfigCmd)
 	sshCmd.PersistentFlags().IntVarP(&port, "port", "p", nbssh.DefaultSSHPort, "Sets remote SSH port. Defaults to "+fmt.Sprint(nbssh.DefaultSSHPort))
 }
@@ -0,0 +1,249 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	osuser "os/user"
+	"path/filepath"
+	"runtime"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/netbirdio/netbird/client/proto"
+	nbssh "github.com/netbirdio/netbird/client/ssh"
+	"github.com/netbirdio/netbird/util"
+)
+
+const (
+	sshIdentityFileName   = "netbird_user_id"
+	sshKnownHostsFileName = "netbird_known_hosts"
+	sshConfigFileName     = "config"
+	// sshLegacyIdentityFileName is the identity file older versions wrote the SSH key of the peer to
+	sshLegacyIdentityFileName = "netbird_id"
+	// sshCertificateSuffix is appended to the identity file by OpenSSH to find its certificate
+	sshCertificateSuffix = "-cert.pub"
+)
+
+var (
+	sshConfigDir    string
+	sshIdentityFile string
+)
+
+var sshConfigCmd = &cobra.Command{
+	Use:   "config",
+	Short: "configures the OpenSSH client to connect to NetBird peers",
+	Long: "Adds a Host entry for every NetBird peer to the OpenSSH client config, " +
+		"together with the peers' SSH host keys, so that NetBird peers can be reached with plain ssh, scp or " +
+		"IDE remote extensions. The peers are authenticated with a key of the user, generated if it doesn't exist, " +
+		"and the certificate issued for it by the management API (POST /api/ssh/certificates) saved next to it " +
+		"with the " + sshCertificateSuffix + " suffix. Run it again to pick up peers added to the network.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		SetFlagsFromEnvVars(rootCmd)
+		SetFlagsFromEnvVars(cmd)
+
+		cmd.SetOut(cmd.OutOrStdout())
+
+		err := util.InitLog(logLevel, "console")
+		if err != nil {
+			return fmt.Errorf("failed initializing log %v", err)
+		}
+
+		resp, err := getStatus(cmd.Context(), cmd)
+		if err != nil {
+			return err
+		}
+
+		dir := sshConfigDir
+		if dir == "" {
+			dir, err = defaultSSHConfigDir()
+			if err != nil {
+				return err
+			}
+		}
+
+		executable, err := os.Executable()
+		if err != nil {
+			return fmt.Errorf("failed getting the netbird executable path: %v", err)
+		}
+
+		identityFile := sshIdentityFile
+		if identityFile == "" {
+			identityFile, err = ensureSSHIdentity(dir)
+			if err != nil {
+				return err
+			}
+		} else if _, err = os.Stat(identityFile); err != nil {
+			return fmt.Errorf("failed reading the identity file: %v", err)
+		}
+
+		hosts := toPeerHosts(resp.GetFullStatus())
+		err = writeSSHConfig(dir, identityFile, hosts, sshProxyCommand(executable))
+		if err != nil {
+			return err
+		}
+
+		cmd.Printf("OpenSSH client config for %d peers written to %s\n", len(hosts), filepath.Join(dir, sshConfigFileName))
+		if _, err = os.Stat(identityFile + sshCertificateSuffix); os.IsNotExist(err) {
+			cmd.Printf("Request a certificate for %s.pub with the management API and save it to %s\n",
+				identityFile, identityFile+sshCertificateSuffix)
+		}
+		return nil
+	},
+}
+
+// ensureSSHIdentity returns the path of the NetBird SSH key of the user in the given directory and generates the key
+// if it doesn't exist. The SSH key of the peer is never used, as it is a credential of the host and not of the user.
+// The copy of the peer key written by older versions is removed
+func ensureSSHIdentity(dir string) (string, error) {
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return "", fmt.Errorf("failed creating %s: %v", dir, err)
+	}
+	chownToSudoUser(dir)
+
+	legacyIdentityFile := filepath.Join(dir, sshLegacyIdentityFileName)
+	err = os.Remove(legacyIdentityFile)
+	if err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed removing %s: %v", legacyIdentityFile, err)
+	}
+
+	identityFile := filepath.Join(dir, sshIdentityFileName)
+	if _, err = os.Stat(identityFile); err == nil {
+		return identityFile, nil
+	}
+
+	privateKey, err := nbssh.GeneratePrivateKey(nbssh.ED25519)
+	if err != nil {
+		return "", fmt.Errorf("failed generating the SSH key: %v", err)
+	}
+	publicKey, err := nbssh.GeneratePublicKey(privateKey)
+	if err != nil {
+		return "", fmt.Errorf("failed generating the SSH public key: %v", err)
+	}
+
+	// OpenSSH reads the PKCS#8 encoded private key as is
+	err = writeSSHFile(identityFile, privateKey, 0600)
+	if err != nil {
+		return "", err
+	}
+	err = writeSSHFile(identityFile+".pub", publicKey, 0644)
+	if err != nil {
+		return "", err
+	}
+
+	return identityFile, nil
+}
+
+// writeSSHConfig writes the known_hosts file and updates the NetBird block of the OpenSSH client config in the
+// given directory
+func writeSSHConfig(dir string, identityFile string, hosts []nbssh.PeerHost, proxyCommand string) error {
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return fmt.Errorf("failed creating %s: %v", dir, err)
+	}
+	chownToSudoUser(dir)
+
+	knownHostsFile := filepath.Join(dir, sshKnownHostsFileName)
+	err = writeSSHFile(knownHostsFile, []byte(nbssh.GenerateKnownHosts(hosts, port)), 0644)
+	if err != nil {
+		return err
+	}
+
+	block := nbssh.GenerateConfigBlock(hosts, nbssh.OpenSSHConfig{
+		Port:           port,
+		IdentityFile:   identityFile,
+		KnownHostsFile: knownHostsFile,
+		ProxyCommand:   proxyCommand,
+	})
+
+	configFile := filepath.Join(dir, sshConfigFileName)
+	existing, err := os.ReadFile(configFile)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed reading %s: %v", configFile, err)
+	}
+
+	return writeSSHFile(configFile, []byte(nbssh.ReplaceConfigBlock(string(existing), block)), 0600)
+}
+
+func writeSSHFile(path string, content []byte, perm os.FileMode) error {
+	err := os.WriteFile(path, content, perm)
+	if err != nil {
+		return fmt.Errorf("failed writing %s: %v", path, err)
+	}
+	// OpenSSH refuses private keys and configs that are readable by others
+	err = os.Chmod(path, perm)
+	if err != nil {
+		return fmt.Errorf("failed setting permissions of %s: %v", path, err)
+	}
+	chownToSudoUser(path)
+	return nil
+}
+
+// toPeerHosts converts the peers of the daemon status to OpenSSH hosts
+func toPeerHosts(fullStatus *proto.FullStatus) []nbssh.PeerHost {
+	var hosts []nbssh.PeerHost
+	for _, peerState := range fullStatus.GetPeers() {
+		if peerState.GetFqdn() == "" {
+			continue
+		}
+		hosts = append(hosts, nbssh.PeerHost{
+			FQDN:    peerState.GetFqdn(),
+			IP:      peerIP(peerState.GetIP()),
+			HostKey: peerState.GetSshHostKey(),
+		})
+	}
+	return hosts
+}
+
+func sshProxyCommand(executable string) string {
+	proxyCommand := fmt.Sprintf("%q ssh proxy %%h %%p", executable)
+	if daemonAddrFlag := rootCmd.PersistentFlags().Lookup("daemon-addr"); daemonAddrFlag != nil &&
+		daemonAddr != daemonAddrFlag.DefValue {
+		proxyCommand += fmt.Sprintf(" --daemon-addr %q", daemonAddr)
+	}
+	return proxyCommand
+}
+
+// defaultSSHConfigDir returns the .ssh directory of the user running the command, or the one of the user that
+// invoked sudo
+func defaultSSHConfigDir() (string, error) {
+	if sudoUser := os.Getenv("SUDO_USER"); sudoUser != "" {
+		u, err := osuser.Lookup(sudoUser)
+		if err == nil {
+			return filepath.Join(u.HomeDir, ".ssh"), nil
+		}
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed getting the home directory: %v", err)
+	}
+	return filepath.Join(home, ".ssh"), nil
+}
+
+// chownToSudoUser hands the generated files over to the user that invoked sudo so that their OpenSSH client can
+// read them
+func chownToSudoUser(path string) {
+	if runtime.GOOS == "windows" {
+		return
+	}
+
+	uid, err := strconv.Atoi(os.Getenv("SUDO_UID"))
+	if err != nil {
+		return
+	}
+	gid, err := strconv.Atoi(os.Getenv("SUDO_GID"))
+	if err != nil {
+		return
+	}
+
+	_ = os.Chown(path, uid, gid)
+}
+
+func init() {
+	sshConfigCmd.Flags().StringVar(&sshConfigDir, "ssh-dir", "", "OpenSSH client directory to write the config to. Defaults to ~/.ssh")
+	sshConfigCmd.Flags().StringVar(&sshIdentityFile, "identity-file", "",
+		"private key of the user to authenticate to the peers with. Defaults to a key generated in the OpenSSH client directory")
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	nbssh "github.com/netbirdio/netbird/client/ssh"
+)
+
+func TestSSHConfig_DoesNotExportPeerKey(t *testing.T) {
+	dir := t.TempDir()
+
+	peerKey, err := nbssh.GeneratePrivateKey(nbssh.ED25519)
+	require.NoError(t, err)
+	// older versions copied the SSH key of the peer to the user directory
+	require.NoError(t, os.WriteFile(filepath.Join(dir, sshLegacyIdentityFileName), peerKey, 0600))
+
+	identityFile, err := ensureSSHIdentity(dir)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, sshIdentityFileName), identityFile)
+
+	hosts := []nbssh.PeerHost{{FQDN: "server.netbird.cloud", IP: "100.64.0.2", HostKey: "ssh-ed25519 AAAA"}}
+	require.NoError(t, writeSSHConfig(dir, identityFile, hosts, "netbird ssh proxy %h %p"))
+
+	assert.NoFileExists(t, filepath.Join(dir, sshLegacyIdentityFileName), "the copy of the peer key should be removed")
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	for _, entry := range entries {
+		content, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		require.NoError(t, err)
+		assert.False(t, bytes.Contains(content, peerKey), "%s should not contain the peer key", entry.Name())
+	}
+
+	userKey, err := os.ReadFile(identityFile)
+	require.NoError(t, err)
+	_, err = nbssh.GeneratePublicKey(userKey)
+	require.NoError(t, err, "the generated user key should be a valid private key")
+	assert.FileExists(t, identityFile+".pub")
+
+	config, err := os.ReadFile(filepath.Join(dir, sshConfigFileName))
+	require.NoError(t, err)
+	assert.Contains(t, string(config), "IdentityFile "+identityFile)
+
+	// the existing user key is kept
+	again, err := ensureSSHIdentity(dir)
+	require.NoError(t, err)
+	assert.Equal(t, identityFile, again)
+	reread, err := os.ReadFile(identityFile)
+	require.NoError(t, err)
+	assert.Equal(t, userKey, reread)
+}
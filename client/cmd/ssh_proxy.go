@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/netbirdio/netbird/client/proto"
+	"github.com/netbirdio/netbird/util"
+)
+
+var sshProxyCmd = &cobra.Command{
+	Use:   "proxy <host> <port>",
+	Short: "connects the standard input and output to a NetBird peer, to be used as OpenSSH ProxyCommand",
+	Long: "Connects the standard input and output to the given port of a NetBird peer. " +
+		"Peers can be addressed by their NetBird domain name or IP address. " +
+		"Use it as ProxyCommand of the OpenSSH client, e.g. ssh -o ProxyCommand=\"netbird ssh proxy %h %p\" peer.netbird.cloud",
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		SetFlagsFromEnvVars(rootCmd)
+		SetFlagsFromEnvVars(cmd)
+
+		// the standard output is the connection, logs go to the standard error only
+		err := util.InitLog(logLevel, "console")
+		if err != nil {
+			return fmt.Errorf("failed initializing log %v", err)
+		}
+		log.SetOutput(os.Stderr)
+
+		proxyPort, err := strconv.Atoi(args[1])
+		if err != nil || proxyPort < 1 || proxyPort > 65535 {
+			return fmt.Errorf("invalid port %s", args[1])
+		}
+
+		proxyHost := args[0]
+		resp, err := getStatus(cmd.Context(), cmd)
+		if err != nil {
+			log.Debugf("unable to resolve %s with the NetBird daemon, using it as is: %v", proxyHost, err)
+		} else {
+			proxyHost = resolvePeerHost(resp.GetFullStatus(), proxyHost)
+		}
+
+		conn, err := net.Dial("tcp", net.JoinHostPort(proxyHost, strconv.Itoa(proxyPort)))
+		if err != nil {
+			return fmt.Errorf("failed connecting to %s: %v", args[0], err)
+		}
+		defer conn.Close()
+
+		return proxyConn(conn, os.Stdin, os.Stdout)
+	},
+}
+
+// resolvePeerHost returns the NetBird IP address of the peer with the given domain name.
+// The host is returned as is if it doesn't match any peer
+func resolvePeerHost(fullStatus *proto.FullStatus, host string) string {
+	fqdn := strings.TrimSuffix(strings.ToLower(host), ".")
+	for _, peerState := range fullStatus.GetPeers() {
+		if peerState.GetFqdn() == "" || strings.ToLower(peerState.GetFqdn()) != fqdn {
+			continue
+		}
+		if ip := peerIP(peerState.GetIP()); ip != "" {
+			return ip
+		}
+	}
+	return host
+}
+
+// peerIP returns the first address of the peer state IP, which holds the allowed IPs of offline peers
+func peerIP(ip string) string {
+	return strings.Split(strings.Split(ip, ",")[0], "/")[0]
+}
+
+// proxyConn copies data between the connection and the given reader and writer until the remote side closes
+// the connection
+func proxyConn(conn net.Conn, in io.Reader, out io.Writer) error {
+	go func() {
+		_, err := io.Copy(conn, in)
+		if err != nil {
+			log.Debugf("failed proxying input: %v", err)
+		}
+		// signal the end of the input to the remote side while still reading its output
+		if tcpConn, ok := conn.(*net.TCPConn); ok {
+			_ = tcpConn.CloseWrite()
+		}
+	}()
+
+	_, err := io.Copy(out, conn)
+	return err
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/netbirdio/netbird/client/proto"
+)
+
+func TestResolvePeerHost(t *testing.T) {
+	fullStatus := &proto.FullStatus{
+		Peers: []*proto.PeerState{
+			{IP: "100.64.0.2", Fqdn: "server.netbird.cloud"},
+			{IP: "100.64.0.3/32", Fqdn: "offline.netbird.cloud"},
+		},
+	}
+
+	testCases := []struct {
+		name     string
+		host     string
+		expected string
+	}{
+		{name: "Should Resolve Peer Domain", host: "server.netbird.cloud", expected: "100.64.0.2"},
+		{name: "Should Resolve Fully Qualified Domain", host: "Server.netbird.cloud.", expected: "100.64.0.2"},
+		{name: "Should Strip Allowed IP Prefix", host: "offline.netbird.cloud", expected: "100.64.0.3"},
+		{name: "Should Keep Unknown Host", host: "100.64.0.4", expected: "100.64.0.4"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.expected, resolvePeerHost(fullStatus, testCase.host))
+		})
+	}
+}
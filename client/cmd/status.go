@@ -22,14 +22,18 @@ import (
 )
 
 type peerStateDetailOutput struct {
-	FQDN             string           `json:"fqdn" yaml:"fqdn"`
-	IP               string           `json:"netbirdIp" yaml:"netbirdIp"`
-	PubKey           string           `json:"publicKey" yaml:"publicKey"`
-	Status           string           `json:"status" yaml:"status"`
-	LastStatusUpdate time.Time        `json:"lastStatusUpdate" yaml:"lastStatusUpdate"`
-	ConnType         string           `json:"connectionType" yaml:"connectionType"`
-	Direct           bool             `json:"direct" yaml:"direct"`
-	IceCandidateType iceCandidateType `json:"iceCandidateType" yaml:"iceCandidateType"`
+	FQDN                   string           `json:"fqdn" yaml:"fqdn"`
+	IP                     string           `json:"netbirdIp" yaml:"netbirdIp"`
+	PubKey                 string           `json:"publicKey" yaml:"publicKey"`
+	Status                 string           `json:"status" yaml:"status"`
+	LastStatusUpdate       time.Time        `json:"lastStatusUpdate" yaml:"lastStatusUpdate"`
+	ConnType               string           `json:"connectionType" yaml:"connectionType"`
+	Direct                 bool             `json:"direct" yaml:"direct"`
+	IceCandidateType       iceCandidateType `json:"iceCandidateType" yaml:"iceCandidateType"`
+	LastWireguardHandshake time.Time        `json:"lastWireguardHandshake" yaml:"lastWireguardHandshake"`
+	TransferReceived       int64            `json:"transferReceived" yaml:"transferReceived"`
+	TransferSent           int64            `json:"transferSent" yaml:"transferSent"`
+	Latency                time.Duration    `json:"latency" yaml:"latency"`
 }
 
 type peersStateOutput struct {
@@ -48,6 +52,12 @@ type managementStateOutput struct {
 	Connected bool   `json:"connected" yaml:"connected"`
 }
 
+type dnsCacheStateOutput struct {
+	Entries int    `json:"entries" yaml:"entries"`
+	Hits    uint64 `json:"hits" yaml:"hits"`
+	Misses  uint64 `json:"misses" yaml:"misses"`
+}
+
 type iceCandidateType struct {
 	Local  string `json:"local" yaml:"local"`
 	Remote string `json:"remote" yaml:"remote"`
@@ -63,6 +73,7 @@ type statusOutputOverview struct {
 	PubKey          string                `json:"publicKey" yaml:"publicKey"`
 	KernelInterface bool                  `json:"usesKernelInterface" yaml:"usesKernelInterface"`
 	FQDN            string                `json:"fqdn" yaml:"fqdn"`
+	DNSCache        dnsCacheStateOutput   `json:"dnsCache" yaml:"dnsCache"`
 }
 
 var (
@@ -217,6 +228,11 @@ func convertToStatusOutputOverview(resp *proto.StatusResponse) statusOutputOverv
 		PubKey:          pbFullStatus.GetLocalPeerState().GetPubKey(),
 		KernelInterface: pbFullStatus.GetLocalPeerState().GetKernelInterface(),
 		FQDN:            pbFullStatus.GetLocalPeerState().GetFqdn(),
+		DNSCache: dnsCacheStateOutput{
+			Entries: int(pbFullStatus.GetDnsCacheState().GetEntries()),
+			Hits:    pbFullStatus.GetDnsCacheState().GetHits(),
+			Misses:  pbFullStatus.GetDnsCacheState().GetMisses(),
+		},
 	}
 
 	return overview
@@ -245,6 +261,10 @@ func mapPeers(peers []*proto.PeerState) peersStateOutput {
 		}
 
 		timeLocal := pbPeerState.GetConnStatusUpdate().AsTime().Local()
+		var lastHandshake time.Time
+		if pbPeerState.GetLastWireguardHandshake() != nil {
+			lastHandshake = pbPeerState.GetLastWireguardHandshake().AsTime().UTC()
+		}
 		peerState := peerStateDetailOutput{
 			IP:               pbPeerState.GetIP(),
 			PubKey:           pbPeerState.GetPubKey(),
@@ -256,7 +276,11 @@ func mapPeers(peers []*proto.PeerState) peersStateOutput {
 				Local:  localICE,
 				Remote: remoteICE,
 			},
-			FQDN: pbPeerState.GetFqdn(),
+			FQDN:                   pbPeerState.GetFqdn(),
+			LastWireguardHandshake: lastHandshake,
+			TransferReceived:       pbPeerState.GetBytesRx(),
+			TransferSent:           pbPeerState.GetBytesTx(),
+			Latency:                pbPeerState.GetLatency().AsDuration(),
 		}
 
 		peersStateDetail = append(peersStateDetail, peerState)
@@ -335,6 +359,9 @@ func parseGeneralSummary(overview statusOutputOverview, showURL bool) string {
 
 	peersCountString := fmt.Sprintf("%d/%d Connected", overview.Peers.Connected, overview.Peers.Total)
 
+	dnsCacheString := fmt.Sprintf("%d entries, %d hits, %d misses",
+		overview.DNSCache.Entries, overview.DNSCache.Hits, overview.DNSCache.Misses)
+
 	summary := fmt.Sprintf(
 		"Daemon version: %s\n"+
 			"CLI version: %s\n"+
@@ -343,7 +370,8 @@ func parseGeneralSummary(overview statusOutputOverview, showURL bool) string {
 			"FQDN: %s\n"+
 			"NetBird IP: %s\n"+
 			"Interface type: %s\n"+
-			"Peers count: %s\n",
+			"Peers count: %s\n"+
+			"DNS cache: %s\n",
 		overview.DaemonVersion,
 		version.NetbirdVersion(),
 		managementConnString,
@@ -352,6 +380,7 @@ func parseGeneralSummary(overview statusOutputOverview, showURL bool) string {
 		interfaceIP,
 		interfaceTypeString,
 		peersCountString,
+		dnsCacheString,
 	)
 	return summary
 }
@@ -386,6 +415,16 @@ func parsePeers(peers peersStateOutput) string {
 			remoteICE = peerState.IceCandidateType.Remote
 		}
 
+		lastHandshake := "-"
+		if !peerState.LastWireguardHandshake.IsZero() {
+			lastHandshake = peerState.LastWireguardHandshake.Format("2006-01-02 15:04:05")
+		}
+
+		latency := "-"
+		if peerState.Latency > 0 {
+			latency = peerState.Latency.Round(10 * time.Microsecond).String()
+		}
+
 		peerString := fmt.Sprintf(
 			"\n %s:\n"+
 				"  NetBird IP: %s\n"+
@@ -395,7 +434,10 @@ func parsePeers(peers peersStateOutput) string {
 				"  Connection type: %s\n"+
 				"  Direct: %t\n"+
 				"  ICE candidate (Local/Remote): %s/%s\n"+
-				"  Last connection update: %s\n",
+				"  Last connection update: %s\n"+
+				"  Last WireGuard handshake: %s\n"+
+				"  Transfer status (received/sent): %s/%s\n"+
+				"  Latency: %s\n",
 			peerState.FQDN,
 			peerState.IP,
 			peerState.PubKey,
@@ -405,6 +447,10 @@ func parsePeers(peers peersStateOutput) string {
 			localICE,
 			remoteICE,
 			peerState.LastStatusUpdate.Format("2006-01-02 15:04:05"),
+			lastHandshake,
+			toIEC(peerState.TransferReceived),
+			toIEC(peerState.TransferSent),
+			latency,
 		)
 
 		peersString = peersString + peerString
@@ -412,6 +458,20 @@ func parsePeers(peers peersStateOutput) string {
 	return peersString
 }
 
+// toIEC formats a number of bytes with binary prefixes, e.g. 1.5 KiB
+func toIEC(b int64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%d B", b)
+	}
+	div, exp := int64(unit), 0
+	for n := b / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(b)/float64(div), "KMGTPE"[exp])
+}
+
 func skipDetailByFilters(peerState *proto.PeerState, isConnected bool) bool {
 	statusEval := false
 	ipEval := false
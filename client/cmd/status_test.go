@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/durationpb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/netbirdio/netbird/client/proto"
@@ -25,6 +26,10 @@ var resp = &proto.StatusResponse{
 				Direct:                 true,
 				LocalIceCandidateType:  "",
 				RemoteIceCandidateType: "",
+				LastWireguardHandshake: timestamppb.New(time.Date(2001, time.Month(1), 1, 1, 1, 2, 0, time.UTC)),
+				BytesRx:                200,
+				BytesTx:                100,
+				Latency:                durationpb.New(15 * time.Millisecond),
 			},
 			{
 				IP:                     "192.168.178.102",
@@ -36,6 +41,9 @@ var resp = &proto.StatusResponse{
 				Direct:                 false,
 				LocalIceCandidateType:  "relay",
 				RemoteIceCandidateType: "prflx",
+				LastWireguardHandshake: timestamppb.New(time.Date(2002, time.Month(2), 2, 2, 2, 3, 0, time.UTC)),
+				BytesRx:                2000,
+				BytesTx:                1000,
 			},
 		},
 		ManagementState: &proto.ManagementState{
@@ -52,6 +60,11 @@ var resp = &proto.StatusResponse{
 			KernelInterface: true,
 			Fqdn:            "some-localhost.awesome-domain.com",
 		},
+		DnsCacheState: &proto.DNSCacheState{
+			Entries: 10,
+			Hits:    42,
+			Misses:  7,
+		},
 	},
 	DaemonVersion: "0.14.1",
 }
@@ -73,6 +86,10 @@ var overview = statusOutputOverview{
 					Local:  "",
 					Remote: "",
 				},
+				LastWireguardHandshake: time.Date(2001, 1, 1, 1, 1, 2, 0, time.UTC),
+				TransferReceived:       200,
+				TransferSent:           100,
+				Latency:                15 * time.Millisecond,
 			},
 			{
 				IP:               "192.168.178.102",
@@ -86,6 +103,9 @@ var overview = statusOutputOverview{
 					Local:  "relay",
 					Remote: "prflx",
 				},
+				LastWireguardHandshake: time.Date(2002, 2, 2, 2, 2, 3, 0, time.UTC),
+				TransferReceived:       2000,
+				TransferSent:           1000,
 			},
 		},
 	},
@@ -103,6 +123,11 @@ var overview = statusOutputOverview{
 	PubKey:          "Some-Pub-Key",
 	KernelInterface: true,
 	FQDN:            "some-localhost.awesome-domain.com",
+	DNSCache: dnsCacheStateOutput{
+		Entries: 10,
+		Hits:    42,
+		Misses:  7,
+	},
 }
 
 func TestConversionFromFullStatusToOutputOverview(t *testing.T) {
@@ -158,7 +183,11 @@ func TestParsingToJSON(t *testing.T) {
 		"{" +
 		"\"local\":\"\"," +
 		"\"remote\":\"\"" +
-		"}" +
+		"}," +
+		"\"lastWireguardHandshake\":\"2001-01-01T01:01:02Z\"," +
+		"\"transferReceived\":200," +
+		"\"transferSent\":100," +
+		"\"latency\":15000000" +
 		"}," +
 		"{" +
 		"\"fqdn\":\"peer-2.awesome-domain.com\"," +
@@ -172,7 +201,11 @@ func TestParsingToJSON(t *testing.T) {
 		"{" +
 		"\"local\":\"relay\"," +
 		"\"remote\":\"prflx\"" +
-		"}" +
+		"}," +
+		"\"lastWireguardHandshake\":\"2002-02-02T02:02:03Z\"," +
+		"\"transferReceived\":2000," +
+		"\"transferSent\":1000," +
+		"\"latency\":0" +
 		"}" +
 		"]" +
 		"}," +
@@ -191,7 +224,13 @@ func TestParsingToJSON(t *testing.T) {
 		"\"netbirdIp\":\"192.168.178.100/16\"," +
 		"\"publicKey\":\"Some-Pub-Key\"," +
 		"\"usesKernelInterface\":true," +
-		"\"fqdn\":\"some-localhost.awesome-domain.com\"" +
+		"\"fqdn\":\"some-localhost.awesome-domain.com\"," +
+		"\"dnsCache\":" +
+		"{" +
+		"\"entries\":10," +
+		"\"hits\":42," +
+		"\"misses\":7" +
+		"}" +
 		"}"
 	// @formatter:on
 
@@ -215,6 +254,10 @@ func TestParsingToYAML(t *testing.T) {
 		"          iceCandidateType:\n" +
 		"            local: \"\"\n" +
 		"            remote: \"\"\n" +
+		"          lastWireguardHandshake: 2001-01-01T01:01:02Z\n" +
+		"          transferReceived: 200\n" +
+		"          transferSent: 100\n" +
+		"          latency: 15ms\n" +
 		"        - fqdn: peer-2.awesome-domain.com\n" +
 		"          netbirdIp: 192.168.178.102\n" +
 		"          publicKey: Pubkey2\n" +
@@ -225,6 +268,10 @@ func TestParsingToYAML(t *testing.T) {
 		"          iceCandidateType:\n" +
 		"            local: relay\n" +
 		"            remote: prflx\n" +
+		"          lastWireguardHandshake: 2002-02-02T02:02:03Z\n" +
+		"          transferReceived: 2000\n" +
+		"          transferSent: 1000\n" +
+		"          latency: 0s\n" +
 		"cliVersion: development\n" +
 		"daemonVersion: 0.14.1\n" +
 		"management:\n" +
@@ -236,7 +283,11 @@ func TestParsingToYAML(t *testing.T) {
 		"netbirdIp: 192.168.178.100/16\n" +
 		"publicKey: Some-Pub-Key\n" +
 		"usesKernelInterface: true\n" +
-		"fqdn: some-localhost.awesome-domain.com\n"
+		"fqdn: some-localhost.awesome-domain.com\n" +
+		"dnsCache:\n" +
+		"    entries: 10\n" +
+		"    hits: 42\n" +
+		"    misses: 7\n"
 
 	assert.Equal(t, expectedYAML, yaml)
 }
@@ -254,6 +305,9 @@ func TestParsingToDetail(t *testing.T) {
 		"  Direct: true\n" +
 		"  ICE candidate (Local/Remote): -/-\n" +
 		"  Last connection update: 2001-01-01 01:01:01\n" +
+		"  Last WireGuard handshake: 2001-01-01 01:01:02\n" +
+		"  Transfer status (received/sent): 200 B/100 B\n" +
+		"  Latency: 15ms\n" +
 		"\n" +
 		" peer-2.awesome-domain.com:\n" +
 		"  NetBird IP: 192.168.178.102\n" +
@@ -264,6 +318,9 @@ func TestParsingToDetail(t *testing.T) {
 		"  Direct: false\n" +
 		"  ICE candidate (Local/Remote): relay/prflx\n" +
 		"  Last connection update: 2002-02-02 02:02:02\n" +
+		"  Last WireGuard handshake: 2002-02-02 02:02:03\n" +
+		"  Transfer status (received/sent): 2.0 KiB/1000 B\n" +
+		"  Latency: -\n" +
 		"\n" +
 		"Daemon version: 0.14.1\n" +
 		"CLI version: development\n" +
@@ -272,7 +329,8 @@ func TestParsingToDetail(t *testing.T) {
 		"FQDN: some-localhost.awesome-domain.com\n" +
 		"NetBird IP: 192.168.178.100/16\n" +
 		"Interface type: Kernel\n" +
-		"Peers count: 2/2 Connected\n"
+		"Peers count: 2/2 Connected\n" +
+		"DNS cache: 10 entries, 42 hits, 7 misses\n"
 
 	assert.Equal(t, expectedDetail, detail)
 }
@@ -287,7 +345,8 @@ func TestParsingToShortVersion(t *testing.T) {
 		"FQDN: some-localhost.awesome-domain.com\n" +
 		"NetBird IP: 192.168.178.100/16\n" +
 		"Interface type: Kernel\n" +
-		"Peers count: 2/2 Connected\n"
+		"Peers count: 2/2 Connected\n" +
+		"DNS cache: 10 entries, 42 hits, 7 misses\n"
 
 	assert.Equal(t, expectedString, shortVersion)
 }
@@ -72,14 +72,19 @@ func runInForegroundMode(ctx context.Context, cmd *cobra.Command) error {
 		return err
 	}
 
-	config, err := internal.UpdateOrCreateConfig(internal.ConfigInput{
+	ic := internal.ConfigInput{
 		ManagementURL:    managementURL,
 		AdminURL:         adminURL,
 		ConfigPath:       configPath,
 		PreSharedKey:     &preSharedKey,
 		NATExternalIPs:   natExternalIPs,
 		CustomDNSAddress: customDNSAddressConverted,
-	})
+	}
+	if cmd.Flag(sshRecordingsDirFlag).Changed {
+		ic.SSHRecordingsDir = &sshRecordingsDir
+	}
+
+	config, err := internal.UpdateOrCreateConfig(ic)
 	if err != nil {
 		return fmt.Errorf("get config file: %v", err)
 	}
@@ -140,6 +145,10 @@ func runInDaemonMode(ctx context.Context, cmd *cobra.Command) error {
 		CustomDNSAddress:    customDNSAddressConverted,
 	}
 
+	if cmd.Flag(sshRecordingsDirFlag).Changed {
+		loginRequest.SshRecordingsDir = &sshRecordingsDir
+	}
+
 	var loginErr error
 
 	var loginResp *proto.LoginResponse
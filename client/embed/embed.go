@@ -0,0 +1,287 @@
+// Package embed runs a NetBird peer inside a Go program.
+//
+// The embedded peer uses the userspace WireGuard implementation on top of the gVisor network stack, so it doesn't
+// need any privileges or a TUN device. The NetBird network is only reachable through the Dial and Listen methods of
+// the Client, the network configuration of the host stays untouched.
+package embed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+	wgnetstack "golang.zx2c4.com/wireguard/tun/netstack"
+
+	"github.com/netbirdio/netbird/client/internal"
+	"github.com/netbirdio/netbird/client/internal/peer"
+	"github.com/netbirdio/netbird/client/system"
+	"github.com/netbirdio/netbird/iface/netstack"
+)
+
+// embeddedIfaceName is the name of the WireGuard interface of embedded peers. It never shows up on the host
+const embeddedIfaceName = "wt-embed"
+
+var (
+	// ErrClientNotStarted is returned when the client is used before it has been started
+	ErrClientNotStarted = errors.New("client not started")
+	// ErrClientAlreadyStarted is returned when starting a client that is running already
+	ErrClientAlreadyStarted = errors.New("client already started")
+)
+
+// Options configure an embedded NetBird peer
+type Options struct {
+	// DeviceName is the name the peer is registered with. Defaults to the hostname
+	DeviceName string
+	// SetupKey registers the peer with the Management service. It is only needed until the peer has been registered
+	SetupKey string
+	// ManagementURL of the Management service. Defaults to the NetBird cloud
+	ManagementURL string
+	// PrivateKey is the WireGuard private key that identifies the peer.
+	// A new key, and thus a new peer, is generated on every start if neither the key nor ConfigPath is set
+	PrivateKey string
+	// PreSharedKey is the optional WireGuard pre-shared key of the network
+	PreSharedKey string
+	// ConfigPath is the file the peer configuration, including the generated keys, is stored in so that the peer
+	// keeps its identity across restarts. The configuration is only kept in memory if empty
+	ConfigPath string
+	// WireguardPort is the UDP port WireGuard listens on. A free port is picked if 0
+	WireguardPort int
+}
+
+// Client is an embedded NetBird peer
+type Client struct {
+	config     *internal.Config
+	deviceName string
+	setupKey   string
+	recorder   *peer.Status
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan error
+
+	// the engine is set while Start holds mu, so it is guarded by a lock of its own
+	engineMu sync.Mutex
+	engine   *internal.Engine
+}
+
+// New returns a new embedded NetBird peer. The peer connects to the network once it has been started
+func New(opts Options) (*Client, error) {
+	input := internal.ConfigInput{
+		ManagementURL: opts.ManagementURL,
+		ConfigPath:    opts.ConfigPath,
+	}
+	if opts.PreSharedKey != "" {
+		input.PreSharedKey = &opts.PreSharedKey
+	}
+
+	config, err := internal.UpdateOrCreateConfig(input)
+	if err != nil {
+		return nil, fmt.Errorf("failed creating the client config: %w", err)
+	}
+
+	if opts.PrivateKey != "" {
+		config.PrivateKey = opts.PrivateKey
+	}
+
+	config.WgIface = embeddedIfaceName
+	config.WgPort = opts.WireguardPort
+	if config.WgPort == 0 {
+		config.WgPort, err = freeUDPPort()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &Client{
+		config:     config,
+		deviceName: opts.DeviceName,
+		setupKey:   opts.SetupKey,
+		recorder:   peer.NewRecorder(config.ManagementURL.String()),
+	}, nil
+}
+
+// Start registers or logs in the peer and connects it to the NetBird network.
+// It blocks until the peer is connected to the Management and Signal services or the context is done
+func (c *Client) Start(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.cancel != nil {
+		return ErrClientAlreadyStarted
+	}
+
+	err := enableNetstackMode()
+	if err != nil {
+		return err
+	}
+
+	loginCtx := system.WithDeviceName(ctx, c.deviceName)
+	err = internal.Login(loginCtx, c.config, c.setupKey, "")
+	if err != nil {
+		return fmt.Errorf("login failed: %w", err)
+	}
+
+	// the client keeps running after Start returns, so it can't inherit the cancellation of the given context
+	runCtx, cancel := context.WithCancel(internal.CtxInitState(system.WithDeviceName(context.Background(), c.deviceName)))
+	started := make(chan struct{})
+	done := make(chan error, 1)
+	var startedOnce sync.Once
+
+	go func() {
+		done <- internal.RunClientWithEngineListener(runCtx, c.config, c.recorder, func(engine *internal.Engine) {
+			c.setEngine(engine)
+			if engine != nil {
+				startedOnce.Do(func() {
+					close(started)
+				})
+			}
+		})
+	}()
+
+	select {
+	case <-started:
+	case err = <-done:
+		cancel()
+		if err == nil {
+			err = errors.New("client stopped before it was connected")
+		}
+		return err
+	case <-ctx.Done():
+		cancel()
+		<-done
+		return ctx.Err()
+	}
+
+	c.cancel = cancel
+	c.done = done
+	return nil
+}
+
+// Stop disconnects the peer from the NetBird network. It blocks until the peer is stopped or the context is done
+func (c *Client) Stop(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.cancel == nil {
+		return ErrClientNotStarted
+	}
+
+	c.cancel()
+	c.cancel = nil
+
+	select {
+	case err := <-c.done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Dial connects to the address on the NetBird network. Peers can be addressed by their NetBird domain names
+func (c *Client) Dial(network, address string) (net.Conn, error) {
+	return c.DialContext(context.Background(), network, address)
+}
+
+// DialContext connects to the address on the NetBird network using the provided context.
+// Peers can be addressed by their NetBird domain names
+func (c *Client) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	tunNet, err := c.netstack()
+	if err != nil {
+		return nil, err
+	}
+
+	return netstack.NewResolvingDialer(tunNet, c.recorder.GetPeerIPByFQDN).DialContext(ctx, network, address)
+}
+
+// Listen announces on the NetBird network, only TCP networks are supported.
+// An empty host listens on the NetBird address of the peer
+func (c *Client) Listen(network, address string) (net.Listener, error) {
+	switch network {
+	case "tcp", "tcp4", "tcp6":
+	default:
+		return nil, fmt.Errorf("listening on network %s is not supported", network)
+	}
+
+	tunNet, err := c.netstack()
+	if err != nil {
+		return nil, err
+	}
+
+	host, portStr, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid port %s: %w", portStr, err)
+	}
+
+	addr := &net.TCPAddr{Port: port}
+	if host != "" {
+		addr.IP = net.ParseIP(host)
+		if addr.IP == nil {
+			return nil, fmt.Errorf("invalid IP address %s", host)
+		}
+	}
+
+	return tunNet.ListenTCP(addr)
+}
+
+func (c *Client) setEngine(engine *internal.Engine) {
+	c.engineMu.Lock()
+	defer c.engineMu.Unlock()
+	c.engine = engine
+}
+
+func (c *Client) netstack() (*wgnetstack.Net, error) {
+	c.engineMu.Lock()
+	engine := c.engine
+	c.engineMu.Unlock()
+
+	if engine == nil {
+		return nil, ErrClientNotStarted
+	}
+
+	tunNet := engine.Netstack()
+	if tunNet == nil {
+		return nil, ErrClientNotStarted
+	}
+	return tunNet, nil
+}
+
+// enableNetstackMode makes the client run on the userspace network stack. The SOCKS5 proxy of netstack mode is
+// disabled unless it has been configured explicitly, embedded clients reach the network through the Client
+func enableNetstackMode() error {
+	err := os.Setenv(netstack.EnvUseNetstackMode, "true")
+	if err != nil {
+		return err
+	}
+
+	if _, ok := os.LookupEnv(netstack.EnvSOCKS5ListenAddr); !ok {
+		err = os.Setenv(netstack.EnvSOCKS5ListenAddr, "")
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// freeUDPPort returns a UDP port that is currently not in use
+func freeUDPPort() (int, error) {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{})
+	if err != nil {
+		return 0, fmt.Errorf("failed finding a free WireGuard port: %w", err)
+	}
+	defer func() {
+		if err := conn.Close(); err != nil {
+			log.Debugf("failed closing UDP port probe: %v", err)
+		}
+	}()
+
+	return conn.LocalAddr().(*net.UDPAddr).Port, nil
+}
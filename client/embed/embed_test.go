@@ -0,0 +1,39 @@
+package embed
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	client, err := New(Options{DeviceName: "embedded", SetupKey: "setupKey"})
+	require.NoError(t, err)
+
+	assert.NotEmpty(t, client.config.PrivateKey, "a private key should be generated")
+	assert.Equal(t, embeddedIfaceName, client.config.WgIface)
+	assert.NotZero(t, client.config.WgPort, "a free WireGuard port should be picked")
+
+	client, err = New(Options{PrivateKey: "privateKey", WireguardPort: 51830})
+	require.NoError(t, err)
+	assert.Equal(t, "privateKey", client.config.PrivateKey)
+	assert.Equal(t, 51830, client.config.WgPort)
+}
+
+func TestClient_NotStarted(t *testing.T) {
+	client, err := New(Options{})
+	require.NoError(t, err)
+
+	_, err = client.Dial("tcp", "100.64.0.1:80")
+	assert.ErrorIs(t, err, ErrClientNotStarted)
+
+	_, err = client.Listen("tcp", ":80")
+	assert.ErrorIs(t, err, ErrClientNotStarted)
+
+	_, err = client.Listen("udp", ":80")
+	assert.Error(t, err, "listening on UDP isn't supported")
+
+	assert.ErrorIs(t, client.Stop(context.Background()), ErrClientNotStarted)
+}
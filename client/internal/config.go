@@ -37,6 +37,7 @@ type ConfigInput struct {
 	PreSharedKey     *string
 	NATExternalIPs   []string
 	CustomDNSAddress []byte
+	SSHRecordingsDir *string
 }
 
 // Config Configuration type
@@ -71,6 +72,9 @@ type Config struct {
 	NATExternalIPs []string
 	// CustomDNSAddress sets the DNS resolver listening address in format ip:port
 	CustomDNSAddress string
+	// SSHRecordingsDir is the directory sessions of the embedded SSH server are recorded to.
+	// Recording is disabled when empty
+	SSHRecordingsDir string
 }
 
 // UpdateConfig update existing configuration according to input configuration and return with the configuration
@@ -95,7 +99,7 @@ func UpdateOrCreateConfig(input ConfigInput) (*Config, error) {
 	return update(input)
 }
 
-// createNewConfig creates a new config generating a new Wireguard key and saving to file
+// createNewConfig creates a new config generating a new Wireguard key and saving to file, unless the config path is empty
 func createNewConfig(input ConfigInput) (*Config, error) {
 	wgKey := generateKey()
 	pem, err := ssh.GeneratePrivateKey(ssh.ED25519)
@@ -113,6 +117,10 @@ func createNewConfig(input ConfigInput) (*Config, error) {
 		CustomDNSAddress:     string(input.CustomDNSAddress),
 	}
 
+	if input.SSHRecordingsDir != nil {
+		config.SSHRecordingsDir = *input.SSHRecordingsDir
+	}
+
 	defaultManagementURL, err := parseURL("Management URL", DefaultManagementURL)
 	if err != nil {
 		return nil, err
@@ -147,6 +155,11 @@ func createNewConfig(input ConfigInput) (*Config, error) {
 
 	config.IFaceBlackList = defaultInterfaceBlacklist
 
+	// configs of embedded clients might only be kept in memory
+	if input.ConfigPath == "" {
+		return config, nil
+	}
+
 	err = util.WriteJson(input.ConfigPath, config)
 	if err != nil {
 		return nil, err
@@ -216,6 +229,13 @@ func update(input ConfigInput) (*Config, error) {
 		refresh = true
 	}
 
+	if input.SSHRecordingsDir != nil && config.SSHRecordingsDir != *input.SSHRecordingsDir {
+		log.Infof("new SSH recordings directory provided, updated to %s (old value %s)",
+			*input.SSHRecordingsDir, config.SSHRecordingsDir)
+		config.SSHRecordingsDir = *input.SSHRecordingsDir
+		refresh = true
+	}
+
 	if refresh {
 		// since we have new management URL, we need to update config file
 		if err := util.WriteJson(input.ConfigPath, config); err != nil {
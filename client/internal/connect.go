@@ -16,6 +16,7 @@ import (
 	"github.com/netbirdio/netbird/client/ssh"
 	"github.com/netbirdio/netbird/client/system"
 	"github.com/netbirdio/netbird/iface"
+	"github.com/netbirdio/netbird/iface/netstack"
 	mgm "github.com/netbirdio/netbird/management/client"
 	mgmProto "github.com/netbirdio/netbird/management/proto"
 	signal "github.com/netbirdio/netbird/signal/client"
@@ -23,6 +24,12 @@ import (
 
 // RunClient with main logic.
 func RunClient(ctx context.Context, config *Config, statusRecorder *peer.Status) error {
+	return RunClientWithEngineListener(ctx, config, statusRecorder, nil)
+}
+
+// RunClientWithEngineListener runs the client like RunClient. The optional listener is called with every engine
+// that has been started, and with nil once the engine is being stopped
+func RunClientWithEngineListener(ctx context.Context, config *Config, statusRecorder *peer.Status, engineListener func(engine *Engine)) error {
 	backOff := &backoff.ExponentialBackOff{
 		InitialInterval:     time.Second,
 		RandomizationFactor: 1,
@@ -108,7 +115,7 @@ func RunClient(ctx context.Context, config *Config, statusRecorder *peer.Status)
 		localPeerState := peer.LocalPeerState{
 			IP:              loginResp.GetPeerConfig().GetAddress(),
 			PubKey:          myPrivateKey.PublicKey().String(),
-			KernelInterface: iface.WireguardModuleIsLoaded(),
+			KernelInterface: iface.WireguardModuleIsLoaded() && !netstack.IsEnabled(),
 			FQDN:            loginResp.GetPeerConfig().GetFqdn(),
 		}
 
@@ -160,8 +167,16 @@ func RunClient(ctx context.Context, config *Config, statusRecorder *peer.Status)
 		log.Print("Netbird engine started, my IP is: ", peerConfig.Address)
 		state.Set(StatusConnected)
 
+		if engineListener != nil {
+			engineListener(engine)
+		}
+
 		<-engineCtx.Done()
 
+		if engineListener != nil {
+			engineListener(nil)
+		}
+
 		backOff.Reset()
 
 		err = engine.Stop()
@@ -203,6 +218,7 @@ func createEngineConfig(key wgtypes.Key, config *Config, peerConfig *mgmProto.Pe
 		SSHKey:               []byte(config.SSHKey),
 		NATExternalIPs:       config.NATExternalIPs,
 		CustomDNSAddress:     config.CustomDNSAddress,
+		SSHRecordingsDir:     config.SSHRecordingsDir,
 	}
 
 	if config.PreSharedKey != "" {
@@ -0,0 +1,279 @@
+package dns
+
+import (
+	"container/list"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+const (
+	// defaultCacheSize is the maximum number of responses kept in the cache
+	defaultCacheSize = 4096
+	// maxCacheTTL caps the time a positive response is cached regardless of its records TTL
+	maxCacheTTL = time.Hour
+	// maxNegativeCacheTTL caps the time a negative response is cached (RFC 2308)
+	maxNegativeCacheTTL = 5 * time.Minute
+	// prefetchThreshold is the fraction of the original TTL left when a hit triggers a prefetch
+	prefetchThreshold = 0.1
+	// minPrefetchTTL is the minimum original TTL for a response to be prefetched
+	minPrefetchTTL = 10 * time.Second
+)
+
+// CacheStats contains the counters of the response cache
+type CacheStats struct {
+	Entries int
+	Hits    uint64
+	Misses  uint64
+}
+
+type cacheEntry struct {
+	key       string
+	msg       *dns.Msg
+	storedAt  time.Time
+	expiresAt time.Time
+	ttl       time.Duration
+}
+
+// responseCache is a bounded LRU cache of upstream responses respecting the records TTL.
+// Responses are cached per resolver group, as the groups may resolve the same name differently
+type responseCache struct {
+	mutex       sync.Mutex
+	capacity    int
+	entries     map[string]*list.Element
+	lru         *list.List
+	prefetching map[string]struct{}
+	// generation is increased by every flush, responses of requests started before are not cached
+	generation uint64
+	hits       atomic.Uint64
+	misses     atomic.Uint64
+}
+
+func newResponseCache(capacity int) *responseCache {
+	return &responseCache{
+		capacity:    capacity,
+		entries:     make(map[string]*list.Element),
+		lru:         list.New(),
+		prefetching: make(map[string]struct{}),
+	}
+}
+
+// cacheKey returns the key of the request in the resolver group. The DNSSEC OK and Checking Disabled bits
+// are part of the key, as they change the records and the validation of the response
+func cacheKey(group string, r *dns.Msg) string {
+	q := r.Question[0]
+	do := false
+	if opt := r.IsEdns0(); opt != nil {
+		do = opt.Do()
+	}
+	return fmt.Sprintf("%s/%s/%d/%d/%t/%t", group, strings.ToLower(q.Name), q.Qtype, q.Qclass, do, r.CheckingDisabled)
+}
+
+// get returns a cached response of the resolver group for the request with the TTLs adjusted to the remaining time.
+// The second return value reports if the caller should refresh the entry in the background,
+// in which case prefetchDone must be called once the refresh finishes
+func (c *responseCache) get(group string, r *dns.Msg) (*dns.Msg, bool) {
+	if len(r.Question) != 1 {
+		return nil, false
+	}
+	key := cacheKey(group, r)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		c.misses.Add(1)
+		return nil, false
+	}
+
+	entry := element.Value.(*cacheEntry)
+	now := time.Now()
+	if !now.Before(entry.expiresAt) {
+		c.removeElement(element)
+		c.misses.Add(1)
+		return nil, false
+	}
+
+	c.lru.MoveToFront(element)
+	c.hits.Add(1)
+
+	rm := entry.msg.Copy()
+	rm.Id = r.Id
+	adjustTTL(rm, uint32(now.Sub(entry.storedAt)/time.Second))
+
+	remaining := entry.expiresAt.Sub(now)
+	prefetch := false
+	if entry.ttl >= minPrefetchTTL && float64(remaining) <= float64(entry.ttl)*prefetchThreshold {
+		if _, inProgress := c.prefetching[key]; !inProgress {
+			c.prefetching[key] = struct{}{}
+			prefetch = true
+		}
+	}
+
+	return rm, prefetch
+}
+
+// currentGeneration returns the generation to pass to set and prefetchDone for a request started now
+func (c *responseCache) currentGeneration() uint64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.generation
+}
+
+// set stores the response to the request of the resolver group if it is cacheable and the cache
+// hasn't been flushed since the request started
+func (c *responseCache) set(group string, r, m *dns.Msg, generation uint64) {
+	if len(r.Question) != 1 {
+		return
+	}
+	ttl := cacheTTL(m)
+	if ttl <= 0 {
+		return
+	}
+
+	key := cacheKey(group, r)
+	now := time.Now()
+	entry := &cacheEntry{
+		key:       key,
+		msg:       m.Copy(),
+		storedAt:  now,
+		expiresAt: now.Add(ttl),
+		ttl:       ttl,
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if generation != c.generation {
+		return
+	}
+
+	if element, ok := c.entries[key]; ok {
+		element.Value = entry
+		c.lru.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.lru.PushFront(entry)
+	for c.lru.Len() > c.capacity {
+		c.removeElement(c.lru.Back())
+	}
+}
+
+// prefetchDone releases the prefetch mark of the request set by get, unless the cache has been flushed since
+func (c *responseCache) prefetchDone(group string, r *dns.Msg, generation uint64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if generation == c.generation {
+		delete(c.prefetching, cacheKey(group, r))
+	}
+}
+
+// flush removes all cached responses and prefetch marks. Responses of the requests in flight are not cached
+func (c *responseCache) flush() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.entries = make(map[string]*list.Element)
+	c.lru.Init()
+	c.prefetching = make(map[string]struct{})
+	c.generation++
+}
+
+// stats returns the current counters of the cache
+func (c *responseCache) stats() CacheStats {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return CacheStats{
+		Entries: c.lru.Len(),
+		Hits:    c.hits.Load(),
+		Misses:  c.misses.Load(),
+	}
+}
+
+// removeElement removes an element from the cache. Should be called with the lock held
+func (c *responseCache) removeElement(element *list.Element) {
+	c.lru.Remove(element)
+	delete(c.entries, element.Value.(*cacheEntry).key)
+}
+
+// cacheTTL returns how long the response can be cached, zero means it should not be cached.
+// Positive responses use the lowest TTL of their records, negative responses use the SOA minimum (RFC 2308)
+func cacheTTL(m *dns.Msg) time.Duration {
+	if m.Truncated {
+		return 0
+	}
+
+	switch {
+	case m.Rcode == dns.RcodeSuccess && len(m.Answer) > 0:
+		minTTL, found := minRecordTTL(m)
+		if !found {
+			return 0
+		}
+		return capTTL(time.Duration(minTTL)*time.Second, maxCacheTTL)
+	case m.Rcode == dns.RcodeNameError || m.Rcode == dns.RcodeSuccess:
+		for _, rr := range m.Ns {
+			soa, ok := rr.(*dns.SOA)
+			if !ok {
+				continue
+			}
+			ttl := soa.Hdr.Ttl
+			if soa.Minttl < ttl {
+				ttl = soa.Minttl
+			}
+			return capTTL(time.Duration(ttl)*time.Second, maxNegativeCacheTTL)
+		}
+		return 0
+	default:
+		return 0
+	}
+}
+
+func minRecordTTL(m *dns.Msg) (uint32, bool) {
+	var minTTL uint32
+	found := false
+	for _, section := range [][]dns.RR{m.Answer, m.Ns, m.Extra} {
+		for _, rr := range section {
+			if rr.Header().Rrtype == dns.TypeOPT {
+				continue
+			}
+			if !found || rr.Header().Ttl < minTTL {
+				minTTL = rr.Header().Ttl
+				found = true
+			}
+		}
+	}
+	return minTTL, found
+}
+
+func capTTL(ttl, maxTTL time.Duration) time.Duration {
+	if ttl > maxTTL {
+		return maxTTL
+	}
+	return ttl
+}
+
+// adjustTTL decreases the TTL of all records by the elapsed seconds
+func adjustTTL(m *dns.Msg, elapsed uint32) {
+	for _, section := range [][]dns.RR{m.Answer, m.Ns, m.Extra} {
+		for _, rr := range section {
+			header := rr.Header()
+			if header.Rrtype == dns.TypeOPT {
+				continue
+			}
+			if header.Ttl > elapsed {
+				header.Ttl -= elapsed
+			} else {
+				header.Ttl = 0
+			}
+		}
+	}
+}
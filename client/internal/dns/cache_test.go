@@ -0,0 +1,191 @@
+package dns
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+func newTestResponse(name string, ttl uint32) *dns.Msg {
+	r := new(dns.Msg).SetQuestion(name, dns.TypeA)
+	m := new(dns.Msg).SetReply(r)
+	m.Answer = append(m.Answer, &dns.A{
+		Hdr: dns.RR_Header{Name: name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: ttl},
+		A:   net.IPv4(10, 0, 0, 1),
+	})
+	return m
+}
+
+const testCacheGroup = "group1"
+
+func TestCacheTTL(t *testing.T) {
+	negative := new(dns.Msg).SetQuestion("missing.netbird.io.", dns.TypeA)
+	negative.Rcode = dns.RcodeNameError
+	negative.Ns = append(negative.Ns, &dns.SOA{
+		Hdr:    dns.RR_Header{Name: "netbird.io.", Rrtype: dns.TypeSOA, Class: dns.ClassINET, Ttl: 3600},
+		Minttl: 60,
+	})
+
+	noSOA := new(dns.Msg).SetQuestion("missing.netbird.io.", dns.TypeA)
+	noSOA.Rcode = dns.RcodeNameError
+
+	serverFailure := newTestResponse("netbird.io.", 300)
+	serverFailure.Rcode = dns.RcodeServerFailure
+
+	truncated := newTestResponse("netbird.io.", 300)
+	truncated.Truncated = true
+
+	testCases := []struct {
+		name     string
+		msg      *dns.Msg
+		expected time.Duration
+	}{
+		{name: "Positive Response", msg: newTestResponse("netbird.io.", 300), expected: 300 * time.Second},
+		{name: "Positive Response Capped", msg: newTestResponse("netbird.io.", 86400), expected: maxCacheTTL},
+		{name: "Negative Response Uses SOA Minimum", msg: negative, expected: 60 * time.Second},
+		{name: "Negative Response Without SOA", msg: noSOA, expected: 0},
+		{name: "Server Failure", msg: serverFailure, expected: 0},
+		{name: "Truncated Response", msg: truncated, expected: 0},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if ttl := cacheTTL(testCase.msg); ttl != testCase.expected {
+				t.Errorf("expected ttl %s, got %s", testCase.expected, ttl)
+			}
+		})
+	}
+}
+
+func TestResponseCache_GetSet(t *testing.T) {
+	cache := newResponseCache(defaultCacheSize)
+	request := new(dns.Msg).SetQuestion("NetBird.io.", dns.TypeA)
+
+	if rm, _ := cache.get(testCacheGroup, request); rm != nil {
+		t.Fatal("empty cache should not return a response")
+	}
+
+	cache.set(testCacheGroup, request, newTestResponse("netbird.io.", 300), cache.currentGeneration())
+
+	rm, prefetch := cache.get(testCacheGroup, request)
+	if rm == nil {
+		t.Fatal("expected a cached response")
+	}
+	if rm.Id != request.Id {
+		t.Errorf("expected response id %d, got %d", request.Id, rm.Id)
+	}
+	if prefetch {
+		t.Errorf("fresh response should not be prefetched")
+	}
+
+	stats := cache.stats()
+	if stats.Entries != 1 || stats.Hits != 1 || stats.Misses != 1 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+
+	cache.flush()
+	if rm, _ = cache.get(testCacheGroup, request); rm != nil {
+		t.Errorf("flushed cache should not return a response")
+	}
+}
+
+func TestResponseCache_Eviction(t *testing.T) {
+	cache := newResponseCache(2)
+	set := func(name string) {
+		cache.set(testCacheGroup, new(dns.Msg).SetQuestion(name, dns.TypeA), newTestResponse(name, 300), 0)
+	}
+	set("a.netbird.io.")
+	set("b.netbird.io.")
+
+	// touch a so b becomes the least recently used entry
+	cache.get(testCacheGroup, new(dns.Msg).SetQuestion("a.netbird.io.", dns.TypeA))
+	set("c.netbird.io.")
+
+	if rm, _ := cache.get(testCacheGroup, new(dns.Msg).SetQuestion("b.netbird.io.", dns.TypeA)); rm != nil {
+		t.Errorf("least recently used entry should be evicted")
+	}
+	if rm, _ := cache.get(testCacheGroup, new(dns.Msg).SetQuestion("a.netbird.io.", dns.TypeA)); rm == nil {
+		t.Errorf("recently used entry should be kept")
+	}
+}
+
+func TestResponseCache_ExpiryAndPrefetch(t *testing.T) {
+	cache := newResponseCache(defaultCacheSize)
+	request := new(dns.Msg).SetQuestion("netbird.io.", dns.TypeA)
+	cache.set(testCacheGroup, request, newTestResponse("netbird.io.", 20), 0)
+
+	element := cache.entries[cacheKey(testCacheGroup, request)]
+	entry := element.Value.(*cacheEntry)
+
+	// move the entry close to its expiry
+	entry.storedAt = entry.storedAt.Add(-19 * time.Second)
+	entry.expiresAt = entry.expiresAt.Add(-19 * time.Second)
+
+	rm, prefetch := cache.get(testCacheGroup, request)
+	if rm == nil || !prefetch {
+		t.Fatalf("expected a cached response with prefetch, got %v, %t", rm, prefetch)
+	}
+	if ttl := rm.Answer[0].Header().Ttl; ttl != 1 {
+		t.Errorf("expected the ttl to be decreased to 1, got %d", ttl)
+	}
+	if _, prefetch = cache.get(testCacheGroup, request); prefetch {
+		t.Errorf("prefetch should be triggered only once")
+	}
+	cache.prefetchDone(testCacheGroup, request, 0)
+
+	entry.expiresAt = time.Now()
+	if rm, _ = cache.get(testCacheGroup, request); rm != nil {
+		t.Errorf("expired entry should not be returned")
+	}
+}
+
+func TestResponseCache_Key(t *testing.T) {
+	cache := newResponseCache(defaultCacheSize)
+	request := new(dns.Msg).SetQuestion("netbird.io.", dns.TypeA)
+	cache.set(testCacheGroup, request, newTestResponse("netbird.io.", 300), 0)
+
+	if rm, _ := cache.get("group2", request); rm != nil {
+		t.Errorf("responses should not be shared between resolver groups")
+	}
+
+	dnssec := new(dns.Msg).SetQuestion("netbird.io.", dns.TypeA)
+	dnssec.SetEdns0(dns.DefaultMsgSize, true)
+	if rm, _ := cache.get(testCacheGroup, dnssec); rm != nil {
+		t.Errorf("a DNSSEC OK request should not get a response cached for a plain request")
+	}
+
+	checkingDisabled := new(dns.Msg).SetQuestion("netbird.io.", dns.TypeA)
+	checkingDisabled.CheckingDisabled = true
+	if rm, _ := cache.get(testCacheGroup, checkingDisabled); rm != nil {
+		t.Errorf("a checking disabled request should not get a response cached for a plain request")
+	}
+}
+
+func TestResponseCache_FlushDuringRequest(t *testing.T) {
+	cache := newResponseCache(defaultCacheSize)
+	request := new(dns.Msg).SetQuestion("netbird.io.", dns.TypeA)
+	cache.set(testCacheGroup, request, newTestResponse("netbird.io.", 20), 0)
+
+	entry := cache.entries[cacheKey(testCacheGroup, request)].Value.(*cacheEntry)
+	entry.storedAt = entry.storedAt.Add(-19 * time.Second)
+	entry.expiresAt = entry.expiresAt.Add(-19 * time.Second)
+
+	generation := cache.currentGeneration()
+	if _, prefetch := cache.get(testCacheGroup, request); !prefetch {
+		t.Fatal("expected a prefetch")
+	}
+
+	cache.flush()
+	if len(cache.prefetching) != 0 {
+		t.Errorf("flush should remove the prefetch marks")
+	}
+
+	// the prefetch started before the flush finishes with the old data
+	cache.set(testCacheGroup, request, newTestResponse("netbird.io.", 300), generation)
+	cache.prefetchDone(testCacheGroup, request, generation)
+	if rm, _ := cache.get(testCacheGroup, request); rm != nil {
+		t.Errorf("responses of requests started before a flush should not be cached")
+	}
+}
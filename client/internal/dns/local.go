@@ -2,12 +2,18 @@ package dns
 
 import (
 	"fmt"
+	"strings"
+	"sync"
+
 	"github.com/miekg/dns"
-	nbdns "github.com/netbirdio/netbird/dns"
 	log "github.com/sirupsen/logrus"
-	"sync"
+
+	nbdns "github.com/netbirdio/netbird/dns"
 )
 
+// maxCNAMEChainLength limits how many local CNAME records are followed for a single question
+const maxCNAMEChainLength = 8
+
 type localResolver struct {
 	registeredMap registrationMap
 	records       sync.Map
@@ -21,10 +27,7 @@ func (d *localResolver) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
 	replyMessage.RecursionAvailable = true
 	replyMessage.Rcode = dns.RcodeSuccess
 
-	response := d.lookupRecord(r)
-	if response != nil {
-		replyMessage.Answer = append(replyMessage.Answer, response)
-	}
+	replyMessage.Answer = append(replyMessage.Answer, d.lookupRecords(r.Question[0])...)
 
 	err := w.WriteMsg(replyMessage)
 	if err != nil {
@@ -32,35 +35,72 @@ func (d *localResolver) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
 	}
 }
 
-func (d *localResolver) lookupRecord(r *dns.Msg) dns.RR {
-	question := r.Question[0]
-	record, found := d.records.Load(buildRecordKey(question.Name, question.Qclass, question.Qtype))
+// lookupRecords returns the records matching the question. When the name has a CNAME record and
+// the question is for another type, the CNAME is returned followed by the records of its target if known locally
+func (d *localResolver) lookupRecords(question dns.Question) []dns.RR {
+	name := question.Name
+	var answer []dns.RR
+	for i := 0; i < maxCNAMEChainLength; i++ {
+		records := d.loadRecords(name, question.Qclass, question.Qtype)
+		if len(records) > 0 || question.Qtype == dns.TypeCNAME {
+			return append(answer, records...)
+		}
+
+		cnames := d.loadRecords(name, question.Qclass, dns.TypeCNAME)
+		if len(cnames) == 0 {
+			return answer
+		}
+		answer = append(answer, cnames[0])
+
+		cname, ok := cnames[0].(*dns.CNAME)
+		if !ok {
+			return answer
+		}
+		name = cname.Target
+	}
+	return answer
+}
+
+func (d *localResolver) loadRecords(name string, class, qType uint16) []dns.RR {
+	records, found := d.records.Load(buildRecordKey(name, class, qType))
 	if !found {
 		return nil
 	}
 
-	return record.(dns.RR)
+	stored := records.([]dns.RR)
+	copied := make([]dns.RR, 0, len(stored))
+	for _, record := range stored {
+		copied = append(copied, dns.Copy(record))
+	}
+	return copied
 }
 
-func (d *localResolver) registerRecord(record nbdns.SimpleRecord) error {
-	fullRecord, err := dns.NewRR(record.String())
-	if err != nil {
-		return err
-	}
+// registerRecords replaces the records registered under the key of each given record
+func (d *localResolver) registerRecords(records []nbdns.SimpleRecord) error {
+	grouped := make(map[string][]dns.RR)
+	for _, record := range records {
+		fullRecord, err := dns.NewRR(record.String())
+		if err != nil {
+			return err
+		}
 
-	fullRecord.Header().Rdlength = record.Len()
+		header := fullRecord.Header()
+		key := buildRecordKey(header.Name, header.Class, header.Rrtype)
+		grouped[key] = append(grouped[key], fullRecord)
+	}
 
-	header := fullRecord.Header()
-	d.records.Store(buildRecordKey(header.Name, header.Class, header.Rrtype), fullRecord)
+	for key, rrs := range grouped {
+		d.records.Store(key, rrs)
+	}
 
 	return nil
 }
 
 func (d *localResolver) deleteRecord(recordKey string) {
-	d.records.Delete(dns.Fqdn(recordKey))
+	d.records.Delete(recordKey)
 }
 
 func buildRecordKey(name string, class, qType uint16) string {
-	key := fmt.Sprintf("%s_%d_%d", name, class, qType)
+	key := fmt.Sprintf("%s_%d_%d", strings.ToLower(dns.Fqdn(name)), class, qType)
 	return key
 }
@@ -53,7 +53,7 @@ func TestLocalResolver_ServeDNS(t *testing.T) {
 			resolver := &localResolver{
 				registeredMap: make(registrationMap),
 			}
-			_ = resolver.registerRecord(testCase.inputRecord)
+			_ = resolver.registerRecords([]nbdns.SimpleRecord{testCase.inputRecord})
 			var responseMSG *dns.Msg
 			responseWriter := &mockResponseWriter{
 				WriteMsgFunc: func(m *dns.Msg) error {
@@ -84,3 +84,82 @@ func TestLocalResolver_ServeDNS(t *testing.T) {
 		})
 	}
 }
+
+func TestLocalResolver_ServeDNSRecordSets(t *testing.T) {
+	records := []nbdns.SimpleRecord{
+		{Name: "multi.netbird.cloud.", Type: int(dns.TypeA), Class: nbdns.DefaultClass, TTL: 300, RData: "10.0.0.1"},
+		{Name: "multi.netbird.cloud.", Type: int(dns.TypeA), Class: nbdns.DefaultClass, TTL: 300, RData: "10.0.0.2"},
+		{Name: "txt.netbird.cloud.", Type: int(dns.TypeTXT), Class: nbdns.DefaultClass, TTL: 300, RData: "\"v=spf1 -all\""},
+		{Name: "_ssh._tcp.netbird.cloud.", Type: int(dns.TypeSRV), Class: nbdns.DefaultClass, TTL: 300, RData: "10 5 22 multi.netbird.cloud."},
+		{Name: "alias.netbird.cloud.", Type: int(dns.TypeCNAME), Class: nbdns.DefaultClass, TTL: 300, RData: "multi.netbird.cloud."},
+	}
+
+	testCases := []struct {
+		name            string
+		inputMSG        *dns.Msg
+		expectedAnswers []string
+	}{
+		{
+			name:            "Should Resolve All A Records",
+			inputMSG:        new(dns.Msg).SetQuestion("multi.netbird.cloud.", dns.TypeA),
+			expectedAnswers: []string{"10.0.0.1", "10.0.0.2"},
+		},
+		{
+			name:            "Should Resolve Case Insensitive",
+			inputMSG:        new(dns.Msg).SetQuestion("MULTI.netbird.cloud.", dns.TypeA),
+			expectedAnswers: []string{"10.0.0.1", "10.0.0.2"},
+		},
+		{
+			name:            "Should Resolve TXT Record",
+			inputMSG:        new(dns.Msg).SetQuestion("txt.netbird.cloud.", dns.TypeTXT),
+			expectedAnswers: []string{"v=spf1 -all"},
+		},
+		{
+			name:            "Should Resolve SRV Record",
+			inputMSG:        new(dns.Msg).SetQuestion("_ssh._tcp.netbird.cloud.", dns.TypeSRV),
+			expectedAnswers: []string{"10 5 22 multi.netbird.cloud."},
+		},
+		{
+			name:            "Should Follow CNAME Record",
+			inputMSG:        new(dns.Msg).SetQuestion("alias.netbird.cloud.", dns.TypeA),
+			expectedAnswers: []string{"multi.netbird.cloud.", "10.0.0.1", "10.0.0.2"},
+		},
+	}
+
+	resolver := &localResolver{
+		registeredMap: make(registrationMap),
+	}
+	err := resolver.registerRecords(records)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var responseMSG *dns.Msg
+			responseWriter := &mockResponseWriter{
+				WriteMsgFunc: func(m *dns.Msg) error {
+					responseMSG = m
+					return nil
+				},
+			}
+
+			resolver.ServeDNS(responseWriter, testCase.inputMSG)
+
+			if responseMSG == nil || len(responseMSG.Answer) != len(testCase.expectedAnswers) {
+				t.Fatalf("expected %d answers, got %v", len(testCase.expectedAnswers), responseMSG)
+			}
+
+			// packing validates the record data lengths
+			if _, err := responseMSG.Pack(); err != nil {
+				t.Fatal(err)
+			}
+
+			for i, expected := range testCase.expectedAnswers {
+				if !strings.Contains(responseMSG.Answer[i].String(), expected) {
+					t.Errorf("answer %s doesn't contain %s", responseMSG.Answer[i].String(), expected)
+				}
+			}
+		})
+	}
+}
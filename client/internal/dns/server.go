@@ -11,6 +11,7 @@ import (
 
 	"github.com/miekg/dns"
 	"github.com/mitchellh/hashstructure/v2"
+	"github.com/netbirdio/netbird/client/internal/telemetry"
 	nbdns "github.com/netbirdio/netbird/dns"
 	"github.com/netbirdio/netbird/iface"
 	log "github.com/sirupsen/logrus"
@@ -49,6 +50,8 @@ type DefaultServer struct {
 	previousConfigHash uint64
 	currentConfig      hostDNSConfig
 	customAddress      *netip.AddrPort
+	cache              *responseCache
+	metrics            *telemetry.ClientMetrics
 }
 
 type registrationMap map[string]struct{}
@@ -92,6 +95,7 @@ func NewDefaultServer(ctx context.Context, wgInterface *iface.WGIface, customAdd
 		wgInterface:   wgInterface,
 		runtimePort:   defaultPort,
 		customAddress: addrPort,
+		cache:         newResponseCache(defaultCacheSize),
 	}
 
 	hostmanager, err := newHostManager(wgInterface)
@@ -103,6 +107,13 @@ func NewDefaultServer(ctx context.Context, wgInterface *iface.WGIface, customAdd
 	return defaultServer, err
 }
 
+// SetMetrics sets the metrics the upstream resolvers report failed queries to
+func (s *DefaultServer) SetMetrics(metrics *telemetry.ClientMetrics) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.metrics = metrics
+}
+
 // Start runs the listener in a go routine
 func (s *DefaultServer) Start() {
 	if s.customAddress != nil {
@@ -207,6 +218,11 @@ func (s *DefaultServer) UpdateDNSServer(serial uint64, update nbdns.Config) erro
 		s.mux.Lock()
 		defer s.mux.Unlock()
 
+		// cached responses may be stale after any network change
+		if serial != s.updateSerial {
+			s.cache.flush()
+		}
+
 		hash, err := hashstructure.Hash(update, hashstructure.FormatV2, &hashstructure.HashOptions{
 			ZeroNil:         true,
 			IgnoreZeroValue: true,
@@ -268,9 +284,9 @@ func (s *DefaultServer) applyConfiguration(update nbdns.Config) error {
 	return nil
 }
 
-func (s *DefaultServer) buildLocalHandlerUpdate(customZones []nbdns.CustomZone) ([]muxUpdate, map[string]nbdns.SimpleRecord, error) {
+func (s *DefaultServer) buildLocalHandlerUpdate(customZones []nbdns.CustomZone) ([]muxUpdate, map[string][]nbdns.SimpleRecord, error) {
 	var muxUpdates []muxUpdate
-	localRecords := make(map[string]nbdns.SimpleRecord, 0)
+	localRecords := make(map[string][]nbdns.SimpleRecord, 0)
 
 	for _, customZone := range customZones {
 
@@ -289,7 +305,7 @@ func (s *DefaultServer) buildLocalHandlerUpdate(customZones []nbdns.CustomZone)
 				return nil, nil, fmt.Errorf("received an invalid class type: %s", record.Class)
 			}
 			key := buildRecordKey(record.Name, class, uint16(record.Type))
-			localRecords[key] = record
+			localRecords[key] = append(localRecords[key], record)
 		}
 	}
 	return muxUpdates, localRecords, nil
@@ -312,13 +328,14 @@ func (s *DefaultServer) buildUpstreamHandlerUpdate(nameServerGroups []*nbdns.Nam
 		ctx, s.upstreamCtxCancel = context.WithCancel(s.ctx)
 
 		handler := newUpstreamResolver(ctx)
+		handler.cache = s.cache
+		handler.cacheGroup = nsGroup.ID
+		handler.metrics = s.metrics
 		for _, ns := range nsGroup.NameServers {
-			if ns.NSType != nbdns.UDPNameServerType {
-				log.Warnf("skiping nameserver %s with type %s, this peer supports only %s",
-					ns.IP.String(), ns.NSType.String(), nbdns.UDPNameServerType.String())
+			if err := handler.addUpstream(ns); err != nil {
+				log.Warnf("skipping nameserver %s with type %s: %v", ns.IP.String(), ns.NSType.String(), err)
 				continue
 			}
-			handler.upstreamServers = append(handler.upstreamServers, getNSHostPort(ns))
 		}
 
 		if len(handler.upstreamServers) == 0 {
@@ -379,7 +396,7 @@ func (s *DefaultServer) updateMux(muxUpdates []muxUpdate) {
 	s.dnsMuxMap = muxUpdateMap
 }
 
-func (s *DefaultServer) updateLocalResolver(update map[string]nbdns.SimpleRecord) {
+func (s *DefaultServer) updateLocalResolver(update map[string][]nbdns.SimpleRecord) {
 	for key := range s.localResolver.registeredMap {
 		_, found := update[key]
 		if !found {
@@ -388,10 +405,10 @@ func (s *DefaultServer) updateLocalResolver(update map[string]nbdns.SimpleRecord
 	}
 
 	updatedMap := make(registrationMap)
-	for key, record := range update {
-		err := s.localResolver.registerRecord(record)
+	for key, records := range update {
+		err := s.localResolver.registerRecords(records)
 		if err != nil {
-			log.Warnf("got an error while registering the record (%s), error: %v", record.String(), err)
+			log.Warnf("got an error while registering the records of %s, error: %v", key, err)
 		}
 		updatedMap[key] = struct{}{}
 	}
@@ -399,6 +416,11 @@ func (s *DefaultServer) updateLocalResolver(update map[string]nbdns.SimpleRecord
 	s.localResolver.registeredMap = updatedMap
 }
 
+// CacheStats returns the counters of the DNS response cache
+func (s *DefaultServer) CacheStats() CacheStats {
+	return s.cache.stats()
+}
+
 func getNSHostPort(ns nbdns.NameServer) string {
 	return fmt.Sprintf("%s:%d", ns.IP.String(), ns.Port)
 }
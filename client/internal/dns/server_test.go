@@ -288,7 +288,7 @@ func TestDNSServerStartStop(t *testing.T) {
 				t.Fatal("dns server listener is not running")
 			}
 			defer dnsServer.Stop()
-			err := dnsServer.localResolver.registerRecord(zoneRecords[0])
+			err := dnsServer.localResolver.registerRecords([]nbdns.SimpleRecord{zoneRecords[0]})
 			if err != nil {
 				t.Error(err)
 			}
@@ -429,5 +429,6 @@ func getDefaultServerWithNoHostManager(t *testing.T, addrPort string) *DefaultSe
 			registeredMap: make(registrationMap),
 		},
 		customAddress: parsedAddrPort,
+		cache:         newResponseCache(defaultCacheSize),
 	}
 }
@@ -10,24 +10,36 @@ import (
 
 	"github.com/miekg/dns"
 	log "github.com/sirupsen/logrus"
+
+	"github.com/netbirdio/netbird/client/internal/telemetry"
+	nbdns "github.com/netbirdio/netbird/dns"
 )
 
 const (
 	failsTillDeact   = int32(3)
 	reactivatePeriod = time.Minute
 	upstreamTimeout  = 15 * time.Second
+	// raceCount is the number of best ranked upstreams queried in parallel
+	raceCount = 2
 )
 
 type upstreamResolver struct {
 	ctx              context.Context
-	upstreamClient   *dns.Client
+	upstreamClient   upstreamClient
 	upstreamServers  []string
+	upstreamClients  map[string]upstreamClient
 	disabled         bool
 	failsCount       atomic.Int32
 	failsTillDeact   int32
 	mutex            sync.Mutex
 	reactivatePeriod time.Duration
 	upstreamTimeout  time.Duration
+	raceCount        int
+	health           *upstreamHealth
+	cache            *responseCache
+	// cacheGroup is the nameserver group the responses are cached for
+	cacheGroup string
+	metrics    *telemetry.ClientMetrics
 
 	deactivate func()
 	reactivate func()
@@ -37,13 +49,38 @@ func newUpstreamResolver(ctx context.Context) *upstreamResolver {
 	return &upstreamResolver{
 		ctx:              ctx,
 		upstreamClient:   &dns.Client{},
+		upstreamClients:  make(map[string]upstreamClient),
 		upstreamTimeout:  upstreamTimeout,
 		reactivatePeriod: reactivatePeriod,
 		failsTillDeact:   failsTillDeact,
+		raceCount:        raceCount,
+		health:           newUpstreamHealth(),
+	}
+}
+
+// addUpstream adds a nameserver to the resolver using the transport of its type
+func (u *upstreamResolver) addUpstream(ns nbdns.NameServer) error {
+	client, err := newUpstreamClient(ns)
+	if err != nil {
+		return err
 	}
+	address := getNSHostPort(ns)
+	u.upstreamServers = append(u.upstreamServers, address)
+	u.upstreamClients[address] = client
+	return nil
+}
+
+// getUpstreamClient returns the client of the upstream, falling back to the default plain DNS client
+func (u *upstreamResolver) getUpstreamClient(upstream string) upstreamClient {
+	if client, ok := u.upstreamClients[upstream]; ok {
+		return client
+	}
+	return u.upstreamClient
 }
 
 // ServeDNS handles a DNS request
+//
+// Cached responses are served directly, otherwise the upstreams are queried with resolve
 func (u *upstreamResolver) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
 	defer u.checkUpstreamFails()
 
@@ -55,36 +92,137 @@ func (u *upstreamResolver) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
 	default:
 	}
 
-	for _, upstream := range u.upstreamServers {
-		ctx, cancel := context.WithTimeout(u.ctx, u.upstreamTimeout)
-		rm, t, err := u.upstreamClient.ExchangeContext(ctx, r, upstream)
-
-		cancel()
-
-		if err != nil {
-			if err == context.DeadlineExceeded || isTimeout(err) {
-				log.WithError(err).WithField("upstream", upstream).
-					Warn("got an error while connecting to upstream")
-				continue
+	var cacheGeneration uint64
+	if u.cache != nil {
+		cacheGeneration = u.cache.currentGeneration()
+		if rm, prefetch := u.cache.get(u.cacheGroup, r); rm != nil {
+			if prefetch {
+				go u.prefetch(r.Copy(), cacheGeneration)
+			}
+			err := w.WriteMsg(rm)
+			if err != nil {
+				log.WithError(err).Error("got an error while writing the cached response")
 			}
-			u.failsCount.Add(1)
-			log.WithError(err).WithField("upstream", upstream).
-				Error("got an error while querying the upstream")
 			return
 		}
+	}
 
-		log.Tracef("took %s to query the upstream %s", t, upstream)
+	rm, err := u.resolve(r)
+	if err != nil {
+		if u.ctx.Err() != nil {
+			return
+		}
+		u.failsCount.Add(1)
+		u.metrics.CountDNSUpstreamFailure()
+		log.Error("all queries to the upstream nameservers failed")
+		return
+	}
+
+	if u.cache != nil {
+		u.cache.set(u.cacheGroup, r, rm, cacheGeneration)
+	}
+
+	err = w.WriteMsg(rm)
+	if err != nil {
+		log.WithError(err).Error("got an error while writing the upstream resolver response")
+	}
+	// count the fails only if they happen sequentially
+	u.failsCount.Store(0)
+}
 
-		err = w.WriteMsg(rm)
+// resolve ranks the upstreams by their health and queries them in batches of raceCount servers at once,
+// returning the first successful answer
+func (u *upstreamResolver) resolve(r *dns.Msg) (*dns.Msg, error) {
+	upstreams := u.health.rank(u.upstreamServers)
+	var lastErr error
+	for len(upstreams) > 0 {
+		batchSize := u.raceCount
+		if batchSize <= 0 || batchSize > len(upstreams) {
+			batchSize = len(upstreams)
+		}
+		batch := upstreams[:batchSize]
+		upstreams = upstreams[batchSize:]
+
+		rm, upstream, err := u.raceUpstreams(r, batch)
 		if err != nil {
-			log.WithError(err).Error("got an error while writing the upstream resolver response")
+			if u.ctx.Err() != nil {
+				return nil, u.ctx.Err()
+			}
+			log.WithError(err).WithField("upstreams", batch).
+				Warn("got an error while querying the upstreams")
+			lastErr = err
+			continue
 		}
-		// count the fails only if they happen sequentially
-		u.failsCount.Store(0)
+
+		log.Tracef("upstream %s won the race for %s", upstream, r.Question[0].Name)
+		return rm, nil
+	}
+	if lastErr == nil {
+		lastErr = errors.New("no upstream nameservers")
+	}
+	return nil, lastErr
+}
+
+// prefetch refreshes a cached response that is about to expire
+func (u *upstreamResolver) prefetch(r *dns.Msg, cacheGeneration uint64) {
+	defer u.cache.prefetchDone(u.cacheGroup, r, cacheGeneration)
+
+	rm, err := u.resolve(r)
+	if err != nil {
+		log.WithError(err).Debugf("failed to prefetch %s", r.Question[0].Name)
 		return
 	}
-	u.failsCount.Add(1)
-	log.Error("all queries to the upstream nameservers failed with timeout")
+	u.cache.set(u.cacheGroup, r, rm, cacheGeneration)
+}
+
+type upstreamResult struct {
+	msg      *dns.Msg
+	upstream string
+	err      error
+}
+
+// raceUpstreams queries all the given upstreams in parallel and returns the first successful response
+func (u *upstreamResolver) raceUpstreams(r *dns.Msg, upstreams []string) (*dns.Msg, string, error) {
+	ctx, cancel := context.WithTimeout(u.ctx, u.upstreamTimeout)
+	defer cancel()
+
+	results := make(chan upstreamResult, len(upstreams))
+	for _, upstream := range upstreams {
+		go func(upstream string) {
+			start := time.Now()
+			rm, rtt, err := u.getUpstreamClient(upstream).ExchangeContext(ctx, r.Copy(), upstream)
+			switch {
+			case err == nil:
+				u.health.recordSuccess(upstream, rtt)
+				log.Tracef("took %s to query the upstream %s", rtt, upstream)
+			case u.ctx.Err() != nil:
+				// the resolver is shutting down
+			case errors.Is(ctx.Err(), context.Canceled):
+				// another upstream won the race, this one was at least as slow as the time spent
+				u.health.recordLatency(upstream, time.Since(start))
+			default:
+				u.health.recordFailure(upstream)
+			}
+			results <- upstreamResult{msg: rm, upstream: upstream, err: err}
+		}(upstream)
+	}
+
+	var lastErr error
+	for range upstreams {
+		res := <-results
+		if res.err == nil {
+			return res.msg, res.upstream, nil
+		}
+		lastErr = res.err
+		if res.err == context.DeadlineExceeded || isTimeout(res.err) {
+			log.WithError(res.err).WithField("upstream", res.upstream).
+				Warn("got an error while connecting to upstream")
+			continue
+		}
+		log.WithError(res.err).WithField("upstream", res.upstream).
+			Error("got an error while querying the upstream")
+	}
+	return nil, "", lastErr
 }
 
 // checkUpstreamFails counts fails and disables or enables upstream resolving
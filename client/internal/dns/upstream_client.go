@@ -0,0 +1,129 @@
+package dns
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/miekg/dns"
+
+	nbdns "github.com/netbirdio/netbird/dns"
+)
+
+const (
+	dohPath        = "/dns-query"
+	dohContentType = "application/dns-message"
+	// dohMaxResponseSize is the maximum size of a DNS message
+	dohMaxResponseSize = 65535
+)
+
+// upstreamClient exchanges DNS messages with an upstream nameserver
+type upstreamClient interface {
+	ExchangeContext(ctx context.Context, m *dns.Msg, address string) (*dns.Msg, time.Duration, error)
+}
+
+// newUpstreamClient returns an upstreamClient using the transport of the nameserver type
+func newUpstreamClient(ns nbdns.NameServer) (upstreamClient, error) {
+	switch ns.NSType {
+	case nbdns.UDPNameServerType:
+		return &dns.Client{Net: "udp"}, nil
+	case nbdns.TCPNameServerType:
+		return &dns.Client{Net: "tcp"}, nil
+	case nbdns.DoTNameServerType:
+		return &dns.Client{Net: "tcp-tls", TLSConfig: newUpstreamTLSConfig(ns)}, nil
+	case nbdns.DoHNameServerType:
+		return newDoHClient(ns), nil
+	default:
+		return nil, fmt.Errorf("unsupported nameserver type %s", ns.NSType.String())
+	}
+}
+
+// newUpstreamTLSConfig returns a TLS config verifying the nameserver certificate against its hostname,
+// or against its IP if no hostname has been provided
+func newUpstreamTLSConfig(ns nbdns.NameServer) *tls.Config {
+	serverName := ns.Hostname
+	if serverName == "" {
+		serverName = ns.IP.String()
+	}
+	return &tls.Config{
+		ServerName: serverName,
+		MinVersion: tls.VersionTLS12,
+	}
+}
+
+// dohClient is a DNS-over-HTTPS (RFC 8484) upstream client
+type dohClient struct {
+	url        string
+	httpClient *http.Client
+}
+
+func newDoHClient(ns nbdns.NameServer) *dohClient {
+	address := getNSHostPort(ns)
+	dialer := &net.Dialer{}
+	transport := &http.Transport{
+		// always dial the nameserver IP to avoid resolving the hostname through ourselves
+		DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, network, address)
+		},
+		TLSClientConfig:   newUpstreamTLSConfig(ns),
+		ForceAttemptHTTP2: true,
+		IdleConnTimeout:   90 * time.Second,
+	}
+
+	host := address
+	if ns.Hostname != "" {
+		host = net.JoinHostPort(ns.Hostname, fmt.Sprint(ns.Port))
+	}
+
+	return &dohClient{
+		url:        fmt.Sprintf("https://%s%s", host, dohPath),
+		httpClient: &http.Client{Transport: transport},
+	}
+}
+
+// ExchangeContext sends the message to the DoH server with a POST request. The address argument is ignored,
+// the client always connects to the nameserver it has been created for
+func (c *dohClient) ExchangeContext(ctx context.Context, m *dns.Msg, _ string) (*dns.Msg, time.Duration, error) {
+	packed, err := m.Pack()
+	if err != nil {
+		return nil, 0, fmt.Errorf("pack dns message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(packed))
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Content-Type", dohContentType)
+	req.Header.Set("Accept", dohContentType)
+
+	start := time.Now()
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("doh server %s returned status %d", c.url, resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, dohMaxResponseSize))
+	if err != nil {
+		return nil, 0, fmt.Errorf("read doh response: %w", err)
+	}
+	rtt := time.Since(start)
+
+	rm := new(dns.Msg)
+	if err = rm.Unpack(body); err != nil {
+		return nil, 0, fmt.Errorf("unpack doh response: %w", err)
+	}
+	// RFC 8484 recommends using ID 0 in requests for caching, restore the original one
+	rm.Id = m.Id
+
+	return rm, rtt, nil
+}
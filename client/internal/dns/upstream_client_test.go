@@ -0,0 +1,144 @@
+package dns
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/miekg/dns"
+
+	nbdns "github.com/netbirdio/netbird/dns"
+)
+
+func TestNewUpstreamClient(t *testing.T) {
+	testCases := []struct {
+		name               string
+		ns                 nbdns.NameServer
+		expectedNet        string
+		expectedServerName string
+		expectedDoH        bool
+		shouldFail         bool
+	}{
+		{
+			name:        "UDP",
+			ns:          nbdns.NameServer{IP: netip.MustParseAddr("1.1.1.1"), NSType: nbdns.UDPNameServerType, Port: 53},
+			expectedNet: "udp",
+		},
+		{
+			name:        "TCP",
+			ns:          nbdns.NameServer{IP: netip.MustParseAddr("1.1.1.1"), NSType: nbdns.TCPNameServerType, Port: 53},
+			expectedNet: "tcp",
+		},
+		{
+			name:               "DoT With Hostname",
+			ns:                 nbdns.NameServer{IP: netip.MustParseAddr("1.1.1.1"), NSType: nbdns.DoTNameServerType, Port: 853, Hostname: "one.one.one.one"},
+			expectedNet:        "tcp-tls",
+			expectedServerName: "one.one.one.one",
+		},
+		{
+			name:               "DoT Without Hostname",
+			ns:                 nbdns.NameServer{IP: netip.MustParseAddr("1.1.1.1"), NSType: nbdns.DoTNameServerType, Port: 853},
+			expectedNet:        "tcp-tls",
+			expectedServerName: "1.1.1.1",
+		},
+		{
+			name:               "DoH",
+			ns:                 nbdns.NameServer{IP: netip.MustParseAddr("1.1.1.1"), NSType: nbdns.DoHNameServerType, Port: 443, Hostname: "cloudflare-dns.com"},
+			expectedServerName: "cloudflare-dns.com",
+			expectedDoH:        true,
+		},
+		{
+			name:       "Invalid Type",
+			ns:         nbdns.NameServer{IP: netip.MustParseAddr("1.1.1.1"), NSType: nbdns.InvalidNameServerType, Port: 53},
+			shouldFail: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			client, err := newUpstreamClient(testCase.ns)
+			if testCase.shouldFail {
+				if err == nil {
+					t.Fatal("should fail")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if testCase.expectedDoH {
+				doh, ok := client.(*dohClient)
+				if !ok {
+					t.Fatalf("expected a DoH client, got %T", client)
+				}
+				if doh.url != "https://cloudflare-dns.com:443/dns-query" {
+					t.Errorf("unexpected DoH url %s", doh.url)
+				}
+				serverName := doh.httpClient.Transport.(*http.Transport).TLSClientConfig.ServerName
+				if serverName != testCase.expectedServerName {
+					t.Errorf("expected server name %s, got %s", testCase.expectedServerName, serverName)
+				}
+				return
+			}
+
+			dnsClient, ok := client.(*dns.Client)
+			if !ok {
+				t.Fatalf("expected a dns client, got %T", client)
+			}
+			if dnsClient.Net != testCase.expectedNet {
+				t.Errorf("expected net %s, got %s", testCase.expectedNet, dnsClient.Net)
+			}
+			if testCase.expectedServerName != "" && dnsClient.TLSConfig.ServerName != testCase.expectedServerName {
+				t.Errorf("expected server name %s, got %s", testCase.expectedServerName, dnsClient.TLSConfig.ServerName)
+			}
+		})
+	}
+}
+
+func TestDoHClient_ExchangeContext(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != dohPath || r.Header.Get("Content-Type") != dohContentType {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		req := new(dns.Msg)
+		if err = req.Unpack(body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		resp := new(dns.Msg)
+		resp.SetReply(req)
+		resp.Id = 0
+		resp.Answer = append(resp.Answer, &dns.A{
+			Hdr: dns.RR_Header{Name: req.Question[0].Name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 60},
+			A:   []byte{10, 0, 0, 1},
+		})
+		packed, _ := resp.Pack()
+		w.Header().Set("Content-Type", dohContentType)
+		_, _ = w.Write(packed)
+	}))
+	defer server.Close()
+
+	client := &dohClient{url: server.URL + dohPath, httpClient: server.Client()}
+
+	req := new(dns.Msg).SetQuestion("netbird.io.", dns.TypeA)
+	resp, _, err := client.ExchangeContext(context.Background(), req, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Id != req.Id {
+		t.Errorf("expected response id %d, got %d", req.Id, resp.Id)
+	}
+	if len(resp.Answer) != 1 || resp.Answer[0].(*dns.A).A.String() != "10.0.0.1" {
+		t.Errorf("unexpected answer %v", resp.Answer)
+	}
+}
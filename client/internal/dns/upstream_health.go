@@ -0,0 +1,124 @@
+package dns
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// ewmaAlpha is the weight of the latest latency sample in the moving average
+	ewmaAlpha = 0.3
+	// serverFailsTillDown is the number of sequential fails after which a single upstream is marked down
+	serverFailsTillDown = 3
+	// serverDownPeriod is the time an upstream marked down is ranked behind the healthy ones
+	serverDownPeriod = 30 * time.Second
+)
+
+// serverHealth holds the health state of a single upstream nameserver
+type serverHealth struct {
+	ewmaRTT    time.Duration
+	samples    int
+	failsCount int
+	downUntil  time.Time
+}
+
+func (s *serverHealth) isDown(now time.Time) bool {
+	return now.Before(s.downUntil)
+}
+
+// addSample adds a latency sample to the exponentially weighted moving average
+func (s *serverHealth) addSample(rtt time.Duration) {
+	if s.samples == 0 {
+		s.ewmaRTT = rtt
+	} else {
+		s.ewmaRTT = time.Duration(ewmaAlpha*float64(rtt) + (1-ewmaAlpha)*float64(s.ewmaRTT))
+	}
+	s.samples++
+}
+
+// upstreamHealth tracks latency and failures of the upstream nameservers of a resolver
+type upstreamHealth struct {
+	mutex         sync.Mutex
+	servers       map[string]*serverHealth
+	failsTillDown int
+	downPeriod    time.Duration
+}
+
+func newUpstreamHealth() *upstreamHealth {
+	return &upstreamHealth{
+		servers:       make(map[string]*serverHealth),
+		failsTillDown: serverFailsTillDown,
+		downPeriod:    serverDownPeriod,
+	}
+}
+
+// get returns the health of the upstream creating it if it doesn't exist. Should be called with the lock held
+func (h *upstreamHealth) get(upstream string) *serverHealth {
+	server, ok := h.servers[upstream]
+	if !ok {
+		server = &serverHealth{}
+		h.servers[upstream] = server
+	}
+	return server
+}
+
+// recordSuccess updates the moving average latency of the upstream and marks it as up
+func (h *upstreamHealth) recordSuccess(upstream string, rtt time.Duration) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	server := h.get(upstream)
+	server.addSample(rtt)
+	server.failsCount = 0
+	server.downUntil = time.Time{}
+}
+
+// recordLatency updates the moving average latency of the upstream without changing its up/down state
+func (h *upstreamHealth) recordLatency(upstream string, rtt time.Duration) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.get(upstream).addSample(rtt)
+}
+
+// recordFailure counts a failed query and marks the upstream down when it fails too many times in a row
+func (h *upstreamHealth) recordFailure(upstream string) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	server := h.get(upstream)
+	server.failsCount++
+	if server.failsCount < h.failsTillDown {
+		return
+	}
+
+	now := time.Now()
+	if !server.isDown(now) {
+		log.Warnf("upstream %s failed %d times in a row, marking it down for %s", upstream, server.failsCount, h.downPeriod)
+	}
+	server.downUntil = now.Add(h.downPeriod)
+}
+
+// rank returns the upstreams ordered by health: servers that are up come first sorted by their latency,
+// servers without latency samples are preferred so they get measured. Down servers are kept at the end,
+// so they are still queried when all others fail
+func (h *upstreamHealth) rank(upstreams []string) []string {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	now := time.Now()
+	ranked := make([]string, len(upstreams))
+	copy(ranked, upstreams)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := h.get(ranked[i]), h.get(ranked[j])
+		aDown, bDown := a.isDown(now), b.isDown(now)
+		if aDown != bDown {
+			return !aDown
+		}
+		return a.ewmaRTT < b.ewmaRTT
+	})
+	return ranked
+}
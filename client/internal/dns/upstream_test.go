@@ -2,10 +2,13 @@ package dns
 
 import (
 	"context"
-	"github.com/miekg/dns"
+	"errors"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/miekg/dns"
 )
 
 func TestUpstreamResolver_ServeDNS(t *testing.T) {
@@ -154,3 +157,129 @@ func TestUpstreamResolver_DeactivationReactivation(t *testing.T) {
 		t.Errorf("should be enabled")
 	}
 }
+
+type mockUpstreamClient struct {
+	delay     time.Duration
+	err       error
+	answerTTL uint32
+	calls     atomic.Int32
+}
+
+func (c *mockUpstreamClient) ExchangeContext(ctx context.Context, m *dns.Msg, _ string) (*dns.Msg, time.Duration, error) {
+	c.calls.Add(1)
+	select {
+	case <-ctx.Done():
+		return nil, 0, ctx.Err()
+	case <-time.After(c.delay):
+	}
+	if c.err != nil {
+		return nil, 0, c.err
+	}
+	rm := new(dns.Msg)
+	rm.SetReply(m)
+	if c.answerTTL > 0 {
+		rm.Answer = append(rm.Answer, &dns.A{
+			Hdr: dns.RR_Header{Name: m.Question[0].Name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: c.answerTTL},
+			A:   []byte{10, 0, 0, 1},
+		})
+	}
+	return rm, c.delay, nil
+}
+
+func TestUpstreamResolver_RacesBestUpstreams(t *testing.T) {
+	resolver := newUpstreamResolver(context.TODO())
+	slow := &mockUpstreamClient{delay: time.Second}
+	fast := &mockUpstreamClient{delay: 10 * time.Millisecond}
+	resolver.upstreamServers = []string{"10.0.0.1:53", "10.0.0.2:53"}
+	resolver.upstreamClients["10.0.0.1:53"] = slow
+	resolver.upstreamClients["10.0.0.2:53"] = fast
+
+	var responseMSG *dns.Msg
+	responseWriter := &mockResponseWriter{
+		WriteMsgFunc: func(m *dns.Msg) error {
+			responseMSG = m
+			return nil
+		},
+	}
+
+	start := time.Now()
+	resolver.ServeDNS(responseWriter, new(dns.Msg).SetQuestion("netbird.io.", dns.TypeA))
+	if took := time.Since(start); took >= slow.delay {
+		t.Errorf("expected the fast upstream to win the race, took %s", took)
+	}
+	if responseMSG == nil {
+		t.Fatal("should write a response message")
+	}
+	if slow.calls.Load() != 1 || fast.calls.Load() != 1 {
+		t.Errorf("expected both upstreams to be queried in parallel")
+	}
+
+	// the canceled upstream records its latency in the background
+	var ranked []string
+	for i := 0; i < 100; i++ {
+		ranked = resolver.health.rank(resolver.upstreamServers)
+		if ranked[0] == "10.0.0.2:53" {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Errorf("expected the fast upstream to be ranked first, got %v", ranked)
+}
+
+func TestUpstreamResolver_MarksServerDown(t *testing.T) {
+	resolver := newUpstreamResolver(context.TODO())
+	resolver.raceCount = 1
+	broken := &mockUpstreamClient{err: errors.New("connection refused")}
+	healthy := &mockUpstreamClient{}
+	resolver.upstreamServers = []string{"10.0.0.1:53", "10.0.0.2:53"}
+	resolver.upstreamClients["10.0.0.1:53"] = broken
+	resolver.upstreamClients["10.0.0.2:53"] = healthy
+
+	for i := 0; i < serverFailsTillDown; i++ {
+		resolver.ServeDNS(&mockResponseWriter{}, new(dns.Msg).SetQuestion("netbird.io.", dns.TypeA))
+	}
+
+	if resolver.failsCount.Load() != 0 {
+		t.Errorf("group fails count should stay 0 while a single upstream fails, got %d", resolver.failsCount.Load())
+	}
+
+	ranked := resolver.health.rank(resolver.upstreamServers)
+	if ranked[0] != "10.0.0.2:53" {
+		t.Fatalf("expected the broken upstream to be ranked last, got %v", ranked)
+	}
+
+	brokenCalls := broken.calls.Load()
+	resolver.ServeDNS(&mockResponseWriter{}, new(dns.Msg).SetQuestion("netbird.io.", dns.TypeA))
+	if broken.calls.Load() != brokenCalls {
+		t.Errorf("down upstream should not be queried while a healthy one answers")
+	}
+}
+
+func TestUpstreamResolver_ServesFromCache(t *testing.T) {
+	resolver := newUpstreamResolver(context.TODO())
+	resolver.cache = newResponseCache(defaultCacheSize)
+	client := &mockUpstreamClient{answerTTL: 300}
+	resolver.upstreamServers = []string{"10.0.0.1:53"}
+	resolver.upstreamClients["10.0.0.1:53"] = client
+
+	for i := 0; i < 3; i++ {
+		var responseMSG *dns.Msg
+		responseWriter := &mockResponseWriter{
+			WriteMsgFunc: func(m *dns.Msg) error {
+				responseMSG = m
+				return nil
+			},
+		}
+		resolver.ServeDNS(responseWriter, new(dns.Msg).SetQuestion("netbird.io.", dns.TypeA))
+		if responseMSG == nil || len(responseMSG.Answer) != 1 {
+			t.Fatalf("expected an answer, got %v", responseMSG)
+		}
+	}
+
+	if calls := client.calls.Load(); calls != 1 {
+		t.Errorf("expected a single upstream query, got %d", calls)
+	}
+	if stats := resolver.cache.stats(); stats.Hits != 2 || stats.Misses != 1 {
+		t.Errorf("unexpected cache stats %+v", stats)
+	}
+}
@@ -2,6 +2,7 @@ package internal
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/rand"
 	"net"
@@ -10,11 +11,14 @@ import (
 	"runtime"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/pion/ice/v2"
 	log "github.com/sirupsen/logrus"
+	wgnetstack "golang.zx2c4.com/wireguard/tun/netstack"
 	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/netbirdio/netbird/client/internal/dns"
 	"github.com/netbirdio/netbird/client/internal/peer"
@@ -23,6 +27,7 @@ import (
 	nbssh "github.com/netbirdio/netbird/client/ssh"
 	nbdns "github.com/netbirdio/netbird/dns"
 	"github.com/netbirdio/netbird/iface"
+	"github.com/netbirdio/netbird/iface/netstack"
 	mgm "github.com/netbirdio/netbird/management/client"
 	mgmProto "github.com/netbirdio/netbird/management/proto"
 	"github.com/netbirdio/netbird/route"
@@ -40,6 +45,9 @@ const (
 	PeerConnectionTimeoutMin = 30000 // ms
 )
 
+// wgStatsInterval is the interval the Wireguard statistics of the peers are collected in
+const wgStatsInterval = 10 * time.Second
+
 var ErrResetConnection = fmt.Errorf("reset connection")
 
 // EngineConfig is a config for the Engine
@@ -71,6 +79,9 @@ type EngineConfig struct {
 	NATExternalIPs []string
 
 	CustomDNSAddress string
+
+	// SSHRecordingsDir is the directory SSH sessions are recorded to. Recording is disabled when empty
+	SSHRecordingsDir string
 }
 
 // Engine is a mechanism responsible for reacting on Signal and Management stream events and managing connections to the remote peers.
@@ -113,6 +124,12 @@ type Engine struct {
 	routeManager routemanager.Manager
 
 	dnsServer dns.Server
+
+	// netstackProxy exposes the overlay network to local applications in netstack mode
+	netstackProxy *netstack.Proxy
+
+	// latencyProbesDenied is set once the OS denied opening the sockets of the latency probes
+	latencyProbesDenied int32
 }
 
 // Peer is an instance of the Connection Peer
@@ -217,22 +234,89 @@ func (e *Engine) Start() error {
 
 	e.routeManager = routemanager.NewManager(e.ctx, e.config.WgPrivateKey.PublicKey().String(), e.wgInterface, e.statusRecorder)
 
-	if e.dnsServer == nil {
+	if netstack.IsEnabled() {
+		err = e.startNetstackProxy()
+		if err != nil {
+			log.Errorf("failed starting the netstack proxy: %s", err.Error())
+			e.close()
+			return err
+		}
+	}
+
+	// without a TUN device there is no interface the host could send DNS queries to
+	if e.dnsServer == nil && !netstack.IsEnabled() {
 		// todo fix custom address
 		dnsServer, err := dns.NewDefaultServer(e.ctx, e.wgInterface, e.config.CustomDNSAddress)
 		if err != nil {
 			e.close()
 			return err
 		}
+		dnsServer.SetMetrics(e.statusRecorder.Metrics())
 		e.dnsServer = dnsServer
+		e.statusRecorder.SetDNSCacheStateGetter(func() peer.DNSCacheState {
+			stats := dnsServer.CacheStats()
+			return peer.DNSCacheState{
+				Entries: stats.Entries,
+				Hits:    stats.Hits,
+				Misses:  stats.Misses,
+			}
+		})
 	}
 
 	e.receiveSignalEvents()
 	e.receiveManagementEvents()
+	e.collectWireguardStats()
 
 	return nil
 }
 
+// Netstack returns the userspace network stack of the engine's interface. It is nil unless the engine runs in
+// netstack mode
+func (e *Engine) Netstack() *wgnetstack.Net {
+	e.syncMsgMux.Lock()
+	defer e.syncMsgMux.Unlock()
+
+	if e.wgInterface == nil {
+		return nil
+	}
+	return e.wgInterface.Netstack()
+}
+
+// TraceConnection makes a new connection attempt to the given peer and reports its steps to the tracer.
+// The returned function stops the tracing
+func (e *Engine) TraceConnection(peerKey string, tracer peer.Tracer) (func(), error) {
+	e.syncMsgMux.Lock()
+	conn, ok := e.peerConns[peerKey]
+	e.syncMsgMux.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("no connection found for peer %s", peerKey)
+	}
+
+	stopTracing, err := conn.AttachTracer(tracer)
+	if err != nil {
+		return nil, err
+	}
+	conn.Reconnect()
+
+	return stopTracing, nil
+}
+
+// startNetstackProxy starts the proxies that expose the overlay network of the userspace network stack
+func (e *Engine) startNetstackProxy() error {
+	proxyConfig, err := netstack.ProxyConfigFromEnv()
+	if err != nil {
+		return err
+	}
+
+	tunNet := e.wgInterface.Netstack()
+	if tunNet == nil {
+		return fmt.Errorf("interface %s isn't running on a userspace network stack", e.wgInterface.Name())
+	}
+
+	e.netstackProxy = netstack.NewProxy(proxyConfig, tunNet, e.statusRecorder.GetPeerIPByFQDN)
+	return e.netstackProxy.Start()
+}
+
 // modifyPeers updates peers that have been modified (e.g. IP address has been changed).
 // It closes the existing connection, removes it from the peerConns map, and creates a new one.
 func (e *Engine) modifyPeers(peersUpdate []*mgmProto.RemotePeerConfig) error {
@@ -250,6 +334,10 @@ func (e *Engine) modifyPeers(peersUpdate []*mgmProto.RemotePeerConfig) error {
 			if err != nil {
 				log.Warnf("error updating peer's %s fqdn in the status recorder, got error: %v", peerPubKey, err)
 			}
+			err = e.statusRecorder.UpdatePeerSSHHostKey(peerPubKey, string(p.GetSshConfig().GetSshPubKey()))
+			if err != nil {
+				log.Warnf("error updating peer's %s SSH host key in the status recorder, got error: %v", peerPubKey, err)
+			}
 		}
 	}
 
@@ -424,6 +512,10 @@ func (e *Engine) updateSSH(sshConf *mgmProto.SSHConfig) error {
 			log.Warnf("running SSH server on Windows is not supported")
 			return nil
 		}
+		if netstack.IsEnabled() {
+			log.Warnf("running SSH server in netstack mode is not supported")
+			return nil
+		}
 		// start SSH server if it wasn't running
 		if isNil(e.sshServer) {
 			//nil sshServer means it has not yet been started
@@ -433,6 +525,15 @@ func (e *Engine) updateSSH(sshConf *mgmProto.SSHConfig) error {
 			if err != nil {
 				return err
 			}
+			e.sshServer.SetSessionAuditor(e.reportSSHSession)
+			if e.config.SSHRecordingsDir != "" {
+				recorder, err := nbssh.NewRecorder(e.config.SSHRecordingsDir)
+				if err != nil {
+					log.Warnf("SSH sessions won't be recorded: %v", err)
+				} else {
+					e.sshServer.SetRecorder(recorder)
+				}
+			}
 			go func() {
 				// blocking
 				err = e.sshServer.Start()
@@ -461,6 +562,31 @@ func (e *Engine) updateSSH(sshConf *mgmProto.SSHConfig) error {
 	return nil
 }
 
+// reportSSHSession reports an audit event of a session handled by the SSH server to the Management Service
+func (e *Engine) reportSSHSession(event nbssh.SessionEvent) {
+	sessionEvent := &mgmProto.SSHSessionEvent{
+		Type:          mgmProto.SSHSessionEvent_START,
+		SessionID:     event.SessionID,
+		RemotePeerKey: event.PeerKey,
+		RemoteAddr:    event.RemoteAddr,
+		LocalUser:     event.LocalUser,
+		Command:       event.Command,
+		StartedAt:     timestamppb.New(event.StartedAt),
+		DurationMs:    event.Duration.Milliseconds(),
+		UserID:        event.UserID,
+	}
+	if event.Type == nbssh.SessionEnded {
+		sessionEvent.Type = mgmProto.SSHSessionEvent_END
+	}
+
+	go func() {
+		err := e.mgmClient.ReportSSHSession(sessionEvent)
+		if err != nil {
+			log.Warnf("failed reporting SSH session %s to the Management Service: %v", event.SessionID, err)
+		}
+	}()
+}
+
 func (e *Engine) updateConfig(conf *mgmProto.PeerConfig) error {
 	if e.wgInterface.Address().String() != conf.Address {
 		oldAddr := e.wgInterface.Address().String()
@@ -483,7 +609,7 @@ func (e *Engine) updateConfig(conf *mgmProto.PeerConfig) error {
 	e.statusRecorder.UpdateLocalPeerState(peer.LocalPeerState{
 		IP:              e.config.WgAddr,
 		PubKey:          e.config.WgPrivateKey.PublicKey().String(),
-		KernelInterface: iface.WireguardModuleIsLoaded(),
+		KernelInterface: iface.WireguardModuleIsLoaded() && !netstack.IsEnabled(),
 		FQDN:            conf.GetFqdn(),
 	})
 
@@ -589,8 +715,14 @@ func (e *Engine) updateNetworkMap(networkMap *mgmProto.NetworkMap) error {
 			return err
 		}
 
-		// update SSHServer by adding remote peer SSH keys
+		// update SSHServer by adding remote peer SSH keys, the local users they are allowed to log in as
+		// and whether they are allowed to forward ports
 		if !isNil(e.sshServer) {
+			portForwarding := make(map[string]bool)
+			var allowedUsers map[string][]string
+			if networkMap.GetPeerConfig().GetSshConfig().GetSshUsersRestricted() {
+				allowedUsers = make(map[string][]string)
+			}
 			for _, config := range networkMap.GetRemotePeers() {
 				if config.GetSshConfig() != nil && config.GetSshConfig().GetSshPubKey() != nil {
 					err := e.sshServer.AddAuthorizedKey(config.WgPubKey, string(config.GetSshConfig().GetSshPubKey()))
@@ -598,6 +730,32 @@ func (e *Engine) updateNetworkMap(networkMap *mgmProto.NetworkMap) error {
 						log.Warnf("failed adding authroized key to SSH DefaultServer %v", err)
 					}
 				}
+				if allowedUsers != nil {
+					allowedUsers[config.WgPubKey] = config.GetSshConfig().GetAllowedUsers()
+				}
+				if config.GetSshConfig().GetPortForwardingAllowed() {
+					portForwarding[config.WgPubKey] = true
+				}
+			}
+			e.sshServer.SetAllowedUsers(allowedUsers)
+			e.sshServer.SetPortForwarding(portForwarding)
+
+			sshConfig := networkMap.GetPeerConfig().GetSshConfig()
+			// users missing from the access are denied, so it must not be nil when there is an authority
+			var certificateAccess map[string]nbssh.CertificateAccess
+			if len(sshConfig.GetUserCAPublicKey()) > 0 {
+				certificateAccess = make(map[string]nbssh.CertificateAccess)
+				for userID, access := range sshConfig.GetCertificateAccess() {
+					certificateAccess[userID] = nbssh.CertificateAccess{
+						LocalUsers:     access.GetAllowedUsers(),
+						PortForwarding: access.GetPortForwardingAllowed(),
+						AnyLocalUser:   !sshConfig.GetSshUsersRestricted(),
+					}
+				}
+			}
+			err := e.sshServer.SetUserCertificateAuthority(string(sshConfig.GetUserCAPublicKey()), certificateAccess)
+			if err != nil {
+				log.Warnf("failed setting SSH user certificate authority %v", err)
 			}
 		}
 	}
@@ -614,9 +772,11 @@ func (e *Engine) updateNetworkMap(networkMap *mgmProto.NetworkMap) error {
 	if protoDNSConfig == nil {
 		protoDNSConfig = &mgmProto.DNSConfig{}
 	}
-	err = e.dnsServer.UpdateDNSServer(serial, toDNSConfig(protoDNSConfig))
-	if err != nil {
-		log.Errorf("failed to update dns server, err: %v", err)
+	if e.dnsServer != nil {
+		err = e.dnsServer.UpdateDNSServer(serial, toDNSConfig(protoDNSConfig))
+		if err != nil {
+			log.Errorf("failed to update dns server, err: %v", err)
+		}
 	}
 
 	e.networkSerial = serial
@@ -672,9 +832,10 @@ func toDNSConfig(protoDNSConfig *mgmProto.DNSConfig) nbdns.Config {
 		}
 		for _, ns := range nsGroup.GetNameServers() {
 			dnsNS := nbdns.NameServer{
-				IP:     netip.MustParseAddr(ns.GetIP()),
-				NSType: nbdns.NameServerType(ns.GetNSType()),
-				Port:   int(ns.GetPort()),
+				IP:       netip.MustParseAddr(ns.GetIP()),
+				NSType:   nbdns.NameServerType(ns.GetNSType()),
+				Port:     int(ns.GetPort()),
+				Hostname: ns.GetHostname(),
 			}
 			dnsNSGroup.NameServers = append(dnsNSGroup.NameServers, dnsNS)
 		}
@@ -691,6 +852,7 @@ func (e *Engine) updateOfflinePeers(offlinePeers []*mgmProto.RemotePeerConfig) {
 			IP:               strings.Join(offlinePeer.GetAllowedIps(), ","),
 			PubKey:           offlinePeer.GetWgPubKey(),
 			FQDN:             offlinePeer.GetFqdn(),
+			SSHHostKey:       string(offlinePeer.GetSshConfig().GetSshPubKey()),
 			ConnStatus:       peer.StatusDisconnected,
 			ConnStatusUpdate: time.Now(),
 		}
@@ -731,6 +893,10 @@ func (e *Engine) addNewPeer(peerConfig *mgmProto.RemotePeerConfig) error {
 	if err != nil {
 		log.Warnf("error updating peer's %s fqdn in the status recorder, got error: %v", peerKey, err)
 	}
+	err = e.statusRecorder.UpdatePeerSSHHostKey(peerKey, string(peerConfig.GetSshConfig().GetSshPubKey()))
+	if err != nil {
+		log.Warnf("error updating peer's %s SSH host key in the status recorder, got error: %v", peerKey, err)
+	}
 	return nil
 }
 
@@ -978,7 +1144,104 @@ func (e *Engine) parseNATExternalIPMappings() []string {
 	return mappedIPs
 }
 
+// collectWireguardStats periodically records the Wireguard traffic statistics of the connected peers
+func (e *Engine) collectWireguardStats() {
+	go func() {
+		ticker := time.NewTicker(wgStatsInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-e.ctx.Done():
+				return
+			case <-ticker.C:
+				e.updateWireguardStats()
+			}
+		}
+	}()
+}
+
+func (e *Engine) updateWireguardStats() {
+	e.syncMsgMux.Lock()
+	wgInterface := e.wgInterface
+	peerKeys := make([]string, 0, len(e.peerConns))
+	for key := range e.peerConns {
+		peerKeys = append(peerKeys, key)
+	}
+	e.syncMsgMux.Unlock()
+
+	if wgInterface == nil || len(peerKeys) == 0 {
+		return
+	}
+
+	stats, err := wgInterface.GetStats()
+	if err != nil {
+		log.Debugf("failed collecting Wireguard statistics: %v", err)
+		return
+	}
+
+	for _, key := range peerKeys {
+		peerStats, ok := stats[key]
+		if !ok {
+			continue
+		}
+		err = e.statusRecorder.UpdateWireguardPeerState(key, peerStats.LastHandshake, peerStats.RxBytes, peerStats.TxBytes)
+		if err != nil {
+			log.Debugf("failed updating Wireguard statistics of peer %s: %v", key, err)
+		}
+	}
+
+	e.updatePeersLatency(wgInterface.Netstack(), peerKeys)
+}
+
+// updatePeersLatency measures the round trip time to the connected peers through the tunnel
+func (e *Engine) updatePeersLatency(tunNet *wgnetstack.Net, peerKeys []string) {
+	if tunNet == nil && atomic.LoadInt32(&e.latencyProbesDenied) == 1 {
+		return
+	}
+
+	var wg sync.WaitGroup
+	probes := make(chan struct{}, maxConcurrentLatencyProbes)
+	for _, key := range peerKeys {
+		state, err := e.statusRecorder.GetPeer(key)
+		if err != nil || state.ConnStatus != peer.StatusConnected {
+			continue
+		}
+
+		wg.Add(1)
+		probes <- struct{}{}
+		go func(key, peerIP string) {
+			defer func() {
+				<-probes
+				wg.Done()
+			}()
+
+			latency, err := probeLatency(e.ctx, tunNet, peerIP)
+			if errors.Is(err, errLatencyProbeNotPermitted) {
+				if atomic.CompareAndSwapInt32(&e.latencyProbesDenied, 0, 1) {
+					log.Warnf("disabling the peer latency measurement: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				log.Tracef("failed measuring the latency of peer %s: %v", key, err)
+				return
+			}
+			err = e.statusRecorder.UpdateLatency(key, latency)
+			if err != nil {
+				log.Debugf("failed updating the latency of peer %s: %v", key, err)
+			}
+		}(key, state.IP)
+	}
+	wg.Wait()
+}
+
 func (e *Engine) close() {
+	if e.netstackProxy != nil {
+		e.netstackProxy.Stop()
+		e.netstackProxy = nil
+	}
+
 	log.Debugf("removing Netbird interface %s", e.config.WgIfaceName)
 	if e.wgInterface != nil {
 		if err := e.wgInterface.Close(); err != nil {
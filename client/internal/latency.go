@@ -0,0 +1,125 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"time"
+
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	wgnetstack "golang.zx2c4.com/wireguard/tun/netstack"
+)
+
+const (
+	// latencyProbeTimeout is how long the latency probe waits for the echo reply of a peer
+	latencyProbeTimeout = 2 * time.Second
+	// maxConcurrentLatencyProbes limits the number of peers probed at the same time
+	maxConcurrentLatencyProbes = 16
+	// icmpProtocolIPv4 is the protocol number of ICMP for IPv4
+	icmpProtocolIPv4 = 1
+)
+
+// errLatencyProbeNotPermitted is returned when the latency probe can't open a raw ICMP socket
+var errLatencyProbeNotPermitted = errors.New("measuring the peer latency with the kernel interface requires the privileges to open raw ICMP sockets (CAP_NET_RAW)")
+
+// pingConn is a connection sending ICMP echo requests to a single peer and reading the ICMP replies
+type pingConn interface {
+	net.Conn
+	ReadFrom(b []byte) (int, net.Addr, error)
+}
+
+// dialPing opens an ICMP connection to the NetBird IP of a peer. It goes through the userspace network stack when
+// the interface runs on one, otherwise a raw ICMP socket of the OS is used which requires elevated privileges
+func dialPing(ctx context.Context, tunNet *wgnetstack.Net, peerIP string) (pingConn, error) {
+	if tunNet != nil {
+		conn, err := tunNet.DialContext(ctx, "ping4", peerIP)
+		if err != nil {
+			return nil, err
+		}
+		return conn.(*wgnetstack.PingConn), nil
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "ip4:icmp", peerIP)
+	if errors.Is(err, os.ErrPermission) {
+		return nil, fmt.Errorf("%w: %v", errLatencyProbeNotPermitted, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return conn.(*net.IPConn), nil
+}
+
+// measureLatency sends an ICMP echo request over the connection and returns the time until the matching reply
+func measureLatency(conn pingConn, timeout time.Duration) (time.Duration, error) {
+	seq, err := rand.Int(rand.Reader, big.NewInt(1<<16))
+	if err != nil {
+		return 0, err
+	}
+	payload := make([]byte, 16)
+	if _, err = rand.Read(payload); err != nil {
+		return 0, err
+	}
+
+	request := &icmp.Echo{ID: os.Getpid() & 0xffff, Seq: int(seq.Int64()), Data: payload}
+	message, err := (&icmp.Message{Type: ipv4.ICMPTypeEcho, Body: request}).Marshal(nil)
+	if err != nil {
+		return 0, err
+	}
+
+	start := time.Now()
+	if err = conn.SetDeadline(start.Add(timeout)); err != nil {
+		return 0, err
+	}
+	if _, err = conn.Write(message); err != nil {
+		return 0, err
+	}
+
+	buf := make([]byte, 1500)
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			return 0, err
+		}
+
+		reply, err := icmp.ParseMessage(icmpProtocolIPv4, buf[:n])
+		if err != nil || reply.Type != ipv4.ICMPTypeEchoReply {
+			continue
+		}
+		// the userspace network stack rewrites the echo ID, so the replies are matched by sequence and payload
+		echo, ok := reply.Body.(*icmp.Echo)
+		if !ok || echo.Seq != request.Seq || !bytes.Equal(echo.Data, request.Data) {
+			continue
+		}
+
+		return time.Since(start), nil
+	}
+}
+
+// probeLatency measures the round trip time to the NetBird IP of a peer through the tunnel
+func probeLatency(ctx context.Context, tunNet *wgnetstack.Net, peerIP string) (time.Duration, error) {
+	ip, _, err := net.ParseCIDR(peerIP)
+	if err != nil {
+		ip = net.ParseIP(peerIP)
+	}
+	if ip == nil || ip.To4() == nil {
+		return 0, fmt.Errorf("invalid peer IP %s", peerIP)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, latencyProbeTimeout)
+	defer cancel()
+
+	conn, err := dialPing(ctx, tunNet, ip.String())
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	return measureLatency(conn, latencyProbeTimeout)
+}
@@ -0,0 +1,74 @@
+package internal
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+)
+
+// echoConn is a pingConn answering every echo request after a delay, preceded by an unrelated reply
+type echoConn struct {
+	net.Conn
+	delay   time.Duration
+	replies [][]byte
+}
+
+func (c *echoConn) SetDeadline(time.Time) error {
+	return nil
+}
+
+func (c *echoConn) Write(b []byte) (int, error) {
+	request, err := icmp.ParseMessage(icmpProtocolIPv4, b)
+	if err != nil {
+		return 0, err
+	}
+	echo := request.Body.(*icmp.Echo)
+
+	stale, err := (&icmp.Message{Type: ipv4.ICMPTypeEchoReply, Body: &icmp.Echo{ID: 1, Seq: echo.Seq + 1, Data: echo.Data}}).Marshal(nil)
+	if err != nil {
+		return 0, err
+	}
+	reply, err := (&icmp.Message{Type: ipv4.ICMPTypeEchoReply, Body: &icmp.Echo{ID: 1, Seq: echo.Seq, Data: echo.Data}}).Marshal(nil)
+	if err != nil {
+		return 0, err
+	}
+	c.replies = append(c.replies, stale, reply)
+
+	return len(b), nil
+}
+
+func (c *echoConn) ReadFrom(b []byte) (int, net.Addr, error) {
+	if len(c.replies) == 0 {
+		return 0, nil, &net.OpError{Op: "read", Err: assert.AnError}
+	}
+	time.Sleep(c.delay)
+	reply := c.replies[0]
+	c.replies = c.replies[1:]
+	return copy(b, reply), nil, nil
+}
+
+func TestMeasureLatency(t *testing.T) {
+	conn := &echoConn{delay: 5 * time.Millisecond}
+
+	latency, err := measureLatency(conn, time.Second)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, latency, 10*time.Millisecond, "the matching reply should be awaited")
+	assert.Empty(t, conn.replies, "the unrelated reply should be skipped")
+
+	_, err = measureLatency(&noReplyConn{}, time.Second)
+	assert.Error(t, err, "should return error when no reply arrives")
+}
+
+// noReplyConn is a pingConn which never receives a reply
+type noReplyConn struct {
+	echoConn
+}
+
+func (c *noReplyConn) Write(b []byte) (int, error) {
+	return len(b), nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -92,6 +93,15 @@ type Conn struct {
 	proxy        proxy.Proxy
 	remoteModeCh chan ModeMessage
 	meta         meta
+
+	// traceMu guards the tracer separately from mu because the connection steps are traced while mu is held
+	traceMu        sync.Mutex
+	tracer         Tracer
+	tracerID       uint64
+	attemptStarted time.Time
+
+	// reconnectCh interrupts the wait for the confirmation of the remote peer in Open
+	reconnectCh chan struct{}
 }
 
 // meta holds meta information about a connection
@@ -127,6 +137,7 @@ func NewConn(config ConnConfig, statusRecorder *Status) (*Conn, error) {
 		remoteAnswerCh: make(chan OfferAnswer),
 		statusRecorder: statusRecorder,
 		remoteModeCh:   make(chan ModeMessage, 1),
+		reconnectCh:    make(chan struct{}),
 	}, nil
 }
 
@@ -207,6 +218,7 @@ func (conn *Conn) reCreateAgent() error {
 // ConnStatus will be set accordingly
 func (conn *Conn) Open() error {
 	log.Debugf("trying to connect to peer %s", conn.config.Key)
+	conn.startTrace()
 
 	peerState := State{PubKey: conn.config.Key}
 
@@ -234,8 +246,10 @@ func (conn *Conn) Open() error {
 
 	err = conn.sendOffer()
 	if err != nil {
+		conn.traceFailure(err)
 		return err
 	}
+	conn.trace(TraceOfferSent, "connection offer sent, waiting for the confirmation", nil)
 
 	log.Debugf("connection offer sent to peer %s, waiting for the confirmation", conn.config.Key)
 
@@ -245,17 +259,25 @@ func (conn *Conn) Open() error {
 	var remoteOfferAnswer OfferAnswer
 	select {
 	case remoteOfferAnswer = <-conn.remoteOffersCh:
+		conn.traceOfferAnswer(TraceOfferReceived, "received connection offer from the remote peer", remoteOfferAnswer)
 		// received confirmation from the remote peer -> ready to proceed
 		err = conn.sendAnswer()
 		if err != nil {
+			conn.traceFailure(err)
 			return err
 		}
 	case remoteOfferAnswer = <-conn.remoteAnswerCh:
+		conn.traceOfferAnswer(TraceAnswerReceived, "received connection answer from the remote peer", remoteOfferAnswer)
 	case <-time.After(conn.config.Timeout):
-		return NewConnectionTimeoutError(conn.config.Key, conn.config.Timeout)
+		err = NewConnectionTimeoutError(conn.config.Key, conn.config.Timeout)
+		conn.traceFailure(err)
+		return err
 	case <-conn.closeCh:
 		// closed externally
 		return NewConnectionClosedError(conn.config.Key)
+	case <-conn.reconnectCh:
+		// a new connection attempt has been requested
+		return NewConnectionDisconnectedError(conn.config.Key)
 	}
 
 	log.Debugf("received connection confirmation from peer %s running version %s and with remote WireGuard listen port %d",
@@ -277,8 +299,11 @@ func (conn *Conn) Open() error {
 		log.Warnf("erro while updating the state of peer %s,err: %v", conn.config.Key, err)
 	}
 
+	conn.statusRecorder.Metrics().CountICEConnectionAttempt()
+
 	err = conn.agent.GatherCandidates()
 	if err != nil {
+		conn.traceFailure(err)
 		return err
 	}
 
@@ -292,7 +317,10 @@ func (conn *Conn) Open() error {
 	} else {
 		remoteConn, err = conn.agent.Accept(conn.ctx, remoteOfferAnswer.IceCredentials.UFrag, remoteOfferAnswer.IceCredentials.Pwd)
 	}
+	conn.traceCandidatePairs()
 	if err != nil {
+		conn.statusRecorder.Metrics().CountICEConnectionFailure()
+		conn.traceFailure(err)
 		return err
 	}
 
@@ -304,17 +332,27 @@ func (conn *Conn) Open() error {
 	// the ice connection has been established successfully so we are ready to start the proxy
 	err = conn.startProxy(remoteConn, remoteWgPort)
 	if err != nil {
+		conn.traceFailure(err)
 		return err
 	}
 
+	var laddr, raddr string
 	if conn.proxy.Type() == proxy.TypeNoProxy {
 		host, _, _ := net.SplitHostPort(remoteConn.LocalAddr().String())
 		rhost, _, _ := net.SplitHostPort(remoteConn.RemoteAddr().String())
+		laddr = net.JoinHostPort(host, strconv.Itoa(conn.config.LocalWgPort))
+		raddr = net.JoinHostPort(rhost, strconv.Itoa(remoteWgPort))
 		// direct Wireguard connection
-		log.Infof("directly connected to peer %s [laddr <-> raddr] [%s:%d <-> %s:%d]", conn.config.Key, host, conn.config.LocalWgPort, rhost, remoteWgPort)
+		log.Infof("directly connected to peer %s [laddr <-> raddr] [%s <-> %s]", conn.config.Key, laddr, raddr)
 	} else {
-		log.Infof("connected to peer %s [laddr <-> raddr] [%s <-> %s]", conn.config.Key, remoteConn.LocalAddr().String(), remoteConn.RemoteAddr().String())
+		laddr = remoteConn.LocalAddr().String()
+		raddr = remoteConn.RemoteAddr().String()
+		log.Infof("connected to peer %s [laddr <-> raddr] [%s <-> %s]", conn.config.Key, laddr, raddr)
 	}
+	conn.trace(TraceConnected, "connection established", map[string]string{
+		"local_address":  laddr,
+		"remote_address": raddr,
+	})
 
 	// wait until connection disconnected or has been closed externally (upper layer, e.g. engine)
 	select {
@@ -422,13 +460,25 @@ func (conn *Conn) getProxyWithMessageExchange(pair *ice.CandidatePair, remoteWgP
 		remoteDirectMode = conn.receiveRemoteDirectMode()
 	}
 
+	fields := map[string]string{
+		"should_use_proxy":   strconv.FormatBool(useProxy),
+		"local_direct_mode":  strconv.FormatBool(localDirectMode),
+		"remote_direct_mode": strconv.FormatBool(remoteDirectMode),
+	}
+
 	if localDirectMode && remoteDirectMode {
 		log.Debugf("using WireGuard direct mode with peer %s", conn.config.Key)
-		return proxy.NewNoProxy(conn.config.ProxyConfig, remoteWgPort)
+		p := proxy.NewNoProxy(conn.config.ProxyConfig, remoteWgPort)
+		fields["proxy_type"] = string(p.Type())
+		conn.trace(TraceProxySelected, "using WireGuard direct mode", fields)
+		return p
 	}
 
 	log.Debugf("falling back to local proxy mode with peer %s", conn.config.Key)
-	return proxy.NewWireguardProxy(conn.config.ProxyConfig)
+	p := proxy.NewWireguardProxy(conn.config.ProxyConfig)
+	fields["proxy_type"] = string(p.Type())
+	conn.trace(TraceProxySelected, "falling back to local proxy mode", fields)
+	return p
 }
 
 func (conn *Conn) sendLocalDirectMode(localMode bool) {
@@ -538,6 +588,7 @@ func (conn *Conn) onICECandidate(candidate ice.Candidate) {
 	if candidate != nil {
 		// TODO: reported port is incorrect for CandidateTypeHost, makes understanding ICE use via logs confusing as port is ignored
 		log.Debugf("discovered local candidate %s", candidate.String())
+		conn.trace(TraceLocalCandidate, "gathered local candidate", candidateFields(candidate))
 		go func() {
 			err := conn.signalCandidate(candidate)
 			if err != nil {
@@ -550,11 +601,16 @@ func (conn *Conn) onICECandidate(candidate ice.Candidate) {
 func (conn *Conn) onICESelectedCandidatePair(c1 ice.Candidate, c2 ice.Candidate) {
 	log.Debugf("selected candidate pair [local <-> remote] -> [%s <-> %s], peer %s", c1.String(), c2.String(),
 		conn.config.Key)
+	conn.trace(TraceSelectedPair, "selected candidate pair", map[string]string{
+		"local":  c1.String(),
+		"remote": c2.String(),
+	})
 }
 
 // onICEConnectionStateChange registers callback of an ICE Agent to track connection state
 func (conn *Conn) onICEConnectionStateChange(state ice.ConnectionState) {
 	log.Debugf("peer %s ICE ConnectionState has changed to %s", conn.config.Key, state.String())
+	conn.trace(TraceICEStateChanged, "ICE connection state changed", map[string]string{"state": state.String()})
 	if state == ice.ConnectionStateFailed || state == ice.ConnectionStateDisconnected {
 		conn.notifyDisconnected()
 	}
@@ -627,6 +683,26 @@ func (conn *Conn) Close() error {
 	}
 }
 
+// Reconnect drops the established connection, the ongoing ICE negotiation or the wait for the remote peer to confirm
+// the connection, so that a new connection attempt is made
+func (conn *Conn) Reconnect() {
+	conn.mu.Lock()
+	defer conn.mu.Unlock()
+
+	if conn.notifyDisconnected != nil {
+		log.Debugf("reconnecting to peer %s", conn.config.Key)
+		conn.notifyDisconnected()
+		return
+	}
+
+	select {
+	case conn.reconnectCh <- struct{}{}:
+		log.Debugf("reconnecting to peer %s", conn.config.Key)
+	default:
+		// no connection attempt is waiting, the next one starts anyway
+	}
+}
+
 // Status returns current status of the Conn
 func (conn *Conn) Status() ConnStatus {
 	conn.mu.Lock()
@@ -667,6 +743,7 @@ func (conn *Conn) OnRemoteAnswer(answer OfferAnswer) bool {
 // OnRemoteCandidate Handles ICE connection Candidate provided by the remote peer.
 func (conn *Conn) OnRemoteCandidate(candidate ice.Candidate) {
 	log.Debugf("OnRemoteCandidate from peer %s -> %s", conn.config.Key, candidate.String())
+	conn.trace(TraceRemoteCandidate, "received remote candidate", candidateFields(candidate))
 	go func() {
 		conn.mu.Lock()
 		defer conn.mu.Unlock()
@@ -438,3 +438,97 @@ func TestGetProxyWithMessageExchange(t *testing.T) {
 		})
 	}
 }
+
+func TestConn_Trace(t *testing.T) {
+	conn, err := NewConn(connConf, NewRecorder("https://mgm"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.SetSignalOffer(func(offer OfferAnswer) error {
+		return nil
+	})
+
+	var events []TraceEvent
+	conn.SetTracer(func(event TraceEvent) {
+		events = append(events, event)
+	})
+
+	err = conn.Open()
+	if _, ok := err.(*ConnectionTimeoutError); !ok {
+		t.Fatalf("expected a connection timeout error, got %v", err)
+	}
+
+	var types []TraceEventType
+	for _, event := range events {
+		types = append(types, event.Type)
+	}
+	assert.Equal(t, types, []TraceEventType{TraceAttemptStarted, TraceOfferSent, TraceFailed})
+	assert.Equal(t, events[2].Fields["error"], err.Error())
+	if events[2].Elapsed < connConf.Timeout {
+		t.Errorf("expected the failure to be reported after the timeout of %s, got %s", connConf.Timeout, events[2].Elapsed)
+	}
+
+	conn.SetTracer(nil)
+	events = nil
+	_ = conn.Open()
+	assert.Equal(t, len(events), 0)
+}
+
+func TestConn_ReconnectWhileWaitingForOffer(t *testing.T) {
+	conf := connConf
+	conf.Timeout = 10 * time.Second
+	conn, err := NewConn(conf, NewRecorder("https://mgm"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	offerSent := make(chan struct{})
+	conn.SetSignalOffer(func(offer OfferAnswer) error {
+		close(offerSent)
+		return nil
+	})
+
+	result := make(chan error, 1)
+	go func() {
+		result <- conn.Open()
+	}()
+
+	<-offerSent
+	// the offer is sent right before the wait for the remote peer starts, so retry until the wait is interrupted
+	for {
+		conn.Reconnect()
+		select {
+		case err = <-result:
+			if _, ok := err.(*ConnectionDisconnectedError); !ok {
+				t.Fatalf("expected a disconnected error, got %v", err)
+			}
+			return
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func TestConn_AttachTracer(t *testing.T) {
+	conn, err := NewConn(connConf, NewRecorder("https://mgm"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	detach, err := conn.AttachTracer(func(event TraceEvent) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = conn.AttachTracer(func(event TraceEvent) {})
+	assert.Equal(t, err, ErrTracerAttached)
+
+	detach()
+	detachSecond, err := conn.AttachTracer(func(event TraceEvent) {})
+	if err != nil {
+		t.Fatalf("expected the tracer to be attached once the previous one is detached, got %v", err)
+	}
+
+	detach()
+	assert.Equal(t, conn.tracing(), true, "a stale detach shouldn't remove the current tracer")
+	detachSecond()
+	assert.Equal(t, conn.tracing(), false)
+}
@@ -2,8 +2,11 @@ package peer
 
 import (
 	"errors"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/netbirdio/netbird/client/internal/telemetry"
 )
 
 // State contains the latest state of a peer
@@ -17,6 +20,14 @@ type State struct {
 	Direct                 bool
 	LocalIceCandidateType  string
 	RemoteIceCandidateType string
+	// SSHHostKey is the public key of the peer's SSH server in the authorized_keys format
+	SSHHostKey string
+	// LastWireguardHandshake is the time of the latest Wireguard handshake with the peer
+	LastWireguardHandshake time.Time
+	BytesRx                int64
+	BytesTx                int64
+	// Latency is the latest round trip time to the peer through the tunnel, zero if unknown
+	Latency time.Duration
 }
 
 // LocalPeerState contains the latest state of the local peer
@@ -39,12 +50,20 @@ type ManagementState struct {
 	Connected bool
 }
 
+// DNSCacheState contains the latest state of the DNS response cache
+type DNSCacheState struct {
+	Entries int
+	Hits    uint64
+	Misses  uint64
+}
+
 // FullStatus contains the full state held by the Status instance
 type FullStatus struct {
 	Peers           []State
 	ManagementState ManagementState
 	SignalState     SignalState
 	LocalPeerState  LocalPeerState
+	DNSCacheState   DNSCacheState
 }
 
 // Status holds a state of peers, signal and management connections
@@ -58,6 +77,11 @@ type Status struct {
 	offlinePeers    []State
 	mgmAddress      string
 	signalAddress   string
+	dnsCacheState   func() DNSCacheState
+	metrics         *telemetry.ClientMetrics
+	// signalConnectedBefore and managementConnectedBefore distinguish reconnects from the initial connections
+	signalConnectedBefore     bool
+	managementConnectedBefore bool
 }
 
 // NewRecorder returns a new Status instance
@@ -138,6 +162,8 @@ func (d *Status) UpdatePeerState(receivedState State) error {
 		peerState.Relayed = receivedState.Relayed
 		peerState.LocalIceCandidateType = receivedState.LocalIceCandidateType
 		peerState.RemoteIceCandidateType = receivedState.RemoteIceCandidateType
+		// the latency of the previous connection doesn't apply to the new one
+		peerState.Latency = 0
 	}
 
 	d.peers[receivedState.PubKey] = peerState
@@ -167,6 +193,84 @@ func (d *Status) UpdatePeerFQDN(peerPubKey, fqdn string) error {
 	return nil
 }
 
+// UpdatePeerSSHHostKey update peer's state SSH host key only
+func (d *Status) UpdatePeerSSHHostKey(peerPubKey, sshHostKey string) error {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	peerState, ok := d.peers[peerPubKey]
+	if !ok {
+		return errors.New("peer doesn't exist")
+	}
+
+	peerState.SSHHostKey = sshHostKey
+	d.peers[peerPubKey] = peerState
+
+	return nil
+}
+
+// UpdateWireguardPeerState updates the Wireguard traffic statistics of the peer
+func (d *Status) UpdateWireguardPeerState(peerPubKey string, lastHandshake time.Time, bytesRx, bytesTx int64) error {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	peerState, ok := d.peers[peerPubKey]
+	if !ok {
+		return errors.New("peer doesn't exist")
+	}
+
+	peerState.LastWireguardHandshake = lastHandshake
+	peerState.BytesRx = bytesRx
+	peerState.BytesTx = bytesTx
+	d.peers[peerPubKey] = peerState
+
+	return nil
+}
+
+// UpdateLatency updates the round trip time to the peer
+func (d *Status) UpdateLatency(peerPubKey string, latency time.Duration) error {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	peerState, ok := d.peers[peerPubKey]
+	if !ok {
+		return errors.New("peer doesn't exist")
+	}
+
+	peerState.Latency = latency
+	d.peers[peerPubKey] = peerState
+
+	return nil
+}
+
+// GetPeerIPByFQDN returns the IP address of the connected or offline peer with the given domain name
+func (d *Status) GetPeerIPByFQDN(fqdn string) (string, bool) {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	fqdn = strings.TrimSuffix(strings.ToLower(fqdn), ".")
+	match := func(state State) (string, bool) {
+		if state.FQDN == "" || strings.ToLower(state.FQDN) != fqdn {
+			return "", false
+		}
+		// offline peers hold their allowed IPs
+		ip := strings.Split(strings.Split(state.IP, ",")[0], "/")[0]
+		return ip, ip != ""
+	}
+
+	for _, state := range d.peers {
+		if ip, ok := match(state); ok {
+			return ip, true
+		}
+	}
+	for _, state := range d.offlinePeers {
+		if ip, ok := match(state); ok {
+			return ip, true
+		}
+	}
+	return "", false
+}
+
 // GetPeerStateChangeNotifier returns a change notifier channel for a peer
 func (d *Status) GetPeerStateChangeNotifier(peer string) <-chan struct{} {
 	d.mux.Lock()
@@ -206,7 +310,11 @@ func (d *Status) MarkManagementDisconnected() {
 func (d *Status) MarkManagementConnected() {
 	d.mux.Lock()
 	defer d.mux.Unlock()
+	if !d.managementState && d.managementConnectedBefore {
+		d.metrics.CountManagementReconnect()
+	}
 	d.managementState = true
+	d.managementConnectedBefore = true
 }
 
 // UpdateSignalAddress update the address of the signal server
@@ -234,7 +342,56 @@ func (d *Status) MarkSignalDisconnected() {
 func (d *Status) MarkSignalConnected() {
 	d.mux.Lock()
 	defer d.mux.Unlock()
+	if !d.signalState && d.signalConnectedBefore {
+		d.metrics.CountSignalReconnect()
+	}
 	d.signalState = true
+	d.signalConnectedBefore = true
+}
+
+// SetMetrics sets the metrics the client components report to and registers the collection of the peers state
+func (d *Status) SetMetrics(metrics *telemetry.ClientMetrics) error {
+	d.mux.Lock()
+	d.metrics = metrics
+	d.mux.Unlock()
+
+	return metrics.RegisterPeersState(d.getPeersState)
+}
+
+// Metrics returns the metrics the client components report to. It is nil if metrics are disabled
+func (d *Status) Metrics() *telemetry.ClientMetrics {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+	return d.metrics
+}
+
+// getPeersState returns the number of peers by connection status, offline peers count as disconnected
+func (d *Status) getPeersState() telemetry.PeersState {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	state := telemetry.PeersState{Disconnected: int64(len(d.offlinePeers))}
+	for _, peerState := range d.peers {
+		switch peerState.ConnStatus {
+		case StatusConnected:
+			state.Connected++
+			if peerState.Relayed {
+				state.Relayed++
+			}
+		case StatusConnecting:
+			state.Connecting++
+		default:
+			state.Disconnected++
+		}
+	}
+	return state
+}
+
+// SetDNSCacheStateGetter sets the function used to read the DNS response cache counters
+func (d *Status) SetDNSCacheStateGetter(getter func() DNSCacheState) {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+	d.dnsCacheState = getter
 }
 
 // GetFullStatus gets full status
@@ -254,6 +411,10 @@ func (d *Status) GetFullStatus() FullStatus {
 		LocalPeerState: d.localPeer,
 	}
 
+	if d.dnsCacheState != nil {
+		fullStatus.DNSCacheState = d.dnsCacheState()
+	}
+
 	for _, status := range d.peers {
 		fullStatus.Peers = append(fullStatus.Peers, status)
 	}
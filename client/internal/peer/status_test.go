@@ -2,6 +2,7 @@ package peer
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -73,6 +74,51 @@ func TestStatus_UpdatePeerFQDN(t *testing.T) {
 	assert.Equal(t, fqdn, state.FQDN, "fqdn should be equal")
 }
 
+func TestStatus_UpdateWireguardPeerState(t *testing.T) {
+	key := "abc"
+	handshake := time.Now().Add(-time.Minute)
+	status := NewRecorder("https://mgm")
+	status.peers[key] = State{
+		PubKey:     key,
+		ConnStatus: StatusConnected,
+	}
+
+	err := status.UpdateWireguardPeerState(key, handshake, 100, 200)
+	assert.NoError(t, err, "shouldn't return error")
+
+	state := status.peers[key]
+	assert.Equal(t, handshake, state.LastWireguardHandshake, "handshake should be equal")
+	assert.Equal(t, int64(100), state.BytesRx, "received bytes should be equal")
+	assert.Equal(t, int64(200), state.BytesTx, "sent bytes should be equal")
+
+	err = status.UpdatePeerState(State{PubKey: key, ConnStatus: StatusDisconnected})
+	assert.NoError(t, err, "shouldn't return error")
+	assert.Equal(t, int64(100), status.peers[key].BytesRx, "connection status updates shouldn't reset the statistics")
+
+	err = status.UpdateWireguardPeerState("non_existing_key", handshake, 0, 0)
+	assert.Error(t, err, "should return error when peer doesn't exist")
+}
+
+func TestStatus_UpdateLatency(t *testing.T) {
+	key := "abc"
+	status := NewRecorder("https://mgm")
+	status.peers[key] = State{
+		PubKey:     key,
+		ConnStatus: StatusConnected,
+	}
+
+	err := status.UpdateLatency(key, 25*time.Millisecond)
+	assert.NoError(t, err, "shouldn't return error")
+	assert.Equal(t, 25*time.Millisecond, status.peers[key].Latency, "latency should be equal")
+
+	err = status.UpdatePeerState(State{PubKey: key, ConnStatus: StatusDisconnected})
+	assert.NoError(t, err, "shouldn't return error")
+	assert.Zero(t, status.peers[key].Latency, "latency should be reset when the connection status changes")
+
+	err = status.UpdateLatency("non_existing_key", time.Millisecond)
+	assert.Error(t, err, "should return error when peer doesn't exist")
+}
+
 func TestGetPeerStateChangeNotifierLogic(t *testing.T) {
 	key := "abc"
 	ip := "10.10.10.10"
@@ -231,3 +277,40 @@ func TestGetFullStatus(t *testing.T) {
 	assert.Equal(t, signalState, fullStatus.SignalState, "signal status should be equal")
 	assert.ElementsMatch(t, []State{peerState1, peerState2}, fullStatus.Peers, "peers states should match")
 }
+
+func TestGetPeerIPByFQDN(t *testing.T) {
+	status := NewRecorder("https://mgm")
+	err := status.AddPeer("abc")
+	assert.NoError(t, err, "shouldn't return error")
+	err = status.UpdatePeerState(State{PubKey: "abc", IP: "100.64.0.2"})
+	assert.NoError(t, err, "shouldn't return error")
+	err = status.UpdatePeerFQDN("abc", "peer-a.netbird.cloud")
+	assert.NoError(t, err, "shouldn't return error")
+	status.ReplaceOfflinePeers([]State{{PubKey: "def", IP: "100.64.0.3/32", FQDN: "peer-b.netbird.cloud"}})
+
+	ip, found := status.GetPeerIPByFQDN("Peer-A.netbird.cloud.")
+	assert.True(t, found, "connected peer should be found")
+	assert.Equal(t, "100.64.0.2", ip)
+
+	ip, found = status.GetPeerIPByFQDN("peer-b.netbird.cloud")
+	assert.True(t, found, "offline peer should be found")
+	assert.Equal(t, "100.64.0.3", ip)
+
+	_, found = status.GetPeerIPByFQDN("unknown.netbird.cloud")
+	assert.False(t, found, "unknown peer shouldn't be found")
+}
+
+func TestGetPeersState(t *testing.T) {
+	status := NewRecorder("https://mgm")
+	status.peers["connected"] = State{PubKey: "connected", ConnStatus: StatusConnected}
+	status.peers["relayed"] = State{PubKey: "relayed", ConnStatus: StatusConnected, Relayed: true}
+	status.peers["connecting"] = State{PubKey: "connecting", ConnStatus: StatusConnecting}
+	status.peers["disconnected"] = State{PubKey: "disconnected", ConnStatus: StatusDisconnected}
+	status.ReplaceOfflinePeers([]State{{PubKey: "offline"}})
+
+	state := status.getPeersState()
+	assert.Equal(t, int64(2), state.Connected, "connected peers should be counted")
+	assert.Equal(t, int64(1), state.Relayed, "relayed peers should be counted")
+	assert.Equal(t, int64(1), state.Connecting, "connecting peers should be counted")
+	assert.Equal(t, int64(2), state.Disconnected, "offline peers should count as disconnected")
+}
@@ -0,0 +1,155 @@
+package peer
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/pion/ice/v2"
+)
+
+// TraceEventType is the type of step of a connection attempt
+type TraceEventType string
+
+const (
+	// TraceAttemptStarted is reported when a new connection attempt starts
+	TraceAttemptStarted TraceEventType = "attempt_started"
+	// TraceOfferSent is reported when the offer has been sent to the remote peer
+	TraceOfferSent TraceEventType = "offer_sent"
+	// TraceOfferReceived is reported when an offer of the remote peer has been received
+	TraceOfferReceived TraceEventType = "offer_received"
+	// TraceAnswerReceived is reported when the answer of the remote peer has been received
+	TraceAnswerReceived TraceEventType = "answer_received"
+	// TraceLocalCandidate is reported for every gathered local ICE candidate
+	TraceLocalCandidate TraceEventType = "local_candidate"
+	// TraceRemoteCandidate is reported for every ICE candidate received from the remote peer
+	TraceRemoteCandidate TraceEventType = "remote_candidate"
+	// TraceICEStateChanged is reported when the state of the ICE agent changes
+	TraceICEStateChanged TraceEventType = "ice_state_changed"
+	// TraceCandidatePair is reported for every checked candidate pair once the ICE negotiation has ended
+	TraceCandidatePair TraceEventType = "candidate_pair"
+	// TraceSelectedPair is reported when the ICE agent selects the candidate pair to use
+	TraceSelectedPair TraceEventType = "selected_pair"
+	// TraceProxySelected is reported when the proxy type for the connection has been chosen
+	TraceProxySelected TraceEventType = "proxy_selected"
+	// TraceConnected is reported when the connection has been established
+	TraceConnected TraceEventType = "connected"
+	// TraceFailed is reported when the connection attempt failed
+	TraceFailed TraceEventType = "failed"
+)
+
+// TraceEvent is a step of a connection attempt to a remote peer
+type TraceEvent struct {
+	Type TraceEventType
+	Time time.Time
+	// Elapsed is the time since the start of the connection attempt
+	Elapsed time.Duration
+	Message string
+	Fields  map[string]string
+}
+
+// Tracer receives the steps of the connection attempts of a Conn. It must not block
+type Tracer func(event TraceEvent)
+
+// ErrTracerAttached is returned when the connection attempts of a Conn are already traced by another tracer
+var ErrTracerAttached = errors.New("the connection is already being traced")
+
+// SetTracer sets the tracer that receives the steps of the connection attempts. A nil tracer disables tracing
+func (conn *Conn) SetTracer(tracer Tracer) {
+	conn.traceMu.Lock()
+	defer conn.traceMu.Unlock()
+	conn.tracer = tracer
+	conn.tracerID++
+}
+
+// AttachTracer sets the tracer unless another tracer is already attached, in which case ErrTracerAttached is returned.
+// The returned function detaches the tracer again
+func (conn *Conn) AttachTracer(tracer Tracer) (func(), error) {
+	conn.traceMu.Lock()
+	defer conn.traceMu.Unlock()
+
+	if conn.tracer != nil {
+		return nil, ErrTracerAttached
+	}
+	conn.tracer = tracer
+	conn.tracerID++
+	id := conn.tracerID
+
+	return func() {
+		conn.traceMu.Lock()
+		defer conn.traceMu.Unlock()
+		if conn.tracerID == id {
+			conn.tracer = nil
+		}
+	}, nil
+}
+
+// startTrace marks the start of a new connection attempt the elapsed time of the following events refers to
+func (conn *Conn) startTrace() {
+	conn.traceMu.Lock()
+	conn.attemptStarted = time.Now()
+	conn.traceMu.Unlock()
+
+	conn.trace(TraceAttemptStarted, "starting a new connection attempt", nil)
+}
+
+func (conn *Conn) trace(eventType TraceEventType, message string, fields map[string]string) {
+	conn.traceMu.Lock()
+	tracer := conn.tracer
+	started := conn.attemptStarted
+	conn.traceMu.Unlock()
+
+	if tracer == nil {
+		return
+	}
+
+	now := time.Now()
+	tracer(TraceEvent{
+		Type:    eventType,
+		Time:    now,
+		Elapsed: now.Sub(started),
+		Message: message,
+		Fields:  fields,
+	})
+}
+
+func (conn *Conn) tracing() bool {
+	conn.traceMu.Lock()
+	defer conn.traceMu.Unlock()
+	return conn.tracer != nil
+}
+
+func (conn *Conn) traceFailure(err error) {
+	conn.trace(TraceFailed, "connection attempt failed", map[string]string{"error": err.Error()})
+}
+
+func (conn *Conn) traceOfferAnswer(eventType TraceEventType, message string, offerAnswer OfferAnswer) {
+	conn.trace(eventType, message, map[string]string{
+		"version":        offerAnswer.Version,
+		"wg_listen_port": strconv.Itoa(offerAnswer.WgListenPort),
+	})
+}
+
+// traceCandidatePairs reports the state of the candidate pairs checked by the ICE agent
+func (conn *Conn) traceCandidatePairs() {
+	if !conn.tracing() || conn.agent == nil {
+		return
+	}
+
+	for _, stats := range conn.agent.GetCandidatePairsStats() {
+		conn.trace(TraceCandidatePair, "checked candidate pair", map[string]string{
+			"local_id":  stats.LocalCandidateID,
+			"remote_id": stats.RemoteCandidateID,
+			"state":     stats.State.String(),
+			"nominated": strconv.FormatBool(stats.Nominated),
+		})
+	}
+}
+
+func candidateFields(candidate ice.Candidate) map[string]string {
+	return map[string]string{
+		"id":        candidate.ID(),
+		"type":      candidate.Type().String(),
+		"candidate": candidate.String(),
+	}
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/netbirdio/netbird/client/internal/peer"
 	"github.com/netbirdio/netbird/iface"
+	"github.com/netbirdio/netbird/iface/netstack"
 	"github.com/netbirdio/netbird/route"
 )
 
@@ -162,6 +163,10 @@ func (c *clientNetwork) removeRouteFromPeerAndSystem() error {
 		if err != nil {
 			return err
 		}
+		// the network stack routes everything to the WireGuard device, there are no system routes to remove
+		if netstack.IsEnabled() {
+			return nil
+		}
 		err = removeFromRouteTableIfNonSystem(c.network, c.wgInterface.Address().IP.String())
 		if err != nil {
 			return fmt.Errorf("couldn't remove route %s from system, err: %v",
@@ -200,7 +205,7 @@ func (c *clientNetwork) recalculateRouteAndUpdatePeerAndSystem() error {
 		if err != nil {
 			return err
 		}
-	} else {
+	} else if !netstack.IsEnabled() {
 		err = addToRouteTableIfNoExists(c.network, c.wgInterface.Address().IP.String())
 		if err != nil {
 			return fmt.Errorf("route %s couldn't be added for peer %s, err: %v",
@@ -209,6 +214,7 @@ func (c *clientNetwork) recalculateRouteAndUpdatePeerAndSystem() error {
 	}
 
 	c.chosenRoute = c.routes[chosen]
+	c.statusRecorder.Metrics().CountRouteSelection()
 	err = c.wgInterface.AddAllowedIP(c.chosenRoute.Peer, c.network.String())
 	if err != nil {
 		log.Errorf("couldn't add allowed IP %s added for peer %s, err: %v",
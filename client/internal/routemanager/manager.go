@@ -10,6 +10,7 @@ import (
 
 	"github.com/netbirdio/netbird/client/internal/peer"
 	"github.com/netbirdio/netbird/iface"
+	"github.com/netbirdio/netbird/iface/netstack"
 	"github.com/netbirdio/netbird/route"
 	"github.com/netbirdio/netbird/version"
 )
@@ -160,6 +161,11 @@ func (m *DefaultManager) UpdateRoutes(updateSerial uint64, newRoutes []*route.Ro
 					log.Warnf("received a route to manage, but agent doesn't support router mode on %s OS", runtime.GOOS)
 					continue
 				}
+				// the userspace network stack can't forward packets to the networks of the host
+				if netstack.IsEnabled() {
+					log.Warnf("received a route to manage, but agent doesn't support router mode in netstack mode")
+					continue
+				}
 				newServerRoutesMap[newRoute.ID] = newRoute
 			}
 		}
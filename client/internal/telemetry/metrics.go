@@ -0,0 +1,250 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	prometheus2 "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/prometheus"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/instrument"
+	"go.opentelemetry.io/otel/metric/instrument/asyncint64"
+	"go.opentelemetry.io/otel/metric/instrument/syncint64"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+)
+
+const defaultEndpoint = "/metrics"
+
+// PeersState holds the number of peers by connection status and type at the time of a metrics collection
+type PeersState struct {
+	Connected    int64
+	Connecting   int64
+	Disconnected int64
+	// Relayed is the number of connected peers whose traffic goes through a TURN relay
+	Relayed int64
+}
+
+// ClientMetrics are the metrics of the client daemon. A nil ClientMetrics discards all measurements,
+// so the components of the client can count unconditionally
+type ClientMetrics struct {
+	ctx      context.Context
+	meter    metric.Meter
+	registry *prometheus2.Registry
+
+	iceConnectionAttempts syncint64.Counter
+	iceConnectionFailures syncint64.Counter
+	signalReconnects      syncint64.Counter
+	managementReconnects  syncint64.Counter
+	routeSelections       syncint64.Counter
+	dnsUpstreamFailures   syncint64.Counter
+	peersGauge            asyncint64.Gauge
+	connectionTypeGauge   asyncint64.Gauge
+
+	mu       sync.Mutex
+	listener net.Listener
+	server   *http.Server
+}
+
+// NewClientMetrics creates new ClientMetrics. The metrics are collected in a registry of their own
+func NewClientMetrics(ctx context.Context) (*ClientMetrics, error) {
+	registry := prometheus2.NewRegistry()
+	exporter, err := prometheus.New(prometheus.WithRegisterer(registry), prometheus.WithoutUnits())
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(exporter))
+	pkg := reflect.TypeOf(defaultEndpoint).PkgPath()
+	meter := provider.Meter(pkg)
+
+	iceConnectionAttempts, err := meter.SyncInt64().Counter("client.ice.connection.attempts", instrument.WithUnit("1"))
+	if err != nil {
+		return nil, err
+	}
+	iceConnectionFailures, err := meter.SyncInt64().Counter("client.ice.connection.failures", instrument.WithUnit("1"))
+	if err != nil {
+		return nil, err
+	}
+	signalReconnects, err := meter.SyncInt64().Counter("client.signal.reconnects", instrument.WithUnit("1"))
+	if err != nil {
+		return nil, err
+	}
+	managementReconnects, err := meter.SyncInt64().Counter("client.management.reconnects", instrument.WithUnit("1"))
+	if err != nil {
+		return nil, err
+	}
+	routeSelections, err := meter.SyncInt64().Counter("client.route.selections", instrument.WithUnit("1"))
+	if err != nil {
+		return nil, err
+	}
+	dnsUpstreamFailures, err := meter.SyncInt64().Counter("client.dns.upstream.failures", instrument.WithUnit("1"))
+	if err != nil {
+		return nil, err
+	}
+
+	peersGauge, err := meter.AsyncInt64().Gauge("client.peers", instrument.WithUnit("1"))
+	if err != nil {
+		return nil, err
+	}
+	connectionTypeGauge, err := meter.AsyncInt64().Gauge("client.peers.connection.type", instrument.WithUnit("1"))
+	if err != nil {
+		return nil, err
+	}
+
+	return &ClientMetrics{
+		ctx:                   ctx,
+		meter:                 meter,
+		registry:              registry,
+		iceConnectionAttempts: iceConnectionAttempts,
+		iceConnectionFailures: iceConnectionFailures,
+		signalReconnects:      signalReconnects,
+		managementReconnects:  managementReconnects,
+		routeSelections:       routeSelections,
+		dnsUpstreamFailures:   dnsUpstreamFailures,
+		peersGauge:            peersGauge,
+		connectionTypeGauge:   connectionTypeGauge,
+	}, nil
+}
+
+// RegisterPeersState registers a function that collects the peers state and feeds it to the peers gauges.
+// Peers are reported by status and connected peers by connection type, relayed or p2p
+func (m *ClientMetrics) RegisterPeersState(producer func() PeersState) error {
+	if m == nil {
+		return nil
+	}
+	return m.meter.RegisterCallback(
+		[]instrument.Asynchronous{
+			m.peersGauge,
+			m.connectionTypeGauge,
+		},
+		func(ctx context.Context) {
+			state := producer()
+			m.peersGauge.Observe(ctx, state.Connected, attribute.String("status", "connected"))
+			m.peersGauge.Observe(ctx, state.Connecting, attribute.String("status", "connecting"))
+			m.peersGauge.Observe(ctx, state.Disconnected, attribute.String("status", "disconnected"))
+			m.connectionTypeGauge.Observe(ctx, state.Relayed, attribute.String("type", "relayed"))
+			m.connectionTypeGauge.Observe(ctx, state.Connected-state.Relayed, attribute.String("type", "p2p"))
+		},
+	)
+}
+
+// CountICEConnectionAttempt counts the attempts to establish an ICE connection to a remote peer
+func (m *ClientMetrics) CountICEConnectionAttempt() {
+	if m == nil {
+		return
+	}
+	m.iceConnectionAttempts.Add(m.ctx, 1)
+}
+
+// CountICEConnectionFailure counts the attempts to establish an ICE connection to a remote peer that failed
+func (m *ClientMetrics) CountICEConnectionFailure() {
+	if m == nil {
+		return
+	}
+	m.iceConnectionFailures.Add(m.ctx, 1)
+}
+
+// CountSignalReconnect counts the times the connection to the Signal service has been re-established
+func (m *ClientMetrics) CountSignalReconnect() {
+	if m == nil {
+		return
+	}
+	m.signalReconnects.Add(m.ctx, 1)
+}
+
+// CountManagementReconnect counts the times the connection to the Management service has been re-established
+func (m *ClientMetrics) CountManagementReconnect() {
+	if m == nil {
+		return
+	}
+	m.managementReconnects.Add(m.ctx, 1)
+}
+
+// CountRouteSelection counts the times a routing peer has been selected for a client network
+func (m *ClientMetrics) CountRouteSelection() {
+	if m == nil {
+		return
+	}
+	m.routeSelections.Add(m.ctx, 1)
+}
+
+// CountDNSUpstreamFailure counts the DNS queries that failed on all upstream nameservers
+func (m *ClientMetrics) CountDNSUpstreamFailure() {
+	if m == nil {
+		return
+	}
+	m.dnsUpstreamFailures.Add(m.ctx, 1)
+}
+
+// Expose serves the metrics in the Prometheus format on the given address, e.g. 127.0.0.1:9090, under /metrics
+func (m *ClientMetrics) Expose(address string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.listener != nil {
+		return errors.New("metrics are already exposed")
+	}
+
+	rootRouter := mux.NewRouter()
+	rootRouter.Handle(defaultEndpoint, promhttp.HandlerFor(
+		m.registry,
+		promhttp.HandlerOpts{EnableOpenMetrics: true}))
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return fmt.Errorf("failed listening on metrics address %s: %w", address, err)
+	}
+	server := &http.Server{Handler: rootRouter, ReadHeaderTimeout: 5 * time.Second}
+	m.listener = listener
+	m.server = server
+
+	go func() {
+		err := server.Serve(listener)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Errorf("failed serving metrics: %v", err)
+		}
+	}()
+
+	log.Infof("enabled client metrics and exposing on http://%s%s", listener.Addr().String(), defaultEndpoint)
+
+	return nil
+}
+
+// Address returns the address the metrics are exposed on, nil if they are not exposed
+func (m *ClientMetrics) Address() net.Addr {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.listener == nil {
+		return nil
+	}
+	return m.listener.Addr()
+}
+
+// Close stops exposing the metrics and closes the open connections of the scrapers
+func (m *ClientMetrics) Close() error {
+	if m == nil {
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.server == nil {
+		return nil
+	}
+	err := m.server.Close()
+	m.listener = nil
+	m.server = nil
+	return err
+}
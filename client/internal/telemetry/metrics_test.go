@@ -0,0 +1,75 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientMetrics_Expose(t *testing.T) {
+	metrics, err := NewClientMetrics(context.Background())
+	require.NoError(t, err)
+
+	err = metrics.RegisterPeersState(func() PeersState {
+		return PeersState{Connected: 3, Connecting: 1, Disconnected: 2, Relayed: 1}
+	})
+	require.NoError(t, err)
+
+	metrics.CountICEConnectionAttempt()
+	metrics.CountICEConnectionAttempt()
+	metrics.CountICEConnectionFailure()
+	metrics.CountSignalReconnect()
+	metrics.CountManagementReconnect()
+	metrics.CountRouteSelection()
+	metrics.CountDNSUpstreamFailure()
+
+	require.NoError(t, metrics.Expose("127.0.0.1:0"))
+
+	assert.Error(t, metrics.Expose("127.0.0.1:0"), "metrics shouldn't be exposed twice")
+
+	resp, err := http.Get(fmt.Sprintf("http://%s/metrics", metrics.Address()))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	expected := []string{
+		`client_peers{status="connected"} 3`,
+		`client_peers{status="connecting"} 1`,
+		`client_peers{status="disconnected"} 2`,
+		`client_peers_connection_type{type="relayed"} 1`,
+		`client_peers_connection_type{type="p2p"} 2`,
+		`client_ice_connection_attempts_total 2`,
+		`client_ice_connection_failures_total 1`,
+		`client_signal_reconnects_total 1`,
+		`client_management_reconnects_total 1`,
+		`client_route_selections_total 1`,
+		`client_dns_upstream_failures_total 1`,
+	}
+	for _, metric := range expected {
+		assert.Contains(t, string(body), metric)
+	}
+
+	require.NoError(t, metrics.Close())
+	assert.Nil(t, metrics.Address(), "metrics shouldn't be exposed after closing")
+}
+
+func TestClientMetrics_Nil(t *testing.T) {
+	var metrics *ClientMetrics
+
+	// the components of the client count unconditionally, so a nil metrics has to be safe to use
+	assert.NotPanics(t, func() {
+		metrics.CountICEConnectionAttempt()
+		metrics.CountICEConnectionFailure()
+		metrics.CountSignalReconnect()
+		metrics.CountManagementReconnect()
+		metrics.CountRouteSelection()
+		metrics.CountDNSUpstreamFailure()
+		assert.NoError(t, metrics.RegisterPeersState(func() PeersState { return PeersState{} }))
+	})
+}
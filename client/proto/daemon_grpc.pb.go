@@ -31,6 +31,8 @@ type DaemonServiceClient interface {
 	Down(ctx context.Context, in *DownRequest, opts ...grpc.CallOption) (*DownResponse, error)
 	// GetConfig of the daemon.
 	GetConfig(ctx context.Context, in *GetConfigRequest, opts ...grpc.CallOption) (*GetConfigResponse, error)
+	// DebugConnect makes a new connection attempt to a peer and streams its steps.
+	DebugConnect(ctx context.Context, in *DebugConnectRequest, opts ...grpc.CallOption) (DaemonService_DebugConnectClient, error)
 }
 
 type daemonServiceClient struct {
@@ -95,6 +97,38 @@ func (c *daemonServiceClient) GetConfig(ctx context.Context, in *GetConfigReques
 	return out, nil
 }
 
+func (c *daemonServiceClient) DebugConnect(ctx context.Context, in *DebugConnectRequest, opts ...grpc.CallOption) (DaemonService_DebugConnectClient, error) {
+	stream, err := c.cc.NewStream(ctx, &DaemonService_ServiceDesc.Streams[0], "/daemon.DaemonService/DebugConnect", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &daemonServiceDebugConnectClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type DaemonService_DebugConnectClient interface {
+	Recv() (*ConnectionTraceEvent, error)
+	grpc.ClientStream
+}
+
+type daemonServiceDebugConnectClient struct {
+	grpc.ClientStream
+}
+
+func (x *daemonServiceDebugConnectClient) Recv() (*ConnectionTraceEvent, error) {
+	m := new(ConnectionTraceEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // DaemonServiceServer is the server API for DaemonService service.
 // All implementations must embed UnimplementedDaemonServiceServer
 // for forward compatibility
@@ -112,6 +146,8 @@ type DaemonServiceServer interface {
 	Down(context.Context, *DownRequest) (*DownResponse, error)
 	// GetConfig of the daemon.
 	GetConfig(context.Context, *GetConfigRequest) (*GetConfigResponse, error)
+	// DebugConnect makes a new connection attempt to a peer and streams its steps.
+	DebugConnect(*DebugConnectRequest, DaemonService_DebugConnectServer) error
 	mustEmbedUnimplementedDaemonServiceServer()
 }
 
@@ -137,6 +173,9 @@ func (UnimplementedDaemonServiceServer) Down(context.Context, *DownRequest) (*Do
 func (UnimplementedDaemonServiceServer) GetConfig(context.Context, *GetConfigRequest) (*GetConfigResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetConfig not implemented")
 }
+func (UnimplementedDaemonServiceServer) DebugConnect(*DebugConnectRequest, DaemonService_DebugConnectServer) error {
+	return status.Errorf(codes.Unimplemented, "method DebugConnect not implemented")
+}
 func (UnimplementedDaemonServiceServer) mustEmbedUnimplementedDaemonServiceServer() {}
 
 // UnsafeDaemonServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -258,6 +297,27 @@ func _DaemonService_GetConfig_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _DaemonService_DebugConnect_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(DebugConnectRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DaemonServiceServer).DebugConnect(m, &daemonServiceDebugConnectServer{stream})
+}
+
+type DaemonService_DebugConnectServer interface {
+	Send(*ConnectionTraceEvent) error
+	grpc.ServerStream
+}
+
+type daemonServiceDebugConnectServer struct {
+	grpc.ServerStream
+}
+
+func (x *daemonServiceDebugConnectServer) Send(m *ConnectionTraceEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 // DaemonService_ServiceDesc is the grpc.ServiceDesc for DaemonService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -290,6 +350,12 @@ var DaemonService_ServiceDesc = grpc.ServiceDesc{
 			Handler:    _DaemonService_GetConfig_Handler,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "DebugConnect",
+			Handler:       _DaemonService_DebugConnect_Handler,
+			ServerStreams: true,
+		},
+	},
 	Metadata: "daemon.proto",
 }
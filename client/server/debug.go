@@ -0,0 +1,122 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	gstatus "google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/netbirdio/netbird/client/internal/peer"
+	"github.com/netbirdio/netbird/client/proto"
+)
+
+const (
+	defaultDebugConnectTimeout = 30 * time.Second
+	// traceEventsBufferSize is the number of trace events buffered for a slow stream before events are dropped
+	traceEventsBufferSize = 256
+)
+
+// DebugConnect makes a new connection attempt to a peer and streams its steps until the connection has been
+// established or the timeout is reached.
+func (s *Server) DebugConnect(msg *proto.DebugConnectRequest, stream proto.DaemonService_DebugConnectServer) error {
+	s.mutex.Lock()
+	statusRecorder := s.statusRecorder
+	s.mutex.Unlock()
+
+	s.engineMu.Lock()
+	engine := s.engine
+	s.engineMu.Unlock()
+
+	if engine == nil || statusRecorder == nil {
+		return gstatus.Errorf(codes.FailedPrecondition, "the client is not connected, please run the up command first")
+	}
+
+	peerKey, err := findPeerKey(statusRecorder.GetFullStatus(), msg.GetPeer())
+	if err != nil {
+		return gstatus.Errorf(codes.NotFound, err.Error())
+	}
+
+	timeout := defaultDebugConnectTimeout
+	if msg.GetTimeout().AsDuration() > 0 {
+		timeout = msg.GetTimeout().AsDuration()
+	}
+
+	events := make(chan peer.TraceEvent, traceEventsBufferSize)
+	stopTracing, err := engine.TraceConnection(peerKey, func(event peer.TraceEvent) {
+		select {
+		case events <- event:
+		default:
+			log.Debugf("dropping %s trace event of the connection to peer %s", event.Type, peerKey)
+		}
+	})
+	if errors.Is(err, peer.ErrTracerAttached) {
+		return gstatus.Errorf(codes.AlreadyExists, "a connection attempt to peer %s is already being traced", msg.GetPeer())
+	} else if err != nil {
+		return gstatus.Errorf(codes.NotFound, err.Error())
+	}
+	defer stopTracing()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		select {
+		case event := <-events:
+			err = stream.Send(toProtoTraceEvent(event))
+			if err != nil {
+				return err
+			}
+			if event.Type == peer.TraceConnected {
+				return nil
+			}
+		case <-timer.C:
+			return gstatus.Errorf(codes.DeadlineExceeded, "no connection to peer %s established within %s", msg.GetPeer(), timeout)
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		}
+	}
+}
+
+// findPeerKey returns the public key of the peer identified by its public key, NetBird IP, FQDN or the first label of the FQDN
+func findPeerKey(fullStatus peer.FullStatus, id string) (string, error) {
+	if id == "" {
+		return "", fmt.Errorf("no peer provided")
+	}
+	name := strings.TrimSuffix(strings.ToLower(id), ".")
+
+	var matches []string
+	for _, peerState := range fullStatus.Peers {
+		fqdn := strings.TrimSuffix(strings.ToLower(peerState.FQDN), ".")
+		if peerState.PubKey == id || peerState.IP == id || fqdn == name {
+			return peerState.PubKey, nil
+		}
+		if fqdn != "" && strings.Split(fqdn, ".")[0] == name {
+			matches = append(matches, peerState.PubKey)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("peer %s not found", id)
+	case 1:
+		return matches[0], nil
+	default:
+		return "", fmt.Errorf("peer name %s is ambiguous, please provide the FQDN", id)
+	}
+}
+
+func toProtoTraceEvent(event peer.TraceEvent) *proto.ConnectionTraceEvent {
+	return &proto.ConnectionTraceEvent{
+		Type:    string(event.Type),
+		Time:    timestamppb.New(event.Time),
+		Elapsed: durationpb.New(event.Elapsed),
+		Message: event.Message,
+		Fields:  event.Fields,
+	}
+}
@@ -10,10 +10,12 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 	gstatus "google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/netbirdio/netbird/client/internal"
 	"github.com/netbirdio/netbird/client/internal/peer"
+	"github.com/netbirdio/netbird/client/internal/telemetry"
 	"github.com/netbirdio/netbird/client/proto"
 	"github.com/netbirdio/netbird/version"
 )
@@ -34,6 +36,11 @@ type Server struct {
 	proto.UnimplementedDaemonServiceServer
 
 	statusRecorder *peer.Status
+	metrics        *telemetry.ClientMetrics
+
+	// the engine is set by the running client while mutex might be held, so it is guarded by a lock of its own
+	engineMu sync.Mutex
+	engine   *internal.Engine
 }
 
 type oauthAuthFlow struct {
@@ -54,6 +61,13 @@ func New(ctx context.Context, configPath, logFile string) *Server {
 	}
 }
 
+// SetMetrics sets the metrics the client reports to. It has to be called before the server is started
+func (s *Server) SetMetrics(metrics *telemetry.ClientMetrics) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.metrics = metrics
+}
+
 func (s *Server) Start() error {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
@@ -96,13 +110,13 @@ func (s *Server) Start() error {
 	s.config = config
 
 	if s.statusRecorder == nil {
-		s.statusRecorder = peer.NewRecorder(config.ManagementURL.String())
+		s.statusRecorder = s.newStatusRecorder(config.ManagementURL.String())
 	} else {
 		s.statusRecorder.UpdateManagementAddress(config.ManagementURL.String())
 	}
 
 	go func() {
-		if err := internal.RunClient(ctx, config, s.statusRecorder); err != nil {
+		if err := internal.RunClientWithEngineListener(ctx, config, s.statusRecorder, s.setEngine); err != nil {
 			log.Errorf("init connections: %v", err)
 		}
 	}()
@@ -179,6 +193,11 @@ func (s *Server) Login(callerCtx context.Context, msg *proto.LoginRequest) (*pro
 		s.latestConfigInput.CustomDNSAddress = []byte{}
 	}
 
+	if msg.SshRecordingsDir != nil {
+		inputConfig.SSHRecordingsDir = msg.SshRecordingsDir
+		s.latestConfigInput.SSHRecordingsDir = msg.SshRecordingsDir
+	}
+
 	s.mutex.Unlock()
 
 	inputConfig.PreSharedKey = &msg.PreSharedKey
@@ -388,13 +407,13 @@ func (s *Server) Up(callerCtx context.Context, _ *proto.UpRequest) (*proto.UpRes
 	}
 
 	if s.statusRecorder == nil {
-		s.statusRecorder = peer.NewRecorder(s.config.ManagementURL.String())
+		s.statusRecorder = s.newStatusRecorder(s.config.ManagementURL.String())
 	} else {
 		s.statusRecorder.UpdateManagementAddress(s.config.ManagementURL.String())
 	}
 
 	go func() {
-		if err := internal.RunClient(ctx, s.config, s.statusRecorder); err != nil {
+		if err := internal.RunClientWithEngineListener(ctx, s.config, s.statusRecorder, s.setEngine); err != nil {
 			log.Errorf("run client connection: %v", err)
 			return
 		}
@@ -434,7 +453,7 @@ func (s *Server) Status(
 	statusResponse := proto.StatusResponse{Status: string(status), DaemonVersion: version.NetbirdVersion()}
 
 	if s.statusRecorder == nil {
-		s.statusRecorder = peer.NewRecorder(s.config.ManagementURL.String())
+		s.statusRecorder = s.newStatusRecorder(s.config.ManagementURL.String())
 	} else {
 		s.statusRecorder.UpdateManagementAddress(s.config.ManagementURL.String())
 	}
@@ -488,6 +507,7 @@ func toProtoFullStatus(fullStatus peer.FullStatus) *proto.FullStatus {
 		SignalState:     &proto.SignalState{},
 		LocalPeerState:  &proto.LocalPeerState{},
 		Peers:           []*proto.PeerState{},
+		DnsCacheState:   &proto.DNSCacheState{},
 	}
 
 	pbFullStatus.ManagementState.URL = fullStatus.ManagementState.URL
@@ -501,6 +521,10 @@ func toProtoFullStatus(fullStatus peer.FullStatus) *proto.FullStatus {
 	pbFullStatus.LocalPeerState.KernelInterface = fullStatus.LocalPeerState.KernelInterface
 	pbFullStatus.LocalPeerState.Fqdn = fullStatus.LocalPeerState.FQDN
 
+	pbFullStatus.DnsCacheState.Entries = int32(fullStatus.DNSCacheState.Entries)
+	pbFullStatus.DnsCacheState.Hits = fullStatus.DNSCacheState.Hits
+	pbFullStatus.DnsCacheState.Misses = fullStatus.DNSCacheState.Misses
+
 	for _, peerState := range fullStatus.Peers {
 		pbPeerState := &proto.PeerState{
 			IP:                     peerState.IP,
@@ -512,8 +536,29 @@ func toProtoFullStatus(fullStatus peer.FullStatus) *proto.FullStatus {
 			LocalIceCandidateType:  peerState.LocalIceCandidateType,
 			RemoteIceCandidateType: peerState.RemoteIceCandidateType,
 			Fqdn:                   peerState.FQDN,
+			SshHostKey:             peerState.SSHHostKey,
+			LastWireguardHandshake: timestamppb.New(peerState.LastWireguardHandshake),
+			BytesRx:                peerState.BytesRx,
+			BytesTx:                peerState.BytesTx,
+			Latency:                durationpb.New(peerState.Latency),
 		}
 		pbFullStatus.Peers = append(pbFullStatus.Peers, pbPeerState)
 	}
 	return &pbFullStatus
 }
+
+// setEngine keeps the engine of the running client, nil once it is stopped
+func (s *Server) setEngine(engine *internal.Engine) {
+	s.engineMu.Lock()
+	defer s.engineMu.Unlock()
+	s.engine = engine
+}
+
+// newStatusRecorder returns a new status recorder that reports to the metrics of the server
+func (s *Server) newStatusRecorder(mgmAddress string) *peer.Status {
+	recorder := peer.NewRecorder(mgmAddress)
+	if err := recorder.SetMetrics(s.metrics); err != nil {
+		log.Warnf("failed registering the peers metrics: %v", err)
+	}
+	return recorder
+}
@@ -0,0 +1,132 @@
+package ssh
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gliderlabs/ssh"
+	log "github.com/sirupsen/logrus"
+)
+
+// SessionEventType is the type of an SSH session audit event
+type SessionEventType int
+
+const (
+	// SessionStarted indicates that a remote peer opened a session
+	SessionStarted SessionEventType = iota
+	// SessionEnded indicates that a session has been closed
+	SessionEnded
+)
+
+// SessionEvent is an audit event of a session handled by the SSH server
+type SessionEvent struct {
+	Type SessionEventType
+	// SessionID is the ID of the SSH session
+	SessionID string
+	// PeerKey is the WireGuard public key of the remote peer that opened the session.
+	// Empty if the session was authenticated with an SSH certificate
+	PeerKey string
+	// RemoteAddr is the address the session was opened from
+	RemoteAddr string
+	// LocalUser is the local user the remote peer logged in as
+	LocalUser string
+	// Command of a non-interactive session, e.g., "uptime" or "subsystem sftp". Empty for interactive shells
+	Command   string
+	StartedAt time.Time
+	// Duration of the session. Only set for SessionEnded events
+	Duration time.Duration
+	// UserID is the ID of the NetBird user of the SSH certificate the session was authenticated with
+	UserID string
+}
+
+// SessionAuditor is notified about the start and the end of the SSH sessions. It must not block
+type SessionAuditor func(event SessionEvent)
+
+// SetSessionAuditor sets the auditor notified about the start and the end of the SSH sessions
+func (srv *DefaultServer) SetSessionAuditor(auditor SessionAuditor) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	srv.auditor = auditor
+}
+
+// SetRecorder sets the recorder of the SSH sessions. A nil recorder disables recording
+func (srv *DefaultServer) SetRecorder(recorder *Recorder) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	srv.recorder = recorder
+}
+
+// auditSessionStart notifies the auditor about a new session and returns a function notifying about its end
+func (srv *DefaultServer) auditSessionStart(session ssh.Session) func() {
+	peer, _ := session.Context().Value(peerKeyContextKey).(string)
+	command := session.RawCommand()
+	if command == "" && session.Subsystem() != "" {
+		command = "subsystem " + session.Subsystem()
+	}
+	event := SessionEvent{
+		Type:       SessionStarted,
+		SessionID:  session.Context().SessionID(),
+		PeerKey:    peer,
+		RemoteAddr: session.RemoteAddr().String(),
+		LocalUser:  session.User(),
+		Command:    command,
+		StartedAt:  time.Now(),
+	}
+	event.UserID, _ = session.Context().Value(certUserContextKey).(string)
+
+	source := "peer " + peer
+	if event.UserID != "" {
+		source = "user " + event.UserID
+	}
+	log.Infof("started SSH session %s from %s (%s) as local user %s", event.SessionID, source, event.RemoteAddr, event.LocalUser)
+	srv.audit(event)
+
+	return func() {
+		event.Type = SessionEnded
+		event.Duration = time.Since(event.StartedAt)
+		log.Infof("ended SSH session %s after %s", event.SessionID, event.Duration)
+		srv.audit(event)
+	}
+}
+
+func (srv *DefaultServer) audit(event SessionEvent) {
+	srv.mu.Lock()
+	auditor := srv.auditor
+	srv.mu.Unlock()
+
+	if auditor != nil {
+		auditor(event)
+	}
+}
+
+// startRecording starts recording the session output if recording is enabled. It returns nil otherwise
+func (srv *DefaultServer) startRecording(session ssh.Session, ptyReq ssh.Pty, shell string) *recording {
+	srv.mu.Lock()
+	recorder := srv.recorder
+	srv.mu.Unlock()
+
+	if recorder == nil {
+		return nil
+	}
+
+	header := recordingHeader{
+		Width:   ptyReq.Window.Width,
+		Height:  ptyReq.Window.Height,
+		Command: session.RawCommand(),
+		Title:   fmt.Sprintf("%s from %s", session.User(), session.RemoteAddr()),
+		Env:     map[string]string{"SHELL": shell},
+	}
+	if ptyReq.Term != "" {
+		header.Env["TERM"] = ptyReq.Term
+	}
+
+	rec, err := recorder.startRecording(session.Context().SessionID(), header)
+	if err != nil {
+		log.Warnf("failed to start recording SSH session from %v: %v", session.RemoteAddr(), err)
+		return nil
+	}
+
+	return rec
+}
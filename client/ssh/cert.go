@@ -0,0 +1,100 @@
+package ssh
+
+import (
+	"github.com/gliderlabs/ssh"
+	log "github.com/sirupsen/logrus"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+const (
+	// certUserContextKey is the SSH context key of the NetBird user ID of the certificate the connection authenticated with
+	certUserContextKey contextKey = "netbird-cert-user"
+	// certPortForwardingContextKey is the SSH context key telling whether the certificate permits port forwarding
+	certPortForwardingContextKey contextKey = "netbird-cert-port-forwarding"
+	// permitPortForwardingExtension is the certificate extension allowing port forwarding
+	permitPortForwardingExtension = "permit-port-forwarding"
+)
+
+// CertificateAccess is what a NetBird user logging in with an SSH certificate is allowed to do
+type CertificateAccess struct {
+	// LocalUsers the user is allowed to log in as
+	LocalUsers []string
+	// PortForwarding allows the user to forward TCP ports
+	PortForwarding bool
+	// AnyLocalUser allows the user to log in as any local user of the certificate principals
+	AnyLocalUser bool
+}
+
+// SetUserCertificateAuthority sets the public key, in the authorized_keys format, of the certificate authority signing
+// the user certificates and the access of the users indexed by NetBird user ID.
+// An empty key disables certificate logins and a nil access map allows any local user of the certificate principals.
+// Users missing from a non-nil access map are denied
+func (srv *DefaultServer) SetUserCertificateAuthority(caPublicKey string, access map[string]CertificateAccess) error {
+	var caKey ssh.PublicKey
+	if caPublicKey != "" {
+		parsedKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(caPublicKey))
+		if err != nil {
+			return err
+		}
+		caKey = parsedKey
+	}
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	srv.userCAKey = caKey
+	srv.certificateAccess = access
+	return nil
+}
+
+// authenticateCertificate checks that a user certificate is signed by the trusted authority, is valid and allows
+// logging in as the requested local user. Must be called with the lock held
+func (srv *DefaultServer) authenticateCertificate(ctx ssh.Context, cert *gossh.Certificate) bool {
+	if srv.userCAKey == nil || cert.CertType != gossh.UserCert || !ssh.KeysEqual(cert.SignatureKey, srv.userCAKey) {
+		log.Warnf("denied SSH certificate %d of %s from %v: not signed by the trusted authority",
+			cert.Serial, cert.KeyId, ctx.RemoteAddr())
+		return false
+	}
+
+	// CheckCert verifies the signature, the validity period and that the local user is one of the principals
+	checker := &gossh.CertChecker{}
+	err := checker.CheckCert(ctx.User(), cert)
+	if err != nil {
+		log.Warnf("denied SSH certificate %d of %s from %v: %v", cert.Serial, cert.KeyId, ctx.RemoteAddr(), err)
+		return false
+	}
+
+	if !srv.isCertificateUserAllowed(cert.KeyId, ctx.User()) {
+		log.Warnf("user %s from %v is not allowed to log in as local user %s", cert.KeyId, ctx.RemoteAddr(), ctx.User())
+		return false
+	}
+
+	ctx.SetValue(certUserContextKey, cert.KeyId)
+	_, permitPortForwarding := cert.Permissions.Extensions[permitPortForwardingExtension]
+	ctx.SetValue(certPortForwardingContextKey, permitPortForwarding)
+	return true
+}
+
+// isCertificateUserAllowed checks whether a NetBird user is allowed to log in as a given local user with a certificate.
+// Must be called with the lock held
+func (srv *DefaultServer) isCertificateUserAllowed(userID, localUser string) bool {
+	if srv.certificateAccess == nil {
+		return true
+	}
+
+	access, ok := srv.certificateAccess[userID]
+	if !ok {
+		return false
+	}
+	if access.AnyLocalUser {
+		return true
+	}
+
+	for _, allowed := range access.LocalUsers {
+		if allowed == localUser {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,116 @@
+package ssh
+
+import (
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+func newTestSigner(t *testing.T) (ssh.Signer, string) {
+	t.Helper()
+
+	privateKey, err := GeneratePrivateKey(ED25519)
+	require.NoError(t, err)
+	signer, err := ssh.ParsePrivateKey(privateKey)
+	require.NoError(t, err)
+	publicKey, err := GeneratePublicKey(privateKey)
+	require.NoError(t, err)
+
+	return signer, string(publicKey)
+}
+
+func newTestCertificate(t *testing.T, ca ssh.Signer, keyID string, principals []string, validBefore time.Time, extensions ...string) *ssh.Certificate {
+	t.Helper()
+
+	userSigner, _ := newTestSigner(t)
+	cert := &ssh.Certificate{
+		Key:             userSigner.PublicKey(),
+		CertType:        ssh.UserCert,
+		KeyId:           keyID,
+		ValidPrincipals: principals,
+		ValidAfter:      uint64(time.Now().Add(-time.Minute).Unix()),
+		ValidBefore:     uint64(validBefore.Unix()),
+	}
+	if len(extensions) > 0 {
+		cert.Permissions.Extensions = make(map[string]string, len(extensions))
+		for _, extension := range extensions {
+			cert.Permissions.Extensions[extension] = ""
+		}
+	}
+	require.NoError(t, cert.SignCert(rand.Reader, ca))
+
+	return cert
+}
+
+func TestServer_CertificateAuthentication(t *testing.T) {
+	key, err := GeneratePrivateKey(ED25519)
+	require.NoError(t, err)
+	server, err := newDefaultServer(key, "localhost:")
+	require.NoError(t, err)
+
+	ca, caPublicKey := newTestSigner(t)
+	otherCA, _ := newTestSigner(t)
+	validBefore := time.Now().Add(time.Hour)
+
+	cert := newTestCertificate(t, ca, "alice", []string{"deploy"}, validBefore)
+	assert.False(t, server.publicKeyHandler(newTestContext("deploy"), cert),
+		"certificates shouldn't be accepted without a trusted authority")
+
+	require.NoError(t, server.SetUserCertificateAuthority(caPublicKey, nil))
+
+	ctx := newTestContext("deploy")
+	assert.True(t, server.publicKeyHandler(ctx, cert))
+	assert.Equal(t, "alice", ctx.Value(certUserContextKey))
+	assert.False(t, server.isPortForwardingAllowed(ctx),
+		"port forwarding should be denied without the certificate extension")
+
+	forwardingCert := newTestCertificate(t, ca, "alice", []string{"deploy"}, validBefore, permitPortForwardingExtension)
+	forwardingCtx := newTestContext("deploy")
+	assert.True(t, server.publicKeyHandler(forwardingCtx, forwardingCert))
+	assert.True(t, server.isPortForwardingAllowed(forwardingCtx),
+		"the certificate extension should allow port forwarding without an access map")
+
+	assert.False(t, server.publicKeyHandler(newTestContext("root"), cert),
+		"the local user should be one of the certificate principals")
+
+	expired := newTestCertificate(t, ca, "alice", []string{"deploy"}, time.Now().Add(-time.Second))
+	assert.False(t, server.publicKeyHandler(newTestContext("deploy"), expired), "expired certificates should be denied")
+
+	untrusted := newTestCertificate(t, otherCA, "alice", []string{"deploy"}, validBefore)
+	assert.False(t, server.publicKeyHandler(newTestContext("deploy"), untrusted),
+		"certificates of other authorities should be denied")
+
+	require.NoError(t, server.SetUserCertificateAuthority(caPublicKey, map[string]CertificateAccess{
+		"bob": {LocalUsers: []string{"deploy"}, PortForwarding: true},
+	}))
+	assert.False(t, server.publicKeyHandler(newTestContext("deploy"), cert),
+		"users without access should be denied when the access is restricted")
+
+	bobCtx := newTestContext("deploy")
+	bobCert := newTestCertificate(t, ca, "bob", []string{"deploy"}, validBefore)
+	assert.True(t, server.publicKeyHandler(bobCtx, bobCert))
+	assert.True(t, server.isPortForwardingAllowed(bobCtx))
+
+	require.NoError(t, server.SetUserCertificateAuthority(caPublicKey, map[string]CertificateAccess{
+		"bob": {AnyLocalUser: true},
+	}))
+	rootCert := newTestCertificate(t, ca, "bob", []string{"deploy", "root"}, validBefore)
+	assert.True(t, server.publicKeyHandler(newTestContext("root"), rootCert),
+		"users should be allowed any local user of the certificate principals")
+	assert.False(t, server.publicKeyHandler(newTestContext("admin"), rootCert),
+		"the local user should still be one of the certificate principals")
+	assert.False(t, server.publicKeyHandler(newTestContext("deploy"), cert), "users without access should be denied")
+
+	// e.g. the only user with access got blocked
+	require.NoError(t, server.SetUserCertificateAuthority(caPublicKey, map[string]CertificateAccess{}))
+	assert.False(t, server.publicKeyHandler(newTestContext("deploy"), bobCert),
+		"all the users should be denied with an empty access")
+
+	require.NoError(t, server.SetUserCertificateAuthority("", nil))
+	assert.False(t, server.publicKeyHandler(newTestContext("deploy"), bobCert),
+		"certificates should be denied once the authority is removed")
+}
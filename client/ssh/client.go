@@ -1,6 +1,7 @@
 package ssh
 
 import (
+	"errors"
 	"fmt"
 	"golang.org/x/crypto/ssh"
 	"golang.org/x/term"
@@ -84,6 +85,36 @@ func (c *Client) OpenTerminal() error {
 	return nil
 }
 
+// ExecuteCommand runs a command on the remote SSH server without a PTY forwarding the standard input and output.
+// It returns the exit code of the remote command
+func (c *Client) ExecuteCommand(command string) (int, error) {
+	session, err := c.client.NewSession()
+	if err != nil {
+		return 0, fmt.Errorf("failed to open new session: %v", err)
+	}
+	defer func() {
+		err := session.Close()
+		if err != nil {
+			return
+		}
+	}()
+
+	session.Stdout = os.Stdout
+	session.Stderr = os.Stderr
+	session.Stdin = os.Stdin
+
+	err = session.Run(command)
+	if err != nil {
+		var exitErr *ssh.ExitError
+		if errors.As(err, &exitErr) {
+			return exitErr.ExitStatus(), nil
+		}
+		return 0, fmt.Errorf("failed running command on the remote host: %s", err)
+	}
+
+	return 0, nil
+}
+
 // DialWithKey connects to the remote SSH server with a provided private key file (PEM).
 func DialWithKey(addr, user string, privateKey []byte) (*Client, error) {
 
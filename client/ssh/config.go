@@ -0,0 +1,119 @@
+package ssh
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// ConfigBlockBegin marks the beginning of the NetBird managed block in an OpenSSH client config file
+	ConfigBlockBegin = "# BEGIN NETBIRD"
+	// ConfigBlockEnd marks the end of the NetBird managed block in an OpenSSH client config file
+	ConfigBlockEnd = "# END NETBIRD"
+)
+
+// PeerHost is a remote peer that can be reached with the OpenSSH client
+type PeerHost struct {
+	// FQDN is the domain name of the peer
+	FQDN string
+	// IP is the NetBird IP address of the peer
+	IP string
+	// HostKey is the public key of the peer's SSH server in the authorized_keys format
+	HostKey string
+}
+
+// OpenSSHConfig holds the settings used to generate the OpenSSH client configuration for NetBird peers
+type OpenSSHConfig struct {
+	// Port of the NetBird SSH servers
+	Port int
+	// IdentityFile is the path of the private key used to authenticate to the peers
+	IdentityFile string
+	// KnownHostsFile is the path of the known_hosts file holding the host keys of the peers
+	KnownHostsFile string
+	// ProxyCommand connects the OpenSSH client to the peers, e.g. "netbird ssh proxy %h %p"
+	ProxyCommand string
+}
+
+// GenerateConfigBlock returns the OpenSSH client config block with one Host entry per peer with a domain name
+func GenerateConfigBlock(hosts []PeerHost, config OpenSSHConfig) string {
+	var b strings.Builder
+	b.WriteString(ConfigBlockBegin + "\n")
+	b.WriteString("# This block is generated by \"netbird ssh config\", manual changes will be overwritten\n")
+	for _, host := range hosts {
+		if host.FQDN == "" {
+			continue
+		}
+		b.WriteString(fmt.Sprintf("Host %s\n", host.FQDN))
+		if host.IP != "" {
+			b.WriteString(fmt.Sprintf("    HostName %s\n", host.IP))
+			// match the known_hosts entries of the peer no matter how the host has been addressed
+			b.WriteString(fmt.Sprintf("    HostKeyAlias %s\n", host.FQDN))
+		}
+		b.WriteString(fmt.Sprintf("    Port %d\n", config.Port))
+		b.WriteString(fmt.Sprintf("    IdentityFile %s\n", quoteConfigValue(config.IdentityFile)))
+		b.WriteString("    IdentitiesOnly yes\n")
+		b.WriteString(fmt.Sprintf("    UserKnownHostsFile %s\n", quoteConfigValue(config.KnownHostsFile)))
+		b.WriteString("    StrictHostKeyChecking yes\n")
+		if config.ProxyCommand != "" {
+			b.WriteString(fmt.Sprintf("    ProxyCommand %s\n", config.ProxyCommand))
+		}
+	}
+	b.WriteString(ConfigBlockEnd + "\n")
+	return b.String()
+}
+
+// GenerateKnownHosts returns the known_hosts entries of the peers that have an SSH server key
+func GenerateKnownHosts(hosts []PeerHost, port int) string {
+	var b strings.Builder
+	for _, host := range hosts {
+		if host.HostKey == "" {
+			continue
+		}
+		var names []string
+		for _, name := range []string{host.FQDN, host.IP} {
+			if name != "" {
+				names = append(names, knownHostsName(name, port))
+			}
+		}
+		if len(names) == 0 {
+			continue
+		}
+		b.WriteString(fmt.Sprintf("%s %s\n", strings.Join(names, ","), strings.TrimSpace(host.HostKey)))
+	}
+	return b.String()
+}
+
+// ReplaceConfigBlock replaces the NetBird managed block of the OpenSSH client config with the given one.
+// The block is prepended to the config if it doesn't exist yet, so that it takes precedence over wildcard hosts
+func ReplaceConfigBlock(config, block string) string {
+	start := strings.Index(config, ConfigBlockBegin)
+	end := strings.Index(config, ConfigBlockEnd)
+	if start == -1 || end == -1 || end < start {
+		if config == "" {
+			return block
+		}
+		return block + "\n" + config
+	}
+
+	end += len(ConfigBlockEnd)
+	// drop the line break of the replaced block
+	if end < len(config) && config[end] == '\n' {
+		end++
+	}
+
+	return config[:start] + block + config[end:]
+}
+
+func knownHostsName(host string, port int) string {
+	if port == 22 {
+		return host
+	}
+	return fmt.Sprintf("[%s]:%d", host, port)
+}
+
+func quoteConfigValue(value string) string {
+	if strings.ContainsAny(value, " \t") {
+		return fmt.Sprintf("%q", value)
+	}
+	return value
+}
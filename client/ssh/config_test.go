@@ -0,0 +1,92 @@
+package ssh
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testPeerHosts = []PeerHost{
+	{FQDN: "server.netbird.cloud", IP: "100.64.0.2", HostKey: "ssh-ed25519 AAAAserver\n"},
+	{FQDN: "laptop.netbird.cloud", IP: "100.64.0.3"},
+	{IP: "100.64.0.4", HostKey: "ssh-ed25519 AAAAnoname"},
+}
+
+func TestGenerateKnownHosts(t *testing.T) {
+	knownHosts := GenerateKnownHosts(testPeerHosts, DefaultSSHPort)
+	assert.Equal(t, "[server.netbird.cloud]:44338,[100.64.0.2]:44338 ssh-ed25519 AAAAserver\n"+
+		"[100.64.0.4]:44338 ssh-ed25519 AAAAnoname\n", knownHosts)
+
+	assert.Equal(t, "server.netbird.cloud,100.64.0.2 ssh-ed25519 AAAAserver\n",
+		GenerateKnownHosts(testPeerHosts[:1], 22), "the default port shouldn't be added to the host names")
+}
+
+func TestGenerateConfigBlock(t *testing.T) {
+	block := GenerateConfigBlock(testPeerHosts, OpenSSHConfig{
+		Port:           DefaultSSHPort,
+		IdentityFile:   "/home/user/.ssh/netbird_user_id",
+		KnownHostsFile: "/home/my user/.ssh/netbird_known_hosts",
+		ProxyCommand:   "netbird ssh proxy %h %p",
+	})
+
+	expected := ConfigBlockBegin + "\n" +
+		"# This block is generated by \"netbird ssh config\", manual changes will be overwritten\n" +
+		"Host server.netbird.cloud\n" +
+		"    HostName 100.64.0.2\n" +
+		"    HostKeyAlias server.netbird.cloud\n" +
+		"    Port 44338\n" +
+		"    IdentityFile /home/user/.ssh/netbird_user_id\n" +
+		"    IdentitiesOnly yes\n" +
+		"    UserKnownHostsFile \"/home/my user/.ssh/netbird_known_hosts\"\n" +
+		"    StrictHostKeyChecking yes\n" +
+		"    ProxyCommand netbird ssh proxy %h %p\n" +
+		"Host laptop.netbird.cloud\n" +
+		"    HostName 100.64.0.3\n" +
+		"    HostKeyAlias laptop.netbird.cloud\n" +
+		"    Port 44338\n" +
+		"    IdentityFile /home/user/.ssh/netbird_user_id\n" +
+		"    IdentitiesOnly yes\n" +
+		"    UserKnownHostsFile \"/home/my user/.ssh/netbird_known_hosts\"\n" +
+		"    StrictHostKeyChecking yes\n" +
+		"    ProxyCommand netbird ssh proxy %h %p\n" +
+		ConfigBlockEnd + "\n"
+
+	assert.Equal(t, expected, block)
+}
+
+func TestReplaceConfigBlock(t *testing.T) {
+	block := ConfigBlockBegin + "\nHost peer\n" + ConfigBlockEnd + "\n"
+	updated := ConfigBlockBegin + "\nHost other\n" + ConfigBlockEnd + "\n"
+	userConfig := "Host *\n    ServerAliveInterval 60\n"
+
+	testCases := []struct {
+		name     string
+		config   string
+		block    string
+		expected string
+	}{
+		{
+			name:     "Should Create Config",
+			block:    block,
+			expected: block,
+		},
+		{
+			name:     "Should Prepend Block",
+			config:   userConfig,
+			block:    block,
+			expected: block + "\n" + userConfig,
+		},
+		{
+			name:     "Should Replace Existing Block",
+			config:   "Host first\n" + block + "\n" + userConfig,
+			block:    updated,
+			expected: "Host first\n" + updated + "\n" + userConfig,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.expected, ReplaceConfigBlock(testCase.config, testCase.block))
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package ssh
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/user"
+	"syscall"
+
+	"github.com/gliderlabs/ssh"
+	log "github.com/sirupsen/logrus"
+)
+
+// defaultPath is the PATH of the commands when the NetBird process has none
+const defaultPath = "/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"
+
+// runCommand runs the command of a session without a PTY in the shell of the local user and returns its exit code.
+// Without a command the shell reads the commands from the standard input
+func runCommand(session ssh.Session, localUser *user.User, shell string, stdout, stderr io.Writer) int {
+	var args []string
+	if session.RawCommand() != "" {
+		args = []string{"-c", session.RawCommand()}
+	}
+
+	cmd := exec.CommandContext(session.Context(), shell, args...)
+	cmd.Env = commandEnv(localUser, shell, session.Environ())
+
+	return runAsUser(cmd, session, localUser, stdout, stderr)
+}
+
+// runAsUser runs a command as the local user in its home directory with the session as the standard input
+// and returns its exit code
+func runAsUser(cmd *exec.Cmd, session ssh.Session, localUser *user.User, stdout, stderr io.Writer) int {
+	sysProcAttr, err := userSysProcAttr(localUser)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "%v\n", err)
+		log.Warnf("failed running command as local user %s: %v", localUser.Username, err)
+		return 1
+	}
+	cmd.SysProcAttr = sysProcAttr
+	cmd.Dir = localUser.HomeDir
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	// a pipe rather than the session itself, so that waiting for the command doesn't wait for the end of the input
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		log.Errorf("failed creating stdin pipe of SSH command: %v", err)
+		return 1
+	}
+
+	err = cmd.Start()
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "failed running command: %v\n", err)
+		log.Warnf("failed running command as local user %s: %v", localUser.Username, err)
+		return 1
+	}
+
+	go func() {
+		_, _ = io.Copy(stdin, session)
+		_ = stdin.Close()
+	}()
+
+	return exitCode(cmd.Wait())
+}
+
+// commandEnv returns the environment of a command run as the local user with the accepted session variables
+func commandEnv(localUser *user.User, shell string, sessionEnv []string) []string {
+	path := os.Getenv("PATH")
+	if path == "" {
+		path = defaultPath
+	}
+
+	env := append(prepareUserEnv(localUser, shell), "PATH="+path)
+	for _, v := range sessionEnv {
+		if acceptEnv(v) {
+			env = append(env, v)
+		}
+	}
+
+	return env
+}
+
+// exitCode returns the exit code of a command from the error returned by waiting for it.
+// Like shells it returns 128 plus the signal number for commands terminated by a signal
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return 1
+	}
+
+	if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		return 128 + int(status.Signal())
+	}
+
+	if exitErr.ExitCode() < 0 {
+		return 1
+	}
+
+	return exitErr.ExitCode()
+}
@@ -0,0 +1,44 @@
+//go:build linux || darwin
+
+package ssh
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"strconv"
+	"syscall"
+)
+
+// userSysProcAttr returns the process attributes running a command as the local user.
+// It returns nil when the local user is the user of the NetBird process
+func userSysProcAttr(localUser *user.User) (*syscall.SysProcAttr, error) {
+	uid, err := strconv.ParseUint(localUser.Uid, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid uid %s of local user %s", localUser.Uid, localUser.Username)
+	}
+	if uint32(os.Getuid()) == uint32(uid) {
+		return nil, nil
+	}
+
+	gid, err := strconv.ParseUint(localUser.Gid, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid gid %s of local user %s", localUser.Gid, localUser.Username)
+	}
+
+	var groups []uint32
+	groupIDs, err := localUser.GroupIds()
+	if err == nil {
+		for _, groupID := range groupIDs {
+			group, err := strconv.ParseUint(groupID, 10, 32)
+			if err != nil {
+				continue
+			}
+			groups = append(groups, uint32(group))
+		}
+	}
+
+	return &syscall.SysProcAttr{
+		Credential: &syscall.Credential{Uid: uint32(uid), Gid: uint32(gid), Groups: groups},
+	}, nil
+}
@@ -0,0 +1,21 @@
+package ssh
+
+import (
+	"fmt"
+	"os/user"
+	"syscall"
+)
+
+// userSysProcAttr returns the process attributes running a command as the local user.
+// Running commands as another user than the user of the NetBird process isn't supported on Windows
+func userSysProcAttr(localUser *user.User) (*syscall.SysProcAttr, error) {
+	current, err := user.Current()
+	if err != nil {
+		return nil, err
+	}
+	if current.Uid != localUser.Uid {
+		return nil, fmt.Errorf("running commands as local user %s is not supported on Windows", localUser.Username)
+	}
+
+	return nil, nil
+}
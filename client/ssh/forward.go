@@ -0,0 +1,99 @@
+package ssh
+
+import (
+	"net"
+
+	"github.com/gliderlabs/ssh"
+	log "github.com/sirupsen/logrus"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// defaultForwardBindHost is the address remote port forwarding listens on when the client doesn't specify one
+const defaultForwardBindHost = "localhost"
+
+// remoteForwardRequest is the payload of the tcpip-forward and cancel-tcpip-forward requests
+type remoteForwardRequest struct {
+	BindAddr string
+	BindPort uint32
+}
+
+// SetPortForwarding sets the peers, indexed by WireGuard public key, allowed to forward TCP ports
+func (srv *DefaultServer) SetPortForwarding(allowedPeers map[string]bool) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	srv.portForwarding = allowedPeers
+}
+
+func (srv *DefaultServer) isPortForwardingAllowed(ctx ssh.Context) bool {
+	peer, _ := ctx.Value(peerKeyContextKey).(string)
+	certUser, isCert := ctx.Value(certUserContextKey).(string)
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	if isCert {
+		if access, ok := srv.certificateAccess[certUser]; ok {
+			return access.PortForwarding
+		}
+		// without an access entry the certificate extensions set by the authority apply
+		permitted, _ := ctx.Value(certPortForwardingContextKey).(bool)
+		return permitted
+	}
+
+	return srv.portForwarding[peer]
+}
+
+// localPortForwardingCallback allows peers with port forwarding permission to open connections from this host
+func (srv *DefaultServer) localPortForwardingCallback(ctx ssh.Context, destinationHost string, destinationPort uint32) bool {
+	if !srv.isPortForwardingAllowed(ctx) {
+		log.Warnf("denied local port forwarding to %s:%d from %v", destinationHost, destinationPort, ctx.RemoteAddr())
+		return false
+	}
+
+	log.Debugf("local port forwarding to %s:%d from %v", destinationHost, destinationPort, ctx.RemoteAddr())
+	return true
+}
+
+// reversePortForwardingCallback allows peers with port forwarding permission to listen on this host.
+// Like the OpenSSH server without GatewayPorts, it only allows listening on the loopback interface
+func (srv *DefaultServer) reversePortForwardingCallback(ctx ssh.Context, bindHost string, bindPort uint32) bool {
+	if bindHost == "" {
+		bindHost = defaultForwardBindHost
+	}
+
+	if !srv.isPortForwardingAllowed(ctx) {
+		log.Warnf("denied remote port forwarding on %s:%d from %v", bindHost, bindPort, ctx.RemoteAddr())
+		return false
+	}
+
+	if !isLoopbackHost(bindHost) {
+		log.Warnf("denied remote port forwarding on non-loopback address %s:%d from %v", bindHost, bindPort, ctx.RemoteAddr())
+		return false
+	}
+
+	log.Debugf("remote port forwarding on %s:%d from %v", bindHost, bindPort, ctx.RemoteAddr())
+	return true
+}
+
+func isLoopbackHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// withDefaultForwardBindHost replaces the empty bind address of the remote port forwarding requests,
+// which means all interfaces in the SSH protocol, with the default bind address before handling them.
+// Cancel requests are changed in the same way so that they match the listeners they were created with
+func withDefaultForwardBindHost(handler ssh.RequestHandler) ssh.RequestHandler {
+	return func(ctx ssh.Context, srv *ssh.Server, req *gossh.Request) (bool, []byte) {
+		var payload remoteForwardRequest
+		if err := gossh.Unmarshal(req.Payload, &payload); err == nil && payload.BindAddr == "" {
+			payload.BindAddr = defaultForwardBindHost
+			req.Payload = gossh.Marshal(&payload)
+		}
+		return handler(ctx, srv, req)
+	}
+}
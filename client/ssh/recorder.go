@@ -0,0 +1,231 @@
+package ssh
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// recordingExtension is the file extension of asciicast recordings
+	recordingExtension = ".cast"
+	// defaultMaxRecordings is the number of recordings kept in the recordings directory
+	defaultMaxRecordings = 500
+	// defaultMaxRecordingsSize is the total size in bytes of the recordings kept in the recordings directory
+	defaultMaxRecordingsSize = 1 << 30
+	// defaultTermWidth and defaultTermHeight are the terminal size of sessions without a PTY
+	defaultTermWidth  = 80
+	defaultTermHeight = 24
+)
+
+// Recorder writes SSH sessions to a local directory in the asciicast v2 format.
+// The oldest recordings are removed once there are more than maxRecordings or they exceed maxSize bytes
+type Recorder struct {
+	dir           string
+	maxRecordings int
+	maxSize       int64
+	mu            sync.Mutex
+}
+
+// NewRecorder creates a Recorder writing to the given directory which is created if it doesn't exist
+func NewRecorder(dir string) (*Recorder, error) {
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return nil, fmt.Errorf("failed creating SSH recordings directory %s: %v", dir, err)
+	}
+
+	return &Recorder{
+		dir:           dir,
+		maxRecordings: defaultMaxRecordings,
+		maxSize:       defaultMaxRecordingsSize,
+	}, nil
+}
+
+// recordingHeader is the header line of an asciicast v2 recording
+type recordingHeader struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Command   string            `json:"command,omitempty"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// recording is a single session recording. It is an io.Writer of the session output.
+// Recording errors never fail the writes, so that the recording can't interrupt the session
+type recording struct {
+	file    *os.File
+	start   time.Time
+	pending []byte
+	closed  bool
+	// failed is set once writing the recording fails, further output is dropped
+	failed bool
+	mu     sync.Mutex
+}
+
+// startRecording creates a new recording file for a session and removes the oldest recordings if needed
+func (r *Recorder) startRecording(sessionID string, header recordingHeader) (*recording, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	start := time.Now()
+	header.Version = 2
+	header.Timestamp = start.Unix()
+	if header.Width == 0 || header.Height == 0 {
+		header.Width = defaultTermWidth
+		header.Height = defaultTermHeight
+	}
+
+	if len(sessionID) > 16 {
+		sessionID = sessionID[:16]
+	}
+	name := fmt.Sprintf("%s-%s%s", start.UTC().Format("20060102T150405.000Z"), sessionID, recordingExtension)
+
+	file, err := os.OpenFile(filepath.Join(r.dir, name), os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	rec := &recording{file: file, start: start}
+	err = rec.writeLine(header)
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
+	r.rotate(name)
+
+	return rec, nil
+}
+
+// rotate removes the oldest recordings exceeding the limits keeping the current one
+func (r *Recorder) rotate(current string) {
+	entries, err := os.ReadDir(r.dir)
+	if err != nil {
+		log.Warnf("failed reading SSH recordings directory %s: %v", r.dir, err)
+		return
+	}
+
+	var recordings []os.DirEntry
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() || !strings.HasSuffix(entry.Name(), recordingExtension) {
+			continue
+		}
+		recordings = append(recordings, entry)
+	}
+
+	// names start with the recording time, so the newest recordings are the first ones after sorting
+	sort.Slice(recordings, func(i, j int) bool {
+		return recordings[i].Name() > recordings[j].Name()
+	})
+
+	var count int
+	var size int64
+	for _, entry := range recordings {
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		count++
+		size += info.Size()
+		if entry.Name() == current || (count <= r.maxRecordings && size <= r.maxSize) {
+			continue
+		}
+
+		err = os.Remove(filepath.Join(r.dir, entry.Name()))
+		if err != nil {
+			log.Warnf("failed removing SSH recording %s: %v", entry.Name(), err)
+		}
+	}
+}
+
+// Write records session output as an output event
+func (rec *recording) Write(p []byte) (int, error) {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	if rec.closed || rec.failed {
+		return len(p), nil
+	}
+
+	// keep incomplete UTF-8 sequences until the rest of the bytes are written
+	data := append(rec.pending, p...)
+	complete := len(data)
+	for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(data[i]) {
+			if !utf8.FullRune(data[i:]) {
+				complete = i
+			}
+			break
+		}
+	}
+	rec.pending = append([]byte(nil), data[complete:]...)
+
+	if complete == 0 {
+		return len(p), nil
+	}
+
+	err := rec.writeEvent("o", string(data[:complete]))
+	if err != nil {
+		log.Warnf("failed recording SSH session output to %s, stopping the recording: %v", rec.file.Name(), err)
+		rec.failed = true
+		rec.pending = nil
+	}
+
+	return len(p), nil
+}
+
+// resize records a terminal size change
+func (rec *recording) resize(width, height int) {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	if rec.closed || rec.failed {
+		return
+	}
+
+	err := rec.writeEvent("r", fmt.Sprintf("%dx%d", width, height))
+	if err != nil {
+		log.Debugf("failed recording SSH terminal resize: %v", err)
+	}
+}
+
+// Close flushes the pending output and closes the recording file
+func (rec *recording) Close() error {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	if rec.closed {
+		return nil
+	}
+	rec.closed = true
+
+	if len(rec.pending) > 0 && !rec.failed {
+		_ = rec.writeEvent("o", string(rec.pending))
+	}
+
+	return rec.file.Close()
+}
+
+func (rec *recording) writeEvent(code, data string) error {
+	elapsed := time.Since(rec.start).Seconds()
+	return rec.writeLine([]interface{}{elapsed, code, data})
+}
+
+func (rec *recording) writeLine(v interface{}) error {
+	line, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = rec.file.Write(append(line, '\n'))
+	return err
+}
@@ -0,0 +1,117 @@
+package ssh
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorder_Recording(t *testing.T) {
+	recorder, err := NewRecorder(filepath.Join(t.TempDir(), "recordings"))
+	require.NoError(t, err)
+
+	rec, err := recorder.startRecording("0123456789abcdef0123", recordingHeader{
+		Width:  120,
+		Height: 40,
+		Title:  "deploy from 100.64.0.2:52000",
+		Env:    map[string]string{"TERM": "xterm"},
+	})
+	require.NoError(t, err)
+
+	euro := []byte("€")
+	_, err = rec.Write([]byte("hello "))
+	require.NoError(t, err)
+	// a multibyte character split across writes should be recorded as a whole
+	_, err = rec.Write(euro[:1])
+	require.NoError(t, err)
+	_, err = rec.Write(euro[1:])
+	require.NoError(t, err)
+	rec.resize(100, 30)
+	require.NoError(t, rec.Close())
+
+	_, err = rec.Write([]byte("after close"))
+	require.NoError(t, err, "writes after close should be ignored")
+
+	entries, err := os.ReadDir(recorder.dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Contains(t, entries[0].Name(), "0123456789abcdef"+recordingExtension)
+
+	file, err := os.Open(filepath.Join(recorder.dir, entries[0].Name()))
+	require.NoError(t, err)
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	require.True(t, scanner.Scan())
+	header := recordingHeader{}
+	require.NoError(t, json.Unmarshal(scanner.Bytes(), &header))
+	assert.Equal(t, 2, header.Version)
+	assert.Equal(t, 120, header.Width)
+	assert.Equal(t, 40, header.Height)
+	assert.Equal(t, "xterm", header.Env["TERM"])
+
+	var events [][]interface{}
+	for scanner.Scan() {
+		var event []interface{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		events = append(events, event)
+	}
+
+	require.Len(t, events, 3)
+	assert.Equal(t, []interface{}{"o", "hello "}, events[0][1:])
+	assert.Equal(t, []interface{}{"o", "€"}, events[1][1:])
+	assert.Equal(t, []interface{}{"r", "100x30"}, events[2][1:])
+}
+
+func TestRecorder_WriteErrorDoesNotBreakSession(t *testing.T) {
+	recorder, err := NewRecorder(filepath.Join(t.TempDir(), "recordings"))
+	require.NoError(t, err)
+
+	rec, err := recorder.startRecording("session", recordingHeader{})
+	require.NoError(t, err)
+
+	// closing the file underneath the recording makes every recording write fail
+	require.NoError(t, rec.file.Close())
+
+	session := &bytes.Buffer{}
+	writer := io.MultiWriter(session, rec)
+
+	n, err := writer.Write([]byte("first "))
+	require.NoError(t, err, "recording errors should not be returned to the session")
+	assert.Equal(t, 6, n)
+
+	_, err = writer.Write([]byte("second"))
+	require.NoError(t, err)
+	assert.Equal(t, "first second", session.String())
+	assert.True(t, rec.failed)
+
+	rec.resize(100, 50)
+	_ = rec.Close()
+}
+
+func TestRecorder_Rotate(t *testing.T) {
+	recorder, err := NewRecorder(t.TempDir())
+	require.NoError(t, err)
+	recorder.maxRecordings = 3
+
+	for i := 0; i < 5; i++ {
+		rec, err := recorder.startRecording(fmt.Sprintf("session%d", i), recordingHeader{})
+		require.NoError(t, err)
+		require.NoError(t, rec.Close())
+	}
+
+	entries, err := os.ReadDir(recorder.dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 3, "only the newest recordings should be kept")
+	for i, entry := range entries {
+		assert.Contains(t, entry.Name(), fmt.Sprintf("session%d", i+2))
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/creack/pty"
 	"github.com/gliderlabs/ssh"
 	log "github.com/sirupsen/logrus"
+	gossh "golang.org/x/crypto/ssh"
 	"io"
 	"net"
 	"os"
@@ -18,6 +19,11 @@ import (
 // DefaultSSHPort is the default SSH port of the NetBird's embedded SSH server
 const DefaultSSHPort = 44338
 
+type contextKey string
+
+// peerKeyContextKey is the SSH context key of the WireGuard public key of the authenticated peer
+const peerKeyContextKey contextKey = "netbird-peer-key"
+
 // DefaultSSHServer is a function that creates DefaultServer
 func DefaultSSHServer(hostKeyPEM []byte, addr string) (Server, error) {
 	return newDefaultServer(hostKeyPEM, addr)
@@ -33,6 +39,19 @@ type Server interface {
 	RemoveAuthorizedKey(peer string)
 	// AddAuthorizedKey add a given peer key to server authorized keys
 	AddAuthorizedKey(peer, newKey string) error
+	// SetAllowedUsers sets the local users each peer, indexed by WireGuard public key, is allowed to log in as.
+	// A nil map allows any local user
+	SetAllowedUsers(allowedUsers map[string][]string)
+	// SetPortForwarding sets the peers, indexed by WireGuard public key, allowed to forward TCP ports
+	SetPortForwarding(allowedPeers map[string]bool)
+	// SetUserCertificateAuthority sets the public key of the authority signing the user certificates and the access of
+	// the users indexed by NetBird user ID. An empty key disables certificate logins and a nil access map allows
+	// any local user of the certificate principals
+	SetUserCertificateAuthority(caPublicKey string, access map[string]CertificateAccess) error
+	// SetSessionAuditor sets the auditor notified about the start and the end of the SSH sessions
+	SetSessionAuditor(auditor SessionAuditor)
+	// SetRecorder sets the recorder of the SSH sessions. A nil recorder disables recording
+	SetRecorder(recorder *Recorder)
 }
 
 // DefaultServer is the embedded NetBird SSH server
@@ -40,9 +59,20 @@ type DefaultServer struct {
 	listener net.Listener
 	// authorizedKeys is ssh pub key indexed by peer WireGuard public key
 	authorizedKeys map[string]ssh.PublicKey
-	mu             sync.Mutex
-	hostKeyPEM     []byte
-	sessions       []ssh.Session
+	// allowedUsers are the local users indexed by peer WireGuard public key. nil allows any local user
+	allowedUsers map[string][]string
+	// portForwarding are the peers allowed to forward TCP ports indexed by peer WireGuard public key
+	portForwarding map[string]bool
+	// userCAKey is the public key of the authority signing the user certificates. nil disables certificate logins
+	userCAKey ssh.PublicKey
+	// certificateAccess is the access of the certificate users indexed by NetBird user ID. nil allows any principal
+	certificateAccess map[string]CertificateAccess
+	mu                sync.Mutex
+	hostKeyPEM        []byte
+	sessions          []ssh.Session
+	auditor           SessionAuditor
+	recorder          *Recorder
+	server            *ssh.Server
 }
 
 // newDefaultServer creates new server with provided host key
@@ -77,11 +107,41 @@ func (srv *DefaultServer) AddAuthorizedKey(peer, newKey string) error {
 	return nil
 }
 
+// SetAllowedUsers sets the local users each peer, indexed by WireGuard public key, is allowed to log in as.
+// A nil map allows any local user
+func (srv *DefaultServer) SetAllowedUsers(allowedUsers map[string][]string) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	srv.allowedUsers = allowedUsers
+}
+
+// isUserAllowed checks whether a peer is allowed to log in as a given local user. Must be called with the lock held
+func (srv *DefaultServer) isUserAllowed(peer, localUser string) bool {
+	if srv.allowedUsers == nil {
+		return true
+	}
+
+	for _, allowed := range srv.allowedUsers[peer] {
+		if allowed == localUser {
+			return true
+		}
+	}
+
+	return false
+}
+
 // Stop stops SSH server.
 func (srv *DefaultServer) Stop() error {
 	srv.mu.Lock()
 	defer srv.mu.Unlock()
-	err := srv.listener.Close()
+	var err error
+	if srv.server != nil {
+		// closes the listener and the connections including the forwarded ones
+		err = srv.server.Close()
+	} else {
+		err = srv.listener.Close()
+	}
 	if err != nil {
 		return err
 	}
@@ -99,10 +159,20 @@ func (srv *DefaultServer) publicKeyHandler(ctx ssh.Context, key ssh.PublicKey) b
 	srv.mu.Lock()
 	defer srv.mu.Unlock()
 
-	for _, allowed := range srv.authorizedKeys {
-		if ssh.KeysEqual(allowed, key) {
-			return true
+	if cert, ok := key.(*gossh.Certificate); ok {
+		return srv.authenticateCertificate(ctx, cert)
+	}
+
+	for peer, allowed := range srv.authorizedKeys {
+		if !ssh.KeysEqual(allowed, key) {
+			continue
+		}
+		if !srv.isUserAllowed(peer, ctx.User()) {
+			log.Warnf("peer %s from %v is not allowed to log in as local user %s", peer, ctx.RemoteAddr(), ctx.User())
+			continue
 		}
+		ctx.SetValue(peerKeyContextKey, peer)
+		return true
 	}
 
 	return false
@@ -124,6 +194,41 @@ func acceptEnv(s string) bool {
 	return split[0] == "TERM" || split[0] == "LANG" || strings.HasPrefix(split[0], "LC_")
 }
 
+// authorizeSession checks that the peer is still allowed to log in as the local user of the session and looks it up.
+// It ends the session otherwise
+func (srv *DefaultServer) authorizeSession(session ssh.Session) (*user.User, bool) {
+	// the allowed users might have changed since the peer has been authenticated
+	peer, _ := session.Context().Value(peerKeyContextKey).(string)
+	certUser, isCert := session.Context().Value(certUserContextKey).(string)
+	srv.mu.Lock()
+	var allowed bool
+	if isCert {
+		allowed = srv.isCertificateUserAllowed(certUser, session.User())
+	} else {
+		allowed = srv.isUserAllowed(peer, session.User())
+	}
+	srv.mu.Unlock()
+	if !allowed {
+		_, _ = fmt.Fprintf(session, "not allowed to log in as local user %s\n", session.User())
+		_ = session.Exit(1)
+		log.Warnf("denied SSH session from %v, user %s", session.RemoteAddr(), session.User())
+		return nil, false
+	}
+
+	localUser, err := userNameLookup(session.User())
+	if err != nil {
+		_, err = fmt.Fprintf(session, "remote SSH server couldn't find local user %s\n", session.User()) //nolint
+		err = session.Exit(1)
+		if err != nil {
+			return nil, false
+		}
+		log.Warnf("failed SSH session from %v, user %s", session.RemoteAddr(), session.User())
+		return nil, false
+	}
+
+	return localUser, true
+}
+
 // sessionHandler handles SSH session post auth
 func (srv *DefaultServer) sessionHandler(session ssh.Session) {
 	srv.mu.Lock()
@@ -137,18 +242,31 @@ func (srv *DefaultServer) sessionHandler(session ssh.Session) {
 		}
 	}()
 
-	localUser, err := userNameLookup(session.User())
-	if err != nil {
-		_, err = fmt.Fprintf(session, "remote SSH server couldn't find local user %s\n", session.User()) //nolint
-		err = session.Exit(1)
-		if err != nil {
-			return
-		}
-		log.Warnf("failed SSH session from %v, user %s", session.RemoteAddr(), session.User())
+	localUser, ok := srv.authorizeSession(session)
+	if !ok {
 		return
 	}
 
 	ptyReq, winCh, isPty := session.Pty()
+	shell := getUserShell(localUser.Uid)
+
+	auditSessionEnd := srv.auditSessionStart(session)
+	defer auditSessionEnd()
+
+	var output io.Writer = session
+	var errOutput io.Writer = session.Stderr()
+	rec := srv.startRecording(session, ptyReq, shell)
+	if rec != nil {
+		defer func() {
+			err := rec.Close()
+			if err != nil {
+				log.Debugf("failed closing SSH session recording: %v", err)
+			}
+		}()
+		output = io.MultiWriter(session, rec)
+		errOutput = io.MultiWriter(errOutput, rec)
+	}
+
 	if isPty {
 		loginCmd, loginArgs, err := getLoginCmd(localUser.Username, session.RemoteAddr())
 		if err != nil {
@@ -156,16 +274,9 @@ func (srv *DefaultServer) sessionHandler(session ssh.Session) {
 			return
 		}
 		cmd := exec.Command(loginCmd, loginArgs...)
-		go func() {
-			<-session.Context().Done()
-			err := cmd.Process.Kill()
-			if err != nil {
-				return
-			}
-		}()
 		cmd.Dir = localUser.HomeDir
 		cmd.Env = append(cmd.Env, fmt.Sprintf("TERM=%s", ptyReq.Term))
-		cmd.Env = append(cmd.Env, prepareUserEnv(localUser, getUserShell(localUser.Uid))...)
+		cmd.Env = append(cmd.Env, prepareUserEnv(localUser, shell)...)
 		for _, v := range session.Environ() {
 			if acceptEnv(v) {
 				cmd.Env = append(cmd.Env, v)
@@ -175,33 +286,42 @@ func (srv *DefaultServer) sessionHandler(session ssh.Session) {
 		file, err := pty.Start(cmd)
 		if err != nil {
 			log.Errorf("failed starting SSH server %v", err)
+			_ = session.Exit(1)
+			return
 		}
 
+		go func() {
+			<-session.Context().Done()
+			err := cmd.Process.Kill()
+			if err != nil {
+				return
+			}
+		}()
+
 		go func() {
 			for win := range winCh {
 				setWinSize(file, win.Width, win.Height)
+				if rec != nil {
+					rec.resize(win.Width, win.Height)
+				}
 			}
 		}()
 
-		srv.stdInOut(file, session)
+		srv.stdInOut(file, session, output)
 
-		err = cmd.Wait()
+		err = session.Exit(exitCode(cmd.Wait()))
 		if err != nil {
-			return
+			log.Debugf("failed sending SSH exit status: %v", err)
 		}
 	} else {
-		_, err := io.WriteString(session, "only PTY is supported.\n")
+		err := session.Exit(runCommand(session, localUser, shell, output, errOutput))
 		if err != nil {
-			return
-		}
-		err = session.Exit(1)
-		if err != nil {
-			return
+			log.Debugf("failed sending SSH exit status: %v", err)
 		}
 	}
 }
 
-func (srv *DefaultServer) stdInOut(file *os.File, session ssh.Session) {
+func (srv *DefaultServer) stdInOut(file *os.File, session ssh.Session, output io.Writer) {
 	go func() {
 		// stdin
 		_, err := io.Copy(file, session)
@@ -212,7 +332,7 @@ func (srv *DefaultServer) stdInOut(file *os.File, session ssh.Session) {
 
 	go func() {
 		// stdout
-		_, err := io.Copy(session, file)
+		_, err := io.Copy(output, file)
 		if err != nil {
 			return
 		}
@@ -223,9 +343,38 @@ func (srv *DefaultServer) stdInOut(file *os.File, session ssh.Session) {
 func (srv *DefaultServer) Start() error {
 	log.Infof("starting SSH server on addr: %s", srv.listener.Addr().String())
 
-	publicKeyOption := ssh.PublicKeyAuth(srv.publicKeyHandler)
-	hostKeyPEM := ssh.HostKeyPEM(srv.hostKeyPEM)
-	err := ssh.Serve(srv.listener, srv.sessionHandler, publicKeyOption, hostKeyPEM)
+	forwardHandler := &ssh.ForwardedTCPHandler{}
+	server := &ssh.Server{
+		Handler: srv.sessionHandler,
+		SubsystemHandlers: map[string]ssh.SubsystemHandler{
+			"sftp": srv.sftpHandler,
+		},
+		ChannelHandlers: map[string]ssh.ChannelHandler{
+			"session":      ssh.DefaultSessionHandler,
+			"direct-tcpip": ssh.DirectTCPIPHandler,
+		},
+		RequestHandlers: map[string]ssh.RequestHandler{
+			"tcpip-forward":        withDefaultForwardBindHost(forwardHandler.HandleSSHRequest),
+			"cancel-tcpip-forward": withDefaultForwardBindHost(forwardHandler.HandleSSHRequest),
+		},
+		LocalPortForwardingCallback:   srv.localPortForwardingCallback,
+		ReversePortForwardingCallback: srv.reversePortForwardingCallback,
+	}
+
+	err := server.SetOption(ssh.PublicKeyAuth(srv.publicKeyHandler))
+	if err != nil {
+		return err
+	}
+	err = server.SetOption(ssh.HostKeyPEM(srv.hostKeyPEM))
+	if err != nil {
+		return err
+	}
+
+	srv.mu.Lock()
+	srv.server = server
+	srv.mu.Unlock()
+
+	err = server.Serve(srv.listener)
 	if err != nil {
 		return err
 	}
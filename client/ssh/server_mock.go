@@ -4,11 +4,16 @@ import "context"
 
 // MockServer mocks ssh.Server
 type MockServer struct {
-	Ctx                     context.Context
-	StopFunc                func() error
-	StartFunc               func() error
-	AddAuthorizedKeyFunc    func(peer, newKey string) error
-	RemoveAuthorizedKeyFunc func(peer string)
+	Ctx                             context.Context
+	StopFunc                        func() error
+	StartFunc                       func() error
+	AddAuthorizedKeyFunc            func(peer, newKey string) error
+	RemoveAuthorizedKeyFunc         func(peer string)
+	SetAllowedUsersFunc             func(allowedUsers map[string][]string)
+	SetPortForwardingFunc           func(allowedPeers map[string]bool)
+	SetUserCertificateAuthorityFunc func(caPublicKey string, access map[string]CertificateAccess) error
+	SetSessionAuditorFunc           func(auditor SessionAuditor)
+	SetRecorderFunc                 func(recorder *Recorder)
 }
 
 // RemoveAuthorizedKey removes SSH key of a given peer from the authorized keys
@@ -27,6 +32,46 @@ func (srv *MockServer) AddAuthorizedKey(peer, newKey string) error {
 	return srv.AddAuthorizedKeyFunc(peer, newKey)
 }
 
+// SetAllowedUsers sets the local users each peer is allowed to log in as
+func (srv *MockServer) SetAllowedUsers(allowedUsers map[string][]string) {
+	if srv.SetAllowedUsersFunc == nil {
+		return
+	}
+	srv.SetAllowedUsersFunc(allowedUsers)
+}
+
+// SetPortForwarding sets the peers allowed to forward TCP ports
+func (srv *MockServer) SetPortForwarding(allowedPeers map[string]bool) {
+	if srv.SetPortForwardingFunc == nil {
+		return
+	}
+	srv.SetPortForwardingFunc(allowedPeers)
+}
+
+// SetUserCertificateAuthority sets the authority signing the user certificates and the access of the users
+func (srv *MockServer) SetUserCertificateAuthority(caPublicKey string, access map[string]CertificateAccess) error {
+	if srv.SetUserCertificateAuthorityFunc == nil {
+		return nil
+	}
+	return srv.SetUserCertificateAuthorityFunc(caPublicKey, access)
+}
+
+// SetSessionAuditor sets the auditor notified about the start and the end of the SSH sessions
+func (srv *MockServer) SetSessionAuditor(auditor SessionAuditor) {
+	if srv.SetSessionAuditorFunc == nil {
+		return
+	}
+	srv.SetSessionAuditorFunc(auditor)
+}
+
+// SetRecorder sets the recorder of the SSH sessions
+func (srv *MockServer) SetRecorder(recorder *Recorder) {
+	if srv.SetRecorderFunc == nil {
+		return
+	}
+	srv.SetRecorderFunc(recorder)
+}
+
 // Stop stops SSH server.
 func (srv *MockServer) Stop() error {
 	if srv.StopFunc == nil {
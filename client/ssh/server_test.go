@@ -1,13 +1,48 @@
 package ssh
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
-	"github.com/stretchr/testify/assert"
-	"golang.org/x/crypto/ssh"
+	"net"
+	"os/user"
+	"runtime"
 	"strings"
 	"testing"
+
+	gliderssh "github.com/gliderlabs/ssh"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
 )
 
+// testContext is a gliderssh.Context of an authenticating connection of a given user
+type testContext struct {
+	gliderssh.Context
+	user   string
+	values map[interface{}]interface{}
+}
+
+func newTestContext(user string) *testContext {
+	return &testContext{user: user, values: make(map[interface{}]interface{})}
+}
+
+func (c *testContext) User() string {
+	return c.user
+}
+
+func (c *testContext) RemoteAddr() net.Addr {
+	return &net.TCPAddr{IP: net.IPv4(100, 64, 0, 1), Port: 22}
+}
+
+func (c *testContext) SetValue(key, value interface{}) {
+	c.values[key] = value
+}
+
+func (c *testContext) Value(key interface{}) interface{} {
+	return c.values[key]
+}
+
 func TestServer_AddAuthorizedKey(t *testing.T) {
 	key, err := GeneratePrivateKey(ED25519)
 	if err != nil {
@@ -113,9 +148,145 @@ func TestServer_PubKeyHandler(t *testing.T) {
 	}
 
 	for _, key := range keys {
-		accepted := server.publicKeyHandler(nil, key)
+		accepted := server.publicKeyHandler(newTestContext("root"), key)
 
 		assert.Truef(t, accepted, "expecting SSH connection to be accepted for a given SSH key %s", string(ssh.MarshalAuthorizedKey(key)))
 	}
 
 }
+
+func TestServer_PubKeyHandlerAllowedUsers(t *testing.T) {
+	key, err := GeneratePrivateKey(ED25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err := newDefaultServer(key, "localhost:")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	remotePrivKey, err := GeneratePrivateKey(ED25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	remotePubKey, err := GeneratePublicKey(remotePrivKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	remoteParsedPubKey, _, _, _, err := ssh.ParseAuthorizedKey(remotePubKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = server.AddAuthorizedKey("remotePeer", string(remotePubKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.True(t, server.publicKeyHandler(newTestContext("root"), remoteParsedPubKey),
+		"expecting any local user to be allowed without restrictions")
+
+	server.SetAllowedUsers(map[string][]string{"remotePeer": {"deploy"}})
+
+	assert.False(t, server.publicKeyHandler(newTestContext("root"), remoteParsedPubKey),
+		"expecting root login to be denied")
+
+	ctx := newTestContext("deploy")
+	assert.True(t, server.publicKeyHandler(ctx, remoteParsedPubKey), "expecting deploy login to be allowed")
+	assert.Equal(t, "remotePeer", ctx.values[peerKeyContextKey], "expecting authenticated peer to be stored in the context")
+
+	server.SetAllowedUsers(map[string][]string{})
+
+	assert.False(t, server.publicKeyHandler(newTestContext("deploy"), remoteParsedPubKey),
+		"expecting login to be denied for a peer without allowed users")
+}
+
+func TestServer_ExecExitStatus(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test requires a POSIX shell")
+	}
+
+	currentUser, err := user.Current()
+	require.NoError(t, err)
+
+	hostKey, err := GeneratePrivateKey(ED25519)
+	require.NoError(t, err)
+	server, err := newDefaultServer(hostKey, "127.0.0.1:0")
+	require.NoError(t, err)
+
+	clientKey, err := GeneratePrivateKey(ED25519)
+	require.NoError(t, err)
+	clientPubKey, err := GeneratePublicKey(clientKey)
+	require.NoError(t, err)
+	require.NoError(t, server.AddAuthorizedKey("remotePeer", string(clientPubKey)))
+
+	go func() {
+		_ = server.Start()
+	}()
+	defer func() {
+		_ = server.Stop()
+	}()
+
+	client, err := DialWithKey(server.listener.Addr().String(), currentUser.Username, clientKey)
+	require.NoError(t, err)
+	defer client.Close()
+
+	session, err := client.client.NewSession()
+	require.NoError(t, err)
+	defer session.Close()
+
+	var stdout, stderr bytes.Buffer
+	session.Stdout = &stdout
+	session.Stderr = &stderr
+	err = session.Run("echo out; echo err >&2; exit 3")
+
+	var exitErr *ssh.ExitError
+	require.True(t, errors.As(err, &exitErr), "expecting an exit error, got %v", err)
+	assert.Equal(t, 3, exitErr.ExitStatus())
+	assert.Equal(t, "out\n", stdout.String())
+	assert.Equal(t, "err\n", stderr.String())
+}
+
+func TestServer_PortForwardingCallbacks(t *testing.T) {
+	key, err := GeneratePrivateKey(ED25519)
+	require.NoError(t, err)
+	server, err := newDefaultServer(key, "localhost:")
+	require.NoError(t, err)
+
+	allowedCtx := newTestContext("root")
+	allowedCtx.SetValue(peerKeyContextKey, "allowedPeer")
+	deniedCtx := newTestContext("root")
+	deniedCtx.SetValue(peerKeyContextKey, "deniedPeer")
+
+	assert.False(t, server.localPortForwardingCallback(allowedCtx, "127.0.0.1", 8080),
+		"port forwarding should be denied by default")
+
+	server.SetPortForwarding(map[string]bool{"allowedPeer": true})
+
+	assert.True(t, server.localPortForwardingCallback(allowedCtx, "10.0.0.1", 8080))
+	assert.False(t, server.localPortForwardingCallback(deniedCtx, "10.0.0.1", 8080))
+
+	assert.True(t, server.reversePortForwardingCallback(allowedCtx, "127.0.0.1", 8080))
+	assert.True(t, server.reversePortForwardingCallback(allowedCtx, "localhost", 8080))
+	assert.True(t, server.reversePortForwardingCallback(allowedCtx, "", 8080),
+		"empty bind address should be normalised to the default bind address")
+	assert.False(t, server.reversePortForwardingCallback(allowedCtx, "0.0.0.0", 8080),
+		"remote port forwarding should be limited to the loopback interface")
+	assert.False(t, server.reversePortForwardingCallback(deniedCtx, "127.0.0.1", 8080))
+}
+
+func TestWithDefaultForwardBindHost(t *testing.T) {
+	var handled remoteForwardRequest
+	handler := withDefaultForwardBindHost(func(ctx gliderssh.Context, srv *gliderssh.Server, req *ssh.Request) (bool, []byte) {
+		require.NoError(t, ssh.Unmarshal(req.Payload, &handled))
+		return true, nil
+	})
+
+	for bindAddr, expected := range map[string]string{"": defaultForwardBindHost, "127.0.0.1": "127.0.0.1"} {
+		req := &ssh.Request{Type: "tcpip-forward", Payload: ssh.Marshal(&remoteForwardRequest{BindAddr: bindAddr, BindPort: 8080})}
+		ok, _ := handler(newTestContext("root"), nil, req)
+		assert.True(t, ok)
+		assert.Equal(t, expected, handled.BindAddr)
+		assert.Equal(t, uint32(8080), handled.BindPort)
+	}
+}
@@ -0,0 +1,74 @@
+package ssh
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+
+	"github.com/gliderlabs/ssh"
+	"github.com/pkg/sftp"
+	log "github.com/sirupsen/logrus"
+)
+
+// SFTPServerArgs are the arguments of the NetBird binary serving SFTP on its standard input and output with ServeSFTP.
+// The SSH server runs it as the local user the remote peer logged in as
+var SFTPServerArgs = []string{"ssh", "sftp-server"}
+
+// ServeSFTP serves the SFTP protocol on the given input and output until the input is closed.
+// It closes the output when done
+func ServeSFTP(in io.Reader, out io.WriteCloser) error {
+	defer func() {
+		_ = out.Close()
+	}()
+
+	server, err := sftp.NewServer(struct {
+		io.Reader
+		io.WriteCloser
+	}{in, out})
+	if err != nil {
+		return err
+	}
+
+	err = server.Serve()
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+
+	return nil
+}
+
+// sftpHandler handles the SFTP subsystem by running the SFTP server of the NetBird binary as the local user
+func (srv *DefaultServer) sftpHandler(session ssh.Session) {
+	defer func() {
+		err := session.Close()
+		if err != nil {
+			return
+		}
+	}()
+
+	localUser, ok := srv.authorizeSession(session)
+	if !ok {
+		return
+	}
+
+	auditSessionEnd := srv.auditSessionStart(session)
+	defer auditSessionEnd()
+
+	executable, err := os.Executable()
+	if err != nil {
+		_, _ = fmt.Fprintf(session.Stderr(), "failed starting SFTP server\n")
+		_ = session.Exit(1)
+		log.Errorf("failed getting NetBird executable for the SFTP server: %v", err)
+		return
+	}
+
+	cmd := exec.CommandContext(session.Context(), executable, SFTPServerArgs...)
+	cmd.Env = prepareUserEnv(localUser, getUserShell(localUser.Uid))
+
+	err = session.Exit(runAsUser(cmd, session, localUser, session, session.Stderr()))
+	if err != nil {
+		log.Debugf("failed sending SFTP exit status: %v", err)
+	}
+}
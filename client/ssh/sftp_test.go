@@ -0,0 +1,39 @@
+package ssh
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/sftp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServeSFTP(t *testing.T) {
+	clientReader, serverWriter := io.Pipe()
+	serverReader, clientWriter := io.Pipe()
+
+	served := make(chan error, 1)
+	go func() {
+		served <- ServeSFTP(serverReader, serverWriter)
+	}()
+
+	client, err := sftp.NewClientPipe(clientReader, clientWriter)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "upload.txt")
+	file, err := client.Create(path)
+	require.NoError(t, err)
+	_, err = file.Write([]byte("hello"))
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(content))
+
+	require.NoError(t, client.Close())
+	assert.NoError(t, <-served, "the server should stop without an error when the client disconnects")
+}
@@ -24,6 +24,23 @@ type Info struct {
 	UIVersion          string
 }
 
+// deviceNameCtxKey is the context key of the device name reported to the Management service
+type deviceNameCtxKey struct{}
+
+// WithDeviceName returns a context that reports the given name instead of the hostname to the Management service
+func WithDeviceName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, deviceNameCtxKey{}, name)
+}
+
+// extractDeviceName returns the device name set with WithDeviceName or the given default name
+func extractDeviceName(ctx context.Context, defaultName string) string {
+	name, ok := ctx.Value(deviceNameCtxKey{}).(string)
+	if !ok || name == "" {
+		return defaultName
+	}
+	return name
+}
+
 // extractUserAgent extracts Netbird's agent (client) name and version from the outgoing context
 func extractUserAgent(ctx context.Context) string {
 	md, hasMeta := metadata.FromOutgoingContext(ctx)
@@ -24,3 +24,8 @@ func Test_UIVersion(t *testing.T) {
 	got := GetInfo(ctx)
 	assert.Equal(t, want, got.UIVersion)
 }
+
+func Test_DeviceName(t *testing.T) {
+	got := GetInfo(WithDeviceName(context.Background(), "embedded-agent"))
+	assert.Equal(t, "embedded-agent", got.Hostname)
+}
@@ -40,17 +40,17 @@ type CustomZone struct {
 	Records []SimpleRecord
 }
 
-// SimpleRecord provides a simple DNS record specification for CNAME, A and AAAA records
+// SimpleRecord provides a simple DNS record specification for A, AAAA, CNAME, TXT and SRV records
 type SimpleRecord struct {
 	// Name domain name
 	Name string
-	// Type of record, 1 for A, 5 for CNAME, 28 for AAAA. see https://pkg.go.dev/github.com/miekg/dns@v1.1.41#pkg-constants
+	// Type of record, 1 for A, 5 for CNAME, 16 for TXT, 28 for AAAA, 33 for SRV. see https://pkg.go.dev/github.com/miekg/dns@v1.1.41#pkg-constants
 	Type int
 	// Class dns class, currently use the DefaultClass for all records
 	Class string
 	// TTL time-to-live for the record
 	TTL int
-	// RData is the actual value resolved in a dns query
+	// RData is the actual value resolved in a dns query, in zone file format
 	RData string
 }
 
@@ -13,6 +13,12 @@ const (
 	InvalidNameServerType NameServerType = iota
 	// UDPNameServerType udp nameserver type
 	UDPNameServerType
+	// TCPNameServerType tcp nameserver type
+	TCPNameServerType
+	// DoTNameServerType DNS-over-TLS nameserver type
+	DoTNameServerType
+	// DoHNameServerType DNS-over-HTTPS nameserver type
+	DoHNameServerType
 )
 
 const (
@@ -22,6 +28,12 @@ const (
 	InvalidNameServerTypeString = "invalid"
 	// UDPNameServerTypeString udp nameserver type as string
 	UDPNameServerTypeString = "udp"
+	// TCPNameServerTypeString tcp nameserver type as string
+	TCPNameServerTypeString = "tcp"
+	// DoTNameServerTypeString DNS-over-TLS nameserver type as string
+	DoTNameServerTypeString = "dot"
+	// DoHNameServerTypeString DNS-over-HTTPS nameserver type as string
+	DoHNameServerTypeString = "doh"
 )
 
 // NameServerType nameserver type
@@ -32,6 +44,12 @@ func (n NameServerType) String() string {
 	switch n {
 	case UDPNameServerType:
 		return UDPNameServerTypeString
+	case TCPNameServerType:
+		return TCPNameServerTypeString
+	case DoTNameServerType:
+		return DoTNameServerTypeString
+	case DoHNameServerType:
+		return DoHNameServerTypeString
 	default:
 		return InvalidNameServerTypeString
 	}
@@ -42,11 +60,22 @@ func ToNameServerType(typeString string) NameServerType {
 	switch typeString {
 	case UDPNameServerTypeString:
 		return UDPNameServerType
+	case TCPNameServerTypeString:
+		return TCPNameServerType
+	case DoTNameServerTypeString:
+		return DoTNameServerType
+	case DoHNameServerTypeString:
+		return DoHNameServerType
 	default:
 		return InvalidNameServerType
 	}
 }
 
+// IsEncrypted returns true if the nameserver type uses an encrypted transport (DoT or DoH)
+func (n NameServerType) IsEncrypted() bool {
+	return n == DoTNameServerType || n == DoHNameServerType
+}
+
 // NameServerGroup group of nameservers and with group ids
 type NameServerGroup struct {
 	// ID identifier of group
@@ -75,6 +104,9 @@ type NameServer struct {
 	NSType NameServerType
 	// Port nameserver listening port
 	Port int
+	// Hostname is the TLS server name of the nameserver used for SNI and certificate verification.
+	// Only used with DoTNameServerType and DoHNameServerType. If empty, the certificate is verified against the IP.
+	Hostname string
 }
 
 // EventMeta returns activity event meta related to the nameserver group
@@ -85,9 +117,10 @@ func (g *NameServerGroup) EventMeta() map[string]any {
 // Copy copies a nameserver object
 func (n *NameServer) Copy() *NameServer {
 	return &NameServer{
-		IP:     n.IP,
-		NSType: n.NSType,
-		Port:   n.Port,
+		IP:       n.IP,
+		NSType:   n.NSType,
+		Port:     n.Port,
+		Hostname: n.Hostname,
 	}
 }
 
@@ -95,10 +128,12 @@ func (n *NameServer) Copy() *NameServer {
 func (n *NameServer) IsEqual(other *NameServer) bool {
 	return other.IP == n.IP &&
 		other.NSType == n.NSType &&
-		other.Port == n.Port
+		other.Port == n.Port &&
+		other.Hostname == n.Hostname
 }
 
 // ParseNameServerURL parses a nameserver url in the format <type>://<ip>:<port>, e.g., udp://1.1.1.1:53
+// Encrypted nameservers accept an optional hostname for TLS verification, e.g., dot://1.1.1.1:853?hostname=one.one.one.one
 func ParseNameServerURL(nsURL string) (NameServer, error) {
 	parsedURL, err := url.Parse(nsURL)
 	if err != nil {
@@ -125,6 +160,13 @@ func ParseNameServerURL(nsURL string) (NameServer, error) {
 
 	ns.IP = parsedAddr
 
+	hostname := parsedURL.Query().Get("hostname")
+	if hostname != "" && !nsType.IsEncrypted() {
+		return NameServer{}, fmt.Errorf("hostname is only supported by %s and %s nameservers, got %s",
+			DoTNameServerTypeString, DoHNameServerTypeString, parsedScheme)
+	}
+	ns.Hostname = hostname
+
 	return ns, nil
 }
 
@@ -96,6 +96,10 @@ func (w *WGIface) UpdateAddr(newAddr string) error {
 		return err
 	}
 
+	if w.userspaceDevice() != nil {
+		return fmt.Errorf("changing the address of interface %s is not supported in netstack mode", w.name)
+	}
+
 	w.address = addr
 	return w.assignAddr()
 }
@@ -187,7 +191,7 @@ func (w *WGIface) RemoveAllowedIP(peerKey string, allowedIP string) error {
 		return err
 	}
 
-	existingPeer, err := getPeer(w.name, peerKey)
+	existingPeer, err := w.findPeer(peerKey)
 	if err != nil {
 		return err
 	}
@@ -248,11 +252,84 @@ func (w *WGIface) RemovePeer(peerKey string) error {
 	return nil
 }
 
+// WGStats holds the traffic and handshake statistics of a Wireguard peer
+type WGStats struct {
+	LastHandshake time.Time
+	TxBytes       int64
+	RxBytes       int64
+}
+
+// GetStats returns the statistics of all peers of the interface mapped by their public keys
+func (w *WGIface) GetStats() (map[string]WGStats, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	peers, err := w.listPeers()
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make(map[string]WGStats, len(peers))
+	for _, peer := range peers {
+		stats[peer.PublicKey.String()] = WGStats{
+			LastHandshake: peer.LastHandshakeTime,
+			TxBytes:       peer.TransmitBytes,
+			RxBytes:       peer.ReceiveBytes,
+		}
+	}
+	return stats, nil
+}
+
+// findPeer returns the Wireguard Peer of the interface
+func (w *WGIface) findPeer(peerPubKey string) (wgtypes.Peer, error) {
+	if w.userspaceDevice() == nil {
+		return getPeer(w.name, peerPubKey)
+	}
+
+	peers, err := w.listPeers()
+	if err != nil {
+		return wgtypes.Peer{}, err
+	}
+	for _, peer := range peers {
+		if peer.PublicKey.String() == peerPubKey {
+			return peer, nil
+		}
+	}
+	return wgtypes.Peer{}, fmt.Errorf("peer not found")
+}
+
+// listPeers returns all Wireguard Peers of the interface
+func (w *WGIface) listPeers() ([]wgtypes.Peer, error) {
+	wgDevice := w.userspaceDevice()
+	if wgDevice == nil {
+		return getPeers(w.name)
+	}
+
+	ipcOutput, err := wgDevice.IpcGet()
+	if err != nil {
+		return nil, err
+	}
+	return parseUAPIPeers(ipcOutput)
+}
+
 func getPeer(ifaceName, peerPubKey string) (wgtypes.Peer, error) {
-	wg, err := wgctrl.New()
+	peers, err := getPeers(ifaceName)
 	if err != nil {
 		return wgtypes.Peer{}, err
 	}
+	for _, peer := range peers {
+		if peer.PublicKey.String() == peerPubKey {
+			return peer, nil
+		}
+	}
+	return wgtypes.Peer{}, fmt.Errorf("peer not found")
+}
+
+func getPeers(ifaceName string) ([]wgtypes.Peer, error) {
+	wg, err := wgctrl.New()
+	if err != nil {
+		return nil, err
+	}
 	defer func() {
 		err = wg.Close()
 		if err != nil {
@@ -262,18 +339,17 @@ func getPeer(ifaceName, peerPubKey string) (wgtypes.Peer, error) {
 
 	wgDevice, err := wg.Device(ifaceName)
 	if err != nil {
-		return wgtypes.Peer{}, err
-	}
-	for _, peer := range wgDevice.Peers {
-		if peer.PublicKey.String() == peerPubKey {
-			return peer, nil
-		}
+		return nil, err
 	}
-	return wgtypes.Peer{}, fmt.Errorf("peer not found")
+	return wgDevice.Peers, nil
 }
 
 // configureDevice configures the wireguard device
 func (w *WGIface) configureDevice(config wgtypes.Config) error {
+	if wgDevice := w.userspaceDevice(); wgDevice != nil {
+		return wgDevice.IpcSet(toUAPIConfig(config))
+	}
+
 	wg, err := wgctrl.New()
 	if err != nil {
 		return err
@@ -4,6 +4,8 @@ import (
 	"os/exec"
 
 	log "github.com/sirupsen/logrus"
+
+	"github.com/netbirdio/netbird/iface/netstack"
 )
 
 // Create Creates a new Wireguard interface, sets a given IP and brings it up.
@@ -11,6 +13,11 @@ func (w *WGIface) Create() error {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
+	if netstack.IsEnabled() {
+		log.Info("using userspace WireGuard with netstack")
+		return w.createWithNetstack()
+	}
+
 	return w.createWithUserspace()
 }
 
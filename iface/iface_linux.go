@@ -6,6 +6,8 @@ import (
 
 	log "github.com/sirupsen/logrus"
 	"github.com/vishvananda/netlink"
+
+	"github.com/netbirdio/netbird/iface/netstack"
 )
 
 // Create creates a new Wireguard interface, sets a given IP and brings it up.
@@ -14,6 +16,11 @@ func (w *WGIface) Create() error {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
+	if netstack.IsEnabled() {
+		log.Info("using userspace WireGuard with netstack")
+		return w.createWithNetstack()
+	}
+
 	if WireguardModuleIsLoaded() {
 		log.Info("using kernel WireGuard")
 		return w.createWithKernel()
@@ -0,0 +1,72 @@
+package iface
+
+import (
+	"fmt"
+	"net/netip"
+
+	log "github.com/sirupsen/logrus"
+	"golang.zx2c4.com/wireguard/conn"
+	"golang.zx2c4.com/wireguard/device"
+	wgnetstack "golang.zx2c4.com/wireguard/tun/netstack"
+)
+
+// netstackDevice is a wireguard-go device running on top of the gVisor network stack
+type netstackDevice struct {
+	device *device.Device
+	net    *wgnetstack.Net
+}
+
+// Close closes the wireguard-go device together with its network stack
+func (d *netstackDevice) Close() error {
+	d.device.Close()
+	return nil
+}
+
+// createWithNetstack Creates a new Wireguard interface using the wireguard-go userspace implementation on top of the
+// gVisor network stack. It doesn't require a TUN device or any privileges, the overlay network is only reachable
+// through the network stack returned by Netstack
+func (w *WGIface) createWithNetstack() error {
+	addr, err := netip.ParseAddr(w.address.IP.String())
+	if err != nil {
+		return err
+	}
+
+	tunIface, tunNet, err := wgnetstack.CreateNetTUN([]netip.Addr{addr}, []netip.Addr{}, w.mtu)
+	if err != nil {
+		return fmt.Errorf("failed creating netstack TUN device: %w", err)
+	}
+
+	tunDevice := device.NewDevice(tunIface, conn.NewDefaultBind(), device.NewLogger(device.LogLevelSilent, "[netbird] "))
+	err = tunDevice.Up()
+	if err != nil {
+		tunDevice.Close()
+		return err
+	}
+
+	w.netInterface = &netstackDevice{device: tunDevice, net: tunNet}
+
+	log.Debugf("netstack WireGuard device %s created with address %s", w.name, w.address.String())
+	return nil
+}
+
+// Netstack returns the network stack of the interface. It is nil unless the interface runs in netstack mode
+func (w *WGIface) Netstack() *wgnetstack.Net {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	nsDevice, ok := w.netInterface.(*netstackDevice)
+	if !ok {
+		return nil
+	}
+	return nsDevice.net
+}
+
+// userspaceDevice returns the wireguard-go device of the interface if it is configured in process rather than
+// through the UAPI socket or the kernel
+func (w *WGIface) userspaceDevice() *device.Device {
+	nsDevice, ok := w.netInterface.(*netstackDevice)
+	if !ok {
+		return nil
+	}
+	return nsDevice.device
+}
@@ -0,0 +1,95 @@
+package iface
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
+
+	"github.com/netbirdio/netbird/iface/netstack"
+)
+
+func TestWGIface_Netstack(t *testing.T) {
+	t.Setenv(netstack.EnvUseNetstackMode, "true")
+
+	newNetstackIface := func(name, addr string, port int) (*WGIface, wgtypes.Key) {
+		wgIface, err := NewWGIFace(name, addr, DefaultMTU)
+		require.NoError(t, err)
+		require.NoError(t, wgIface.Create())
+		t.Cleanup(func() {
+			assert.NoError(t, wgIface.Close())
+		})
+
+		privateKey, err := wgtypes.GeneratePrivateKey()
+		require.NoError(t, err)
+		require.NoError(t, wgIface.Configure(privateKey.String(), port))
+		require.NotNil(t, wgIface.Netstack(), "interface should run on a userspace network stack")
+		return wgIface, privateKey
+	}
+
+	iface1, key1 := newNetstackIface("netstack1", "100.64.0.1/24", 33101)
+	iface2, key2 := newNetstackIface("netstack2", "100.64.0.2/24", 33102)
+
+	endpoint1 := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 33101}
+	endpoint2 := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 33102}
+	require.NoError(t, iface1.UpdatePeer(key2.PublicKey().String(), "100.64.0.2/32", 0, endpoint2, nil))
+	require.NoError(t, iface2.UpdatePeer(key1.PublicKey().String(), "100.64.0.1/32", 0, endpoint1, nil))
+
+	require.NoError(t, iface1.AddAllowedIP(key2.PublicKey().String(), "10.10.0.0/16"))
+	peer, err := iface1.findPeer(key2.PublicKey().String())
+	require.NoError(t, err)
+	assert.Len(t, peer.AllowedIPs, 2)
+	assert.Equal(t, endpoint2.String(), peer.Endpoint.String())
+
+	require.NoError(t, iface1.RemoveAllowedIP(key2.PublicKey().String(), "10.10.0.0/16"))
+	peer, err = iface1.findPeer(key2.PublicKey().String())
+	require.NoError(t, err)
+	require.Len(t, peer.AllowedIPs, 1)
+	assert.Equal(t, "100.64.0.2/32", peer.AllowedIPs[0].String())
+
+	listener, err := iface2.Netstack().ListenTCP(&net.TCPAddr{Port: 8080})
+	require.NoError(t, err)
+	defer listener.Close()
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_, _ = io.Copy(conn, conn)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	conn, err := iface1.Netstack().DialContext(ctx, "tcp", "100.64.0.2:8080")
+	require.NoError(t, err, "peers should be reachable over the tunnel")
+	defer conn.Close()
+
+	message := fmt.Sprintf("hello from %s", iface1.Address().IP)
+	_, err = conn.Write([]byte(message))
+	require.NoError(t, err)
+
+	reply := make([]byte, len(message))
+	_, err = io.ReadFull(conn, reply)
+	require.NoError(t, err)
+	assert.Equal(t, message, string(reply))
+
+	stats, err := iface1.GetStats()
+	require.NoError(t, err)
+	peerStats, ok := stats[key2.PublicKey().String()]
+	require.True(t, ok, "stats of the peer should be collected")
+	assert.NotZero(t, peerStats.TxBytes)
+	assert.NotZero(t, peerStats.RxBytes)
+	assert.False(t, peerStats.LastHandshake.IsZero(), "peers should have completed a handshake")
+
+	require.NoError(t, iface1.RemovePeer(key2.PublicKey().String()))
+	_, err = iface1.findPeer(key2.PublicKey().String())
+	assert.Error(t, err, "peer should have been removed")
+}
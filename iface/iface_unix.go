@@ -26,11 +26,18 @@ func (w *WGIface) Close() error {
 	if w.netInterface == nil {
 		return nil
 	}
+	// the netstack device has no UAPI socket, which might belong to another agent using the same interface name
+	_, isNetstack := w.netInterface.(*netstackDevice)
+
 	err := w.netInterface.Close()
 	if err != nil {
 		return err
 	}
 
+	if isNetstack {
+		return nil
+	}
+
 	sockPath := "/var/run/wireguard/" + w.name + ".sock"
 	if _, statErr := os.Stat(sockPath); statErr == nil {
 		statErr = os.Remove(sockPath)
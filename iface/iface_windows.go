@@ -7,6 +7,8 @@ import (
 	log "github.com/sirupsen/logrus"
 	"golang.org/x/sys/windows"
 	"golang.zx2c4.com/wireguard/windows/driver"
+
+	"github.com/netbirdio/netbird/iface/netstack"
 )
 
 // Create Creates a new Wireguard interface, sets a given IP and brings it up.
@@ -14,6 +16,11 @@ func (w *WGIface) Create() error {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
+	if netstack.IsEnabled() {
+		log.Info("using userspace WireGuard with netstack")
+		return w.createWithNetstack()
+	}
+
 	WintunStaticRequestedGUID, _ := windows.GenerateGUID()
 	adapter, err := driver.CreateAdapter(w.name, "WireGuard", &WintunStaticRequestedGUID)
 	if err != nil {
@@ -35,7 +42,10 @@ func (w *WGIface) GetInterfaceGUIDString() (string, error) {
 	if w.netInterface == nil {
 		return "", fmt.Errorf("interface has not been initialized yet")
 	}
-	windowsDevice := w.netInterface.(*driver.Adapter)
+	windowsDevice, ok := w.netInterface.(*driver.Adapter)
+	if !ok {
+		return "", fmt.Errorf("interface %s is not a Windows adapter", w.name)
+	}
 	luid := windowsDevice.LUID()
 	guid, err := luid.GUID()
 	if err != nil {
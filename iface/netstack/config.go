@@ -0,0 +1,105 @@
+package netstack
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	// EnvUseNetstackMode is the environment variable that enables the userspace WireGuard mode with the gVisor
+	// network stack. In this mode no TUN device is created, so the agent doesn't need root or CAP_NET_ADMIN
+	// privileges, and the overlay network is only reachable through the proxies of the agent
+	EnvUseNetstackMode = "NB_USE_NETSTACK_MODE"
+	// EnvSOCKS5ListenAddr is the environment variable with the listen address of the SOCKS5 proxy
+	EnvSOCKS5ListenAddr = "NB_SOCKS5_LISTEN_ADDR"
+	// EnvHTTPProxyListenAddr is the environment variable with the listen address of the HTTP proxy
+	EnvHTTPProxyListenAddr = "NB_HTTP_PROXY_LISTEN_ADDR"
+	// EnvPortForwards is the environment variable with a comma separated list of local port forwards in the
+	// [bind_address:]port:host:hostport format of ssh -L
+	EnvPortForwards = "NB_PORT_FORWARDS"
+
+	// DefaultSOCKS5ListenAddr is the listen address of the SOCKS5 proxy if none has been configured
+	DefaultSOCKS5ListenAddr = "127.0.0.1:1080"
+
+	defaultForwardBindAddr = "127.0.0.1"
+)
+
+// IsEnabled returns true if the WireGuard interface should run on top of the gVisor network stack
+func IsEnabled() bool {
+	enabled, _ := strconv.ParseBool(os.Getenv(EnvUseNetstackMode))
+	return enabled
+}
+
+// PortForward forwards the connections accepted on a local address to an address of the overlay network
+type PortForward struct {
+	ListenAddr string
+	TargetAddr string
+}
+
+// ProxyConfig holds the local endpoints that expose the overlay network in netstack mode.
+// Empty addresses disable the corresponding proxy
+type ProxyConfig struct {
+	SOCKS5ListenAddr    string
+	HTTPProxyListenAddr string
+	PortForwards        []PortForward
+}
+
+// ProxyConfigFromEnv reads the proxy configuration from the environment.
+// The SOCKS5 proxy is enabled on DefaultSOCKS5ListenAddr unless its listen address is set to an empty value
+func ProxyConfigFromEnv() (ProxyConfig, error) {
+	config := ProxyConfig{
+		SOCKS5ListenAddr:    DefaultSOCKS5ListenAddr,
+		HTTPProxyListenAddr: os.Getenv(EnvHTTPProxyListenAddr),
+	}
+
+	if addr, ok := os.LookupEnv(EnvSOCKS5ListenAddr); ok {
+		config.SOCKS5ListenAddr = addr
+	}
+
+	forwards, err := ParsePortForwards(os.Getenv(EnvPortForwards))
+	if err != nil {
+		return ProxyConfig{}, err
+	}
+	config.PortForwards = forwards
+
+	return config, nil
+}
+
+// ParsePortForwards parses a comma separated list of port forwards in the [bind_address:]port:host:hostport format.
+// Forwards without a bind address listen on the loopback interface
+func ParsePortForwards(value string) ([]PortForward, error) {
+	var forwards []PortForward
+	for _, spec := range strings.Split(value, ",") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+
+		parts := strings.Split(spec, ":")
+		switch len(parts) {
+		case 3:
+			parts = append([]string{defaultForwardBindAddr}, parts...)
+		case 4:
+		default:
+			return nil, fmt.Errorf("invalid port forward %s, expecting [bind_address:]port:host:hostport", spec)
+		}
+
+		for _, port := range []string{parts[1], parts[3]} {
+			if p, err := strconv.Atoi(port); err != nil || p < 1 || p > 65535 {
+				return nil, fmt.Errorf("invalid port %s in port forward %s", port, spec)
+			}
+		}
+		if parts[2] == "" {
+			return nil, fmt.Errorf("missing host in port forward %s", spec)
+		}
+
+		forwards = append(forwards, PortForward{
+			ListenAddr: net.JoinHostPort(parts[0], parts[1]),
+			TargetAddr: net.JoinHostPort(parts[2], parts[3]),
+		})
+	}
+	return forwards, nil
+}
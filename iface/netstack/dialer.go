@@ -0,0 +1,65 @@
+package netstack
+
+import (
+	"context"
+	"fmt"
+	"net"
+)
+
+// Dialer dials connections over the overlay network
+type Dialer interface {
+	DialContext(ctx context.Context, network, address string) (net.Conn, error)
+}
+
+// PeerResolver returns the overlay IP address of the peer with the given domain name
+type PeerResolver func(host string) (string, bool)
+
+// ResolvingDialer resolves host names before dialing through the userspace network stack, which has no resolver
+// of its own. Peer domain names are resolved with the peer resolver, other host names with the system resolver
+type ResolvingDialer struct {
+	dialer   Dialer
+	resolver PeerResolver
+}
+
+// NewResolvingDialer returns a new ResolvingDialer. The resolver is optional
+func NewResolvingDialer(dialer Dialer, resolver PeerResolver) *ResolvingDialer {
+	return &ResolvingDialer{
+		dialer:   dialer,
+		resolver: resolver,
+	}
+}
+
+// DialContext connects to the address over the overlay network
+func (d *ResolvingDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	if net.ParseIP(host) == nil {
+		resolved, err := d.resolve(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		host = resolved
+	}
+
+	return d.dialer.DialContext(ctx, network, net.JoinHostPort(host, port))
+}
+
+func (d *ResolvingDialer) resolve(ctx context.Context, host string) (string, error) {
+	if d.resolver != nil {
+		if ip, ok := d.resolver(host); ok {
+			return ip, nil
+		}
+	}
+
+	addrs, err := net.DefaultResolver.LookupHost(ctx, host)
+	if err != nil {
+		return "", err
+	}
+	if len(addrs) == 0 {
+		return "", fmt.Errorf("no addresses found for %s", host)
+	}
+	return addrs[0], nil
+}
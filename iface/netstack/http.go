@@ -0,0 +1,119 @@
+package netstack
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// hopHeaders are the hop-by-hop headers that mustn't be forwarded by proxies, see RFC 7230
+var hopHeaders = []string{
+	"Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Proxy-Connection",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+func (p *Proxy) newHTTPProxyServer() *http.Server {
+	transport := &http.Transport{
+		DialContext:           p.dial,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		ExpectContinueTimeout: time.Second,
+	}
+
+	return &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodConnect {
+				p.serveHTTPConnect(w, r)
+				return
+			}
+			p.serveHTTPForward(w, r, transport)
+		}),
+		ReadHeaderTimeout: 30 * time.Second,
+	}
+}
+
+// serveHTTPConnect tunnels the connection of the client to the requested address, used for HTTPS and any other
+// TCP based protocol
+func (p *Proxy) serveHTTPConnect(w http.ResponseWriter, r *http.Request) {
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "connection hijacking not supported", http.StatusInternalServerError)
+		return
+	}
+
+	remote, err := p.dial(r.Context(), "tcp", r.Host)
+	if err != nil {
+		log.Debugf("HTTP proxy failed connecting to %s: %v", r.Host, err)
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer remote.Close()
+
+	w.WriteHeader(http.StatusOK)
+	conn, buf, err := hijacker.Hijack()
+	if err != nil {
+		log.Debugf("failed hijacking HTTP proxy connection: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	// forward the data the client might have sent right after the request
+	if buf.Reader.Buffered() > 0 {
+		_, err = io.CopyN(remote, buf, int64(buf.Reader.Buffered()))
+		if err != nil {
+			return
+		}
+	}
+
+	pipe(conn, remote)
+}
+
+// serveHTTPForward forwards plain HTTP requests sent with an absolute URL to the overlay network
+func (p *Proxy) serveHTTPForward(w http.ResponseWriter, r *http.Request, transport http.RoundTripper) {
+	if !r.URL.IsAbs() {
+		http.Error(w, "this is a proxy server, requests must use an absolute URL", http.StatusBadRequest)
+		return
+	}
+
+	outReq := r.Clone(r.Context())
+	outReq.RequestURI = ""
+	removeHopHeaders(outReq.Header)
+
+	resp, err := transport.RoundTrip(outReq)
+	if err != nil {
+		log.Debugf("HTTP proxy failed forwarding request to %s: %v", r.URL.Host, err)
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+
+	removeHopHeaders(resp.Header)
+	for key, values := range resp.Header {
+		for _, value := range values {
+			w.Header().Add(key, value)
+		}
+	}
+	w.WriteHeader(resp.StatusCode)
+	_, _ = io.Copy(w, resp.Body)
+}
+
+func removeHopHeaders(header http.Header) {
+	for _, connectionHeader := range header.Values("Connection") {
+		for _, name := range strings.Split(connectionHeader, ",") {
+			header.Del(strings.TrimSpace(name))
+		}
+	}
+	for _, name := range hopHeaders {
+		header.Del(name)
+	}
+}
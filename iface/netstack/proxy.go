@@ -0,0 +1,176 @@
+package netstack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const dialTimeout = 30 * time.Second
+
+// Proxy exposes the overlay network of the userspace network stack to local applications through a SOCKS5 proxy,
+// an HTTP proxy and port forwards
+type Proxy struct {
+	config ProxyConfig
+	dialer *ResolvingDialer
+
+	mu         sync.Mutex
+	listeners  []net.Listener
+	httpServer *http.Server
+	wg         sync.WaitGroup
+}
+
+// NewProxy returns a new Proxy dialing through the given dialer, resolving host names like a ResolvingDialer
+func NewProxy(config ProxyConfig, dialer Dialer, resolver PeerResolver) *Proxy {
+	return &Proxy{
+		config: config,
+		dialer: NewResolvingDialer(dialer, resolver),
+	}
+}
+
+// Start starts listening on the configured addresses. Already started listeners are closed if one of them fails
+func (p *Proxy) Start() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	err := p.start()
+	if err != nil {
+		p.stop()
+		return err
+	}
+	return nil
+}
+
+func (p *Proxy) start() error {
+	if p.config.SOCKS5ListenAddr != "" {
+		listener, err := p.listen(p.config.SOCKS5ListenAddr)
+		if err != nil {
+			return err
+		}
+		p.serve(listener, p.serveSOCKS5)
+		log.Infof("SOCKS5 proxy to the NetBird network listening on %s", listener.Addr())
+	}
+
+	if p.config.HTTPProxyListenAddr != "" {
+		listener, err := p.listen(p.config.HTTPProxyListenAddr)
+		if err != nil {
+			return err
+		}
+		p.httpServer = p.newHTTPProxyServer()
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			err := p.httpServer.Serve(listener)
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Errorf("HTTP proxy stopped: %v", err)
+			}
+		}()
+		log.Infof("HTTP proxy to the NetBird network listening on %s", listener.Addr())
+	}
+
+	for _, forward := range p.config.PortForwards {
+		listener, err := p.listen(forward.ListenAddr)
+		if err != nil {
+			return err
+		}
+		targetAddr := forward.TargetAddr
+		p.serve(listener, func(conn net.Conn) {
+			p.forward(conn, targetAddr)
+		})
+		log.Infof("forwarding %s to %s over the NetBird network", listener.Addr(), targetAddr)
+	}
+
+	return nil
+}
+
+// Stop closes the listeners of the proxy. Established connections are kept until either side closes them
+func (p *Proxy) Stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.stop()
+	p.wg.Wait()
+}
+
+func (p *Proxy) stop() {
+	// the HTTP server has to be closed before its listener to stop serving gracefully
+	if p.httpServer != nil {
+		_ = p.httpServer.Close()
+		p.httpServer = nil
+	}
+
+	for _, listener := range p.listeners {
+		err := listener.Close()
+		if err != nil && !errors.Is(err, net.ErrClosed) {
+			log.Debugf("failed closing proxy listener %s: %v", listener.Addr(), err)
+		}
+	}
+	p.listeners = nil
+}
+
+func (p *Proxy) listen(addr string) (net.Listener, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed listening on %s: %w", addr, err)
+	}
+	p.listeners = append(p.listeners, listener)
+	return listener, nil
+}
+
+func (p *Proxy) serve(listener net.Listener, handle func(conn net.Conn)) {
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				if !errors.Is(err, net.ErrClosed) {
+					log.Errorf("proxy listener %s stopped: %v", listener.Addr(), err)
+				}
+				return
+			}
+			go handle(conn)
+		}
+	}()
+}
+
+// forward pipes the accepted connection to the target address of the overlay network
+func (p *Proxy) forward(conn net.Conn, targetAddr string) {
+	defer conn.Close()
+
+	remote, err := p.dial(context.Background(), "tcp", targetAddr)
+	if err != nil {
+		log.Debugf("failed forwarding connection from %s to %s: %v", conn.RemoteAddr(), targetAddr, err)
+		return
+	}
+	defer remote.Close()
+
+	pipe(conn, remote)
+}
+
+// dial connects to the address over the overlay network
+func (p *Proxy) dial(ctx context.Context, network, addr string) (net.Conn, error) {
+	ctx, cancel := context.WithTimeout(ctx, dialTimeout)
+	defer cancel()
+
+	return p.dialer.DialContext(ctx, network, addr)
+}
+
+// pipe copies data between both connections until one of them is closed
+func pipe(a, b net.Conn) {
+	done := make(chan struct{}, 2)
+	cp := func(dst, src net.Conn) {
+		_, _ = io.Copy(dst, src)
+		done <- struct{}{}
+	}
+	go cp(a, b)
+	go cp(b, a)
+	<-done
+}
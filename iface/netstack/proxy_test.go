@@ -0,0 +1,162 @@
+package netstack
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/proxy"
+)
+
+func TestParsePortForwards(t *testing.T) {
+	forwards, err := ParsePortForwards("8080:peer.netbird.cloud:80, 0.0.0.0:5432:100.64.0.5:5432")
+	require.NoError(t, err)
+	assert.Equal(t, []PortForward{
+		{ListenAddr: "127.0.0.1:8080", TargetAddr: "peer.netbird.cloud:80"},
+		{ListenAddr: "0.0.0.0:5432", TargetAddr: "100.64.0.5:5432"},
+	}, forwards)
+
+	for _, invalid := range []string{"8080:peer", "8080:peer:http", "0:peer:80", "8080::80", "a:b:c:d:e"} {
+		_, err = ParsePortForwards(invalid)
+		assert.Error(t, err, "port forward %s should be invalid", invalid)
+	}
+}
+
+// startEchoServer starts a server replying with the received lines prefixed with its name
+func startEchoServer(t *testing.T) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = listener.Close()
+	})
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				scanner := bufio.NewScanner(conn)
+				for scanner.Scan() {
+					_, _ = fmt.Fprintf(conn, "echo %s\n", scanner.Text())
+				}
+			}()
+		}
+	}()
+
+	return listener.Addr().String()
+}
+
+func assertEcho(t *testing.T, conn net.Conn) {
+	t.Helper()
+
+	_, err := fmt.Fprintf(conn, "hello\n")
+	require.NoError(t, err)
+	reply, err := bufio.NewReader(conn).ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "echo hello\n", reply)
+}
+
+func TestProxy(t *testing.T) {
+	echoAddr := startEchoServer(t)
+	_, echoPort, err := net.SplitHostPort(echoAddr)
+	require.NoError(t, err)
+
+	httpServer := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, "hello from %s", r.Host)
+	})}
+	httpListener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() {
+		_ = httpServer.Serve(httpListener)
+	}()
+	defer httpServer.Close()
+	_, httpPort, err := net.SplitHostPort(httpListener.Addr().String())
+	require.NoError(t, err)
+
+	freeAddr := func() string {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer listener.Close()
+		return listener.Addr().String()
+	}
+
+	config := ProxyConfig{
+		SOCKS5ListenAddr:    freeAddr(),
+		HTTPProxyListenAddr: freeAddr(),
+		PortForwards:        []PortForward{{ListenAddr: freeAddr(), TargetAddr: "peer.netbird.cloud:" + echoPort}},
+	}
+	resolver := func(host string) (string, bool) {
+		return "127.0.0.1", host == "peer.netbird.cloud"
+	}
+
+	p := NewProxy(config, &net.Dialer{}, resolver)
+	require.NoError(t, p.Start())
+	defer p.Stop()
+
+	t.Run("SOCKS5", func(t *testing.T) {
+		dialer, err := proxy.SOCKS5("tcp", config.SOCKS5ListenAddr, nil, proxy.Direct)
+		require.NoError(t, err)
+
+		conn, err := dialer.Dial("tcp", "peer.netbird.cloud:"+echoPort)
+		require.NoError(t, err, "peer domain names should be resolved by the proxy")
+		defer conn.Close()
+		assertEcho(t, conn)
+
+		_, err = dialer.Dial("tcp", "127.0.0.1:1")
+		assert.Error(t, err, "unreachable addresses should be reported to the client")
+	})
+
+	t.Run("HTTP Connect", func(t *testing.T) {
+		conn, err := net.Dial("tcp", config.HTTPProxyListenAddr)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		_, err = fmt.Fprintf(conn, "CONNECT peer.netbird.cloud:%s HTTP/1.1\r\nHost: peer.netbird.cloud:%s\r\n\r\n",
+			echoPort, echoPort)
+		require.NoError(t, err)
+
+		reader := bufio.NewReader(conn)
+		resp, err := http.ReadResponse(reader, nil)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		_, err = fmt.Fprintf(conn, "hello\n")
+		require.NoError(t, err)
+		reply, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		assert.Equal(t, "echo hello\n", reply)
+	})
+
+	t.Run("HTTP Forward", func(t *testing.T) {
+		proxyURL, err := url.Parse("http://" + config.HTTPProxyListenAddr)
+		require.NoError(t, err)
+		client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)}}
+
+		resp, err := client.Get("http://peer.netbird.cloud:" + httpPort + "/")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "hello from peer.netbird.cloud:"+httpPort, string(body))
+	})
+
+	t.Run("Port Forward", func(t *testing.T) {
+		conn, err := net.Dial("tcp", config.PortForwards[0].ListenAddr)
+		require.NoError(t, err)
+		defer conn.Close()
+		assertEcho(t, conn)
+	})
+}
@@ -0,0 +1,165 @@
+package netstack
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// SOCKS5 protocol constants, see RFC 1928
+const (
+	socks5Version = 0x05
+
+	socks5MethodNoAuth       = 0x00
+	socks5MethodNoAcceptable = 0xff
+
+	socks5CmdConnect = 0x01
+
+	socks5AddrIPv4   = 0x01
+	socks5AddrDomain = 0x03
+	socks5AddrIPv6   = 0x04
+
+	socks5ReplySucceeded           = 0x00
+	socks5ReplyGeneralFailure      = 0x01
+	socks5ReplyHostUnreachable     = 0x04
+	socks5ReplyCommandNotSupported = 0x07
+	socks5ReplyAddrNotSupported    = 0x08
+)
+
+var (
+	errSOCKS5AddrNotSupported    = errors.New("address type not supported")
+	errSOCKS5CommandNotSupported = errors.New("command not supported")
+)
+
+// serveSOCKS5 handles a SOCKS5 client connection. Only the CONNECT command without authentication is supported,
+// which is what browsers, curl and most SOCKS aware tools use
+func (p *Proxy) serveSOCKS5(conn net.Conn) {
+	defer conn.Close()
+
+	err := socks5Handshake(conn)
+	if err != nil {
+		log.Debugf("SOCKS5 handshake with %s failed: %v", conn.RemoteAddr(), err)
+		return
+	}
+
+	targetAddr, err := readSOCKS5Request(conn)
+	if err != nil {
+		reply := byte(socks5ReplyGeneralFailure)
+		switch {
+		case errors.Is(err, errSOCKS5AddrNotSupported):
+			reply = socks5ReplyAddrNotSupported
+		case errors.Is(err, errSOCKS5CommandNotSupported):
+			reply = socks5ReplyCommandNotSupported
+		}
+		_ = writeSOCKS5Reply(conn, reply)
+		log.Debugf("invalid SOCKS5 request from %s: %v", conn.RemoteAddr(), err)
+		return
+	}
+
+	remote, err := p.dial(context.Background(), "tcp", targetAddr)
+	if err != nil {
+		_ = writeSOCKS5Reply(conn, socks5ReplyHostUnreachable)
+		log.Debugf("SOCKS5 proxy failed connecting to %s: %v", targetAddr, err)
+		return
+	}
+	defer remote.Close()
+
+	err = writeSOCKS5Reply(conn, socks5ReplySucceeded)
+	if err != nil {
+		return
+	}
+
+	pipe(conn, remote)
+}
+
+func socks5Handshake(conn net.Conn) error {
+	header := make([]byte, 2)
+	_, err := io.ReadFull(conn, header)
+	if err != nil {
+		return err
+	}
+	if header[0] != socks5Version {
+		return fmt.Errorf("unsupported SOCKS version %d", header[0])
+	}
+
+	methods := make([]byte, header[1])
+	_, err = io.ReadFull(conn, methods)
+	if err != nil {
+		return err
+	}
+
+	for _, method := range methods {
+		if method == socks5MethodNoAuth {
+			_, err = conn.Write([]byte{socks5Version, socks5MethodNoAuth})
+			return err
+		}
+	}
+
+	_, _ = conn.Write([]byte{socks5Version, socks5MethodNoAcceptable})
+	return errors.New("client doesn't support connecting without authentication")
+}
+
+// readSOCKS5Request reads the request of the client and returns the requested address
+func readSOCKS5Request(conn net.Conn) (string, error) {
+	header := make([]byte, 4)
+	_, err := io.ReadFull(conn, header)
+	if err != nil {
+		return "", err
+	}
+	if header[0] != socks5Version {
+		return "", fmt.Errorf("unsupported SOCKS version %d", header[0])
+	}
+
+	var host string
+	switch header[3] {
+	case socks5AddrIPv4, socks5AddrIPv6:
+		ip := make(net.IP, net.IPv4len)
+		if header[3] == socks5AddrIPv6 {
+			ip = make(net.IP, net.IPv6len)
+		}
+		_, err = io.ReadFull(conn, ip)
+		if err != nil {
+			return "", err
+		}
+		host = ip.String()
+	case socks5AddrDomain:
+		length := make([]byte, 1)
+		_, err = io.ReadFull(conn, length)
+		if err != nil {
+			return "", err
+		}
+		domain := make([]byte, length[0])
+		_, err = io.ReadFull(conn, domain)
+		if err != nil {
+			return "", err
+		}
+		host = string(domain)
+	default:
+		return "", errSOCKS5AddrNotSupported
+	}
+
+	port := make([]byte, 2)
+	_, err = io.ReadFull(conn, port)
+	if err != nil {
+		return "", err
+	}
+
+	if header[1] != socks5CmdConnect {
+		return "", errSOCKS5CommandNotSupported
+	}
+
+	return net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(port)))), nil
+}
+
+// writeSOCKS5Reply writes a reply with an unspecified bound address, clients connecting through the proxy don't
+// need it
+func writeSOCKS5Reply(conn net.Conn, reply byte) error {
+	_, err := conn.Write([]byte{socks5Version, reply, 0x00, socks5AddrIPv4, 0, 0, 0, 0, 0, 0})
+	return err
+}
@@ -0,0 +1,134 @@
+package iface
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
+)
+
+// toUAPIConfig converts a wgctrl config to the set operation of the WireGuard cross-platform userspace API.
+// See https://www.wireguard.com/xplatform/
+func toUAPIConfig(config wgtypes.Config) string {
+	var b strings.Builder
+	if config.PrivateKey != nil {
+		b.WriteString(fmt.Sprintf("private_key=%s\n", hex.EncodeToString(config.PrivateKey[:])))
+	}
+	if config.ListenPort != nil {
+		b.WriteString(fmt.Sprintf("listen_port=%d\n", *config.ListenPort))
+	}
+	if config.FirewallMark != nil {
+		b.WriteString(fmt.Sprintf("fwmark=%d\n", *config.FirewallMark))
+	}
+	if config.ReplacePeers {
+		b.WriteString("replace_peers=true\n")
+	}
+
+	for _, peer := range config.Peers {
+		b.WriteString(fmt.Sprintf("public_key=%s\n", hex.EncodeToString(peer.PublicKey[:])))
+		if peer.Remove {
+			b.WriteString("remove=true\n")
+			continue
+		}
+		if peer.UpdateOnly {
+			b.WriteString("update_only=true\n")
+		}
+		if peer.PresharedKey != nil {
+			b.WriteString(fmt.Sprintf("preshared_key=%s\n", hex.EncodeToString(peer.PresharedKey[:])))
+		}
+		if peer.Endpoint != nil {
+			b.WriteString(fmt.Sprintf("endpoint=%s\n", peer.Endpoint.String()))
+		}
+		if peer.PersistentKeepaliveInterval != nil {
+			b.WriteString(fmt.Sprintf("persistent_keepalive_interval=%d\n", int(peer.PersistentKeepaliveInterval.Seconds())))
+		}
+		if peer.ReplaceAllowedIPs {
+			b.WriteString("replace_allowed_ips=true\n")
+		}
+		for _, allowedIP := range peer.AllowedIPs {
+			b.WriteString(fmt.Sprintf("allowed_ip=%s\n", allowedIP.String()))
+		}
+	}
+
+	return b.String()
+}
+
+// parseUAPIPeers parses the peers of the get operation output of the WireGuard cross-platform userspace API
+func parseUAPIPeers(ipcOutput string) ([]wgtypes.Peer, error) {
+	var peers []wgtypes.Peer
+	var peer *wgtypes.Peer
+	var handshakeSec, handshakeNsec int64
+
+	flush := func() {
+		if peer == nil {
+			return
+		}
+		if handshakeSec != 0 || handshakeNsec != 0 {
+			peer.LastHandshakeTime = time.Unix(handshakeSec, handshakeNsec)
+		}
+		peers = append(peers, *peer)
+		handshakeSec, handshakeNsec = 0, 0
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(ipcOutput))
+	for scanner.Scan() {
+		key, value, found := strings.Cut(scanner.Text(), "=")
+		if !found {
+			continue
+		}
+
+		if key == "public_key" {
+			flush()
+			keyBytes, err := hex.DecodeString(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid peer public key %s: %w", value, err)
+			}
+			publicKey, err := wgtypes.NewKey(keyBytes)
+			if err != nil {
+				return nil, err
+			}
+			peer = &wgtypes.Peer{PublicKey: publicKey}
+			continue
+		}
+
+		// device level settings come before the first peer
+		if peer == nil {
+			continue
+		}
+
+		var err error
+		switch key {
+		case "endpoint":
+			peer.Endpoint, err = net.ResolveUDPAddr("udp", value)
+		case "allowed_ip":
+			var ipNet *net.IPNet
+			_, ipNet, err = net.ParseCIDR(value)
+			if err == nil {
+				peer.AllowedIPs = append(peer.AllowedIPs, *ipNet)
+			}
+		case "rx_bytes":
+			peer.ReceiveBytes, err = strconv.ParseInt(value, 10, 64)
+		case "tx_bytes":
+			peer.TransmitBytes, err = strconv.ParseInt(value, 10, 64)
+		case "last_handshake_time_sec":
+			handshakeSec, err = strconv.ParseInt(value, 10, 64)
+		case "last_handshake_time_nsec":
+			handshakeNsec, err = strconv.ParseInt(value, 10, 64)
+		case "persistent_keepalive_interval":
+			var interval int64
+			interval, err = strconv.ParseInt(value, 10, 64)
+			peer.PersistentKeepaliveInterval = time.Duration(interval) * time.Second
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid value of %s: %w", key, err)
+		}
+	}
+	flush()
+
+	return peers, scanner.Err()
+}
@@ -318,6 +318,32 @@ func (c *GrpcClient) GetDeviceAuthorizationFlow(serverKey wgtypes.Key) (*proto.D
 	return flowInfoResp, nil
 }
 
+// ReportSSHSession reports an audit event of a session handled by the embedded SSH server.
+// It also takes care of encrypting the message.
+func (c *GrpcClient) ReportSSHSession(event *proto.SSHSessionEvent) error {
+	if !c.ready() {
+		return fmt.Errorf("no connection to management in order to report SSH session")
+	}
+
+	serverPubKey, err := c.GetServerPublicKey()
+	if err != nil {
+		return err
+	}
+
+	encryptedMSG, err := encryption.EncryptMessage(*serverPubKey, c.key, event)
+	if err != nil {
+		return err
+	}
+
+	mgmCtx, cancel := context.WithTimeout(c.ctx, 5*time.Second)
+	defer cancel()
+	_, err = c.realClient.ReportSSHSession(mgmCtx, &proto.EncryptedMessage{
+		WgPubKey: c.key.PublicKey().String(),
+		Body:     encryptedMSG,
+	})
+	return err
+}
+
 func (c *GrpcClient) notifyDisconnected() {
 	c.connStateCallbackLock.RLock()
 	defer c.connStateCallbackLock.RUnlock()
@@ -13,6 +13,7 @@ type MockClient struct {
 	RegisterFunc                   func(serverKey wgtypes.Key, setupKey string, jwtToken string, info *system.Info, sshKey []byte) (*proto.LoginResponse, error)
 	LoginFunc                      func(serverKey wgtypes.Key, info *system.Info, sshKey []byte) (*proto.LoginResponse, error)
 	GetDeviceAuthorizationFlowFunc func(serverKey wgtypes.Key) (*proto.DeviceAuthorizationFlow, error)
+	ReportSSHSessionFunc           func(event *proto.SSHSessionEvent) error
 }
 
 func (m *MockClient) Close() error {
@@ -56,3 +57,10 @@ func (m *MockClient) GetDeviceAuthorizationFlow(serverKey wgtypes.Key) (*proto.D
 	}
 	return m.GetDeviceAuthorizationFlowFunc(serverKey)
 }
+
+func (m *MockClient) ReportSSHSession(event *proto.SSHSessionEvent) error {
+	if m.ReportSSHSessionFunc == nil {
+		return nil
+	}
+	return m.ReportSSHSessionFunc(event)
+}
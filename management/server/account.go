@@ -32,6 +32,8 @@ const (
 	CacheExpirationMax         = 7 * 24 * 3600 * time.Second // 7 days
 	CacheExpirationMin         = 3 * 24 * 3600 * time.Second // 3 days
 	DefaultPeerLoginExpiration = 24 * time.Hour
+	// DefaultEphemeralPeersGracePeriod is the default period of time disconnected ephemeral peers are deleted after
+	DefaultEphemeralPeersGracePeriod = 10 * time.Minute
 )
 
 func cacheEntryExpiration() time.Duration {
@@ -42,7 +44,7 @@ func cacheEntryExpiration() time.Duration {
 type AccountManager interface {
 	GetOrCreateAccountByUser(userId, domain string) (*Account, error)
 	CreateSetupKey(accountID string, keyName string, keyType SetupKeyType, expiresIn time.Duration,
-		autoGroups []string, usageLimit int, userID string) (*SetupKey, error)
+		autoGroups []string, usageLimit int, ipPool string, ephemeral bool, userID string) (*SetupKey, error)
 	SaveSetupKey(accountID string, key *SetupKey, userID string) (*SetupKey, error)
 	CreateUser(accountID, userID string, key *UserInfo) (*UserInfo, error)
 	ListSetupKeys(accountID, userID string) ([]*SetupKey, error)
@@ -50,12 +52,17 @@ type AccountManager interface {
 	GetSetupKey(accountID, userID, keyID string) (*SetupKey, error)
 	GetAccountByUserOrAccountID(userID, accountID, domain string) (*Account, error)
 	GetAccountFromToken(claims jwtclaims.AuthorizationClaims) (*Account, *User, error)
+	GetAccountFromPAT(plainToken string) (*Account, *User, *PersonalAccessToken, error)
+	CreatePAT(accountID, executingUserID, targetUserID, tokenName string, expiresIn int) (*PersonalAccessTokenGenerated, error)
+	DeletePAT(accountID, executingUserID, targetUserID, tokenID string) error
 	IsUserAdmin(claims jwtclaims.AuthorizationClaims) (bool, error)
 	AccountExists(accountId string) (*bool, error)
 	GetPeerByKey(peerKey string) (*Peer, error)
 	GetPeers(accountID, userID string) ([]*Peer, error)
 	MarkPeerConnected(peerKey string, connected bool) error
 	DeletePeer(accountID, peerID, userID string) (*Peer, error)
+	ApprovePeer(accountID, userID, peerID string) (*Peer, error)
+	RejectPeer(accountID, userID, peerID string) error
 	GetPeerByIP(accountId string, peerIP string) (*Peer, error)
 	UpdatePeer(accountID, userID string, peer *Peer) (*Peer, error)
 	GetNetworkMap(peerID string) (*NetworkMap, error)
@@ -75,6 +82,7 @@ type AccountManager interface {
 	SavePolicy(accountID, userID string, policy *Policy) error
 	DeletePolicy(accountID, policyID, userID string) error
 	ListPolicies(accountID, userID string) ([]*Policy, error)
+	EvaluatePolicies(accountID, userID string, query *PolicyEvaluationQuery) ([]*PolicyEvaluation, error)
 	GetRoute(accountID, routeID, userID string) (*route.Route, error)
 	CreateRoute(accountID string, prefix, peerID, description, netID string, masquerade bool, metric int, groups []string, enabled bool, userID string) (*route.Route, error)
 	SaveRoute(accountID, userID string, route *route.Route) error
@@ -91,6 +99,21 @@ type AccountManager interface {
 	GetEvents(accountID, userID string) ([]*activity.Event, error)
 	GetDNSSettings(accountID string, userID string) (*DNSSettings, error)
 	SaveDNSSettings(accountID string, userID string, dnsSettingsToSave *DNSSettings) error
+	GetDNSZone(accountID, zoneID, userID string) (*DNSZone, error)
+	SaveDNSZone(accountID, userID string, zoneToSave *DNSZone) error
+	DeleteDNSZone(accountID, zoneID, userID string) error
+	ListDNSZones(accountID, userID string) ([]*DNSZone, error)
+	GetSSHPolicy(accountID, policyID, userID string) (*SSHPolicy, error)
+	SaveSSHPolicy(accountID, userID string, policyToSave *SSHPolicy) error
+	DeleteSSHPolicy(accountID, policyID, userID string) error
+	ListSSHPolicies(accountID, userID string) ([]*SSHPolicy, error)
+	GetPostureCheck(accountID, checkID, userID string) (*PostureCheck, error)
+	SavePostureCheck(accountID, userID string, checkToSave *PostureCheck) error
+	DeletePostureCheck(accountID, checkID, userID string) error
+	ListPostureChecks(accountID, userID string) ([]*PostureCheck, error)
+	StoreSSHSessionEvent(peerPubKey string, event *SSHSessionEvent) error
+	GetSSHCertificateAuthority(accountID, userID string) (string, error)
+	IssueSSHCertificate(accountID, userID string, req *SSHCertificateRequest) (*SSHCertificate, error)
 	GetPeer(accountID, peerID, userID string) (*Peer, error)
 	UpdateAccountSettings(accountID, userID string, newSettings *Settings) (*Account, error)
 	LoginPeer(login PeerLogin) (*Peer, *NetworkMap, error) // used by peer gRPC API
@@ -118,6 +141,8 @@ type DefaultAccountManager struct {
 	// dnsDomain is used for peer resolution. This is appended to the peer's name
 	dnsDomain       string
 	peerLoginExpiry Scheduler
+	// ephemeralPeers schedules the deletion of the disconnected ephemeral peers by account ID
+	ephemeralPeers Scheduler
 }
 
 // Settings represents Account settings structure that can be modified via API and Dashboard
@@ -127,6 +152,14 @@ type Settings struct {
 	// PeerLoginExpiration is a setting that indicates when peer login expires.
 	// Applies to all peers that have Peer.LoginExpirationEnabled set to true.
 	PeerLoginExpiration time.Duration
+	// EphemeralPeersGracePeriod is a setting that indicates how long ephemeral peers can be disconnected before they
+	// are deleted. A zero value means DefaultEphemeralPeersGracePeriod.
+	EphemeralPeersGracePeriod time.Duration
+	// PeerApprovalEnabled requires newly added peers to be approved by an admin before they get access to the network
+	PeerApprovalEnabled bool
+	// JWTGroupsClaimName is the name of the JWT claim holding the IdP groups of the user. When set, the groups are
+	// synchronized into the account groups and the user's AutoGroups on every login. Empty disables the sync.
+	JWTGroupsClaimName string
 }
 
 // Copy copies the Settings struct
@@ -134,9 +167,20 @@ func (s *Settings) Copy() *Settings {
 	return &Settings{
 		PeerLoginExpirationEnabled: s.PeerLoginExpirationEnabled,
 		PeerLoginExpiration:        s.PeerLoginExpiration,
+		EphemeralPeersGracePeriod:  s.EphemeralPeersGracePeriod,
+		PeerApprovalEnabled:        s.PeerApprovalEnabled,
+		JWTGroupsClaimName:         s.JWTGroupsClaimName,
 	}
 }
 
+// GetEphemeralPeersGracePeriod returns the period of time disconnected ephemeral peers are deleted after
+func (s *Settings) GetEphemeralPeersGracePeriod() time.Duration {
+	if s.EphemeralPeersGracePeriod <= 0 {
+		return DefaultEphemeralPeersGracePeriod
+	}
+	return s.EphemeralPeersGracePeriod
+}
+
 // Account represents a unique account of the system
 type Account struct {
 	Id string
@@ -155,17 +199,24 @@ type Account struct {
 	Routes                 map[string]*route.Route
 	NameServerGroups       map[string]*nbdns.NameServerGroup
 	DNSSettings            *DNSSettings
+	DNSZones               map[string]*DNSZone
+	SSHPolicies            map[string]*SSHPolicy
+	PostureChecks          map[string]*PostureCheck
+	// SSHCertificateAuthority signs the SSH user certificates. It is created when first used
+	SSHCertificateAuthority *SSHCertificateAuthority
 	// Settings is a dictionary of Account settings
 	Settings *Settings
 }
 
 type UserInfo struct {
-	ID         string   `json:"id"`
-	Email      string   `json:"email"`
-	Name       string   `json:"name"`
-	Role       string   `json:"role"`
-	AutoGroups []string `json:"auto_groups"`
-	Status     string   `json:"-"`
+	ID            string   `json:"id"`
+	Email         string   `json:"email"`
+	Name          string   `json:"name"`
+	Role          string   `json:"role"`
+	AutoGroups    []string `json:"auto_groups"`
+	Status        string   `json:"-"`
+	IsBlocked     bool     `json:"is_blocked"`
+	IsServiceUser bool     `json:"is_service_user"`
 }
 
 // getRoutesToSync returns the enabled routes for the peer ID and the routes
@@ -272,6 +323,13 @@ func (a *Account) GetGroup(groupID string) *Group {
 
 // GetPeerNetworkMap returns a group by ID if exists, nil otherwise
 func (a *Account) GetPeerNetworkMap(peerID, dnsDomain string) *NetworkMap {
+	// peers pending an approval and peers of blocked users don't get access to the network
+	if peer := a.GetPeer(peerID); peer != nil && !a.hasNetworkAccess(peer) {
+		return &NetworkMap{
+			Network: a.Network.Copy(),
+		}
+	}
+
 	aclPeers, _ := a.getPeersByPolicy(peerID)
 	// exclude expired peers
 	var peersToConnect []*Peer
@@ -298,17 +356,26 @@ func (a *Account) GetPeerNetworkMap(peerID, dnsDomain string) *NetworkMap {
 		if peersCustomZone.Domain != "" {
 			zones = append(zones, peersCustomZone)
 		}
+		zones = append(zones, getPeerDNSZones(a, peerID)...)
 		dnsUpdate.CustomZones = zones
 		dnsUpdate.NameServerGroups = getPeerNSGroups(a, peerID)
 	}
 
-	return &NetworkMap{
-		Peers:        peersToConnect,
-		Network:      a.Network.Copy(),
-		Routes:       routesUpdate,
-		DNSConfig:    dnsUpdate,
-		OfflinePeers: expiredPeers,
+	networkMap := &NetworkMap{
+		Peers:                peersToConnect,
+		Network:              a.Network.Copy(),
+		Routes:               routesUpdate,
+		DNSConfig:            dnsUpdate,
+		OfflinePeers:         expiredPeers,
+		SSHUsers:             a.getPeerSSHUsers(peerID),
+		SSHPortForwarding:    a.getPeerSSHPortForwarding(peerID),
+		SSHCertificateAccess: a.getPeerSSHCertificateAccess(peerID),
 	}
+	if a.SSHCertificateAuthority != nil {
+		networkMap.SSHUserCAKey = a.SSHCertificateAuthority.PublicKey
+	}
+
+	return networkMap
 }
 
 // GetExpiredPeers returns peers that have been expired
@@ -351,6 +418,50 @@ func (a *Account) GetNextPeerExpiration() (time.Duration, bool) {
 	return *nextExpiry, true
 }
 
+// GetEphemeralPeers returns a list of peers that have been registered with an ephemeral setup key
+func (a *Account) GetEphemeralPeers() []*Peer {
+	var peers []*Peer
+	for _, peer := range a.Peers {
+		if peer.Ephemeral {
+			peers = append(peers, peer)
+		}
+	}
+	return peers
+}
+
+// GetExpiredEphemeralPeers returns a list of ephemeral peers that have been disconnected for longer than
+// the ephemeral peers grace period
+func (a *Account) GetExpiredEphemeralPeers() []*Peer {
+	var peers []*Peer
+	for _, peer := range a.GetEphemeralPeers() {
+		if expired, _ := peer.EphemeralExpired(a.Settings.GetEphemeralPeersGracePeriod()); expired {
+			peers = append(peers, peer)
+		}
+	}
+	return peers
+}
+
+// GetNextEphemeralPeerDeletion returns the minimum duration in which the next disconnected ephemeral peer
+// of the account has to be deleted. If there is no disconnected ephemeral peer this function returns false and a duration of 0.
+func (a *Account) GetNextEphemeralPeerDeletion() (time.Duration, bool) {
+	var nextDeletion *time.Duration
+	for _, peer := range a.GetEphemeralPeers() {
+		if peer.Status == nil || peer.Status.Connected {
+			continue
+		}
+		_, duration := peer.EphemeralExpired(a.Settings.GetEphemeralPeersGracePeriod())
+		if nextDeletion == nil || duration < *nextDeletion {
+			nextDeletion = &duration
+		}
+	}
+
+	if nextDeletion == nil {
+		return 0, false
+	}
+
+	return *nextDeletion, true
+}
+
 // GetPeersWithExpiration returns a list of peers that have Peer.LoginExpirationEnabled set to true
 func (a *Account) GetPeersWithExpiration() []*Peer {
 	peers := make([]*Peer, 0)
@@ -417,6 +528,17 @@ func (a *Account) FindPeerByPubKey(peerPubKey string) (*Peer, error) {
 	return nil, status.Errorf(status.NotFound, "peer with the public key %s not found", peerPubKey)
 }
 
+// hasNetworkAccess returns false if the peer is pending an approval or was added by a blocked user, true otherwise
+func (a *Account) hasNetworkAccess(peer *Peer) bool {
+	if peer.ApprovalRequired() {
+		return false
+	}
+	if user, ok := a.Users[peer.UserID]; ok && user.IsBlocked() {
+		return false
+	}
+	return true
+}
+
 // FindUserPeers returns a list of peers that user owns (created)
 func (a *Account) FindUserPeers(userID string) ([]*Peer, error) {
 	peers := make([]*Peer, 0)
@@ -439,7 +561,7 @@ func (a *Account) FindUser(userID string) (*User, error) {
 	return user, nil
 }
 
-// FindSetupKey looks for a given SetupKey in the Account or returns error if it wasn't found.
+// FindSetupKey looks for a SetupKey by its hash in the Account or returns error if it wasn't found.
 func (a *Account) FindSetupKey(setupKey string) (*SetupKey, error) {
 	key := a.SetupKeys[setupKey]
 	if key == nil {
@@ -558,28 +680,52 @@ func (a *Account) Copy() *Account {
 		dnsSettings = a.DNSSettings.Copy()
 	}
 
+	dnsZones := map[string]*DNSZone{}
+	for id, zone := range a.DNSZones {
+		dnsZones[id] = zone.Copy()
+	}
+
+	sshPolicies := map[string]*SSHPolicy{}
+	for id, policy := range a.SSHPolicies {
+		sshPolicies[id] = policy.Copy()
+	}
+
+	postureChecks := map[string]*PostureCheck{}
+	for id, check := range a.PostureChecks {
+		postureChecks[id] = check.Copy()
+	}
+
+	var sshCA *SSHCertificateAuthority
+	if a.SSHCertificateAuthority != nil {
+		sshCA = a.SSHCertificateAuthority.Copy()
+	}
+
 	var settings *Settings
 	if a.Settings != nil {
 		settings = a.Settings.Copy()
 	}
 
 	return &Account{
-		Id:                     a.Id,
-		CreatedBy:              a.CreatedBy,
-		Domain:                 a.Domain,
-		DomainCategory:         a.DomainCategory,
-		IsDomainPrimaryAccount: a.IsDomainPrimaryAccount,
-		SetupKeys:              setupKeys,
-		Network:                a.Network.Copy(),
-		Peers:                  peers,
-		Users:                  users,
-		Groups:                 groups,
-		Rules:                  rules,
-		Policies:               policies,
-		Routes:                 routes,
-		NameServerGroups:       nsGroups,
-		DNSSettings:            dnsSettings,
-		Settings:               settings,
+		Id:                      a.Id,
+		CreatedBy:               a.CreatedBy,
+		Domain:                  a.Domain,
+		DomainCategory:          a.DomainCategory,
+		IsDomainPrimaryAccount:  a.IsDomainPrimaryAccount,
+		SetupKeys:               setupKeys,
+		Network:                 a.Network.Copy(),
+		Peers:                   peers,
+		Users:                   users,
+		Groups:                  groups,
+		Rules:                   rules,
+		Policies:                policies,
+		Routes:                  routes,
+		NameServerGroups:        nsGroups,
+		DNSSettings:             dnsSettings,
+		DNSZones:                dnsZones,
+		SSHPolicies:             sshPolicies,
+		PostureChecks:           postureChecks,
+		SSHCertificateAuthority: sshCA,
+		Settings:                settings,
 	}
 }
 
@@ -611,6 +757,7 @@ func BuildManager(store Store, peersUpdateManager *PeersUpdateManager, idpManage
 		dnsDomain:          dnsDomain,
 		eventStore:         eventStore,
 		peerLoginExpiry:    NewDefaultScheduler(),
+		ephemeralPeers:     NewDefaultScheduler(),
 	}
 	allAccounts := store.GetAllAccounts()
 	// enable single account mode only if configured by user and number of existing accounts is not grater than 1
@@ -645,6 +792,25 @@ func BuildManager(store Store, peersUpdateManager *PeersUpdateManager, idpManage
 				return nil, err
 			}
 		}
+
+		// all peers are disconnected on start, ephemeral peers get the grace period to reconnect before they are deleted.
+		// The ephemeral peers still stored as connected are considered disconnected since now
+		for _, peer := range account.GetEphemeralPeers() {
+			if peer.Status == nil || !peer.Status.Connected {
+				continue
+			}
+			newStatus := peer.Status.Copy()
+			newStatus.Connected = false
+			newStatus.LastSeen = time.Now()
+			err = store.SavePeerStatus(account.Id, peer.ID, *newStatus)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if len(account.GetEphemeralPeers()) > 0 {
+			go am.ephemeralPeers.Schedule(account.Settings.GetEphemeralPeersGracePeriod(), account.Id,
+				am.ephemeralPeersDeletionJob(account.Id))
+		}
 	}
 
 	goCacheClient := gocache.New(CacheExpirationMax, 30*time.Minute)
@@ -680,6 +846,10 @@ func (am *DefaultAccountManager) UpdateAccountSettings(accountID, userID string,
 		return nil, status.Errorf(status.InvalidArgument, "peer login expiration can't be smaller than one hour")
 	}
 
+	if newSettings.EphemeralPeersGracePeriod != 0 && newSettings.EphemeralPeersGracePeriod < time.Minute {
+		return nil, status.Errorf(status.InvalidArgument, "ephemeral peers grace period can't be smaller than one minute")
+	}
+
 	unlock := am.Store.AcquireAccountLock(accountID)
 	defer unlock()
 
@@ -714,8 +884,30 @@ func (am *DefaultAccountManager) UpdateAccountSettings(accountID, userID string,
 		am.checkAndSchedulePeerLoginExpiration(account)
 	}
 
+	if oldSettings.PeerApprovalEnabled != newSettings.PeerApprovalEnabled {
+		event := activity.AccountPeerApprovalEnabled
+		if !newSettings.PeerApprovalEnabled {
+			event = activity.AccountPeerApprovalDisabled
+		}
+		am.storeEvent(userID, accountID, accountID, event, nil)
+	}
+
+	if oldSettings.JWTGroupsClaimName != newSettings.JWTGroupsClaimName {
+		am.storeEvent(userID, accountID, accountID, activity.AccountJWTGroupsClaimNameUpdated,
+			map[string]any{"claim": newSettings.JWTGroupsClaimName})
+	}
+
+	gracePeriodUpdated := oldSettings.GetEphemeralPeersGracePeriod() != newSettings.GetEphemeralPeersGracePeriod()
+	if gracePeriodUpdated {
+		am.storeEvent(userID, accountID, accountID, activity.AccountEphemeralPeersGracePeriodUpdated, nil)
+	}
+
 	updatedAccount := account.UpdateSettings(newSettings)
 
+	if gracePeriodUpdated {
+		am.checkAndScheduleEphemeralPeersDeletion(updatedAccount)
+	}
+
 	err = am.Store.SaveAccount(account)
 	if err != nil {
 		return nil, err
@@ -772,6 +964,62 @@ func (am *DefaultAccountManager) checkAndSchedulePeerLoginExpiration(account *Ac
 	}
 }
 
+func (am *DefaultAccountManager) ephemeralPeersDeletionJob(accountID string) func() (time.Duration, bool) {
+	return func() (time.Duration, bool) {
+		unlock := am.Store.AcquireAccountLock(accountID)
+		defer unlock()
+
+		account, err := am.Store.GetAccount(accountID)
+		if err != nil {
+			log.Errorf("failed getting account %s deleting ephemeral peers", accountID)
+			return 0, false
+		}
+
+		peers := account.GetExpiredEphemeralPeers()
+		if len(peers) == 0 {
+			return account.GetNextEphemeralPeerDeletion()
+		}
+
+		for _, peer := range peers {
+			account.DeletePeer(peer.ID)
+		}
+		account.updateDynamicGroups()
+
+		err = am.Store.SaveAccount(account)
+		if err != nil {
+			log.Errorf("failed saving account %s while deleting ephemeral peers: %v", accountID, err)
+			return account.GetNextEphemeralPeerDeletion()
+		}
+
+		log.Debugf("deleted %d ephemeral peers of account %s", len(peers), accountID)
+
+		for _, peer := range peers {
+			am.peersUpdateManager.CloseChannel(peer.ID)
+
+			// the setup key the peer was registered with is the initiator of the deletion
+			initiatorID := accountID
+			if key, ok := account.SetupKeys[peer.SetupKey]; ok {
+				initiatorID = key.Id
+			}
+			am.storeEvent(initiatorID, peer.ID, accountID, activity.EphemeralPeerRemoved, peer.EventMeta(am.GetDNSDomain()))
+		}
+
+		err = am.updateAccountPeers(account)
+		if err != nil {
+			log.Errorf("failed updating account peers while deleting ephemeral peers of account %s", accountID)
+		}
+
+		return account.GetNextEphemeralPeerDeletion()
+	}
+}
+
+func (am *DefaultAccountManager) checkAndScheduleEphemeralPeersDeletion(account *Account) {
+	am.ephemeralPeers.Cancel([]string{account.Id})
+	if nextRun, ok := account.GetNextEphemeralPeerDeletion(); ok {
+		go am.ephemeralPeers.Schedule(nextRun, account.Id, am.ephemeralPeersDeletionJob(account.Id))
+	}
+}
+
 // newAccount creates a new Account with a generated ID and generated default setup keys.
 // If ID is already in use (due to collision) we try one more time before returning error
 func (am *DefaultAccountManager) newAccount(userID, domain string) (*Account, error) {
@@ -889,7 +1137,10 @@ func (am *DefaultAccountManager) lookupUserInCacheByEmail(email string, accountI
 func (am *DefaultAccountManager) lookupUserInCache(userID string, account *Account) (*idp.UserData, error) {
 	users := make(map[string]struct{}, len(account.Users))
 	for _, user := range account.Users {
-		users[user.Id] = struct{}{}
+		// service users don't exist in the IdP
+		if !user.IsServiceUser {
+			users[user.Id] = struct{}{}
+		}
 	}
 	log.Debugf("looking up user %s of account %s in cache", userID, account.Id)
 	userData, err := am.lookupCache(users, account.Id)
@@ -1125,7 +1376,14 @@ func (am *DefaultAccountManager) GetAccountFromToken(claims jwtclaims.Authorizat
 		log.Infof("overriding JWT Domain and DomainCategory claims since single account mode is enabled")
 	}
 
-	account, err := am.getAccountWithAuthorizationClaims(claims)
+	var account *Account
+	var err error
+	if claims.AuthenticatedByPAT {
+		// the account has been resolved from the personal access token already
+		account, err = am.Store.GetAccount(claims.AccountId)
+	} else {
+		account, err = am.getAccountWithAuthorizationClaims(claims)
+	}
 	if err != nil {
 		return nil, nil, err
 	}
@@ -1136,14 +1394,178 @@ func (am *DefaultAccountManager) GetAccountFromToken(claims jwtclaims.Authorizat
 		return nil, nil, status.Errorf(status.NotFound, "user %s not found", claims.UserId)
 	}
 
+	if user.IsBlocked() {
+		return nil, nil, status.Errorf(status.PermissionDenied, "user %s is blocked", claims.UserId)
+	}
+
+	if claims.AuthenticatedByPAT {
+		// tokens are issued to existing users only, so there is neither an invite to redeem nor JWT groups to sync
+		return account, user, nil
+	}
+
+	if user.IsServiceUser {
+		return nil, nil, status.Errorf(status.PermissionDenied, "service user %s can't authenticate with a JWT", claims.UserId)
+	}
+
 	err = am.redeemInvite(account, claims.UserId)
 	if err != nil {
 		return nil, nil, err
 	}
 
+	if account.Settings.JWTGroupsClaimName != "" {
+		account, user, err = am.syncJWTGroups(account.Id, claims)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
 	return account, user, nil
 }
 
+// syncJWTGroups synchronizes the IdP groups of the user, read from the JWT claim configured in the account settings,
+// into the account groups and the user's AutoGroups. Missing groups are created, the user's peers are added to the
+// groups the user joined and removed from the groups assigned from the claim before that the user no longer belongs to.
+func (am *DefaultAccountManager) syncJWTGroups(accountID string, claims jwtclaims.AuthorizationClaims) (*Account, *User, error) {
+	unlock := am.Store.AcquireAccountLock(accountID)
+	defer unlock()
+
+	account, err := am.Store.GetAccount(accountID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	user := account.Users[claims.UserId]
+	if user == nil {
+		return nil, nil, status.Errorf(status.NotFound, "user %s not found", claims.UserId)
+	}
+
+	var createdGroups []*Group
+	var claimGroups []string
+	for _, name := range extractJWTGroups(claims, account.Settings.JWTGroupsClaimName) {
+		if name == "All" {
+			continue
+		}
+		group := account.getGroupByName(name)
+		if group == nil {
+			group = &Group{
+				ID:     xid.New().String(),
+				Name:   name,
+				Peers:  []string{},
+				Issued: GroupIssuedJWT,
+			}
+			account.Groups[group.ID] = group
+			createdGroups = append(createdGroups, group)
+		}
+		if len(difference([]string{group.ID}, claimGroups)) > 0 {
+			claimGroups = append(claimGroups, group.ID)
+		}
+	}
+
+	// groups assigned to the user manually are kept as is, only the groups assigned from the claim are synchronized
+	newJWTGroups := intersection(user.JWTGroups, claimGroups)
+	newJWTGroups = append(newJWTGroups, difference(claimGroups, user.AutoGroups)...)
+	newAutoGroups := difference(user.AutoGroups, difference(user.JWTGroups, claimGroups))
+	newAutoGroups = append(newAutoGroups, difference(claimGroups, user.AutoGroups)...)
+
+	addedGroups := difference(newAutoGroups, user.AutoGroups)
+	removedGroups := difference(user.AutoGroups, newAutoGroups)
+	if len(addedGroups) == 0 && len(removedGroups) == 0 && len(difference(user.JWTGroups, newJWTGroups)) == 0 {
+		return account, user, nil
+	}
+
+	newUser := user.Copy()
+	newUser.AutoGroups = newAutoGroups
+	newUser.JWTGroups = newJWTGroups
+	account.Users[newUser.Id] = newUser
+
+	userPeers, err := account.FindUserPeers(newUser.Id)
+	if err != nil {
+		return nil, nil, err
+	}
+	var userPeerIDs []string
+	for _, peer := range userPeers {
+		userPeerIDs = append(userPeerIDs, peer.ID)
+	}
+
+	for _, groupID := range addedGroups {
+		group := account.GetGroup(groupID)
+		if group == nil || group.IsDynamic() {
+			continue
+		}
+		group.Peers = append(removeFromList(group.Peers, userPeerIDs), userPeerIDs...)
+	}
+	for _, groupID := range removedGroups {
+		group := account.GetGroup(groupID)
+		if group == nil || group.IsDynamic() {
+			continue
+		}
+		group.Peers = removeFromList(group.Peers, userPeerIDs)
+	}
+
+	account.Network.IncSerial()
+	if err = am.Store.SaveAccount(account); err != nil {
+		return nil, nil, err
+	}
+
+	for _, group := range createdGroups {
+		am.storeEvent(newUser.Id, group.ID, accountID, activity.GroupCreated, group.EventMeta())
+	}
+	for _, groupID := range addedGroups {
+		if group := account.GetGroup(groupID); group != nil {
+			am.storeEvent(newUser.Id, newUser.Id, accountID, activity.GroupAddedToUser,
+				map[string]any{"group": group.Name, "group_id": group.ID})
+		}
+	}
+	for _, groupID := range removedGroups {
+		if group := account.GetGroup(groupID); group != nil {
+			am.storeEvent(newUser.Id, newUser.Id, accountID, activity.GroupRemovedFromUser,
+				map[string]any{"group": group.Name, "group_id": group.ID})
+		}
+	}
+
+	if len(userPeers) > 0 {
+		if err = am.updateAccountPeers(account); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return account, newUser, nil
+}
+
+// extractJWTGroups returns the group names from the given JWT claim. The claim can hold a list of names or a single one.
+func extractJWTGroups(claims jwtclaims.AuthorizationClaims, claimName string) []string {
+	var groups []string
+	switch value := claims.Raw[claimName].(type) {
+	case string:
+		if value != "" {
+			groups = append(groups, value)
+		}
+	case []interface{}:
+		for _, item := range value {
+			if name, ok := item.(string); ok && name != "" {
+				groups = append(groups, name)
+			}
+		}
+	case []string:
+		for _, name := range value {
+			if name != "" {
+				groups = append(groups, name)
+			}
+		}
+	}
+	return groups
+}
+
+// getGroupByName looks up a Group by its name
+func (a *Account) getGroupByName(name string) *Group {
+	for _, group := range a.Groups {
+		if group.Name == name {
+			return group
+		}
+	}
+	return nil
+}
+
 // getAccountWithAuthorizationClaims retrievs an account using JWT Claims.
 // if domain is of the PrivateCategory category, it will evaluate
 // if account is new, existing or if there is another account with the same domain
@@ -1291,7 +1713,7 @@ func newAccountWithId(accountId, userId, domain string) *Account {
 	dnsSettings := &DNSSettings{
 		DisabledManagementGroups: make([]string, 0),
 	}
-	log.Debugf("created new account %s with setup key %s", accountId, defaultKey.Key)
+	log.Debugf("created new account %s with setup key %s", accountId, defaultKey.Id)
 
 	acc := &Account{
 		Id:               accountId,
@@ -1304,9 +1726,13 @@ func newAccountWithId(accountId, userId, domain string) *Account {
 		Routes:           routes,
 		NameServerGroups: nameServersGroups,
 		DNSSettings:      dnsSettings,
+		DNSZones:         make(map[string]*DNSZone),
+		SSHPolicies:      make(map[string]*SSHPolicy),
+		PostureChecks:    make(map[string]*PostureCheck),
 		Settings: &Settings{
 			PeerLoginExpirationEnabled: true,
 			PeerLoginExpiration:        DefaultPeerLoginExpiration,
+			EphemeralPeersGracePeriod:  DefaultEphemeralPeersGracePeriod,
 		},
 	}
 
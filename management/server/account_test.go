@@ -12,6 +12,7 @@ import (
 	"github.com/netbirdio/netbird/management/server/activity"
 	"github.com/netbirdio/netbird/route"
 
+	"github.com/golang-jwt/jwt"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
@@ -458,6 +459,111 @@ func TestDefaultAccountManager_GetAccountFromToken(t *testing.T) {
 	}
 }
 
+func TestDefaultAccountManager_GetAccountFromToken_JWTGroupsSync(t *testing.T) {
+	manager, err := createManager(t)
+	require.NoError(t, err, "unable to create account manager")
+	account, err := manager.GetAccountByUserOrAccountID(userID, "", "")
+	require.NoError(t, err, "unable to create an account")
+
+	err = manager.SaveGroup(account.Id, userID, &Group{ID: "manual", Name: "manual", Issued: GroupIssuedAPI})
+	require.NoError(t, err, "unable to save group")
+	_, err = manager.SaveUser(account.Id, userID, &User{Id: userID, Role: UserRoleAdmin, AutoGroups: []string{"manual"}})
+	require.NoError(t, err, "unable to save user")
+
+	key, err := wgtypes.GeneratePrivateKey()
+	require.NoError(t, err)
+	peer, _, err := manager.AddPeer("", userID, &Peer{Key: key.PublicKey().String(), Meta: PeerSystemMeta{Hostname: "peer"}})
+	require.NoError(t, err, "unable to add peer")
+
+	_, err = manager.UpdateAccountSettings(account.Id, userID, &Settings{
+		PeerLoginExpiration: time.Hour,
+		JWTGroupsClaimName:  "groups",
+	})
+	require.NoError(t, err, "unable to update account settings")
+
+	login := func(groups ...interface{}) (*Account, *User) {
+		claims := jwtclaims.AuthorizationClaims{UserId: userID, Raw: jwt.MapClaims{"groups": groups}}
+		account, user, err := manager.GetAccountFromToken(claims)
+		require.NoError(t, err, "unable to get account from token")
+		return account, user
+	}
+
+	account, user := login("devs", "ops")
+	devs, ops := account.getGroupByName("devs"), account.getGroupByName("ops")
+	require.NotNil(t, devs, "group from the JWT claim should be created")
+	require.NotNil(t, ops, "group from the JWT claim should be created")
+	assert.Equal(t, GroupIssuedJWT, devs.Issued)
+	assert.ElementsMatch(t, []string{"manual", devs.ID, ops.ID}, user.AutoGroups)
+	assert.Contains(t, devs.Peers, peer.ID, "user peers should be added to the JWT groups")
+	assert.Contains(t, ops.Peers, peer.ID, "user peers should be added to the JWT groups")
+
+	account, user = login("devs")
+	assert.ElementsMatch(t, []string{"manual", devs.ID}, user.AutoGroups)
+	assert.NotContains(t, account.Groups[ops.ID].Peers, peer.ID, "user peers should be removed from the left JWT groups")
+	assert.Contains(t, account.Groups[devs.ID].Peers, peer.ID)
+	assert.Len(t, account.Groups, 4, "existing groups should be reused")
+
+	_, user = login()
+	assert.ElementsMatch(t, []string{"manual"}, user.AutoGroups, "manually assigned groups should be kept")
+
+	// a claim matching a manually assigned group doesn't make it managed by the claim
+	_, user = login("manual")
+	assert.ElementsMatch(t, []string{"manual"}, user.AutoGroups)
+	assert.Empty(t, user.JWTGroups)
+	_, user = login()
+	assert.ElementsMatch(t, []string{"manual"}, user.AutoGroups, "manually assigned groups should be kept")
+}
+
+func TestDefaultAccountManager_GetAccountFromToken_JWTGroupsSyncExistingGroup(t *testing.T) {
+	manager, err := createManager(t)
+	require.NoError(t, err, "unable to create account manager")
+	account, err := manager.GetAccountByUserOrAccountID(userID, "", "")
+	require.NoError(t, err, "unable to create an account")
+
+	err = manager.SaveGroup(account.Id, userID, &Group{ID: "admins", Name: "admins", Issued: GroupIssuedAPI})
+	require.NoError(t, err, "unable to save group")
+
+	key, err := wgtypes.GeneratePrivateKey()
+	require.NoError(t, err)
+	peer, _, err := manager.AddPeer("", userID, &Peer{Key: key.PublicKey().String(), Meta: PeerSystemMeta{Hostname: "peer"}})
+	require.NoError(t, err, "unable to add peer")
+
+	_, err = manager.UpdateAccountSettings(account.Id, userID, &Settings{
+		PeerLoginExpiration: time.Hour,
+		JWTGroupsClaimName:  "groups",
+	})
+	require.NoError(t, err, "unable to update account settings")
+
+	login := func(groups ...interface{}) (*Account, *User) {
+		claims := jwtclaims.AuthorizationClaims{UserId: userID, Raw: jwt.MapClaims{"groups": groups}}
+		account, user, err := manager.GetAccountFromToken(claims)
+		require.NoError(t, err, "unable to get account from token")
+		return account, user
+	}
+
+	account, user := login("admins")
+	assert.ElementsMatch(t, []string{"admins"}, user.AutoGroups, "the existing group should be assigned from the claim")
+	assert.ElementsMatch(t, []string{"admins"}, user.JWTGroups)
+	assert.Contains(t, account.Groups["admins"].Peers, peer.ID)
+	assert.Equal(t, GroupIssuedAPI, account.Groups["admins"].Issued)
+
+	account, user = login()
+	assert.Empty(t, user.AutoGroups, "the group should be removed once it is dropped from the claim")
+	assert.Empty(t, user.JWTGroups)
+	assert.NotContains(t, account.Groups["admins"].Peers, peer.ID)
+	require.NotNil(t, account.Groups["admins"], "the group should not be deleted")
+
+	// the group removed from the user manually is no longer managed by the claim
+	_, user = login("admins")
+	require.ElementsMatch(t, []string{"admins"}, user.JWTGroups)
+	_, err = manager.SaveUser(account.Id, userID, &User{Id: userID, Role: UserRoleAdmin, AutoGroups: []string{}})
+	require.NoError(t, err, "unable to save user")
+	_, err = manager.SaveUser(account.Id, userID, &User{Id: userID, Role: UserRoleAdmin, AutoGroups: []string{"admins"}})
+	require.NoError(t, err, "unable to save user")
+	_, user = login()
+	assert.ElementsMatch(t, []string{"admins"}, user.AutoGroups, "manually assigned groups should be kept")
+}
+
 func TestAccountManager_PrivateAccount(t *testing.T) {
 	manager, err := createManager(t)
 	if err != nil {
@@ -616,7 +722,7 @@ func TestAccountManager_GetAccount(t *testing.T) {
 	}
 
 	for _, key := range account.SetupKeys {
-		if _, ok := getAccount.SetupKeys[key.Key]; !ok {
+		if _, err := getAccount.findSetupKeyByPlainKey(key.Key); err != nil {
 			t.Errorf("expected account to have setup key %s, not found", key.Key)
 		}
 	}
@@ -657,7 +763,6 @@ func TestAccountManager_AddPeer(t *testing.T) {
 		return
 	}
 	expectedPeerKey := key.PublicKey().String()
-	expectedSetupKey := setupKey.Key
 
 	peer, _, err := manager.AddPeer(setupKey.Key, "", &Peer{
 		Key:  expectedPeerKey,
@@ -674,6 +779,13 @@ func TestAccountManager_AddPeer(t *testing.T) {
 		return
 	}
 
+	storedSetupKey, err := account.findSetupKeyByPlainKey(setupKey.Key)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	expectedSetupKey := storedSetupKey.Key
+
 	if peer.Key != expectedPeerKey {
 		t.Errorf("expecting just added peer to have key = %s, got %s", expectedPeerKey, peer.Key)
 	}
@@ -1248,7 +1360,31 @@ func TestAccount_Copy(t *testing.T) {
 			},
 		},
 		DNSSettings: &DNSSettings{DisabledManagementGroups: []string{}},
-		Settings:    &Settings{},
+		DNSZones: map[string]*DNSZone{
+			"zone1": {
+				ID:      "zone1",
+				Records: []nbdns.SimpleRecord{},
+				Groups:  []string{},
+			},
+		},
+		SSHPolicies: map[string]*SSHPolicy{
+			"sshPolicy1": {
+				ID:           "sshPolicy1",
+				Sources:      []string{},
+				Destinations: []string{},
+				LocalUsers:   []string{},
+			},
+		},
+		PostureChecks: map[string]*PostureCheck{
+			"postureCheck1": {
+				ID:               "postureCheck1",
+				AllowedOS:        []string{},
+				AllowedPlatforms: []string{},
+				HostnamePatterns: []string{},
+			},
+		},
+		SSHCertificateAuthority: &SSHCertificateAuthority{},
+		Settings:                &Settings{},
 	}
 	err := hasNilField(account)
 	if err != nil {
@@ -1290,6 +1426,7 @@ func TestDefaultAccountManager_DefaultAccountSettings(t *testing.T) {
 	assert.NotNil(t, account.Settings)
 	assert.Equal(t, account.Settings.PeerLoginExpirationEnabled, true)
 	assert.Equal(t, account.Settings.PeerLoginExpiration, 24*time.Hour)
+	assert.Equal(t, account.Settings.EphemeralPeersGracePeriod, DefaultEphemeralPeersGracePeriod)
 }
 
 func TestDefaultAccountManager_UpdatePeer_PeerLoginExpiration(t *testing.T) {
@@ -1778,3 +1915,48 @@ func waitTimeout(wg *sync.WaitGroup, timeout time.Duration) bool {
 		return true
 	}
 }
+
+func TestAccount_GetExpiredEphemeralPeers(t *testing.T) {
+	gracePeriod := time.Hour
+	account := &Account{
+		Peers: map[string]*Peer{
+			"connected": {
+				ID:        "connected",
+				Ephemeral: true,
+				Status:    &PeerStatus{Connected: true, LastSeen: time.Now().Add(-2 * gracePeriod)},
+			},
+			"expired": {
+				ID:        "expired",
+				Ephemeral: true,
+				Status:    &PeerStatus{Connected: false, LastSeen: time.Now().Add(-2 * gracePeriod)},
+			},
+			"within-grace-period": {
+				ID:        "within-grace-period",
+				Ephemeral: true,
+				Status:    &PeerStatus{Connected: false, LastSeen: time.Now().Add(-gracePeriod / 2)},
+			},
+			"not-ephemeral": {
+				ID:     "not-ephemeral",
+				Status: &PeerStatus{Connected: false, LastSeen: time.Now().Add(-2 * gracePeriod)},
+			},
+		},
+		Settings: &Settings{EphemeralPeersGracePeriod: gracePeriod},
+	}
+
+	expired := account.GetExpiredEphemeralPeers()
+	require.Len(t, expired, 1)
+	assert.Equal(t, "expired", expired[0].ID)
+
+	nextRun, ok := account.GetNextEphemeralPeerDeletion()
+	assert.True(t, ok)
+	assert.LessOrEqual(t, nextRun, time.Duration(0), "expired peer should be deleted right away")
+
+	delete(account.Peers, "expired")
+	nextRun, ok = account.GetNextEphemeralPeerDeletion()
+	assert.True(t, ok)
+	assert.InDelta(t, gracePeriod/2, nextRun, float64(time.Second))
+
+	delete(account.Peers, "within-grace-period")
+	_, ok = account.GetNextEphemeralPeerDeletion()
+	assert.False(t, ok, "no disconnected ephemeral peers left")
+}
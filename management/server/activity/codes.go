@@ -83,6 +83,56 @@ const (
 	AccountPeerLoginExpirationDisabled
 	// AccountPeerLoginExpirationDurationUpdated indicates that a user updated peer login expiration duration for the account
 	AccountPeerLoginExpirationDurationUpdated
+	// PeerIPUpdated indicates that a user updated the IP address of a peer
+	PeerIPUpdated
+	// DNSZoneCreated indicates that a user created a custom DNS zone
+	DNSZoneCreated
+	// DNSZoneUpdated indicates that a user updated a custom DNS zone
+	DNSZoneUpdated
+	// DNSZoneDeleted indicates that a user deleted a custom DNS zone
+	DNSZoneDeleted
+	// SSHPolicyCreated indicates that a user created an SSH access policy
+	SSHPolicyCreated
+	// SSHPolicyUpdated indicates that a user updated an SSH access policy
+	SSHPolicyUpdated
+	// SSHPolicyDeleted indicates that a user deleted an SSH access policy
+	SSHPolicyDeleted
+	// PeerSSHSessionStarted indicates that a remote peer opened a session on the embedded SSH server of a peer
+	PeerSSHSessionStarted
+	// PeerSSHSessionEnded indicates that a session on the embedded SSH server of a peer ended
+	PeerSSHSessionEnded
+	// SSHCertificateIssued indicates that the account SSH certificate authority issued a certificate to a user
+	SSHCertificateIssued
+	// EphemeralPeerRemoved indicates that an ephemeral peer was removed after being disconnected for the grace period
+	EphemeralPeerRemoved
+	// AccountEphemeralPeersGracePeriodUpdated indicates that a user updated the ephemeral peers grace period for the account
+	AccountEphemeralPeersGracePeriodUpdated
+	// PeerApproved indicates that a user approved a peer that was pending an approval
+	PeerApproved
+	// PeerRejected indicates that a user rejected a peer that was pending an approval
+	PeerRejected
+	// AccountPeerApprovalEnabled indicates that a user enabled peer approval for the account
+	AccountPeerApprovalEnabled
+	// AccountPeerApprovalDisabled indicates that a user disabled peer approval for the account
+	AccountPeerApprovalDisabled
+	// PostureCheckCreated indicates that a user created a posture check
+	PostureCheckCreated
+	// PostureCheckUpdated indicates that a user updated a posture check
+	PostureCheckUpdated
+	// PostureCheckDeleted indicates that a user deleted a posture check
+	PostureCheckDeleted
+	// AccountJWTGroupsClaimNameUpdated indicates that a user updated the name of the JWT claim the user groups are synchronized from
+	AccountJWTGroupsClaimNameUpdated
+	// UserBlocked indicates that a user blocked another user
+	UserBlocked
+	// UserUnblocked indicates that a user unblocked another user
+	UserUnblocked
+	// ServiceUserCreated indicates that a user created a service user
+	ServiceUserCreated
+	// PersonalAccessTokenCreated indicates that a user created a personal access token
+	PersonalAccessTokenCreated
+	// PersonalAccessTokenDeleted indicates that a user deleted a personal access token
+	PersonalAccessTokenDeleted
 )
 
 const (
@@ -168,6 +218,56 @@ const (
 	AccountPeerLoginExpirationDisabledMessage string = "Peer login expiration disabled for the account"
 	// AccountPeerLoginExpirationDurationUpdatedMessage is a human-readable text message of the AccountPeerLoginExpirationDurationUpdated activity
 	AccountPeerLoginExpirationDurationUpdatedMessage string = "Peer login expiration duration updated"
+	// PeerIPUpdatedMessage is a human-readable text message of the PeerIPUpdated activity
+	PeerIPUpdatedMessage string = "Peer IP address updated"
+	// DNSZoneCreatedMessage is a human-readable text message of the DNSZoneCreated activity
+	DNSZoneCreatedMessage string = "DNS zone created"
+	// DNSZoneUpdatedMessage is a human-readable text message of the DNSZoneUpdated activity
+	DNSZoneUpdatedMessage string = "DNS zone updated"
+	// DNSZoneDeletedMessage is a human-readable text message of the DNSZoneDeleted activity
+	DNSZoneDeletedMessage string = "DNS zone deleted"
+	// SSHPolicyCreatedMessage is a human-readable text message of the SSHPolicyCreated activity
+	SSHPolicyCreatedMessage string = "SSH policy created"
+	// SSHPolicyUpdatedMessage is a human-readable text message of the SSHPolicyUpdated activity
+	SSHPolicyUpdatedMessage string = "SSH policy updated"
+	// SSHPolicyDeletedMessage is a human-readable text message of the SSHPolicyDeleted activity
+	SSHPolicyDeletedMessage string = "SSH policy deleted"
+	// PeerSSHSessionStartedMessage is a human-readable text message of the PeerSSHSessionStarted activity
+	PeerSSHSessionStartedMessage string = "Peer SSH session started"
+	// PeerSSHSessionEndedMessage is a human-readable text message of the PeerSSHSessionEnded activity
+	PeerSSHSessionEndedMessage string = "Peer SSH session ended"
+	// SSHCertificateIssuedMessage is a human-readable text message of the SSHCertificateIssued activity
+	SSHCertificateIssuedMessage string = "SSH certificate issued"
+	// EphemeralPeerRemovedMessage is a human-readable text message of the EphemeralPeerRemoved activity
+	EphemeralPeerRemovedMessage string = "Ephemeral peer deleted"
+	// AccountEphemeralPeersGracePeriodUpdatedMessage is a human-readable text message of the AccountEphemeralPeersGracePeriodUpdated activity
+	AccountEphemeralPeersGracePeriodUpdatedMessage string = "Ephemeral peers grace period updated"
+	// PeerApprovedMessage is a human-readable text message of the PeerApproved activity
+	PeerApprovedMessage string = "Peer approved"
+	// PeerRejectedMessage is a human-readable text message of the PeerRejected activity
+	PeerRejectedMessage string = "Peer rejected"
+	// AccountPeerApprovalEnabledMessage is a human-readable text message of the AccountPeerApprovalEnabled activity
+	AccountPeerApprovalEnabledMessage string = "Peer approval enabled"
+	// AccountPeerApprovalDisabledMessage is a human-readable text message of the AccountPeerApprovalDisabled activity
+	AccountPeerApprovalDisabledMessage string = "Peer approval disabled"
+	// PostureCheckCreatedMessage is a human-readable text message of the PostureCheckCreated activity
+	PostureCheckCreatedMessage string = "Posture check created"
+	// PostureCheckUpdatedMessage is a human-readable text message of the PostureCheckUpdated activity
+	PostureCheckUpdatedMessage string = "Posture check updated"
+	// PostureCheckDeletedMessage is a human-readable text message of the PostureCheckDeleted activity
+	PostureCheckDeletedMessage string = "Posture check deleted"
+	// AccountJWTGroupsClaimNameUpdatedMessage is a human-readable text message of the AccountJWTGroupsClaimNameUpdated activity
+	AccountJWTGroupsClaimNameUpdatedMessage string = "JWT groups claim name updated"
+	// UserBlockedMessage is a human-readable text message of the UserBlocked activity
+	UserBlockedMessage string = "User blocked"
+	// UserUnblockedMessage is a human-readable text message of the UserUnblocked activity
+	UserUnblockedMessage string = "User unblocked"
+	// ServiceUserCreatedMessage is a human-readable text message of the ServiceUserCreated activity
+	ServiceUserCreatedMessage string = "Service user created"
+	// PersonalAccessTokenCreatedMessage is a human-readable text message of the PersonalAccessTokenCreated activity
+	PersonalAccessTokenCreatedMessage string = "Personal access token created"
+	// PersonalAccessTokenDeletedMessage is a human-readable text message of the PersonalAccessTokenDeleted activity
+	PersonalAccessTokenDeletedMessage string = "Personal access token deleted"
 )
 
 // Activity that triggered an Event
@@ -258,6 +358,56 @@ func (a Activity) Message() string {
 		return AccountPeerLoginExpirationDisabledMessage
 	case AccountPeerLoginExpirationDurationUpdated:
 		return AccountPeerLoginExpirationDurationUpdatedMessage
+	case PeerIPUpdated:
+		return PeerIPUpdatedMessage
+	case DNSZoneCreated:
+		return DNSZoneCreatedMessage
+	case DNSZoneUpdated:
+		return DNSZoneUpdatedMessage
+	case DNSZoneDeleted:
+		return DNSZoneDeletedMessage
+	case SSHPolicyCreated:
+		return SSHPolicyCreatedMessage
+	case SSHPolicyUpdated:
+		return SSHPolicyUpdatedMessage
+	case SSHPolicyDeleted:
+		return SSHPolicyDeletedMessage
+	case PeerSSHSessionStarted:
+		return PeerSSHSessionStartedMessage
+	case PeerSSHSessionEnded:
+		return PeerSSHSessionEndedMessage
+	case SSHCertificateIssued:
+		return SSHCertificateIssuedMessage
+	case EphemeralPeerRemoved:
+		return EphemeralPeerRemovedMessage
+	case AccountEphemeralPeersGracePeriodUpdated:
+		return AccountEphemeralPeersGracePeriodUpdatedMessage
+	case PeerApproved:
+		return PeerApprovedMessage
+	case PeerRejected:
+		return PeerRejectedMessage
+	case AccountPeerApprovalEnabled:
+		return AccountPeerApprovalEnabledMessage
+	case AccountPeerApprovalDisabled:
+		return AccountPeerApprovalDisabledMessage
+	case PostureCheckCreated:
+		return PostureCheckCreatedMessage
+	case PostureCheckUpdated:
+		return PostureCheckUpdatedMessage
+	case PostureCheckDeleted:
+		return PostureCheckDeletedMessage
+	case AccountJWTGroupsClaimNameUpdated:
+		return AccountJWTGroupsClaimNameUpdatedMessage
+	case UserBlocked:
+		return UserBlockedMessage
+	case UserUnblocked:
+		return UserUnblockedMessage
+	case ServiceUserCreated:
+		return ServiceUserCreatedMessage
+	case PersonalAccessTokenCreated:
+		return PersonalAccessTokenCreatedMessage
+	case PersonalAccessTokenDeleted:
+		return PersonalAccessTokenDeletedMessage
 	default:
 		return "UNKNOWN_ACTIVITY"
 	}
@@ -348,6 +498,56 @@ func (a Activity) StringCode() string {
 		return "account.setting.peer.login.expiration.enable"
 	case AccountPeerLoginExpirationDisabled:
 		return "account.setting.peer.login.expiration.disable"
+	case PeerIPUpdated:
+		return "peer.ip.update"
+	case DNSZoneCreated:
+		return "dns.zone.add"
+	case DNSZoneUpdated:
+		return "dns.zone.update"
+	case DNSZoneDeleted:
+		return "dns.zone.delete"
+	case SSHPolicyCreated:
+		return "ssh.policy.add"
+	case SSHPolicyUpdated:
+		return "ssh.policy.update"
+	case SSHPolicyDeleted:
+		return "ssh.policy.delete"
+	case PeerSSHSessionStarted:
+		return "peer.ssh.session.start"
+	case PeerSSHSessionEnded:
+		return "peer.ssh.session.end"
+	case SSHCertificateIssued:
+		return "ssh.certificate.issue"
+	case EphemeralPeerRemoved:
+		return "peer.ephemeral.delete"
+	case AccountEphemeralPeersGracePeriodUpdated:
+		return "account.setting.ephemeral.peers.grace.period.update"
+	case PeerApproved:
+		return "peer.approve"
+	case PeerRejected:
+		return "peer.reject"
+	case AccountPeerApprovalEnabled:
+		return "account.setting.peer.approval.enable"
+	case AccountPeerApprovalDisabled:
+		return "account.setting.peer.approval.disable"
+	case PostureCheckCreated:
+		return "posture.check.create"
+	case PostureCheckUpdated:
+		return "posture.check.update"
+	case PostureCheckDeleted:
+		return "posture.check.delete"
+	case AccountJWTGroupsClaimNameUpdated:
+		return "account.setting.jwt.groups.claim.update"
+	case UserBlocked:
+		return "user.block"
+	case UserUnblocked:
+		return "user.unblock"
+	case ServiceUserCreated:
+		return "service.user.create"
+	case PersonalAccessTokenCreated:
+		return "personal.access.token.create"
+	case PersonalAccessTokenDeleted:
+		return "personal.access.token.delete"
 	default:
 		return "UNKNOWN_ACTIVITY"
 	}
@@ -147,9 +147,10 @@ func toProtocolDNSConfig(update nbdns.Config) *proto.DNSConfig {
 		}
 		for _, ns := range nsGroup.NameServers {
 			protoNS := &proto.NameServer{
-				IP:     ns.IP.String(),
-				Port:   int64(ns.Port),
-				NSType: int64(ns.NSType),
+				IP:       ns.IP.String(),
+				Port:     int64(ns.Port),
+				NSType:   int64(ns.NSType),
+				Hostname: ns.Hostname,
 			}
 			protoGroup.NameServers = append(protoGroup.NameServers, protoNS)
 		}
@@ -170,6 +171,9 @@ func getPeersCustomZone(account *Account, dnsDomain string) nbdns.CustomZone {
 	}
 
 	for _, peer := range account.Peers {
+		if !account.hasNetworkAccess(peer) {
+			continue
+		}
 		if peer.DNSLabel == "" {
 			log.Errorf("found a peer with empty dns label. It was probably caused by a invalid character in its name. Peer Name: %s", peer.Name)
 			continue
@@ -0,0 +1,347 @@
+package server
+
+import (
+	"net"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/miekg/dns"
+
+	nbdns "github.com/netbirdio/netbird/dns"
+	"github.com/netbirdio/netbird/management/server/activity"
+	"github.com/netbirdio/netbird/management/server/status"
+)
+
+// maxTXTStringLength is the maximum length of a single character-string in a TXT record
+const maxTXTStringLength = 255
+
+// supportedDNSZoneRecordTypes lists the record types that can be added to a custom DNS zone
+var supportedDNSZoneRecordTypes = map[uint16]struct{}{
+	dns.TypeA:     {},
+	dns.TypeAAAA:  {},
+	dns.TypeCNAME: {},
+	dns.TypeTXT:   {},
+	dns.TypeSRV:   {},
+}
+
+// DNSZone represents a custom DNS zone with records managed by the account admins
+// and distributed to the peers of its groups
+type DNSZone struct {
+	// ID of the zone
+	ID string
+	// Name of the zone visible in the UI
+	Name string
+	// Description of the zone visible in the UI
+	Description string
+	// Domain is the zone's domain
+	Domain string
+	// Records of the zone. Names are fully qualified and RData is in zone file format
+	Records []nbdns.SimpleRecord
+	// Groups of peers the zone is distributed to
+	Groups []string
+	// Enabled indicates whether the zone is distributed to peers
+	Enabled bool
+}
+
+// Copy returns a copy of the DNS zone
+func (z *DNSZone) Copy() *DNSZone {
+	zone := &DNSZone{
+		ID:          z.ID,
+		Name:        z.Name,
+		Description: z.Description,
+		Domain:      z.Domain,
+		Records:     make([]nbdns.SimpleRecord, len(z.Records)),
+		Groups:      make([]string, len(z.Groups)),
+		Enabled:     z.Enabled,
+	}
+	copy(zone.Records, z.Records)
+	copy(zone.Groups, z.Groups)
+	return zone
+}
+
+// EventMeta returns activity event meta related to the DNS zone
+func (z *DNSZone) EventMeta() map[string]any {
+	return map[string]any{"name": z.Name, "domain": z.Domain}
+}
+
+// toCustomZone returns the zone in the format distributed to peers
+func (z *DNSZone) toCustomZone() nbdns.CustomZone {
+	records := make([]nbdns.SimpleRecord, len(z.Records))
+	copy(records, z.Records)
+	return nbdns.CustomZone{
+		Domain:  dns.Fqdn(z.Domain),
+		Records: records,
+	}
+}
+
+// GetDNSZone validates a user role and returns the DNS zone with the provided ID
+func (am *DefaultAccountManager) GetDNSZone(accountID, zoneID, userID string) (*DNSZone, error) {
+	unlock := am.Store.AcquireAccountLock(accountID)
+	defer unlock()
+
+	account, err := am.Store.GetAccount(accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := account.FindUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.IsAdmin() {
+		return nil, status.Errorf(status.PermissionDenied, "only admins are allowed to view DNS zones")
+	}
+
+	zone, ok := account.DNSZones[zoneID]
+	if !ok {
+		return nil, status.Errorf(status.NotFound, "DNS zone with ID %s not found", zoneID)
+	}
+
+	return zone.Copy(), nil
+}
+
+// SaveDNSZone validates a user role and creates or updates a DNS zone. The zone records are normalized in place
+func (am *DefaultAccountManager) SaveDNSZone(accountID, userID string, zoneToSave *DNSZone) error {
+	unlock := am.Store.AcquireAccountLock(accountID)
+	defer unlock()
+
+	if zoneToSave == nil {
+		return status.Errorf(status.InvalidArgument, "DNS zone provided is nil")
+	}
+
+	account, err := am.Store.GetAccount(accountID)
+	if err != nil {
+		return err
+	}
+
+	user, err := account.FindUser(userID)
+	if err != nil {
+		return err
+	}
+
+	if !user.IsAdmin() {
+		return status.Errorf(status.PermissionDenied, "only admins are allowed to update DNS zones")
+	}
+
+	err = validateDNSZone(zoneToSave, account, am.dnsDomain)
+	if err != nil {
+		return err
+	}
+
+	if account.DNSZones == nil {
+		account.DNSZones = make(map[string]*DNSZone)
+	}
+
+	_, exists := account.DNSZones[zoneToSave.ID]
+	account.DNSZones[zoneToSave.ID] = zoneToSave.Copy()
+
+	account.Network.IncSerial()
+	if err = am.Store.SaveAccount(account); err != nil {
+		return err
+	}
+
+	action := activity.DNSZoneCreated
+	if exists {
+		action = activity.DNSZoneUpdated
+	}
+	am.storeEvent(userID, zoneToSave.ID, accountID, action, zoneToSave.EventMeta())
+
+	return am.updateAccountPeers(account)
+}
+
+// DeleteDNSZone validates a user role and deletes the DNS zone with the provided ID
+func (am *DefaultAccountManager) DeleteDNSZone(accountID, zoneID, userID string) error {
+	unlock := am.Store.AcquireAccountLock(accountID)
+	defer unlock()
+
+	account, err := am.Store.GetAccount(accountID)
+	if err != nil {
+		return err
+	}
+
+	user, err := account.FindUser(userID)
+	if err != nil {
+		return err
+	}
+
+	if !user.IsAdmin() {
+		return status.Errorf(status.PermissionDenied, "only admins are allowed to delete DNS zones")
+	}
+
+	zone, ok := account.DNSZones[zoneID]
+	if !ok {
+		return status.Errorf(status.NotFound, "DNS zone with ID %s not found", zoneID)
+	}
+	delete(account.DNSZones, zoneID)
+
+	account.Network.IncSerial()
+	if err = am.Store.SaveAccount(account); err != nil {
+		return err
+	}
+
+	am.storeEvent(userID, zone.ID, accountID, activity.DNSZoneDeleted, zone.EventMeta())
+
+	return am.updateAccountPeers(account)
+}
+
+// ListDNSZones validates a user role and returns the DNS zones of the account
+func (am *DefaultAccountManager) ListDNSZones(accountID, userID string) ([]*DNSZone, error) {
+	unlock := am.Store.AcquireAccountLock(accountID)
+	defer unlock()
+
+	account, err := am.Store.GetAccount(accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := account.FindUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.IsAdmin() {
+		return nil, status.Errorf(status.PermissionDenied, "only admins are allowed to view DNS zones")
+	}
+
+	zones := make([]*DNSZone, 0, len(account.DNSZones))
+	for _, zone := range account.DNSZones {
+		zones = append(zones, zone.Copy())
+	}
+
+	return zones, nil
+}
+
+// getPeerDNSZones returns the enabled custom zones distributed to the groups of the peer
+func getPeerDNSZones(account *Account, peerID string) []nbdns.CustomZone {
+	groupList := account.getPeerGroups(peerID)
+
+	var zones []nbdns.CustomZone
+	for _, zone := range account.DNSZones {
+		// peers reject custom zones without records
+		if !zone.Enabled || len(zone.Records) == 0 {
+			continue
+		}
+		for _, gID := range zone.Groups {
+			_, found := groupList[gID]
+			if found {
+				zones = append(zones, zone.toCustomZone())
+				break
+			}
+		}
+	}
+
+	return zones
+}
+
+func validateDNSZone(zone *DNSZone, account *Account, peersDomain string) error {
+	if zone.ID == "" {
+		return status.Errorf(status.InvalidArgument, "DNS zone ID should not be empty")
+	}
+
+	if utf8.RuneCountInString(zone.Name) > nbdns.MaxGroupNameChar || zone.Name == "" {
+		return status.Errorf(status.InvalidArgument, "DNS zone name should be between 1 and %d", nbdns.MaxGroupNameChar)
+	}
+
+	for _, existing := range account.DNSZones {
+		if existing.Name == zone.Name && existing.ID != zone.ID {
+			return status.Errorf(status.InvalidArgument, "a DNS zone with name %s already exists", zone.Name)
+		}
+	}
+
+	zone.Domain = strings.ToLower(dns.Fqdn(zone.Domain))
+	if _, valid := dns.IsDomainName(zone.Domain); !valid || zone.Domain == nbdns.RootZone {
+		return status.Errorf(status.InvalidArgument, "DNS zone got an invalid domain: %s", zone.Domain)
+	}
+
+	if peersDomain != "" && dns.IsSubDomain(dns.Fqdn(peersDomain), zone.Domain) {
+		return status.Errorf(status.InvalidArgument, "DNS zone domain %s overlaps with the peers domain %s", zone.Domain, peersDomain)
+	}
+
+	err := validateGroups(zone.Groups, account.Groups)
+	if err != nil {
+		return err
+	}
+
+	for i, record := range zone.Records {
+		normalized, err := normalizeDNSZoneRecord(zone.Domain, record)
+		if err != nil {
+			return err
+		}
+		zone.Records[i] = normalized
+	}
+
+	return nil
+}
+
+// normalizeDNSZoneRecord returns the record with a fully qualified name, the default class and TTL
+// and quoted TXT data. It returns an error if the record can't be served by the peers
+func normalizeDNSZoneRecord(zoneDomain string, record nbdns.SimpleRecord) (nbdns.SimpleRecord, error) {
+	if _, ok := supportedDNSZoneRecordTypes[uint16(record.Type)]; !ok {
+		return record, status.Errorf(status.InvalidArgument, "DNS record type %s is not supported", dns.Type(record.Type).String())
+	}
+
+	switch {
+	case record.Name == "" || record.Name == "@":
+		record.Name = zoneDomain
+	case !dns.IsFqdn(record.Name):
+		record.Name = record.Name + "." + zoneDomain
+	}
+	record.Name = strings.ToLower(record.Name)
+
+	if _, valid := dns.IsDomainName(record.Name); !valid || !dns.IsSubDomain(zoneDomain, record.Name) {
+		return record, status.Errorf(status.InvalidArgument, "DNS record name %s is not part of the zone %s", record.Name, zoneDomain)
+	}
+
+	if record.TTL < 0 {
+		return record, status.Errorf(status.InvalidArgument, "DNS record %s has a negative TTL", record.Name)
+	}
+	if record.TTL == 0 {
+		record.TTL = defaultTTL
+	}
+	record.Class = nbdns.DefaultClass
+
+	switch uint16(record.Type) {
+	case dns.TypeA:
+		ip := net.ParseIP(record.RData)
+		if ip == nil || ip.To4() == nil {
+			return record, status.Errorf(status.InvalidArgument, "DNS record %s has an invalid IPv4 address %s", record.Name, record.RData)
+		}
+	case dns.TypeAAAA:
+		ip := net.ParseIP(record.RData)
+		if ip == nil || ip.To4() != nil {
+			return record, status.Errorf(status.InvalidArgument, "DNS record %s has an invalid IPv6 address %s", record.Name, record.RData)
+		}
+	case dns.TypeCNAME:
+		record.RData = dns.Fqdn(record.RData)
+	case dns.TypeTXT:
+		record.RData = quoteTXT(record.RData)
+	}
+
+	if _, err := dns.NewRR(record.String()); err != nil {
+		return record, status.Errorf(status.InvalidArgument, "DNS record %s has invalid data: %v", record.Name, err)
+	}
+
+	return record, nil
+}
+
+// quoteTXT returns the TXT data in zone file format splitting it into character-strings of up to 255 chars.
+// Data that is already quoted is returned as is
+func quoteTXT(data string) string {
+	if strings.HasPrefix(data, "\"") {
+		return data
+	}
+
+	var parts []string
+	for len(data) > maxTXTStringLength {
+		parts = append(parts, data[:maxTXTStringLength])
+		data = data[maxTXTStringLength:]
+	}
+	parts = append(parts, data)
+
+	escaper := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	for i, part := range parts {
+		parts[i] = "\"" + escaper.Replace(part) + "\""
+	}
+	return strings.Join(parts, " ")
+}
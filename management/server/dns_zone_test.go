@@ -0,0 +1,199 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/require"
+
+	nbdns "github.com/netbirdio/netbird/dns"
+)
+
+func TestSaveDNSZone(t *testing.T) {
+	testCases := []struct {
+		name            string
+		zone            *DNSZone
+		shouldFail      bool
+		expectedRecords []nbdns.SimpleRecord
+	}{
+		{
+			name: "Should Normalize Records",
+			zone: &DNSZone{
+				ID:     "zone1",
+				Name:   "internal",
+				Domain: "Internal.Example",
+				Groups: []string{group1ID},
+				Records: []nbdns.SimpleRecord{
+					{Name: "@", Type: int(dns.TypeA), RData: "10.0.0.1"},
+					{Name: "www", Type: int(dns.TypeCNAME), TTL: 60, RData: "internal.example"},
+					{Name: "v6.internal.example.", Type: int(dns.TypeAAAA), RData: "fd00::1"},
+					{Name: "txt", Type: int(dns.TypeTXT), RData: "v=spf1 \"quoted\" -all"},
+					{Name: "_ssh._tcp", Type: int(dns.TypeSRV), RData: "10 5 22 internal.example."},
+				},
+				Enabled: true,
+			},
+			expectedRecords: []nbdns.SimpleRecord{
+				{Name: "internal.example.", Type: int(dns.TypeA), Class: nbdns.DefaultClass, TTL: defaultTTL, RData: "10.0.0.1"},
+				{Name: "www.internal.example.", Type: int(dns.TypeCNAME), Class: nbdns.DefaultClass, TTL: 60, RData: "internal.example."},
+				{Name: "v6.internal.example.", Type: int(dns.TypeAAAA), Class: nbdns.DefaultClass, TTL: defaultTTL, RData: "fd00::1"},
+				{Name: "txt.internal.example.", Type: int(dns.TypeTXT), Class: nbdns.DefaultClass, TTL: defaultTTL, RData: "\"v=spf1 \\\"quoted\\\" -all\""},
+				{Name: "_ssh._tcp.internal.example.", Type: int(dns.TypeSRV), Class: nbdns.DefaultClass, TTL: defaultTTL, RData: "10 5 22 internal.example."},
+			},
+		},
+		{
+			name: "Should Fail On Unsupported Record Type",
+			zone: &DNSZone{
+				ID: "zone1", Name: "internal", Domain: "internal.example", Groups: []string{group1ID},
+				Records: []nbdns.SimpleRecord{{Name: "@", Type: int(dns.TypeMX), RData: "10 mail.internal.example."}},
+			},
+			shouldFail: true,
+		},
+		{
+			name: "Should Fail On Invalid IPv4 Address",
+			zone: &DNSZone{
+				ID: "zone1", Name: "internal", Domain: "internal.example", Groups: []string{group1ID},
+				Records: []nbdns.SimpleRecord{{Name: "@", Type: int(dns.TypeA), RData: "fd00::1"}},
+			},
+			shouldFail: true,
+		},
+		{
+			name: "Should Fail On Record Outside The Zone",
+			zone: &DNSZone{
+				ID: "zone1", Name: "internal", Domain: "internal.example", Groups: []string{group1ID},
+				Records: []nbdns.SimpleRecord{{Name: "other.example.", Type: int(dns.TypeA), RData: "10.0.0.1"}},
+			},
+			shouldFail: true,
+		},
+		{
+			name: "Should Fail On Invalid SRV Data",
+			zone: &DNSZone{
+				ID: "zone1", Name: "internal", Domain: "internal.example", Groups: []string{group1ID},
+				Records: []nbdns.SimpleRecord{{Name: "_ssh._tcp", Type: int(dns.TypeSRV), RData: "not a srv"}},
+			},
+			shouldFail: true,
+		},
+		{
+			name: "Should Fail On Unknown Group",
+			zone: &DNSZone{
+				ID: "zone1", Name: "internal", Domain: "internal.example", Groups: []string{"missingGroup"},
+			},
+			shouldFail: true,
+		},
+		{
+			name: "Should Fail On Root Domain",
+			zone: &DNSZone{
+				ID: "zone1", Name: "internal", Domain: ".", Groups: []string{group1ID},
+			},
+			shouldFail: true,
+		},
+		{
+			name: "Should Fail On Empty Name",
+			zone: &DNSZone{
+				ID: "zone1", Domain: "internal.example", Groups: []string{group1ID},
+			},
+			shouldFail: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			am, err := createNSManager(t)
+			require.NoError(t, err, "failed to create account manager")
+
+			account, err := initTestNSAccount(t, am)
+			require.NoError(t, err, "failed to init testing account")
+
+			err = am.SaveDNSZone(account.Id, userID, testCase.zone)
+			if testCase.shouldFail {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			savedZone, err := am.GetDNSZone(account.Id, testCase.zone.ID, userID)
+			require.NoError(t, err)
+			require.Equal(t, "internal.example.", savedZone.Domain)
+			require.Equal(t, testCase.expectedRecords, savedZone.Records)
+		})
+	}
+}
+
+func TestValidateDNSZonePeersDomainOverlap(t *testing.T) {
+	account := newAccountWithId("testingAcc", userID, "example.com")
+	account.Groups[group1ID] = &Group{ID: group1ID, Name: group1ID}
+
+	zone := &DNSZone{ID: "zone1", Name: "peers", Domain: "sub.netbird.cloud", Groups: []string{group1ID}}
+	require.Error(t, validateDNSZone(zone, account, "netbird.cloud"), "zone under the peers domain should be rejected")
+
+	zone.Domain = "internal.example"
+	require.NoError(t, validateDNSZone(zone, account, "netbird.cloud"))
+}
+
+func TestDeleteDNSZone(t *testing.T) {
+	am, err := createNSManager(t)
+	require.NoError(t, err, "failed to create account manager")
+
+	account, err := initTestNSAccount(t, am)
+	require.NoError(t, err, "failed to init testing account")
+
+	zone := &DNSZone{ID: "zone1", Name: "internal", Domain: "internal.example", Groups: []string{group1ID}}
+	require.NoError(t, am.SaveDNSZone(account.Id, userID, zone))
+
+	require.NoError(t, am.DeleteDNSZone(account.Id, zone.ID, userID))
+
+	_, err = am.GetDNSZone(account.Id, zone.ID, userID)
+	require.Error(t, err, "zone shouldn't be found after delete")
+
+	require.Error(t, am.DeleteDNSZone(account.Id, zone.ID, userID), "deleting a missing zone should fail")
+}
+
+func TestGetPeerDNSZones(t *testing.T) {
+	am, err := createNSManager(t)
+	require.NoError(t, err, "failed to create account manager")
+
+	_, err = initTestNSAccount(t, am)
+	require.NoError(t, err, "failed to init testing account")
+
+	account, err := am.Store.GetAccount("testingAcc")
+	require.NoError(t, err)
+
+	var peerInGroup, peerNotInGroup string
+	for id := range account.Peers {
+		if peerInGroup == "" {
+			peerInGroup = id
+			continue
+		}
+		peerNotInGroup = id
+	}
+	account.Groups[group1ID].Peers = append(account.Groups[group1ID].Peers, peerInGroup)
+	require.NoError(t, am.Store.SaveAccount(account))
+
+	zone := &DNSZone{
+		ID:      "zone1",
+		Name:    "internal",
+		Domain:  "internal.example",
+		Groups:  []string{group1ID},
+		Records: []nbdns.SimpleRecord{{Name: "app", Type: int(dns.TypeA), RData: "10.0.0.1"}},
+		Enabled: true,
+	}
+	require.NoError(t, am.SaveDNSZone(account.Id, userID, zone))
+
+	emptyZone := &DNSZone{ID: "zone2", Name: "empty", Domain: "empty.example", Groups: []string{group1ID}, Enabled: true}
+	require.NoError(t, am.SaveDNSZone(account.Id, userID, emptyZone))
+
+	account, err = am.Store.GetAccount(account.Id)
+	require.NoError(t, err)
+
+	zones := getPeerDNSZones(account, peerInGroup)
+	require.Len(t, zones, 1, "only the zone with records should be distributed")
+	require.Equal(t, "internal.example.", zones[0].Domain)
+	require.Equal(t, "app.internal.example.", zones[0].Records[0].Name)
+
+	require.Len(t, getPeerDNSZones(account, peerNotInGroup), 0, "peer outside of the zone groups shouldn't get the zone")
+
+	zone.Enabled = false
+	require.NoError(t, am.SaveDNSZone(account.Id, userID, zone))
+	account, err = am.Store.GetAccount(account.Id)
+	require.NoError(t, err)
+	require.Len(t, getPeerDNSZones(account, peerInGroup), 0, "disabled zone shouldn't be distributed")
+}
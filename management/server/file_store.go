@@ -19,13 +19,19 @@ const storeFileName = "store.json"
 
 // FileStore represents an account storage backed by a file persisted to disk
 type FileStore struct {
-	Accounts                map[string]*Account
-	SetupKeyID2AccountID    map[string]string `json:"-"`
-	PeerKeyID2AccountID     map[string]string `json:"-"`
-	PeerID2AccountID        map[string]string `json:"-"`
-	UserID2AccountID        map[string]string `json:"-"`
-	PrivateDomain2AccountID map[string]string `json:"-"`
+	Accounts             map[string]*Account
+	SetupKeyID2AccountID map[string]string `json:"-"`
+	// SetupKeyPrefix2IDs indexes the hashed setup keys by their upper case KeyPrefix
+	SetupKeyPrefix2IDs      map[string][]string `json:"-"`
+	PeerKeyID2AccountID     map[string]string   `json:"-"`
+	PeerID2AccountID        map[string]string   `json:"-"`
+	UserID2AccountID        map[string]string   `json:"-"`
+	PrivateDomain2AccountID map[string]string   `json:"-"`
+	HashedPAT2AccountID     map[string]string   `json:"-"`
 	InstallationID          string
+	// SetupKeySalt is the salt that was shared by the setup keys of all accounts before every key got its own salt.
+	// It is only kept to migrate the keys hashed with it and is cleared once they are migrated
+	SetupKeySalt string `json:",omitempty"`
 
 	// mutex to synchronise Store read/write operations
 	mux       sync.Mutex `json:"-"`
@@ -53,14 +59,16 @@ func restore(file string) (*FileStore, error) {
 			mux:                     sync.Mutex{},
 			globalAccountLock:       sync.Mutex{},
 			SetupKeyID2AccountID:    make(map[string]string),
+			SetupKeyPrefix2IDs:      make(map[string][]string),
 			PeerKeyID2AccountID:     make(map[string]string),
 			UserID2AccountID:        make(map[string]string),
 			PrivateDomain2AccountID: make(map[string]string),
 			PeerID2AccountID:        make(map[string]string),
+			HashedPAT2AccountID:     make(map[string]string),
 			storeFile:               file,
 		}
 
-		err = s.persist(file)
+		err := s.persist(file)
 		if err != nil {
 			return nil, err
 		}
@@ -76,10 +84,12 @@ func restore(file string) (*FileStore, error) {
 	store := read.(*FileStore)
 	store.storeFile = file
 	store.SetupKeyID2AccountID = make(map[string]string)
+	store.SetupKeyPrefix2IDs = make(map[string][]string)
 	store.PeerKeyID2AccountID = make(map[string]string)
 	store.UserID2AccountID = make(map[string]string)
 	store.PrivateDomain2AccountID = make(map[string]string)
 	store.PeerID2AccountID = make(map[string]string)
+	store.HashedPAT2AccountID = make(map[string]string)
 
 	for accountID, account := range store.Accounts {
 		if account.Settings == nil {
@@ -89,8 +99,17 @@ func restore(file string) (*FileStore, error) {
 			}
 		}
 
-		for setupKeyId := range account.SetupKeys {
-			store.SetupKeyID2AccountID[strings.ToUpper(setupKeyId)] = accountID
+		// migration to hashed setup keys. Keys stored before were kept in plaintext or hashed with the store salt.
+		if store.SetupKeySalt != "" {
+			setLegacySetupKeySalt(account, store.SetupKeySalt)
+		}
+		err = hashSetupKeys(account)
+		if err != nil {
+			return nil, err
+		}
+
+		for hashedKey, key := range account.SetupKeys {
+			store.indexSetupKey(hashedKey, key, accountID)
 		}
 
 		for _, peer := range account.Peers {
@@ -103,9 +122,9 @@ func restore(file string) (*FileStore, error) {
 		}
 		for _, user := range account.Users {
 			store.UserID2AccountID[user.Id] = accountID
-		}
-		for _, user := range account.Users {
-			store.UserID2AccountID[user.Id] = accountID
+			for _, pat := range user.PATs {
+				store.HashedPAT2AccountID[pat.HashedToken] = accountID
+			}
 		}
 
 		if account.Domain != "" && account.DomainCategory == PrivateCategory &&
@@ -190,6 +209,8 @@ func restore(file string) (*FileStore, error) {
 			}
 		}
 	}
+	// every setup key carries its own salt now
+	store.SetupKeySalt = ""
 
 	// we need this persist to apply changes we made to account.Peers (we set them to Disconnected)
 	err = store.persist(store.storeFile)
@@ -236,18 +257,24 @@ func (s *FileStore) AcquireAccountLock(accountID string) (unlock func()) {
 	return unlock
 }
 
+// SaveAccount saves a copy of the account. Setup keys that hold a plaintext key are stored hashed,
+// the provided account is not modified.
 func (s *FileStore) SaveAccount(account *Account) error {
 	s.mux.Lock()
 	defer s.mux.Unlock()
 
 	accountCopy := account.Copy()
+	err := hashSetupKeys(accountCopy)
+	if err != nil {
+		return err
+	}
 
 	s.Accounts[accountCopy.Id] = accountCopy
 
 	// todo check that account.Id and keyId are not exist already
 	// because if keyId exists for other accounts this can be bad
-	for keyID := range accountCopy.SetupKeys {
-		s.SetupKeyID2AccountID[strings.ToUpper(keyID)] = accountCopy.Id
+	for hashedKey, key := range accountCopy.SetupKeys {
+		s.indexSetupKey(hashedKey, key, accountCopy.Id)
 	}
 
 	// enforce peer to account index and delete peer to route indexes for rebuild
@@ -258,6 +285,9 @@ func (s *FileStore) SaveAccount(account *Account) error {
 
 	for _, user := range accountCopy.Users {
 		s.UserID2AccountID[user.Id] = accountCopy.Id
+		for _, pat := range user.PATs {
+			s.HashedPAT2AccountID[pat.HashedToken] = accountCopy.Id
+		}
 	}
 
 	if accountCopy.DomainCategory == PrivateCategory && accountCopy.IsDomainPrimaryAccount {
@@ -294,14 +324,14 @@ func (s *FileStore) GetAccountByPrivateDomain(domain string) (*Account, error) {
 	return account.Copy(), nil
 }
 
-// GetAccountBySetupKey returns account by setup key id
-func (s *FileStore) GetAccountBySetupKey(setupKey string) (*Account, error) {
+// GetAccountByHashedToken returns the account of the user owning the personal access token with the hash
+func (s *FileStore) GetAccountByHashedToken(hashedToken string) (*Account, error) {
 	s.mux.Lock()
 	defer s.mux.Unlock()
 
-	accountID, accountIDFound := s.SetupKeyID2AccountID[strings.ToUpper(setupKey)]
+	accountID, accountIDFound := s.HashedPAT2AccountID[hashedToken]
 	if !accountIDFound {
-		return nil, status.Errorf(status.NotFound, "account not found: provided setup key doesn't exists")
+		return nil, status.Errorf(status.NotFound, "account not found: provided token doesn't exist")
 	}
 
 	account, err := s.getAccount(accountID)
@@ -312,6 +342,33 @@ func (s *FileStore) GetAccountBySetupKey(setupKey string) (*Account, error) {
 	return account.Copy(), nil
 }
 
+// GetAccountBySetupKey returns account by the plaintext setup key
+func (s *FileStore) GetAccountBySetupKey(setupKey string) (*Account, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	for _, hashedKey := range s.SetupKeyPrefix2IDs[strings.ToUpper(plaintextKeyPrefix(setupKey))] {
+		account, err := s.getAccount(s.SetupKeyID2AccountID[hashedKey])
+		if err != nil {
+			continue
+		}
+		if key, ok := account.SetupKeys[hashedKey]; ok && key.matches(setupKey) {
+			return account.Copy(), nil
+		}
+	}
+
+	return nil, status.Errorf(status.NotFound, "account not found: provided setup key doesn't exists")
+}
+
+// indexSetupKey adds the hashed setup key of the account to the setup key indexes
+func (s *FileStore) indexSetupKey(hashedKey string, key *SetupKey, accountID string) {
+	if _, ok := s.SetupKeyID2AccountID[hashedKey]; !ok {
+		prefix := strings.ToUpper(key.KeyPrefix)
+		s.SetupKeyPrefix2IDs[prefix] = append(s.SetupKeyPrefix2IDs[prefix], hashedKey)
+	}
+	s.SetupKeyID2AccountID[hashedKey] = accountID
+}
+
 // GetAllAccounts returns all accounts
 func (s *FileStore) GetAllAccounts() (all []*Account) {
 	s.mux.Lock()
@@ -2,7 +2,9 @@ package server
 
 import (
 	"net"
+	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -106,7 +108,8 @@ func TestSaveAccount(t *testing.T) {
 		t.Errorf("expecting UserID2AccountID index updated after SaveAccount()")
 	}
 
-	if store.SetupKeyID2AccountID[setupKey.Key] == "" {
+	storedSetupKey, err := store.Accounts[account.Id].findSetupKeyByPlainKey(setupKey.Key)
+	if err != nil || store.SetupKeyID2AccountID[storedSetupKey.Key] == "" {
 		t.Errorf("expecting SetupKeyID2AccountID index updated after SaveAccount()")
 	}
 }
@@ -237,7 +240,8 @@ func TestRestore(t *testing.T) {
 
 	require.NotNil(t, account.Network, "failed to restore a FileStore file - missing Account Network")
 
-	require.NotNil(t, account.SetupKeys["A2C8E62B-38F5-4553-B31E-DD66C696CEBB"], "failed to restore a FileStore file - missing Account SetupKey A2C8E62B-38F5-4553-B31E-DD66C696CEBB")
+	_, err = account.findSetupKeyByPlainKey("A2C8E62B-38F5-4553-B31E-DD66C696CEBB")
+	require.NoError(t, err, "failed to restore a FileStore file - missing Account SetupKey A2C8E62B-38F5-4553-B31E-DD66C696CEBB")
 
 	require.Len(t, store.UserID2AccountID, 2, "failed to restore a FileStore wrong UserID2AccountID mapping length")
 
@@ -283,6 +287,142 @@ func TestRestorePolicies_Migration(t *testing.T) {
 		"failed to restore a FileStore file - missing Account Policies Sources")
 }
 
+func TestRestore_SetupKeysMigration(t *testing.T) {
+	storeDir := t.TempDir()
+	plainKey := "A2C8E62B-38F5-4553-B31E-DD66C696CEBB"
+
+	storeFile := filepath.Join(storeDir, "store.json")
+	err := util.CopyFileContents("testdata/store.json", storeFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := NewFileStore(storeDir)
+	require.NoError(t, err)
+
+	account := store.Accounts["bf1c8084-ba50-4ce7-9439-34653001fc3b"]
+	setupKey, err := account.findSetupKeyByPlainKey(plainKey)
+	require.NoError(t, err)
+	hashedKey := setupKey.Key
+	require.NotEmpty(t, setupKey.KeySalt, "expected the setup key to get its own salt")
+	assert.Equal(t, HashSetupKey(setupKey.KeySalt, plainKey), hashedKey)
+	require.NotNil(t, account.SetupKeys[hashedKey], "expected the setup key to be stored by its hash")
+	assert.Nil(t, account.SetupKeys[plainKey], "expected the plaintext setup key to be removed")
+	assert.Equal(t, "A2C8E", setupKey.KeyPrefix)
+
+	content, err := os.ReadFile(storeFile)
+	require.NoError(t, err)
+	assert.NotContains(t, string(content), plainKey, "expected the plaintext setup key not to be persisted")
+
+	found, err := store.GetAccountBySetupKey(strings.ToLower(plainKey))
+	require.NoError(t, err)
+	assert.Equal(t, account.Id, found.Id)
+
+	_, err = store.GetAccountBySetupKey(hashedKey)
+	assert.Error(t, err, "expected the hash not to be accepted as a setup key")
+
+	// restoring the migrated store must keep the salts and the hashes
+	restored, err := NewFileStore(storeDir)
+	require.NoError(t, err)
+	assert.Equal(t, account.SetupKeys, restored.Accounts[account.Id].SetupKeys)
+}
+
+func TestRestore_LegacySaltedSetupKeysMigration(t *testing.T) {
+	storeDir := t.TempDir()
+	store, err := NewFileStore(storeDir)
+	require.NoError(t, err)
+
+	legacySalt := "legacy salt"
+	plainKey := "A2C8E62B-38F5-4553-B31E-DD66C696CEBB"
+	legacyKey := GenerateDefaultSetupKey()
+	legacyKey.Key = HashSetupKey(legacySalt, plainKey)
+	legacyKey.KeyPrefix = "A2C8E"
+
+	account := newAccountWithId("account_id", "testuser", "")
+	account.SetupKeys = map[string]*SetupKey{legacyKey.Key: legacyKey}
+	store.Accounts[account.Id] = account
+	store.SetupKeySalt = legacySalt
+	require.NoError(t, store.persist(store.storeFile))
+
+	restored, err := NewFileStore(storeDir)
+	require.NoError(t, err)
+	assert.Empty(t, restored.SetupKeySalt, "expected the store salt to be cleared after the migration")
+
+	setupKey := restored.Accounts[account.Id].SetupKeys[legacyKey.Key]
+	require.NotNil(t, setupKey, "expected the legacy hash to be kept")
+	assert.Equal(t, legacySalt, setupKey.KeySalt)
+
+	found, err := restored.GetAccountBySetupKey(plainKey)
+	require.NoError(t, err)
+	assert.Equal(t, account.Id, found.Id)
+
+	// the salt is persisted with the key and the legacy salt is no longer needed
+	restored, err = NewFileStore(storeDir)
+	require.NoError(t, err)
+	_, err = restored.GetAccountBySetupKey(plainKey)
+	require.NoError(t, err)
+}
+
+func TestGetAccountBySetupKey_SameKeyInAccounts(t *testing.T) {
+	store := newStore(t)
+
+	setupKey := GenerateDefaultSetupKey()
+	for _, accountID := range []string{"account1", "account2"} {
+		account := newAccountWithId(accountID, accountID+"_user", "")
+		account.SetupKeys = map[string]*SetupKey{setupKey.Key: setupKey.Copy()}
+		require.NoError(t, store.SaveAccount(account))
+	}
+
+	first, err := store.Accounts["account1"].findSetupKeyByPlainKey(setupKey.Key)
+	require.NoError(t, err)
+	second, err := store.Accounts["account2"].findSetupKeyByPlainKey(setupKey.Key)
+	require.NoError(t, err)
+	assert.NotEqual(t, first.KeySalt, second.KeySalt, "expected every key to get its own salt")
+	assert.NotEqual(t, first.Key, second.Key, "expected the same key to be hashed differently in every account")
+
+	_, err = store.GetAccountBySetupKey(setupKey.Key)
+	require.NoError(t, err)
+	_, err = store.GetAccountBySetupKey(GenerateDefaultSetupKey().Key)
+	assert.Error(t, err)
+}
+
+func TestSaveAccount_HashesSetupKeys(t *testing.T) {
+	store := newStore(t)
+
+	account := newAccountWithId("account_id", "testuser", "")
+	setupKey := GenerateDefaultSetupKey()
+	account.SetupKeys[setupKey.Key] = setupKey
+	account.Peers["testpeer"] = &Peer{
+		Key:      "peerkey",
+		SetupKey: setupKey.Key,
+		IP:       net.IP{127, 0, 0, 1},
+		Status:   &PeerStatus{},
+	}
+
+	err := store.SaveAccount(account)
+	require.NoError(t, err)
+
+	assert.Equal(t, setupKey, account.SetupKeys[setupKey.Key], "expected the saved account not to be modified")
+
+	stored, err := store.GetAccount(account.Id)
+	require.NoError(t, err)
+	storedKey, err := stored.findSetupKeyByPlainKey(setupKey.Key)
+	require.NoError(t, err)
+	hashedKey := storedKey.Key
+	assert.Equal(t, HashSetupKey(storedKey.KeySalt, setupKey.Key), hashedKey)
+	require.NotNil(t, stored.SetupKeys[hashedKey])
+	assert.Len(t, stored.SetupKeys, len(account.SetupKeys))
+	assert.True(t, stored.SetupKeys[hashedKey].IsHashed())
+	assert.Equal(t, hashedKey, stored.Peers["testpeer"].SetupKey)
+
+	// saving the stored account again must not hash the keys twice
+	err = store.SaveAccount(stored)
+	require.NoError(t, err)
+	stored, err = store.GetAccount(account.Id)
+	require.NoError(t, err)
+	assert.NotNil(t, stored.SetupKeys[hashedKey])
+}
+
 func TestGetAccountByPrivateDomain(t *testing.T) {
 	storeDir := t.TempDir()
 
@@ -1,6 +1,10 @@
 package server
 
 import (
+	"path"
+	"sort"
+	"strings"
+
 	"github.com/netbirdio/netbird/management/server/activity"
 	"github.com/netbirdio/netbird/management/server/status"
 	log "github.com/sirupsen/logrus"
@@ -16,6 +20,127 @@ type Group struct {
 
 	// Peers list of the group
 	Peers []string
+
+	// Rules of a dynamic group. When set, the group membership is computed from the peer attributes and Peers
+	// holds all the peers matching every rule
+	Rules []*GroupRule
+
+	// Issued indicates how the group was created, GroupIssuedAPI or GroupIssuedJWT
+	Issued string
+}
+
+const (
+	// GroupIssuedAPI indicates that the group was created by a user via the API
+	GroupIssuedAPI = "api"
+	// GroupIssuedJWT indicates that the group was created from the IdP groups claim of a user JWT
+	GroupIssuedJWT = "jwt"
+)
+
+// GroupRuleAttribute is a peer attribute a dynamic group rule is evaluated against
+type GroupRuleAttribute string
+
+// GroupRuleOperator is a comparison applied by a dynamic group rule
+type GroupRuleOperator string
+
+const (
+	// GroupRuleAttributeName matches the peer name
+	GroupRuleAttributeName GroupRuleAttribute = "name"
+	// GroupRuleAttributeHostname matches the hostname reported by the peer
+	GroupRuleAttributeHostname GroupRuleAttribute = "hostname"
+	// GroupRuleAttributeOS matches the operating system type reported by the peer, e.g. linux, darwin or windows
+	GroupRuleAttributeOS GroupRuleAttribute = "os"
+	// GroupRuleAttributePlatform matches the platform reported by the peer, e.g. x86_64
+	GroupRuleAttributePlatform GroupRuleAttribute = "platform"
+	// GroupRuleAttributeUser matches the ID of the user that registered the peer
+	GroupRuleAttributeUser GroupRuleAttribute = "user"
+	// GroupRuleAttributeSetupKey matches the ID of the setup key the peer was registered with
+	GroupRuleAttributeSetupKey GroupRuleAttribute = "setup_key"
+
+	// GroupRuleOperatorEquals requires the attribute to be equal to the rule value (case-insensitive)
+	GroupRuleOperatorEquals GroupRuleOperator = "equals"
+	// GroupRuleOperatorNotEquals requires the attribute to differ from the rule value (case-insensitive)
+	GroupRuleOperatorNotEquals GroupRuleOperator = "not_equals"
+	// GroupRuleOperatorMatches requires the attribute to match the rule value as a shell pattern (case-insensitive)
+	GroupRuleOperatorMatches GroupRuleOperator = "matches"
+)
+
+// GroupRule is a condition on a peer attribute used to compute the membership of a dynamic group
+type GroupRule struct {
+	// Attribute of the peer the rule applies to
+	Attribute GroupRuleAttribute
+	// Operator used to compare the attribute with the Value
+	Operator GroupRuleOperator
+	// Value the attribute is compared with
+	Value string
+}
+
+// Copy returns a copy of the group rule
+func (r *GroupRule) Copy() *GroupRule {
+	return &GroupRule{
+		Attribute: r.Attribute,
+		Operator:  r.Operator,
+		Value:     r.Value,
+	}
+}
+
+func (r *GroupRule) validate() error {
+	switch r.Attribute {
+	case GroupRuleAttributeName, GroupRuleAttributeHostname, GroupRuleAttributeOS, GroupRuleAttributePlatform,
+		GroupRuleAttributeUser, GroupRuleAttributeSetupKey:
+	default:
+		return status.Errorf(status.InvalidArgument, "unknown group rule attribute %q", r.Attribute)
+	}
+
+	if r.Value == "" {
+		return status.Errorf(status.InvalidArgument, "group rule value for attribute %s shouldn't be empty", r.Attribute)
+	}
+
+	switch r.Operator {
+	case GroupRuleOperatorEquals, GroupRuleOperatorNotEquals:
+	case GroupRuleOperatorMatches:
+		if _, err := path.Match(r.Value, ""); err != nil {
+			return status.Errorf(status.InvalidArgument, "invalid group rule pattern %q", r.Value)
+		}
+	default:
+		return status.Errorf(status.InvalidArgument, "unknown group rule operator %q", r.Operator)
+	}
+
+	return nil
+}
+
+// matchPeer checks whether the peer of the account satisfies the rule
+func (r *GroupRule) matchPeer(account *Account, peer *Peer) bool {
+	var value string
+	switch r.Attribute {
+	case GroupRuleAttributeName:
+		value = peer.Name
+	case GroupRuleAttributeHostname:
+		value = peer.Meta.Hostname
+	case GroupRuleAttributeOS:
+		value = peer.Meta.GoOS
+	case GroupRuleAttributePlatform:
+		value = peer.Meta.Platform
+	case GroupRuleAttributeUser:
+		value = peer.UserID
+	case GroupRuleAttributeSetupKey:
+		if key, ok := account.SetupKeys[peer.SetupKey]; ok && peer.SetupKey != "" {
+			value = key.Id
+		}
+	default:
+		return false
+	}
+
+	switch r.Operator {
+	case GroupRuleOperatorEquals:
+		return strings.EqualFold(value, r.Value)
+	case GroupRuleOperatorNotEquals:
+		return !strings.EqualFold(value, r.Value)
+	case GroupRuleOperatorMatches:
+		matched, err := path.Match(strings.ToLower(r.Value), strings.ToLower(value))
+		return err == nil && matched
+	default:
+		return false
+	}
 }
 
 const (
@@ -44,11 +169,65 @@ func (g *Group) EventMeta() map[string]any {
 }
 
 func (g *Group) Copy() *Group {
+	var rules []*GroupRule
+	for _, rule := range g.Rules {
+		rules = append(rules, rule.Copy())
+	}
 	return &Group{
-		ID:    g.ID,
-		Name:  g.Name,
-		Peers: g.Peers[:],
+		ID:     g.ID,
+		Name:   g.Name,
+		Peers:  g.Peers[:],
+		Rules:  rules,
+		Issued: g.Issued,
+	}
+}
+
+// IsDynamic returns true if the group membership is computed from its rules
+func (g *Group) IsDynamic() bool {
+	return len(g.Rules) > 0
+}
+
+// matchPeer checks whether the peer of the account satisfies all the rules of the group
+func (g *Group) matchPeer(account *Account, peer *Peer) bool {
+	for _, rule := range g.Rules {
+		if !rule.matchPeer(account, peer) {
+			return false
+		}
+	}
+	return true
+}
+
+// updateDynamicGroups recomputes the membership of all the dynamic groups of the account.
+// Returns true if any group membership has changed.
+func (a *Account) updateDynamicGroups() bool {
+	changed := false
+	for _, group := range a.Groups {
+		if !group.IsDynamic() {
+			continue
+		}
+		if a.updateDynamicGroup(group) {
+			changed = true
+		}
 	}
+	return changed
+}
+
+// updateDynamicGroup replaces the peers of the dynamic group with the account peers matching its rules.
+// Returns true if the group membership has changed.
+func (a *Account) updateDynamicGroup(group *Group) bool {
+	peers := make([]string, 0)
+	for _, peer := range a.Peers {
+		if group.matchPeer(a, peer) {
+			peers = append(peers, peer.ID)
+		}
+	}
+	sort.Strings(peers)
+
+	if len(difference(peers, group.Peers)) == 0 && len(difference(group.Peers, peers)) == 0 {
+		return false
+	}
+	group.Peers = peers
+	return true
 }
 
 // GetGroup object of the peers
@@ -78,7 +257,19 @@ func (am *DefaultAccountManager) SaveGroup(accountID, userID string, newGroup *G
 	if err != nil {
 		return err
 	}
+	for _, rule := range newGroup.Rules {
+		if err = rule.validate(); err != nil {
+			return err
+		}
+	}
+	if newGroup.IsDynamic() {
+		account.updateDynamicGroup(newGroup)
+	}
+
 	oldGroup, exists := account.Groups[newGroup.ID]
+	if exists && newGroup.Issued == "" {
+		newGroup.Issued = oldGroup.Issued
+	}
 	account.Groups[newGroup.ID] = newGroup
 
 	account.Network.IncSerial()
@@ -147,6 +338,21 @@ func difference(a, b []string) []string {
 	return diff
 }
 
+// intersection returns the elements in `a` that are also in `b`.
+func intersection(a, b []string) []string {
+	mb := make(map[string]struct{}, len(b))
+	for _, x := range b {
+		mb[x] = struct{}{}
+	}
+	var common []string
+	for _, x := range a {
+		if _, found := mb[x]; found {
+			common = append(common, x)
+		}
+	}
+	return common
+}
+
 // UpdateGroup updates a group using a list of operations
 func (am *DefaultAccountManager) UpdateGroup(accountID string,
 	groupID string, operations []GroupUpdateOperation,
@@ -167,6 +373,10 @@ func (am *DefaultAccountManager) UpdateGroup(accountID string,
 	group := groupToUpdate.Copy()
 
 	for _, operation := range operations {
+		if operation.Type != UpdateGroupName && group.IsDynamic() {
+			return nil, status.Errorf(status.PreconditionFailed,
+				"peers of the dynamic group %s are computed from its rules and can't be updated", groupID)
+		}
 		switch operation.Type {
 		case UpdateGroupName:
 			group.Name = operation.Values[0]
@@ -251,6 +461,11 @@ func (am *DefaultAccountManager) GroupAddPeer(accountID, groupID, peerID string)
 		return status.Errorf(status.NotFound, "group with ID %s not found", groupID)
 	}
 
+	if group.IsDynamic() {
+		return status.Errorf(status.PreconditionFailed,
+			"peers of the dynamic group %s are computed from its rules and can't be updated", groupID)
+	}
+
 	add := true
 	for _, itemID := range group.Peers {
 		if itemID == peerID {
@@ -285,6 +500,11 @@ func (am *DefaultAccountManager) GroupDeletePeer(accountID, groupID, peerKey str
 		return status.Errorf(status.NotFound, "group with ID %s not found", groupID)
 	}
 
+	if group.IsDynamic() {
+		return status.Errorf(status.PreconditionFailed,
+			"peers of the dynamic group %s are computed from its rules and can't be updated", groupID)
+	}
+
 	account.Network.IncSerial()
 	for i, itemID := range group.Peers {
 		if itemID == peerKey {
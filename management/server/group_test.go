@@ -0,0 +1,189 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
+)
+
+func TestGroup_matchPeer(t *testing.T) {
+	account := &Account{
+		SetupKeys: map[string]*SetupKey{
+			"hashed-key": {Id: "setup-key-id", Key: "hashed-key"},
+		},
+	}
+	peer := &Peer{
+		ID:       "peer",
+		Name:     "database",
+		UserID:   "user",
+		SetupKey: "hashed-key",
+		Meta:     PeerSystemMeta{Hostname: "DB-1", GoOS: "linux", Platform: "x86_64"},
+	}
+
+	testCases := []struct {
+		name    string
+		rules   []*GroupRule
+		matches bool
+	}{
+		{
+			name:    "OS Equals",
+			rules:   []*GroupRule{{Attribute: GroupRuleAttributeOS, Operator: GroupRuleOperatorEquals, Value: "Linux"}},
+			matches: true,
+		},
+		{
+			name:  "OS Not Equals",
+			rules: []*GroupRule{{Attribute: GroupRuleAttributeOS, Operator: GroupRuleOperatorNotEquals, Value: "linux"}},
+		},
+		{
+			name:    "Hostname Matches",
+			rules:   []*GroupRule{{Attribute: GroupRuleAttributeHostname, Operator: GroupRuleOperatorMatches, Value: "db-*"}},
+			matches: true,
+		},
+		{
+			name:    "User Equals",
+			rules:   []*GroupRule{{Attribute: GroupRuleAttributeUser, Operator: GroupRuleOperatorEquals, Value: "user"}},
+			matches: true,
+		},
+		{
+			name:    "Setup Key Equals",
+			rules:   []*GroupRule{{Attribute: GroupRuleAttributeSetupKey, Operator: GroupRuleOperatorEquals, Value: "setup-key-id"}},
+			matches: true,
+		},
+		{
+			name: "All Rules Should Match",
+			rules: []*GroupRule{
+				{Attribute: GroupRuleAttributeOS, Operator: GroupRuleOperatorEquals, Value: "linux"},
+				{Attribute: GroupRuleAttributePlatform, Operator: GroupRuleOperatorEquals, Value: "arm64"},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			group := &Group{ID: "group", Rules: testCase.rules}
+			assert.Equal(t, testCase.matches, group.matchPeer(account, peer))
+		})
+	}
+}
+
+func TestDefaultAccountManager_DynamicGroups(t *testing.T) {
+	manager, err := createManager(t)
+	require.NoError(t, err, "unable to create account manager")
+	account, err := manager.GetAccountByUserOrAccountID(userID, "", "")
+	require.NoError(t, err, "unable to create an account")
+
+	invalidRules := [][]*GroupRule{
+		{{Attribute: "unknown", Operator: GroupRuleOperatorEquals, Value: "linux"}},
+		{{Attribute: GroupRuleAttributeOS, Operator: "unknown", Value: "linux"}},
+		{{Attribute: GroupRuleAttributeOS, Operator: GroupRuleOperatorEquals}},
+		{{Attribute: GroupRuleAttributeHostname, Operator: GroupRuleOperatorMatches, Value: "db-["}},
+	}
+	for _, rules := range invalidRules {
+		err = manager.SaveGroup(account.Id, userID, &Group{ID: "invalid", Name: "invalid", Rules: rules})
+		require.Error(t, err, "group with rules %+v should be rejected", rules)
+	}
+
+	addPeer := func(hostname string) *Peer {
+		key, err := wgtypes.GeneratePrivateKey()
+		require.NoError(t, err)
+		peer, _, err := manager.AddPeer("", userID, &Peer{
+			Key:  key.PublicKey().String(),
+			Meta: PeerSystemMeta{Hostname: hostname, GoOS: "linux"},
+		})
+		require.NoError(t, err, "unable to add peer")
+		return peer
+	}
+
+	dbPeer := addPeer("db-1")
+
+	group := &Group{
+		ID:    "databases",
+		Name:  "Databases",
+		Peers: []string{"ignored"},
+		Rules: []*GroupRule{
+			{Attribute: GroupRuleAttributeOS, Operator: GroupRuleOperatorEquals, Value: "linux"},
+			{Attribute: GroupRuleAttributeHostname, Operator: GroupRuleOperatorMatches, Value: "db-*"},
+		},
+	}
+	err = manager.SaveGroup(account.Id, userID, group)
+	require.NoError(t, err, "unable to save dynamic group")
+
+	getGroupPeers := func() []string {
+		group, err := manager.GetGroup(account.Id, "databases")
+		require.NoError(t, err)
+		return group.Peers
+	}
+	assert.ElementsMatch(t, []string{dbPeer.ID}, getGroupPeers(), "existing matching peers should be members")
+
+	webPeer := addPeer("web-1")
+	newDBPeer := addPeer("db-2")
+	assert.ElementsMatch(t, []string{dbPeer.ID, newDBPeer.ID}, getGroupPeers(), "new matching peers should be added")
+
+	_, _, err = manager.LoginPeer(PeerLogin{
+		WireGuardPubKey: webPeer.Key,
+		Meta:            PeerSystemMeta{Hostname: "db-3", GoOS: "linux"},
+	})
+	require.NoError(t, err, "unable to login peer")
+	assert.ElementsMatch(t, []string{dbPeer.ID, newDBPeer.ID, webPeer.ID}, getGroupPeers(),
+		"peers should be added once their meta matches")
+
+	_, _, err = manager.LoginPeer(PeerLogin{
+		WireGuardPubKey: dbPeer.Key,
+		Meta:            PeerSystemMeta{Hostname: "db-1", GoOS: "windows"},
+	})
+	require.NoError(t, err, "unable to login peer")
+	assert.ElementsMatch(t, []string{newDBPeer.ID, webPeer.ID}, getGroupPeers(),
+		"peers should be removed once their meta doesn't match")
+
+	err = manager.GroupAddPeer(account.Id, "databases", dbPeer.ID)
+	require.Error(t, err, "peers of a dynamic group should not be assigned manually")
+
+	_, err = manager.UpdateGroup(account.Id, "databases", []GroupUpdateOperation{{Type: UpdateGroupPeers, Values: []string{dbPeer.ID}}})
+	require.Error(t, err, "peers of a dynamic group should not be assigned manually")
+}
+
+func TestDefaultAccountManager_DynamicGroupsMembershipChanges(t *testing.T) {
+	manager, err := createManager(t)
+	require.NoError(t, err, "unable to create account manager")
+	account, err := manager.GetAccountByUserOrAccountID(userID, "", "")
+	require.NoError(t, err, "unable to create an account")
+
+	err = manager.SaveGroup(account.Id, userID, &Group{
+		ID:    "databases",
+		Name:  "Databases",
+		Rules: []*GroupRule{{Attribute: GroupRuleAttributeHostname, Operator: GroupRuleOperatorMatches, Value: "db-*"}},
+	})
+	require.NoError(t, err, "unable to save dynamic group")
+
+	setupKey, err := manager.CreateSetupKey(account.Id, "key", SetupKeyReusable, DefaultSetupKeyDuration,
+		[]string{"databases"}, SetupKeyUnlimitedUsage, "", false, userID)
+	require.NoError(t, err, "unable to create setup key")
+
+	addPeer := func(hostname string) *Peer {
+		key, err := wgtypes.GeneratePrivateKey()
+		require.NoError(t, err)
+		peer, _, err := manager.AddPeer(setupKey.Key, "", &Peer{
+			Key:  key.PublicKey().String(),
+			Meta: PeerSystemMeta{Hostname: hostname},
+		})
+		require.NoError(t, err, "unable to add peer")
+		return peer
+	}
+
+	getGroupPeers := func() []string {
+		group, err := manager.GetGroup(account.Id, "databases")
+		require.NoError(t, err)
+		return group.Peers
+	}
+
+	dbPeer := addPeer("db-1")
+	addPeer("web-1")
+	assert.ElementsMatch(t, []string{dbPeer.ID}, getGroupPeers(),
+		"the setup key auto groups should not add peers to a dynamic group")
+
+	_, err = manager.DeletePeer(account.Id, dbPeer.ID, userID)
+	require.NoError(t, err, "unable to delete peer")
+	assert.Empty(t, getGroupPeers(), "deleted peers should be removed from a dynamic group")
+}
@@ -393,32 +393,54 @@ func toPeerConfig(peer *Peer, network *Network, dnsName string) *proto.PeerConfi
 	}
 }
 
-func toRemotePeerConfig(peers []*Peer, dnsName string) []*proto.RemotePeerConfig {
+func toRemotePeerConfig(peers []*Peer, dnsName string, sshUsers map[string][]string, sshPortForwarding map[string]bool) []*proto.RemotePeerConfig {
 	remotePeers := []*proto.RemotePeerConfig{}
 	for _, rPeer := range peers {
 		fqdn := rPeer.FQDN(dnsName)
 		remotePeers = append(remotePeers, &proto.RemotePeerConfig{
 			WgPubKey:   rPeer.Key,
 			AllowedIps: []string{fmt.Sprintf(AllowedIPsFormat, rPeer.IP)},
-			SshConfig:  &proto.SSHConfig{SshPubKey: []byte(rPeer.SSHKey)},
-			Fqdn:       fqdn,
+			SshConfig: &proto.SSHConfig{
+				SshPubKey:             []byte(rPeer.SSHKey),
+				AllowedUsers:          sshUsers[rPeer.ID],
+				PortForwardingAllowed: sshPortForwarding[rPeer.ID],
+			},
+			Fqdn: fqdn,
 		})
 	}
 	return remotePeers
 }
 
+func toProtocolSSHCertificateAccess(access map[string]*SSHUserAccess) map[string]*proto.SSHCertificateAccess {
+	if access == nil {
+		return nil
+	}
+
+	protoAccess := make(map[string]*proto.SSHCertificateAccess, len(access))
+	for userID, userAccess := range access {
+		protoAccess[userID] = &proto.SSHCertificateAccess{
+			AllowedUsers:          userAccess.LocalUsers,
+			PortForwardingAllowed: userAccess.PortForwarding,
+		}
+	}
+	return protoAccess
+}
+
 func toSyncResponse(config *Config, peer *Peer, turnCredentials *TURNCredentials, networkMap *NetworkMap, dnsName string) *proto.SyncResponse {
 	wtConfig := toWiretrusteeConfig(config, turnCredentials)
 
 	pConfig := toPeerConfig(peer, networkMap.Network, dnsName)
+	pConfig.SshConfig.SshUsersRestricted = networkMap.SSHUsers != nil
+	pConfig.SshConfig.UserCAPublicKey = []byte(networkMap.SSHUserCAKey)
+	pConfig.SshConfig.CertificateAccess = toProtocolSSHCertificateAccess(networkMap.SSHCertificateAccess)
 
-	remotePeers := toRemotePeerConfig(networkMap.Peers, dnsName)
+	remotePeers := toRemotePeerConfig(networkMap.Peers, dnsName, networkMap.SSHUsers, networkMap.SSHPortForwarding)
 
 	routesUpdate := toProtocolRoutes(networkMap.Routes)
 
 	dnsUpdate := toProtocolDNSConfig(networkMap.DNSConfig)
 
-	offlinePeers := toRemotePeerConfig(networkMap.OfflinePeers, dnsName)
+	offlinePeers := toRemotePeerConfig(networkMap.OfflinePeers, dnsName, networkMap.SSHUsers, networkMap.SSHPortForwarding)
 
 	return &proto.SyncResponse{
 		WiretrusteeConfig:  wtConfig,
@@ -521,3 +543,43 @@ func (s *GRPCServer) GetDeviceAuthorizationFlow(ctx context.Context, req *proto.
 		Body:     encryptedResp,
 	}, nil
 }
+
+// ReportSSHSession stores an audit event of a session handled by the embedded SSH server of the peer
+func (s *GRPCServer) ReportSSHSession(ctx context.Context, req *proto.EncryptedMessage) (*proto.Empty, error) {
+	peerKey, err := wgtypes.ParseKey(req.GetWgPubKey())
+	if err != nil {
+		errMSG := fmt.Sprintf("error while parsing peer's Wireguard public key %s on ReportSSHSession request.", req.WgPubKey)
+		log.Warn(errMSG)
+		return nil, status.Error(codes.InvalidArgument, errMSG)
+	}
+
+	sessionEvent := &proto.SSHSessionEvent{}
+	err = encryption.DecryptMessage(peerKey, s.wgKey, req.Body, sessionEvent)
+	if err != nil {
+		errMSG := fmt.Sprintf("error while decrypting peer's message with Wireguard public key %s.", req.WgPubKey)
+		log.Warn(errMSG)
+		return nil, status.Error(codes.InvalidArgument, errMSG)
+	}
+
+	event := &SSHSessionEvent{
+		Type:          SSHSessionStart,
+		SessionID:     sessionEvent.GetSessionID(),
+		RemotePeerKey: sessionEvent.GetRemotePeerKey(),
+		RemoteAddr:    sessionEvent.GetRemoteAddr(),
+		LocalUser:     sessionEvent.GetLocalUser(),
+		Command:       sessionEvent.GetCommand(),
+		StartedAt:     sessionEvent.GetStartedAt().AsTime(),
+		Duration:      time.Duration(sessionEvent.GetDurationMs()) * time.Millisecond,
+		UserID:        sessionEvent.GetUserID(),
+	}
+	if sessionEvent.GetType() == proto.SSHSessionEvent_END {
+		event.Type = SSHSessionEnd
+	}
+
+	err = s.accountManager.StoreSSHSessionEvent(peerKey.String(), event)
+	if err != nil {
+		return nil, mapError(err)
+	}
+
+	return &proto.Empty{}, nil
+}
@@ -52,7 +52,7 @@ func (h *AccountsHandler) GetAllAccounts(w http.ResponseWriter, r *http.Request)
 // UpdateAccount is HTTP PUT handler that updates the provided account. Updates only account settings (server.Settings)
 func (h *AccountsHandler) UpdateAccount(w http.ResponseWriter, r *http.Request) {
 	claims := h.claimsExtractor.FromRequestContext(r)
-	_, user, err := h.accountManager.GetAccountFromToken(claims)
+	account, user, err := h.accountManager.GetAccountFromToken(claims)
 	if err != nil {
 		util.WriteError(err, w)
 		return
@@ -72,9 +72,28 @@ func (h *AccountsHandler) UpdateAccount(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	// keep the current values of the optional settings the request doesn't specify
+	ephemeralPeersGracePeriod := account.Settings.EphemeralPeersGracePeriod
+	if req.Settings.EphemeralPeersGracePeriod != nil {
+		ephemeralPeersGracePeriod = time.Duration(*req.Settings.EphemeralPeersGracePeriod) * time.Second
+	}
+
+	peerApprovalEnabled := account.Settings.PeerApprovalEnabled
+	if req.Settings.PeerApprovalEnabled != nil {
+		peerApprovalEnabled = *req.Settings.PeerApprovalEnabled
+	}
+
+	jwtGroupsClaimName := account.Settings.JWTGroupsClaimName
+	if req.Settings.JwtGroupsClaimName != nil {
+		jwtGroupsClaimName = *req.Settings.JwtGroupsClaimName
+	}
+
 	updatedAccount, err := h.accountManager.UpdateAccountSettings(accountID, user.Id, &server.Settings{
 		PeerLoginExpirationEnabled: req.Settings.PeerLoginExpirationEnabled,
 		PeerLoginExpiration:        time.Duration(float64(time.Second.Nanoseconds()) * float64(req.Settings.PeerLoginExpiration)),
+		EphemeralPeersGracePeriod:  ephemeralPeersGracePeriod,
+		PeerApprovalEnabled:        peerApprovalEnabled,
+		JWTGroupsClaimName:         jwtGroupsClaimName,
 	})
 
 	if err != nil {
@@ -88,11 +107,17 @@ func (h *AccountsHandler) UpdateAccount(w http.ResponseWriter, r *http.Request)
 }
 
 func toAccountResponse(account *server.Account) *api.Account {
+	ephemeralPeersGracePeriod := int(account.Settings.GetEphemeralPeersGracePeriod().Seconds())
+	peerApprovalEnabled := account.Settings.PeerApprovalEnabled
+	jwtGroupsClaimName := account.Settings.JWTGroupsClaimName
 	return &api.Account{
 		Id: account.Id,
 		Settings: api.AccountSettings{
 			PeerLoginExpiration:        int(account.Settings.PeerLoginExpiration.Seconds()),
 			PeerLoginExpirationEnabled: account.Settings.PeerLoginExpirationEnabled,
+			EphemeralPeersGracePeriod:  &ephemeralPeersGracePeriod,
+			PeerApprovalEnabled:        &peerApprovalEnabled,
+			JwtGroupsClaimName:         &jwtGroupsClaimName,
 		},
 	}
 }
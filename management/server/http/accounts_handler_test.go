@@ -71,6 +71,13 @@ func TestAccounts_AccountsHandler(t *testing.T) {
 		},
 	}, adminUser)
 
+	defaultGracePeriod := int(server.DefaultEphemeralPeersGracePeriod.Seconds())
+	updatedGracePeriod := int(time.Hour.Seconds())
+	peerApprovalDisabled := false
+	peerApprovalEnabled := true
+	noJWTGroupsClaim := ""
+	jwtGroupsClaim := "groups"
+
 	tt := []struct {
 		name             string
 		expectedStatus   int
@@ -91,6 +98,9 @@ func TestAccounts_AccountsHandler(t *testing.T) {
 			expectedSettings: api.AccountSettings{
 				PeerLoginExpiration:        int(time.Hour.Seconds()),
 				PeerLoginExpirationEnabled: false,
+				EphemeralPeersGracePeriod:  &defaultGracePeriod,
+				PeerApprovalEnabled:        &peerApprovalDisabled,
+				JwtGroupsClaimName:         &noJWTGroupsClaim,
 			},
 			expectedArray: true,
 			expectedID:    accountID,
@@ -105,6 +115,60 @@ func TestAccounts_AccountsHandler(t *testing.T) {
 			expectedSettings: api.AccountSettings{
 				PeerLoginExpiration:        15552000,
 				PeerLoginExpirationEnabled: true,
+				EphemeralPeersGracePeriod:  &defaultGracePeriod,
+				PeerApprovalEnabled:        &peerApprovalDisabled,
+				JwtGroupsClaimName:         &noJWTGroupsClaim,
+			},
+			expectedArray: false,
+			expectedID:    accountID,
+		},
+		{
+			name:           "PutAccount OK with ephemeral_peers_grace_period",
+			expectedBody:   true,
+			requestType:    http.MethodPut,
+			requestPath:    "/api/accounts/" + accountID,
+			requestBody:    bytes.NewBufferString("{\"settings\": {\"peer_login_expiration\": 3600,\"peer_login_expiration_enabled\": true,\"ephemeral_peers_grace_period\": 3600}}"),
+			expectedStatus: http.StatusOK,
+			expectedSettings: api.AccountSettings{
+				PeerLoginExpiration:        3600,
+				PeerLoginExpirationEnabled: true,
+				EphemeralPeersGracePeriod:  &updatedGracePeriod,
+				PeerApprovalEnabled:        &peerApprovalDisabled,
+				JwtGroupsClaimName:         &noJWTGroupsClaim,
+			},
+			expectedArray: false,
+			expectedID:    accountID,
+		},
+		{
+			name:           "PutAccount OK with peer_approval_enabled",
+			expectedBody:   true,
+			requestType:    http.MethodPut,
+			requestPath:    "/api/accounts/" + accountID,
+			requestBody:    bytes.NewBufferString("{\"settings\": {\"peer_login_expiration\": 3600,\"peer_login_expiration_enabled\": true,\"peer_approval_enabled\": true}}"),
+			expectedStatus: http.StatusOK,
+			expectedSettings: api.AccountSettings{
+				PeerLoginExpiration:        3600,
+				PeerLoginExpirationEnabled: true,
+				EphemeralPeersGracePeriod:  &defaultGracePeriod,
+				PeerApprovalEnabled:        &peerApprovalEnabled,
+				JwtGroupsClaimName:         &noJWTGroupsClaim,
+			},
+			expectedArray: false,
+			expectedID:    accountID,
+		},
+		{
+			name:           "PutAccount OK with jwt_groups_claim_name",
+			expectedBody:   true,
+			requestType:    http.MethodPut,
+			requestPath:    "/api/accounts/" + accountID,
+			requestBody:    bytes.NewBufferString("{\"settings\": {\"peer_login_expiration\": 3600,\"peer_login_expiration_enabled\": true,\"jwt_groups_claim_name\": \"groups\"}}"),
+			expectedStatus: http.StatusOK,
+			expectedSettings: api.AccountSettings{
+				PeerLoginExpiration:        3600,
+				PeerLoginExpirationEnabled: true,
+				EphemeralPeersGracePeriod:  &defaultGracePeriod,
+				PeerApprovalEnabled:        &peerApprovalDisabled,
+				JwtGroupsClaimName:         &jwtGroupsClaim,
 			},
 			expectedArray: false,
 			expectedID:    accountID,
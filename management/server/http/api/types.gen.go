@@ -9,49 +9,100 @@ import (
 
 const (
 	BearerAuthScopes = "BearerAuth.Scopes"
+	TokenAuthScopes  = "TokenAuth.Scopes"
+)
+
+// Defines values for DNSRecordType.
+const (
+	DNSRecordTypeA     DNSRecordType = "A"
+	DNSRecordTypeAAAA  DNSRecordType = "AAAA"
+	DNSRecordTypeCNAME DNSRecordType = "CNAME"
+	DNSRecordTypeSRV   DNSRecordType = "SRV"
+	DNSRecordTypeTXT   DNSRecordType = "TXT"
 )
 
 // Defines values for EventActivityCode.
 const (
-	EventActivityCodeAccountCreate                            EventActivityCode = "account.create"
-	EventActivityCodeAccountSettingPeerLoginExpirationDisable EventActivityCode = "account.setting.peer.login.expiration.disable"
-	EventActivityCodeAccountSettingPeerLoginExpirationEnable  EventActivityCode = "account.setting.peer.login.expiration.enable"
-	EventActivityCodeAccountSettingPeerLoginExpirationUpdate  EventActivityCode = "account.setting.peer.login.expiration.update"
-	EventActivityCodeDnsSettingDisabledManagementGroupAdd     EventActivityCode = "dns.setting.disabled.management.group.add"
-	EventActivityCodeDnsSettingDisabledManagementGroupDelete  EventActivityCode = "dns.setting.disabled.management.group.delete"
-	EventActivityCodeGroupAdd                                 EventActivityCode = "group.add"
-	EventActivityCodeGroupUpdate                              EventActivityCode = "group.update"
-	EventActivityCodeNameserverGroupAdd                       EventActivityCode = "nameserver.group.add"
-	EventActivityCodeNameserverGroupDelete                    EventActivityCode = "nameserver.group.delete"
-	EventActivityCodeNameserverGroupUpdate                    EventActivityCode = "nameserver.group.update"
-	EventActivityCodePeerLoginExpirationDisable               EventActivityCode = "peer.login.expiration.disable"
-	EventActivityCodePeerLoginExpirationEnable                EventActivityCode = "peer.login.expiration.enable"
-	EventActivityCodePeerRename                               EventActivityCode = "peer.rename"
-	EventActivityCodePeerSshDisable                           EventActivityCode = "peer.ssh.disable"
-	EventActivityCodePeerSshEnable                            EventActivityCode = "peer.ssh.enable"
-	EventActivityCodePolicyAdd                                EventActivityCode = "policy.add"
-	EventActivityCodePolicyDelete                             EventActivityCode = "policy.delete"
-	EventActivityCodePolicyUpdate                             EventActivityCode = "policy.update"
-	EventActivityCodeRouteAdd                                 EventActivityCode = "route.add"
-	EventActivityCodeRouteDelete                              EventActivityCode = "route.delete"
-	EventActivityCodeRouteUpdate                              EventActivityCode = "route.update"
-	EventActivityCodeRuleAdd                                  EventActivityCode = "rule.add"
-	EventActivityCodeRuleDelete                               EventActivityCode = "rule.delete"
-	EventActivityCodeRuleUpdate                               EventActivityCode = "rule.update"
-	EventActivityCodeSetupkeyAdd                              EventActivityCode = "setupkey.add"
-	EventActivityCodeSetupkeyGroupAdd                         EventActivityCode = "setupkey.group.add"
-	EventActivityCodeSetupkeyGroupDelete                      EventActivityCode = "setupkey.group.delete"
-	EventActivityCodeSetupkeyOveruse                          EventActivityCode = "setupkey.overuse"
-	EventActivityCodeSetupkeyPeerAdd                          EventActivityCode = "setupkey.peer.add"
-	EventActivityCodeSetupkeyRevoke                           EventActivityCode = "setupkey.revoke"
-	EventActivityCodeSetupkeyUpdate                           EventActivityCode = "setupkey.update"
-	EventActivityCodeUserGroupAdd                             EventActivityCode = "user.group.add"
-	EventActivityCodeUserGroupDelete                          EventActivityCode = "user.group.delete"
-	EventActivityCodeUserInvite                               EventActivityCode = "user.invite"
-	EventActivityCodeUserJoin                                 EventActivityCode = "user.join"
-	EventActivityCodeUserPeerAdd                              EventActivityCode = "user.peer.add"
-	EventActivityCodeUserPeerDelete                           EventActivityCode = "user.peer.delete"
-	EventActivityCodeUserRoleUpdate                           EventActivityCode = "user.role.update"
+	EventActivityCodeAccountCreate                                 EventActivityCode = "account.create"
+	EventActivityCodeAccountSettingEphemeralPeersGracePeriodUpdate EventActivityCode = "account.setting.ephemeral.peers.grace.period.update"
+	EventActivityCodeAccountSettingJwtGroupsClaimUpdate            EventActivityCode = "account.setting.jwt.groups.claim.update"
+	EventActivityCodeAccountSettingPeerApprovalDisable             EventActivityCode = "account.setting.peer.approval.disable"
+	EventActivityCodeAccountSettingPeerApprovalEnable              EventActivityCode = "account.setting.peer.approval.enable"
+	EventActivityCodeAccountSettingPeerLoginExpirationDisable      EventActivityCode = "account.setting.peer.login.expiration.disable"
+	EventActivityCodeAccountSettingPeerLoginExpirationEnable       EventActivityCode = "account.setting.peer.login.expiration.enable"
+	EventActivityCodeAccountSettingPeerLoginExpirationUpdate       EventActivityCode = "account.setting.peer.login.expiration.update"
+	EventActivityCodeDnsSettingDisabledManagementGroupAdd          EventActivityCode = "dns.setting.disabled.management.group.add"
+	EventActivityCodeDnsSettingDisabledManagementGroupDelete       EventActivityCode = "dns.setting.disabled.management.group.delete"
+	EventActivityCodeDnsZoneAdd                                    EventActivityCode = "dns.zone.add"
+	EventActivityCodeDnsZoneDelete                                 EventActivityCode = "dns.zone.delete"
+	EventActivityCodeDnsZoneUpdate                                 EventActivityCode = "dns.zone.update"
+	EventActivityCodeGroupAdd                                      EventActivityCode = "group.add"
+	EventActivityCodeGroupUpdate                                   EventActivityCode = "group.update"
+	EventActivityCodeNameserverGroupAdd                            EventActivityCode = "nameserver.group.add"
+	EventActivityCodeNameserverGroupDelete                         EventActivityCode = "nameserver.group.delete"
+	EventActivityCodeNameserverGroupUpdate                         EventActivityCode = "nameserver.group.update"
+	EventActivityCodePeerApprove                                   EventActivityCode = "peer.approve"
+	EventActivityCodePeerEphemeralDelete                           EventActivityCode = "peer.ephemeral.delete"
+	EventActivityCodePeerIpUpdate                                  EventActivityCode = "peer.ip.update"
+	EventActivityCodePeerLoginExpirationDisable                    EventActivityCode = "peer.login.expiration.disable"
+	EventActivityCodePeerLoginExpirationEnable                     EventActivityCode = "peer.login.expiration.enable"
+	EventActivityCodePeerReject                                    EventActivityCode = "peer.reject"
+	EventActivityCodePeerRename                                    EventActivityCode = "peer.rename"
+	EventActivityCodePeerSshDisable                                EventActivityCode = "peer.ssh.disable"
+	EventActivityCodePeerSshEnable                                 EventActivityCode = "peer.ssh.enable"
+	EventActivityCodePeerSshSessionEnd                             EventActivityCode = "peer.ssh.session.end"
+	EventActivityCodePeerSshSessionStart                           EventActivityCode = "peer.ssh.session.start"
+	EventActivityCodePolicyAdd                                     EventActivityCode = "policy.add"
+	EventActivityCodePolicyDelete                                  EventActivityCode = "policy.delete"
+	EventActivityCodePolicyUpdate                                  EventActivityCode = "policy.update"
+	EventActivityCodePostureCheckCreate                            EventActivityCode = "posture.check.create"
+	EventActivityCodePostureCheckDelete                            EventActivityCode = "posture.check.delete"
+	EventActivityCodePostureCheckUpdate                            EventActivityCode = "posture.check.update"
+	EventActivityCodeRouteAdd                                      EventActivityCode = "route.add"
+	EventActivityCodeRouteDelete                                   EventActivityCode = "route.delete"
+	EventActivityCodeRouteUpdate                                   EventActivityCode = "route.update"
+	EventActivityCodeRuleAdd                                       EventActivityCode = "rule.add"
+	EventActivityCodeRuleDelete                                    EventActivityCode = "rule.delete"
+	EventActivityCodeRuleUpdate                                    EventActivityCode = "rule.update"
+	EventActivityCodeServiceUserCreate                             EventActivityCode = "service.user.create"
+	EventActivityCodeSetupkeyAdd                                   EventActivityCode = "setupkey.add"
+	EventActivityCodeSetupkeyGroupAdd                              EventActivityCode = "setupkey.group.add"
+	EventActivityCodeSetupkeyGroupDelete                           EventActivityCode = "setupkey.group.delete"
+	EventActivityCodeSetupkeyOveruse                               EventActivityCode = "setupkey.overuse"
+	EventActivityCodeSetupkeyPeerAdd                               EventActivityCode = "setupkey.peer.add"
+	EventActivityCodeSetupkeyRevoke                                EventActivityCode = "setupkey.revoke"
+	EventActivityCodeSetupkeyUpdate                                EventActivityCode = "setupkey.update"
+	EventActivityCodeSshCertificateIssue                           EventActivityCode = "ssh.certificate.issue"
+	EventActivityCodeSshPolicyAdd                                  EventActivityCode = "ssh.policy.add"
+	EventActivityCodeSshPolicyDelete                               EventActivityCode = "ssh.policy.delete"
+	EventActivityCodeSshPolicyUpdate                               EventActivityCode = "ssh.policy.update"
+	EventActivityCodeUserBlock                                     EventActivityCode = "user.block"
+	EventActivityCodeUserGroupAdd                                  EventActivityCode = "user.group.add"
+	EventActivityCodeUserGroupDelete                               EventActivityCode = "user.group.delete"
+	EventActivityCodeUserInvite                                    EventActivityCode = "user.invite"
+	EventActivityCodeUserJoin                                      EventActivityCode = "user.join"
+	EventActivityCodeUserPeerAdd                                   EventActivityCode = "user.peer.add"
+	EventActivityCodeUserPeerDelete                                EventActivityCode = "user.peer.delete"
+	EventActivityCodeUserRoleUpdate                                EventActivityCode = "user.role.update"
+	EventActivityCodeUserUnblock                                   EventActivityCode = "user.unblock"
+)
+
+// Defines values for FirewallRuleDirection.
+const (
+	FirewallRuleDirectionDst FirewallRuleDirection = "dst"
+	FirewallRuleDirectionSrc FirewallRuleDirection = "src"
+)
+
+// Defines values for GroupIssued.
+const (
+	GroupIssuedApi GroupIssued = "api"
+	GroupIssuedJwt GroupIssued = "jwt"
+)
+
+// Defines values for GroupMinimumIssued.
+const (
+	GroupMinimumIssuedApi GroupMinimumIssued = "api"
+	GroupMinimumIssuedJwt GroupMinimumIssued = "jwt"
 )
 
 // Defines values for GroupPatchOperationOp.
@@ -67,8 +118,28 @@ const (
 	GroupPatchOperationPathPeers GroupPatchOperationPath = "peers"
 )
 
+// Defines values for GroupRuleAttribute.
+const (
+	GroupRuleAttributeHostname GroupRuleAttribute = "hostname"
+	GroupRuleAttributeName     GroupRuleAttribute = "name"
+	GroupRuleAttributeOs       GroupRuleAttribute = "os"
+	GroupRuleAttributePlatform GroupRuleAttribute = "platform"
+	GroupRuleAttributeSetupKey GroupRuleAttribute = "setup_key"
+	GroupRuleAttributeUser     GroupRuleAttribute = "user"
+)
+
+// Defines values for GroupRuleOperator.
+const (
+	GroupRuleOperatorEquals    GroupRuleOperator = "equals"
+	GroupRuleOperatorMatches   GroupRuleOperator = "matches"
+	GroupRuleOperatorNotEquals GroupRuleOperator = "not_equals"
+)
+
 // Defines values for NameserverNsType.
 const (
+	NameserverNsTypeDoh NameserverNsType = "doh"
+	NameserverNsTypeDot NameserverNsType = "dot"
+	NameserverNsTypeTcp NameserverNsType = "tcp"
 	NameserverNsTypeUdp NameserverNsType = "udp"
 )
 
@@ -129,6 +200,11 @@ const (
 	UserStatusInvited  UserStatus = "invited"
 )
 
+// Defines values for GetApiPeersParamsStatus.
+const (
+	GetApiPeersParamsStatusPending GetApiPeersParamsStatus = "pending"
+)
+
 // Account defines model for Account.
 type Account struct {
 	// Id Account ID
@@ -138,6 +214,15 @@ type Account struct {
 
 // AccountSettings defines model for AccountSettings.
 type AccountSettings struct {
+	// EphemeralPeersGracePeriod Period of time after which disconnected ephemeral peers are deleted (seconds).
+	EphemeralPeersGracePeriod *int `json:"ephemeral_peers_grace_period,omitempty"`
+
+	// JwtGroupsClaimName Name of the JWT claim holding the IdP groups of the user. When set, the groups are synchronized into NetBird groups on every login. Empty disables the synchronization.
+	JwtGroupsClaimName *string `json:"jwt_groups_claim_name,omitempty"`
+
+	// PeerApprovalEnabled Enables or disables admin approval of newly added peers. Pending peers don't get access to the network until they are approved.
+	PeerApprovalEnabled *bool `json:"peer_approval_enabled,omitempty"`
+
 	// PeerLoginExpiration Period of time after which peer login expires (seconds).
 	PeerLoginExpiration int `json:"peer_login_expiration"`
 
@@ -145,12 +230,75 @@ type AccountSettings struct {
 	PeerLoginExpirationEnabled bool `json:"peer_login_expiration_enabled"`
 }
 
+// DNSRecord defines model for DNSRecord.
+type DNSRecord struct {
+	// Name Record name, relative to the zone domain or fully qualified. Use "@" for the zone apex
+	Name string `json:"name"`
+
+	// Rdata Record value in zone file format, e.g., "10.0.0.1" for A or "10 5 443 host.example.com." for SRV records. TXT values are quoted automatically
+	Rdata string `json:"rdata"`
+
+	// Ttl Record time-to-live in seconds, defaults to 300
+	Ttl *int `json:"ttl,omitempty"`
+
+	// Type Record type
+	Type DNSRecordType `json:"type"`
+}
+
+// DNSRecordType Record type
+type DNSRecordType string
+
 // DNSSettings defines model for DNSSettings.
 type DNSSettings struct {
 	// DisabledManagementGroups Groups whose DNS management is disabled
 	DisabledManagementGroups []string `json:"disabled_management_groups"`
 }
 
+// DNSZone defines model for DNSZone.
+type DNSZone struct {
+	// Description DNS zone description
+	Description string `json:"description"`
+
+	// Domain DNS zone domain
+	Domain string `json:"domain"`
+
+	// Enabled DNS zone status
+	Enabled bool `json:"enabled"`
+
+	// Groups Groups of peers the zone is distributed to
+	Groups []string `json:"groups"`
+
+	// Id DNS zone ID
+	Id string `json:"id"`
+
+	// Name DNS zone name
+	Name string `json:"name"`
+
+	// Records DNS zone records
+	Records []DNSRecord `json:"records"`
+}
+
+// DNSZoneRequest defines model for DNSZoneRequest.
+type DNSZoneRequest struct {
+	// Description DNS zone description
+	Description string `json:"description"`
+
+	// Domain DNS zone domain
+	Domain string `json:"domain"`
+
+	// Enabled DNS zone status
+	Enabled bool `json:"enabled"`
+
+	// Groups Groups of peers the zone is distributed to
+	Groups []string `json:"groups"`
+
+	// Name DNS zone name
+	Name string `json:"name"`
+
+	// Records DNS zone records
+	Records []DNSRecord `json:"records"`
+}
+
 // Event defines model for Event.
 type Event struct {
 	// Activity The activity that occurred during the event
@@ -178,11 +326,35 @@ type Event struct {
 // EventActivityCode The string code of the activity that occurred during the event
 type EventActivityCode string
 
+// FirewallRule defines model for FirewallRule.
+type FirewallRule struct {
+	// Action Action applied to the traffic
+	Action string `json:"action"`
+
+	// Direction Direction of the traffic
+	Direction FirewallRuleDirection `json:"direction"`
+
+	// PeerId ID of the peer the rule applies to
+	PeerId string `json:"peer_id"`
+
+	// PeerIp IP address of the peer the rule applies to
+	PeerIp string `json:"peer_ip"`
+
+	// Port Port of the traffic, empty for all ports
+	Port string `json:"port"`
+}
+
+// FirewallRuleDirection Direction of the traffic
+type FirewallRuleDirection string
+
 // Group defines model for Group.
 type Group struct {
 	// Id Group ID
 	Id string `json:"id"`
 
+	// Issued How the group was created. Groups with "jwt" were created from the IdP groups of a user
+	Issued *GroupIssued `json:"issued,omitempty"`
+
 	// Name Group Name identifier
 	Name string `json:"name"`
 
@@ -191,13 +363,22 @@ type Group struct {
 
 	// PeersCount Count of peers associated to the group
 	PeersCount int `json:"peers_count"`
+
+	// Rules Rules of a dynamic group. Peers matching all the rules are members of the group
+	Rules *[]GroupRule `json:"rules,omitempty"`
 }
 
+// GroupIssued How the group was created. Groups with "jwt" were created from the IdP groups of a user
+type GroupIssued string
+
 // GroupMinimum defines model for GroupMinimum.
 type GroupMinimum struct {
 	// Id Group ID
 	Id string `json:"id"`
 
+	// Issued How the group was created. Groups with "jwt" were created from the IdP groups of a user
+	Issued *GroupMinimumIssued `json:"issued,omitempty"`
+
 	// Name Group Name identifier
 	Name string `json:"name"`
 
@@ -205,6 +386,9 @@ type GroupMinimum struct {
 	PeersCount int `json:"peers_count"`
 }
 
+// GroupMinimumIssued How the group was created. Groups with "jwt" were created from the IdP groups of a user
+type GroupMinimumIssued string
+
 // GroupPatchOperation defines model for GroupPatchOperation.
 type GroupPatchOperation struct {
 	// Op Patch operation type
@@ -223,8 +407,29 @@ type GroupPatchOperationOp string
 // GroupPatchOperationPath Group field to update in form /<field>
 type GroupPatchOperationPath string
 
+// GroupRule defines model for GroupRule.
+type GroupRule struct {
+	// Attribute Peer attribute the rule is evaluated against
+	Attribute GroupRuleAttribute `json:"attribute"`
+
+	// Operator Comparison applied to the attribute. Comparisons are case-insensitive and "matches" accepts shell patterns, e.g. db-*
+	Operator GroupRuleOperator `json:"operator"`
+
+	// Value Value the attribute is compared with
+	Value string `json:"value"`
+}
+
+// GroupRuleAttribute Peer attribute the rule is evaluated against
+type GroupRuleAttribute string
+
+// GroupRuleOperator Comparison applied to the attribute. Comparisons are case-insensitive and "matches" accepts shell patterns, e.g. db-*
+type GroupRuleOperator string
+
 // Nameserver defines model for Nameserver.
 type Nameserver struct {
+	// Hostname Nameserver hostname used for TLS server name verification. Only applies to "dot" and "doh" nameservers.
+	Hostname *string `json:"hostname,omitempty"`
+
 	// Ip Nameserver IP
 	Ip string `json:"ip"`
 
@@ -321,6 +526,9 @@ type PatchMinimumOp string
 
 // Peer defines model for Peer.
 type Peer struct {
+	// ApprovalRequired Indicates whether the peer is pending an admin approval before it gets access to the network
+	ApprovalRequired bool `json:"approval_required"`
+
 	// Connected Peer to Management connection status
 	Connected bool `json:"connected"`
 
@@ -357,6 +565,9 @@ type Peer struct {
 	// Os Peer's operating system and version
 	Os string `json:"os"`
 
+	// PostureCheckFailures Posture checks of the policies applying to the peer that the peer doesn't pass
+	PostureCheckFailures *[]PostureCheckFailure `json:"posture_check_failures,omitempty"`
+
 	// SshEnabled Indicates whether SSH server is enabled on this peer
 	SshEnabled bool `json:"ssh_enabled"`
 
@@ -379,6 +590,44 @@ type PeerMinimum struct {
 	Name string `json:"name"`
 }
 
+// PersonalAccessToken defines model for PersonalAccessToken.
+type PersonalAccessToken struct {
+	// CreatedAt Date the token was created
+	CreatedAt time.Time `json:"created_at"`
+
+	// CreatedBy User ID of the user who created the token
+	CreatedBy string `json:"created_by"`
+
+	// ExpirationDate Date the token expires
+	ExpirationDate time.Time `json:"expiration_date"`
+
+	// Id ID of a token
+	Id string `json:"id"`
+
+	// LastUsed Date the token was last used
+	LastUsed time.Time `json:"last_used"`
+
+	// Name Name of the token
+	Name string `json:"name"`
+}
+
+// PersonalAccessTokenGenerated defines model for PersonalAccessTokenGenerated.
+type PersonalAccessTokenGenerated struct {
+	PersonalAccessToken PersonalAccessToken `json:"personal_access_token"`
+
+	// PlainToken Plain text representation of the generated token. It is shown once and can't be retrieved later
+	PlainToken string `json:"plain_token"`
+}
+
+// PersonalAccessTokenRequest defines model for PersonalAccessTokenRequest.
+type PersonalAccessTokenRequest struct {
+	// ExpiresIn Expiration in days
+	ExpiresIn int `json:"expires_in"`
+
+	// Name Name of the token
+	Name string `json:"name"`
+}
+
 // Policy defines model for Policy.
 type Policy struct {
 	// Description Policy friendly description
@@ -393,11 +642,55 @@ type Policy struct {
 	// Name Policy name identifier
 	Name string `json:"name"`
 
+	// PostureChecks IDs of the posture checks peers have to pass to be part of the policy
+	PostureChecks *[]string `json:"posture_checks,omitempty"`
+
 	// Query Policy Rego query
 	Query string `json:"query"`
 
 	// Rules Policy rule object for policy UI editor
 	Rules []PolicyRule `json:"rules"`
+
+	// Tests Rego unit tests of the policy query executed when the policy is saved. Test rules have to be prefixed with test_
+	Tests *string `json:"tests,omitempty"`
+}
+
+// PolicyEvaluation defines model for PolicyEvaluation.
+type PolicyEvaluation struct {
+	// Allowed Indicates whether the peers have access to each other
+	Allowed bool `json:"allowed"`
+
+	// DestinationPeerId Destination peer ID
+	DestinationPeerId string `json:"destination_peer_id"`
+
+	// FirewallRules Firewall rules the source peer receives for the destination peer from all the policies
+	FirewallRules []FirewallRule `json:"firewall_rules"`
+
+	// Matches Policies granting the access
+	Matches []PolicyEvaluationMatch `json:"matches"`
+
+	// SourcePeerId Source peer ID
+	SourcePeerId string `json:"source_peer_id"`
+}
+
+// PolicyEvaluationMatch defines model for PolicyEvaluationMatch.
+type PolicyEvaluationMatch struct {
+	// PolicyId ID of the policy granting the access
+	PolicyId string `json:"policy_id"`
+
+	// PolicyName Name of the policy granting the access
+	PolicyName string `json:"policy_name"`
+
+	// RuleIds IDs of the policy rules the peers belong to, empty when the access is granted by a custom Rego query
+	RuleIds []string `json:"rule_ids"`
+}
+
+// PolicyEvaluationRequest defines model for PolicyEvaluationRequest.
+type PolicyEvaluationRequest struct {
+	Policy PolicyMinimum `json:"policy"`
+
+	// PolicyId ID of the Policy replaced by the candidate Policy, the candidate is added as a new Policy when empty
+	PolicyId *string `json:"policy_id,omitempty"`
 }
 
 // PolicyMinimum defines model for PolicyMinimum.
@@ -411,11 +704,17 @@ type PolicyMinimum struct {
 	// Name Policy name identifier
 	Name string `json:"name"`
 
+	// PostureChecks IDs of the posture checks peers have to pass to be part of the policy
+	PostureChecks *[]string `json:"posture_checks,omitempty"`
+
 	// Query Policy Rego query
 	Query string `json:"query"`
 
 	// Rules Policy rule object for policy UI editor
 	Rules []PolicyRule `json:"rules"`
+
+	// Tests Rego unit tests of the policy query executed when the policy is saved. Test rules have to be prefixed with test_
+	Tests *string `json:"tests,omitempty"`
 }
 
 // PolicyRule defines model for PolicyRule.
@@ -445,6 +744,69 @@ type PolicyRule struct {
 // PolicyRuleAction policy accept or drops packets
 type PolicyRuleAction string
 
+// PostureCheck defines model for PostureCheck.
+type PostureCheck struct {
+	// AllowedOs Operating systems peers have to run, matched against the peer's OS type (e.g., "linux", "darwin", "windows") or OS name
+	AllowedOs *[]string `json:"allowed_os,omitempty"`
+
+	// AllowedPlatforms Platforms (architectures) peers have to run on, e.g., "x86_64" or "arm64"
+	AllowedPlatforms *[]string `json:"allowed_platforms,omitempty"`
+
+	// Description Posture check description
+	Description string `json:"description"`
+
+	// HostnamePatterns Shell patterns one of which the peer's hostname has to match, e.g., "prod-*"
+	HostnamePatterns *[]string `json:"hostname_patterns,omitempty"`
+
+	// Id Posture check ID
+	Id string `json:"id"`
+
+	// MinKernelVersion Minimum kernel version peers have to run, e.g., "5.10"
+	MinKernelVersion *string `json:"min_kernel_version,omitempty"`
+
+	// MinVersion Minimum NetBird version peers have to run, e.g., "0.21.0"
+	MinVersion *string `json:"min_version,omitempty"`
+
+	// Name Posture check name
+	Name string `json:"name"`
+}
+
+// PostureCheckFailure defines model for PostureCheckFailure.
+type PostureCheckFailure struct {
+	// Id Posture check ID
+	Id string `json:"id"`
+
+	// Name Posture check name
+	Name string `json:"name"`
+
+	// Reason The reason the peer doesn't pass the posture check
+	Reason string `json:"reason"`
+}
+
+// PostureCheckRequest defines model for PostureCheckRequest.
+type PostureCheckRequest struct {
+	// AllowedOs Operating systems peers have to run, matched against the peer's OS type (e.g., "linux", "darwin", "windows") or OS name
+	AllowedOs *[]string `json:"allowed_os,omitempty"`
+
+	// AllowedPlatforms Platforms (architectures) peers have to run on, e.g., "x86_64" or "arm64"
+	AllowedPlatforms *[]string `json:"allowed_platforms,omitempty"`
+
+	// Description Posture check description
+	Description string `json:"description"`
+
+	// HostnamePatterns Shell patterns one of which the peer's hostname has to match, e.g., "prod-*"
+	HostnamePatterns *[]string `json:"hostname_patterns,omitempty"`
+
+	// MinKernelVersion Minimum kernel version peers have to run, e.g., "5.10"
+	MinKernelVersion *string `json:"min_kernel_version,omitempty"`
+
+	// MinVersion Minimum NetBird version peers have to run, e.g., "0.21.0"
+	MinVersion *string `json:"min_version,omitempty"`
+
+	// Name Posture check name
+	Name string `json:"name"`
+}
+
 // Route defines model for Route.
 type Route struct {
 	// Description Route description
@@ -562,18 +924,114 @@ type RuleMinimum struct {
 	Name string `json:"name"`
 }
 
+// SSHCertificate defines model for SSHCertificate.
+type SSHCertificate struct {
+	// Certificate Signed SSH certificate in the authorized_keys format to be used as the certificate file of an SSH client
+	Certificate string `json:"certificate"`
+
+	// KeyId Key ID of the certificate, the ID of the user it has been issued to
+	KeyId string `json:"key_id"`
+
+	// Principals Local users the certificate allows to log in as
+	Principals []string `json:"principals"`
+
+	// Serial Serial number of the certificate
+	Serial string `json:"serial"`
+
+	// ValidAfter The date and time when the certificate becomes valid
+	ValidAfter time.Time `json:"valid_after"`
+
+	// ValidBefore The date and time when the certificate expires
+	ValidBefore time.Time `json:"valid_before"`
+}
+
+// SSHCertificateAuthority defines model for SSHCertificateAuthority.
+type SSHCertificateAuthority struct {
+	// PublicKey Public key of the account SSH certificate authority in the authorized_keys format
+	PublicKey string `json:"public_key"`
+}
+
+// SSHCertificateRequest defines model for SSHCertificateRequest.
+type SSHCertificateRequest struct {
+	// Principals Local users the certificate allows to log in as, e.g., "deploy" or "root"
+	Principals []string `json:"principals"`
+
+	// PublicKey SSH public key of the user to sign in the authorized_keys format
+	PublicKey string `json:"public_key"`
+
+	// ValiditySeconds Validity of the certificate in seconds. Defaults to 3600 and can't exceed 86400
+	ValiditySeconds *int `json:"validity_seconds,omitempty"`
+}
+
+// SSHPolicy defines model for SSHPolicy.
+type SSHPolicy struct {
+	// AllowPortForwarding Allows the source peers to forward local and remote TCP ports through the SSH server of the destination peers
+	AllowPortForwarding *bool `json:"allow_port_forwarding,omitempty"`
+
+	// Description SSH policy description
+	Description string `json:"description"`
+
+	// Destinations Groups of peers running the SSH server the policy applies to
+	Destinations []string `json:"destinations"`
+
+	// Enabled SSH policy status
+	Enabled bool `json:"enabled"`
+
+	// Id SSH policy ID
+	Id string `json:"id"`
+
+	// LocalUsers Local users of the destination peers the source peers are allowed to log in as, e.g., "deploy" or "root"
+	LocalUsers []string `json:"local_users"`
+
+	// Name SSH policy name
+	Name string `json:"name"`
+
+	// Sources Groups of peers allowed to log in
+	Sources []string `json:"sources"`
+}
+
+// SSHPolicyRequest defines model for SSHPolicyRequest.
+type SSHPolicyRequest struct {
+	// AllowPortForwarding Allows the source peers to forward local and remote TCP ports through the SSH server of the destination peers
+	AllowPortForwarding *bool `json:"allow_port_forwarding,omitempty"`
+
+	// Description SSH policy description
+	Description string `json:"description"`
+
+	// Destinations Groups of peers running the SSH server the policy applies to
+	Destinations []string `json:"destinations"`
+
+	// Enabled SSH policy status
+	Enabled bool `json:"enabled"`
+
+	// LocalUsers Local users of the destination peers the source peers are allowed to log in as, e.g., "deploy" or "root"
+	LocalUsers []string `json:"local_users"`
+
+	// Name SSH policy name
+	Name string `json:"name"`
+
+	// Sources Groups of peers allowed to log in
+	Sources []string `json:"sources"`
+}
+
 // SetupKey defines model for SetupKey.
 type SetupKey struct {
 	// AutoGroups Setup key groups to auto-assign to peers registered with this key
 	AutoGroups []string `json:"auto_groups"`
 
+	// Ephemeral Indicates that peers registered with this key are deleted once they have been disconnected for longer than the ephemeral peers grace period of the account
+	Ephemeral bool `json:"ephemeral"`
+
 	// Expires Setup Key expiration date
 	Expires time.Time `json:"expires"`
 
 	// Id Setup Key ID
 	Id string `json:"id"`
 
-	// Key Setup Key value
+	// IpPool Optional CIDR range within the account network that peers registered with this key get their IP from
+	IpPool *string `json:"ip_pool,omitempty"`
+
+	// Key Setup Key value. The full key is returned only when the key is created, otherwise only its prefix is visible
 	Key string `json:"key"`
 
 	// LastUsed Setup key last usage date
@@ -609,9 +1067,15 @@ type SetupKeyRequest struct {
 	// AutoGroups Setup key groups to auto-assign to peers registered with this key
 	AutoGroups []string `json:"auto_groups"`
 
+	// Ephemeral Peers registered with an ephemeral key are deleted once they have been disconnected for longer than the ephemeral peers grace period of the account. Can only be set on creation
+	Ephemeral *bool `json:"ephemeral,omitempty"`
+
 	// ExpiresIn Expiration time in seconds
 	ExpiresIn int `json:"expires_in"`
 
+	// IpPool Optional CIDR range within the account network that peers registered with this key get their IP from
+	IpPool *string `json:"ip_pool,omitempty"`
+
 	// Name Setup Key name
 	Name string `json:"name"`
 
@@ -636,9 +1100,15 @@ type User struct {
 	// Id User ID
 	Id string `json:"id"`
 
+	// IsBlocked Is true if this user is blocked. Blocked users can't use the API and their peers have no access to the network
+	IsBlocked bool `json:"is_blocked"`
+
 	// IsCurrent Is true if authenticated user is the same as this user
 	IsCurrent *bool `json:"is_current,omitempty"`
 
+	// IsServiceUser Is true if this user is a service user created for automation without an IdP identity
+	IsServiceUser bool `json:"is_service_user"`
+
 	// Name User's name from idp provider
 	Name string `json:"name"`
 
@@ -657,10 +1127,13 @@ type UserCreateRequest struct {
 	// AutoGroups Groups to auto-assign to peers registered by this user
 	AutoGroups []string `json:"auto_groups"`
 
-	// Email User's Email to send invite to
-	Email string `json:"email"`
+	// Email User's Email to send invite to. Required for regular users
+	Email *string `json:"email,omitempty"`
+
+	// IsServiceUser If set to true, a service user is created in NetBird only, without an IdP identity and an invite
+	IsServiceUser *bool `json:"is_service_user,omitempty"`
 
-	// Name User's full name
+	// Name User's full name. Required for service users
 	Name *string `json:"name,omitempty"`
 
 	// Role User's NetBird account role
@@ -672,6 +1145,9 @@ type UserRequest struct {
 	// AutoGroups Groups to auto-assign to peers registered by this user
 	AutoGroups []string `json:"auto_groups"`
 
+	// IsBlocked If set to true then the user is blocked and loses access to the system and the network
+	IsBlocked *bool `json:"is_blocked,omitempty"`
+
 	// Role User's NetBird account role
 	Role string `json:"role"`
 }
@@ -688,6 +1164,9 @@ type PatchApiDnsNameserversIdJSONBody = []NameserverGroupPatchOperation
 type PostApiGroupsJSONBody struct {
 	Name  string    `json:"name"`
 	Peers *[]string `json:"peers,omitempty"`
+
+	// Rules Rules of a dynamic group. Peers matching all the rules become members of the group and peers can't be assigned manually
+	Rules *[]GroupRule `json:"rules,omitempty"`
 }
 
 // PatchApiGroupsIdJSONBody defines parameters for PatchApiGroupsId.
@@ -697,18 +1176,62 @@ type PatchApiGroupsIdJSONBody = []GroupPatchOperation
 type PutApiGroupsIdJSONBody struct {
 	Name  *string   `json:"Name,omitempty"`
 	Peers *[]string `json:"Peers,omitempty"`
+
+	// Rules Rules of a dynamic group. Peers matching all the rules become members of the group and peers can't be assigned manually
+	Rules *[]GroupRule `json:"rules,omitempty"`
+}
+
+// GetApiPeersParams defines parameters for GetApiPeers.
+type GetApiPeersParams struct {
+	// Status Filters peers by status. "pending" returns only peers that are waiting for an admin approval
+	Status *GetApiPeersParamsStatus `form:"status,omitempty" json:"status,omitempty"`
 }
 
+// GetApiPeersParamsStatus defines parameters for GetApiPeers.
+type GetApiPeersParamsStatus string
+
 // PutApiPeersIdJSONBody defines parameters for PutApiPeersId.
 type PutApiPeersIdJSONBody struct {
-	LoginExpirationEnabled bool   `json:"login_expiration_enabled"`
-	Name                   string `json:"name"`
-	SshEnabled             bool   `json:"ssh_enabled"`
+	// Ip Peer's IP address. Has to be a free IP within the account network. Omit to keep the current IP.
+	Ip                     *string `json:"ip,omitempty"`
+	LoginExpirationEnabled bool    `json:"login_expiration_enabled"`
+	Name                   string  `json:"name"`
+	SshEnabled             bool    `json:"ssh_enabled"`
 }
 
 // PostApiPoliciesJSONBody defines parameters for PostApiPolicies.
 type PostApiPoliciesJSONBody = PolicyMinimum
 
+// GetApiPoliciesEvaluateParams defines parameters for GetApiPoliciesEvaluate.
+type GetApiPoliciesEvaluateParams struct {
+	// Source Source peer ID. Either a source peer or a source group is required
+	Source *string `form:"source,omitempty" json:"source,omitempty"`
+
+	// SourceGroup Source group ID, every peer of the group is evaluated
+	SourceGroup *string `form:"source_group,omitempty" json:"source_group,omitempty"`
+
+	// Destination Destination peer ID. Either a destination peer or a destination group is required
+	Destination *string `form:"destination,omitempty" json:"destination,omitempty"`
+
+	// DestinationGroup Destination group ID, every peer of the group is evaluated
+	DestinationGroup *string `form:"destination_group,omitempty" json:"destination_group,omitempty"`
+}
+
+// PostApiPoliciesEvaluateParams defines parameters for PostApiPoliciesEvaluate.
+type PostApiPoliciesEvaluateParams struct {
+	// Source Source peer ID. Either a source peer or a source group is required
+	Source *string `form:"source,omitempty" json:"source,omitempty"`
+
+	// SourceGroup Source group ID, every peer of the group is evaluated
+	SourceGroup *string `form:"source_group,omitempty" json:"source_group,omitempty"`
+
+	// Destination Destination peer ID. Either a destination peer or a destination group is required
+	Destination *string `form:"destination,omitempty" json:"destination,omitempty"`
+
+	// DestinationGroup Destination group ID, every peer of the group is evaluated
+	DestinationGroup *string `form:"destination_group,omitempty" json:"destination_group,omitempty"`
+}
+
 // PutApiPoliciesIdJSONBody defines parameters for PutApiPoliciesId.
 type PutApiPoliciesIdJSONBody = PolicyMinimum
 
@@ -749,6 +1272,12 @@ type PutApiRulesIdJSONBody struct {
 	Sources *[]string `json:"sources,omitempty"`
 }
 
+// GetApiUsersParams defines parameters for GetApiUsers.
+type GetApiUsersParams struct {
+	// ServiceUser Filters users by type. If true, only service users are returned, otherwise only regular users
+	ServiceUser *bool `form:"service_user,omitempty" json:"service_user,omitempty"`
+}
+
 // PutApiAccountsIdJSONRequestBody defines body for PutApiAccountsId for application/json ContentType.
 type PutApiAccountsIdJSONRequestBody PutApiAccountsIdJSONBody
 
@@ -764,6 +1293,12 @@ type PutApiDnsNameserversIdJSONRequestBody = NameserverGroupRequest
 // PutApiDnsSettingsJSONRequestBody defines body for PutApiDnsSettings for application/json ContentType.
 type PutApiDnsSettingsJSONRequestBody = DNSSettings
 
+// PostApiDnsZonesJSONRequestBody defines body for PostApiDnsZones for application/json ContentType.
+type PostApiDnsZonesJSONRequestBody = DNSZoneRequest
+
+// PutApiDnsZonesIdJSONRequestBody defines body for PutApiDnsZonesId for application/json ContentType.
+type PutApiDnsZonesIdJSONRequestBody = DNSZoneRequest
+
 // PostApiGroupsJSONRequestBody defines body for PostApiGroups for application/json ContentType.
 type PostApiGroupsJSONRequestBody PostApiGroupsJSONBody
 
@@ -779,9 +1314,18 @@ type PutApiPeersIdJSONRequestBody PutApiPeersIdJSONBody
 // PostApiPoliciesJSONRequestBody defines body for PostApiPolicies for application/json ContentType.
 type PostApiPoliciesJSONRequestBody = PostApiPoliciesJSONBody
 
+// PostApiPoliciesEvaluateJSONRequestBody defines body for PostApiPoliciesEvaluate for application/json ContentType.
+type PostApiPoliciesEvaluateJSONRequestBody = PolicyEvaluationRequest
+
 // PutApiPoliciesIdJSONRequestBody defines body for PutApiPoliciesId for application/json ContentType.
 type PutApiPoliciesIdJSONRequestBody = PutApiPoliciesIdJSONBody
 
+// PostApiPostureChecksJSONRequestBody defines body for PostApiPostureChecks for application/json ContentType.
+type PostApiPostureChecksJSONRequestBody = PostureCheckRequest
+
+// PutApiPostureChecksIdJSONRequestBody defines body for PutApiPostureChecksId for application/json ContentType.
+type PutApiPostureChecksIdJSONRequestBody = PostureCheckRequest
+
 // PostApiRoutesJSONRequestBody defines body for PostApiRoutes for application/json ContentType.
 type PostApiRoutesJSONRequestBody = RouteRequest
 
@@ -803,8 +1347,20 @@ type PostApiSetupKeysJSONRequestBody = SetupKeyRequest
 // PutApiSetupKeysIdJSONRequestBody defines body for PutApiSetupKeysId for application/json ContentType.
 type PutApiSetupKeysIdJSONRequestBody = SetupKeyRequest
 
+// PostApiSshCertificatesJSONRequestBody defines body for PostApiSshCertificates for application/json ContentType.
+type PostApiSshCertificatesJSONRequestBody = SSHCertificateRequest
+
+// PostApiSshPoliciesJSONRequestBody defines body for PostApiSshPolicies for application/json ContentType.
+type PostApiSshPoliciesJSONRequestBody = SSHPolicyRequest
+
+// PutApiSshPoliciesIdJSONRequestBody defines body for PutApiSshPoliciesId for application/json ContentType.
+type PutApiSshPoliciesIdJSONRequestBody = SSHPolicyRequest
+
 // PostApiUsersJSONRequestBody defines body for PostApiUsers for application/json ContentType.
 type PostApiUsersJSONRequestBody = UserCreateRequest
 
 // PutApiUsersIdJSONRequestBody defines body for PutApiUsersId for application/json ContentType.
 type PutApiUsersIdJSONRequestBody = UserRequest
+
+// PostApiUsersUserIdTokensJSONRequestBody defines body for PostApiUsersUserIdTokens for application/json ContentType.
+type PostApiUsersUserIdTokensJSONRequestBody = PersonalAccessTokenRequest
@@ -0,0 +1,234 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/miekg/dns"
+	"github.com/rs/xid"
+
+	nbdns "github.com/netbirdio/netbird/dns"
+	"github.com/netbirdio/netbird/management/server"
+	"github.com/netbirdio/netbird/management/server/http/api"
+	"github.com/netbirdio/netbird/management/server/http/util"
+	"github.com/netbirdio/netbird/management/server/jwtclaims"
+	"github.com/netbirdio/netbird/management/server/status"
+)
+
+// DNSZonesHandler is the custom DNS zones handler of the account
+type DNSZonesHandler struct {
+	accountManager  server.AccountManager
+	claimsExtractor *jwtclaims.ClaimsExtractor
+}
+
+// NewDNSZonesHandler returns a new instance of DNSZonesHandler handler
+func NewDNSZonesHandler(accountManager server.AccountManager, authCfg AuthCfg) *DNSZonesHandler {
+	return &DNSZonesHandler{
+		accountManager: accountManager,
+		claimsExtractor: jwtclaims.NewClaimsExtractor(
+			jwtclaims.WithAudience(authCfg.Audience),
+			jwtclaims.WithUserIDClaim(authCfg.UserIDClaim),
+		),
+	}
+}
+
+// GetAllDNSZones returns the list of custom DNS zones for the account
+func (h *DNSZonesHandler) GetAllDNSZones(w http.ResponseWriter, r *http.Request) {
+	claims := h.claimsExtractor.FromRequestContext(r)
+	account, user, err := h.accountManager.GetAccountFromToken(claims)
+	if err != nil {
+		util.WriteError(err, w)
+		return
+	}
+
+	zones, err := h.accountManager.ListDNSZones(account.Id, user.Id)
+	if err != nil {
+		util.WriteError(err, w)
+		return
+	}
+
+	apiZones := make([]*api.DNSZone, 0, len(zones))
+	for _, zone := range zones {
+		apiZones = append(apiZones, toDNSZoneResponse(zone))
+	}
+
+	util.WriteJSONObject(w, apiZones)
+}
+
+// CreateDNSZone handles custom DNS zone creation request
+func (h *DNSZonesHandler) CreateDNSZone(w http.ResponseWriter, r *http.Request) {
+	claims := h.claimsExtractor.FromRequestContext(r)
+	account, user, err := h.accountManager.GetAccountFromToken(claims)
+	if err != nil {
+		util.WriteError(err, w)
+		return
+	}
+
+	var req api.PostApiDnsZonesJSONRequestBody
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		util.WriteErrorResponse("couldn't parse JSON request", http.StatusBadRequest, w)
+		return
+	}
+
+	zone, err := toServerDNSZone(xid.New().String(), req)
+	if err != nil {
+		util.WriteError(err, w)
+		return
+	}
+
+	err = h.accountManager.SaveDNSZone(account.Id, user.Id, zone)
+	if err != nil {
+		util.WriteError(err, w)
+		return
+	}
+
+	util.WriteJSONObject(w, toDNSZoneResponse(zone))
+}
+
+// UpdateDNSZone handles update to a custom DNS zone identified by a given ID
+func (h *DNSZonesHandler) UpdateDNSZone(w http.ResponseWriter, r *http.Request) {
+	claims := h.claimsExtractor.FromRequestContext(r)
+	account, user, err := h.accountManager.GetAccountFromToken(claims)
+	if err != nil {
+		util.WriteError(err, w)
+		return
+	}
+
+	zoneID := mux.Vars(r)["id"]
+	if len(zoneID) == 0 {
+		util.WriteError(status.Errorf(status.InvalidArgument, "invalid DNS zone ID"), w)
+		return
+	}
+
+	_, err = h.accountManager.GetDNSZone(account.Id, zoneID, user.Id)
+	if err != nil {
+		util.WriteError(err, w)
+		return
+	}
+
+	var req api.PutApiDnsZonesIdJSONRequestBody
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		util.WriteErrorResponse("couldn't parse JSON request", http.StatusBadRequest, w)
+		return
+	}
+
+	zone, err := toServerDNSZone(zoneID, req)
+	if err != nil {
+		util.WriteError(err, w)
+		return
+	}
+
+	err = h.accountManager.SaveDNSZone(account.Id, user.Id, zone)
+	if err != nil {
+		util.WriteError(err, w)
+		return
+	}
+
+	util.WriteJSONObject(w, toDNSZoneResponse(zone))
+}
+
+// DeleteDNSZone handles custom DNS zone deletion request
+func (h *DNSZonesHandler) DeleteDNSZone(w http.ResponseWriter, r *http.Request) {
+	claims := h.claimsExtractor.FromRequestContext(r)
+	account, user, err := h.accountManager.GetAccountFromToken(claims)
+	if err != nil {
+		util.WriteError(err, w)
+		return
+	}
+
+	zoneID := mux.Vars(r)["id"]
+	if len(zoneID) == 0 {
+		util.WriteError(status.Errorf(status.InvalidArgument, "invalid DNS zone ID"), w)
+		return
+	}
+
+	err = h.accountManager.DeleteDNSZone(account.Id, zoneID, user.Id)
+	if err != nil {
+		util.WriteError(err, w)
+		return
+	}
+
+	util.WriteJSONObject(w, "")
+}
+
+// GetDNSZone handles a custom DNS zone Get request identified by ID
+func (h *DNSZonesHandler) GetDNSZone(w http.ResponseWriter, r *http.Request) {
+	claims := h.claimsExtractor.FromRequestContext(r)
+	account, user, err := h.accountManager.GetAccountFromToken(claims)
+	if err != nil {
+		util.WriteError(err, w)
+		return
+	}
+
+	zoneID := mux.Vars(r)["id"]
+	if len(zoneID) == 0 {
+		util.WriteError(status.Errorf(status.InvalidArgument, "invalid DNS zone ID"), w)
+		return
+	}
+
+	zone, err := h.accountManager.GetDNSZone(account.Id, zoneID, user.Id)
+	if err != nil {
+		util.WriteError(err, w)
+		return
+	}
+
+	util.WriteJSONObject(w, toDNSZoneResponse(zone))
+}
+
+func toServerDNSZone(zoneID string, req api.DNSZoneRequest) (*server.DNSZone, error) {
+	zone := &server.DNSZone{
+		ID:          zoneID,
+		Name:        req.Name,
+		Description: req.Description,
+		Domain:      req.Domain,
+		Groups:      req.Groups,
+		Enabled:     req.Enabled,
+		Records:     make([]nbdns.SimpleRecord, 0, len(req.Records)),
+	}
+
+	for _, apiRecord := range req.Records {
+		recordType, ok := dns.StringToType[string(apiRecord.Type)]
+		if !ok {
+			return nil, status.Errorf(status.InvalidArgument, "invalid DNS record type %s", apiRecord.Type)
+		}
+
+		record := nbdns.SimpleRecord{
+			Name:  apiRecord.Name,
+			Type:  int(recordType),
+			Class: nbdns.DefaultClass,
+			RData: apiRecord.Rdata,
+		}
+		if apiRecord.Ttl != nil {
+			record.TTL = *apiRecord.Ttl
+		}
+		zone.Records = append(zone.Records, record)
+	}
+
+	return zone, nil
+}
+
+func toDNSZoneResponse(zone *server.DNSZone) *api.DNSZone {
+	records := make([]api.DNSRecord, 0, len(zone.Records))
+	for _, record := range zone.Records {
+		ttl := record.TTL
+		records = append(records, api.DNSRecord{
+			Name:  record.Name,
+			Type:  api.DNSRecordType(dns.Type(record.Type).String()),
+			Ttl:   &ttl,
+			Rdata: record.RData,
+		})
+	}
+
+	return &api.DNSZone{
+		Id:          zone.ID,
+		Name:        zone.Name,
+		Description: zone.Description,
+		Domain:      zone.Domain,
+		Records:     records,
+		Groups:      zone.Groups,
+		Enabled:     zone.Enabled,
+	}
+}
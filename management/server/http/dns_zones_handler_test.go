@@ -0,0 +1,204 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+
+	nbdns "github.com/netbirdio/netbird/dns"
+	"github.com/netbirdio/netbird/management/server"
+	"github.com/netbirdio/netbird/management/server/http/api"
+	"github.com/netbirdio/netbird/management/server/jwtclaims"
+	"github.com/netbirdio/netbird/management/server/mock_server"
+	"github.com/netbirdio/netbird/management/server/status"
+)
+
+const (
+	testDNSZonesAccountID = "test_id"
+	testDNSZonesUserID    = "test_user"
+	existingDNSZoneID     = "existingZone"
+	notFoundDNSZoneID     = "notFoundZone"
+)
+
+var baseExistingDNSZone = &server.DNSZone{
+	ID:          existingDNSZoneID,
+	Name:        "internal",
+	Description: "internal zone",
+	Domain:      "internal.example.",
+	Records: []nbdns.SimpleRecord{
+		{Name: "app.internal.example.", Type: int(dns.TypeA), Class: nbdns.DefaultClass, TTL: 300, RData: "10.0.0.1"},
+	},
+	Groups:  []string{"group1"},
+	Enabled: true,
+}
+
+var testingDNSZonesAccount = &server.Account{
+	Id:     testDNSZonesAccountID,
+	Domain: "hotmail.com",
+	Users: map[string]*server.User{
+		testDNSZonesUserID: server.NewAdminUser(testDNSZonesUserID),
+	},
+}
+
+func initDNSZonesTestData() *DNSZonesHandler {
+	return &DNSZonesHandler{
+		accountManager: &mock_server.MockAccountManager{
+			GetDNSZoneFunc: func(_, zoneID, _ string) (*server.DNSZone, error) {
+				if zoneID == existingDNSZoneID {
+					return baseExistingDNSZone.Copy(), nil
+				}
+				return nil, status.Errorf(status.NotFound, "DNS zone with ID %s not found", zoneID)
+			},
+			SaveDNSZoneFunc: func(_, _ string, zoneToSave *server.DNSZone) error {
+				for i, record := range zoneToSave.Records {
+					if record.Type == int(dns.TypeMX) {
+						return status.Errorf(status.InvalidArgument, "DNS record type MX is not supported")
+					}
+					zoneToSave.Records[i].Name = dns.Fqdn(record.Name + "." + zoneToSave.Domain)
+				}
+				zoneToSave.Domain = dns.Fqdn(zoneToSave.Domain)
+				return nil
+			},
+			ListDNSZonesFunc: func(_, _ string) ([]*server.DNSZone, error) {
+				return []*server.DNSZone{baseExistingDNSZone.Copy()}, nil
+			},
+			DeleteDNSZoneFunc: func(_, zoneID, _ string) error {
+				if zoneID == existingDNSZoneID {
+					return nil
+				}
+				return status.Errorf(status.NotFound, "DNS zone with ID %s not found", zoneID)
+			},
+			GetAccountFromTokenFunc: func(_ jwtclaims.AuthorizationClaims) (*server.Account, *server.User, error) {
+				return testingDNSZonesAccount, testingDNSZonesAccount.Users[testDNSZonesUserID], nil
+			},
+		},
+		claimsExtractor: jwtclaims.NewClaimsExtractor(
+			jwtclaims.WithFromRequestContext(func(r *http.Request) jwtclaims.AuthorizationClaims {
+				return jwtclaims.AuthorizationClaims{
+					UserId:    testDNSZonesUserID,
+					Domain:    "hotmail.com",
+					AccountId: testDNSZonesAccountID,
+				}
+			}),
+		),
+	}
+}
+
+func TestDNSZonesHandlers(t *testing.T) {
+	ttl := 300
+	tt := []struct {
+		name           string
+		requestType    string
+		requestPath    string
+		requestBody    io.Reader
+		expectedStatus int
+		expectedZone   *api.DNSZone
+	}{
+		{
+			name:           "Get Existing Zone",
+			requestType:    http.MethodGet,
+			requestPath:    "/api/dns/zones/" + existingDNSZoneID,
+			expectedStatus: http.StatusOK,
+			expectedZone:   toDNSZoneResponse(baseExistingDNSZone),
+		},
+		{
+			name:           "Get Not Existing Zone",
+			requestType:    http.MethodGet,
+			requestPath:    "/api/dns/zones/" + notFoundDNSZoneID,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:        "Create Zone",
+			requestType: http.MethodPost,
+			requestPath: "/api/dns/zones",
+			requestBody: bytes.NewBufferString(`{"name":"internal","description":"internal zone","domain":"internal.example",` +
+				`"records":[{"name":"app","type":"A","rdata":"10.0.0.1","ttl":300}],"groups":["group1"],"enabled":true}`),
+			expectedStatus: http.StatusOK,
+			expectedZone: &api.DNSZone{
+				Name:        "internal",
+				Description: "internal zone",
+				Domain:      "internal.example.",
+				Records: []api.DNSRecord{
+					{Name: "app.internal.example.", Type: api.DNSRecordTypeA, Rdata: "10.0.0.1", Ttl: &ttl},
+				},
+				Groups:  []string{"group1"},
+				Enabled: true,
+			},
+		},
+		{
+			name:        "Create Zone With Unsupported Record",
+			requestType: http.MethodPost,
+			requestPath: "/api/dns/zones",
+			requestBody: bytes.NewBufferString(`{"name":"internal","description":"","domain":"internal.example",` +
+				`"records":[{"name":"@","type":"MX","rdata":"10 mail.internal.example."}],"groups":["group1"],"enabled":true}`),
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:        "Update Not Existing Zone",
+			requestType: http.MethodPut,
+			requestPath: "/api/dns/zones/" + notFoundDNSZoneID,
+			requestBody: bytes.NewBufferString(`{"name":"internal","description":"","domain":"internal.example",` +
+				`"records":[],"groups":["group1"],"enabled":true}`),
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "Delete Zone",
+			requestType:    http.MethodDelete,
+			requestPath:    "/api/dns/zones/" + existingDNSZoneID,
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	p := initDNSZonesTestData()
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			req := httptest.NewRequest(tc.requestType, tc.requestPath, tc.requestBody)
+
+			router := mux.NewRouter()
+			router.HandleFunc("/api/dns/zones", p.GetAllDNSZones).Methods("GET")
+			router.HandleFunc("/api/dns/zones", p.CreateDNSZone).Methods("POST")
+			router.HandleFunc("/api/dns/zones/{id}", p.GetDNSZone).Methods("GET")
+			router.HandleFunc("/api/dns/zones/{id}", p.UpdateDNSZone).Methods("PUT")
+			router.HandleFunc("/api/dns/zones/{id}", p.DeleteDNSZone).Methods("DELETE")
+			router.ServeHTTP(recorder, req)
+
+			res := recorder.Result()
+			defer res.Body.Close()
+
+			content, err := io.ReadAll(res.Body)
+			if err != nil {
+				t.Fatalf("I don't know what I expected; %v", err)
+			}
+
+			if status := recorder.Code; status != tc.expectedStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v, content: %s",
+					status, tc.expectedStatus, string(content))
+			}
+
+			if tc.expectedZone == nil {
+				return
+			}
+
+			got := &api.DNSZone{}
+			if err = json.Unmarshal(content, got); err != nil {
+				t.Fatalf("Sent content is not in correct json format; %v", err)
+			}
+
+			// IDs of created zones are generated by the handler
+			if tc.requestType == http.MethodPost {
+				assert.NotEmpty(t, got.Id)
+				got.Id = ""
+			}
+			assert.Equal(t, tc.expectedZone, got)
+		})
+	}
+}
@@ -110,6 +110,7 @@ func (h *GroupsHandler) UpdateGroup(w http.ResponseWriter, r *http.Request) {
 		ID:    groupID,
 		Name:  *req.Name,
 		Peers: peers,
+		Rules: toServerGroupRules(req.Rules),
 	}
 
 	if err := h.accountManager.SaveGroup(account.Id, user.Id, &group); err != nil {
@@ -253,9 +254,11 @@ func (h *GroupsHandler) CreateGroup(w http.ResponseWriter, r *http.Request) {
 		peers = *req.Peers
 	}
 	group := server.Group{
-		ID:    xid.New().String(),
-		Name:  req.Name,
-		Peers: peers,
+		ID:     xid.New().String(),
+		Name:   req.Name,
+		Peers:  peers,
+		Rules:  toServerGroupRules(req.Rules),
+		Issued: server.GroupIssuedAPI,
 	}
 
 	err = h.accountManager.SaveGroup(account.Id, user.Id, &group)
@@ -358,6 +361,21 @@ func peerIPsToKeys(account *server.Account, peerIPs *[]string) []string {
 	return mappedPeerKeys
 }
 
+func toServerGroupRules(rules *[]api.GroupRule) []*server.GroupRule {
+	if rules == nil {
+		return nil
+	}
+	var serverRules []*server.GroupRule
+	for _, rule := range *rules {
+		serverRules = append(serverRules, &server.GroupRule{
+			Attribute: server.GroupRuleAttribute(rule.Attribute),
+			Operator:  server.GroupRuleOperator(rule.Operator),
+			Value:     rule.Value,
+		})
+	}
+	return serverRules
+}
+
 func toGroupResponse(account *server.Account, group *server.Group) *api.Group {
 	cache := make(map[string]api.PeerMinimum)
 	gr := api.Group{
@@ -365,6 +383,10 @@ func toGroupResponse(account *server.Account, group *server.Group) *api.Group {
 		Name:       group.Name,
 		PeersCount: len(group.Peers),
 	}
+	if group.Issued != "" {
+		issued := api.GroupIssued(group.Issued)
+		gr.Issued = &issued
+	}
 
 	for _, pid := range group.Peers {
 		_, ok := cache[pid]
@@ -381,5 +403,17 @@ func toGroupResponse(account *server.Account, group *server.Group) *api.Group {
 			gr.Peers = append(gr.Peers, peerResp)
 		}
 	}
+
+	if group.IsDynamic() {
+		rules := make([]api.GroupRule, 0, len(group.Rules))
+		for _, rule := range group.Rules {
+			rules = append(rules, api.GroupRule{
+				Attribute: api.GroupRuleAttribute(rule.Attribute),
+				Operator:  api.GroupRuleOperator(rule.Operator),
+				Value:     rule.Value,
+			})
+		}
+		gr.Rules = &rules
+	}
 	return &gr
 }
@@ -169,6 +169,7 @@ func TestGetGroup(t *testing.T) {
 }
 
 func TestWriteGroup(t *testing.T) {
+	groupIssuedAPI := api.GroupIssuedApi
 	tt := []struct {
 		name           string
 		expectedStatus int
@@ -187,8 +188,28 @@ func TestWriteGroup(t *testing.T) {
 			expectedStatus: http.StatusOK,
 			expectedBody:   true,
 			expectedGroup: &api.Group{
-				Id:   "id-was-set",
-				Name: "Default POSTed Group",
+				Id:     "id-was-set",
+				Name:   "Default POSTed Group",
+				Issued: &groupIssuedAPI,
+			},
+		},
+		{
+			name:        "Write Dynamic Group POST OK",
+			requestType: http.MethodPost,
+			requestPath: "/api/groups",
+			requestBody: bytes.NewBuffer(
+				[]byte(`{"name":"Databases","rules":[{"attribute":"os","operator":"equals","value":"linux"},` +
+					`{"attribute":"hostname","operator":"matches","value":"db-*"}]}`)),
+			expectedStatus: http.StatusOK,
+			expectedBody:   true,
+			expectedGroup: &api.Group{
+				Id:     "id-was-set",
+				Name:   "Databases",
+				Issued: &groupIssuedAPI,
+				Rules: &[]api.GroupRule{
+					{Attribute: api.GroupRuleAttributeOs, Operator: api.GroupRuleOperatorEquals, Value: "linux"},
+					{Attribute: api.GroupRuleAttributeHostname, Operator: api.GroupRuleOperatorMatches, Value: "db-*"},
+				},
 			},
 		},
 		{
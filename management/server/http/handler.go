@@ -8,6 +8,7 @@ import (
 
 	s "github.com/netbirdio/netbird/management/server"
 	"github.com/netbirdio/netbird/management/server/http/middleware"
+	"github.com/netbirdio/netbird/management/server/jwtclaims"
 	"github.com/netbirdio/netbird/management/server/telemetry"
 )
 
@@ -35,6 +36,23 @@ func APIHandler(accountManager s.AccountManager, appMetrics telemetry.AppMetrics
 		return nil, err
 	}
 
+	authMiddleware := middleware.NewAuthMiddleware(
+		func(plainToken string) (jwtclaims.AuthorizationClaims, error) {
+			account, user, _, err := accountManager.GetAccountFromPAT(plainToken)
+			if err != nil {
+				return jwtclaims.AuthorizationClaims{}, err
+			}
+			return jwtclaims.AuthorizationClaims{
+				UserId:         user.Id,
+				AccountId:      account.Id,
+				Domain:         account.Domain,
+				DomainCategory: account.DomainCategory,
+			}, nil
+		},
+		jwtMiddleware,
+		authCfg.Audience,
+		authCfg.UserIDClaim)
+
 	corsMiddleware := cors.AllowAll()
 
 	acMiddleware := middleware.NewAccessControl(
@@ -46,7 +64,7 @@ func APIHandler(accountManager s.AccountManager, appMetrics telemetry.AppMetrics
 	metricsMiddleware := appMetrics.HTTPMiddleware()
 
 	router := rootRouter.PathPrefix("/api").Subrouter()
-	router.Use(metricsMiddleware.Handler, corsMiddleware.Handler, jwtMiddleware.Handler, acMiddleware.Handler)
+	router.Use(metricsMiddleware.Handler, corsMiddleware.Handler, authMiddleware.Handler, acMiddleware.Handler)
 
 	api := apiHandler{
 		Router:         router,
@@ -57,6 +75,7 @@ func APIHandler(accountManager s.AccountManager, appMetrics telemetry.AppMetrics
 	api.addAccountsEndpoint()
 	api.addPeersEndpoint()
 	api.addUsersEndpoint()
+	api.addUsersTokensEndpoint()
 	api.addSetupKeysEndpoint()
 	api.addRulesEndpoint()
 	api.addPoliciesEndpoint()
@@ -64,6 +83,10 @@ func APIHandler(accountManager s.AccountManager, appMetrics telemetry.AppMetrics
 	api.addRoutesEndpoint()
 	api.addDNSNameserversEndpoint()
 	api.addDNSSettingEndpoint()
+	api.addDNSZonesEndpoint()
+	api.addPostureChecksEndpoint()
+	api.addSSHPoliciesEndpoint()
+	api.addSSHCertificatesEndpoint()
 	api.addEventsEndpoint()
 
 	err = api.Router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
@@ -101,6 +124,8 @@ func (apiHandler *apiHandler) addPeersEndpoint() {
 	apiHandler.Router.HandleFunc("/peers", peersHandler.GetAllPeers).Methods("GET", "OPTIONS")
 	apiHandler.Router.HandleFunc("/peers/{id}", peersHandler.HandlePeer).
 		Methods("GET", "PUT", "DELETE", "OPTIONS")
+	apiHandler.Router.HandleFunc("/peers/{id}/approve", peersHandler.ApprovePeer).Methods("POST", "OPTIONS")
+	apiHandler.Router.HandleFunc("/peers/{id}/reject", peersHandler.RejectPeer).Methods("POST", "OPTIONS")
 }
 
 func (apiHandler *apiHandler) addUsersEndpoint() {
@@ -110,6 +135,12 @@ func (apiHandler *apiHandler) addUsersEndpoint() {
 	apiHandler.Router.HandleFunc("/users", userHandler.CreateUser).Methods("POST", "OPTIONS")
 }
 
+func (apiHandler *apiHandler) addUsersTokensEndpoint() {
+	tokenHandler := NewPATsHandler(apiHandler.AccountManager, apiHandler.AuthCfg)
+	apiHandler.Router.HandleFunc("/users/{userId}/tokens", tokenHandler.CreateToken).Methods("POST", "OPTIONS")
+	apiHandler.Router.HandleFunc("/users/{userId}/tokens/{tokenId}", tokenHandler.DeleteToken).Methods("DELETE", "OPTIONS")
+}
+
 func (apiHandler *apiHandler) addSetupKeysEndpoint() {
 	keysHandler := NewSetupKeysHandler(apiHandler.AccountManager, apiHandler.AuthCfg)
 	apiHandler.Router.HandleFunc("/setup-keys", keysHandler.GetAllSetupKeys).Methods("GET", "OPTIONS")
@@ -131,6 +162,7 @@ func (apiHandler *apiHandler) addPoliciesEndpoint() {
 	policiesHandler := NewPoliciesHandler(apiHandler.AccountManager, apiHandler.AuthCfg)
 	apiHandler.Router.HandleFunc("/policies", policiesHandler.GetAllPolicies).Methods("GET", "OPTIONS")
 	apiHandler.Router.HandleFunc("/policies", policiesHandler.CreatePolicy).Methods("POST", "OPTIONS")
+	apiHandler.Router.HandleFunc("/policies/evaluate", policiesHandler.EvaluatePolicies).Methods("GET", "POST", "OPTIONS")
 	apiHandler.Router.HandleFunc("/policies/{id}", policiesHandler.UpdatePolicy).Methods("PUT", "OPTIONS")
 	apiHandler.Router.HandleFunc("/policies/{id}", policiesHandler.GetPolicy).Methods("GET", "OPTIONS")
 	apiHandler.Router.HandleFunc("/policies/{id}", policiesHandler.DeletePolicy).Methods("DELETE", "OPTIONS")
@@ -172,6 +204,39 @@ func (apiHandler *apiHandler) addDNSSettingEndpoint() {
 	apiHandler.Router.HandleFunc("/dns/settings", dnsSettingsHandler.UpdateDNSSettings).Methods("PUT", "OPTIONS")
 }
 
+func (apiHandler *apiHandler) addDNSZonesEndpoint() {
+	dnsZonesHandler := NewDNSZonesHandler(apiHandler.AccountManager, apiHandler.AuthCfg)
+	apiHandler.Router.HandleFunc("/dns/zones", dnsZonesHandler.GetAllDNSZones).Methods("GET", "OPTIONS")
+	apiHandler.Router.HandleFunc("/dns/zones", dnsZonesHandler.CreateDNSZone).Methods("POST", "OPTIONS")
+	apiHandler.Router.HandleFunc("/dns/zones/{id}", dnsZonesHandler.UpdateDNSZone).Methods("PUT", "OPTIONS")
+	apiHandler.Router.HandleFunc("/dns/zones/{id}", dnsZonesHandler.GetDNSZone).Methods("GET", "OPTIONS")
+	apiHandler.Router.HandleFunc("/dns/zones/{id}", dnsZonesHandler.DeleteDNSZone).Methods("DELETE", "OPTIONS")
+}
+
+func (apiHandler *apiHandler) addPostureChecksEndpoint() {
+	postureChecksHandler := NewPostureChecksHandler(apiHandler.AccountManager, apiHandler.AuthCfg)
+	apiHandler.Router.HandleFunc("/posture-checks", postureChecksHandler.GetAllPostureChecks).Methods("GET", "OPTIONS")
+	apiHandler.Router.HandleFunc("/posture-checks", postureChecksHandler.CreatePostureCheck).Methods("POST", "OPTIONS")
+	apiHandler.Router.HandleFunc("/posture-checks/{id}", postureChecksHandler.UpdatePostureCheck).Methods("PUT", "OPTIONS")
+	apiHandler.Router.HandleFunc("/posture-checks/{id}", postureChecksHandler.GetPostureCheck).Methods("GET", "OPTIONS")
+	apiHandler.Router.HandleFunc("/posture-checks/{id}", postureChecksHandler.DeletePostureCheck).Methods("DELETE", "OPTIONS")
+}
+
+func (apiHandler *apiHandler) addSSHPoliciesEndpoint() {
+	sshPoliciesHandler := NewSSHPoliciesHandler(apiHandler.AccountManager, apiHandler.AuthCfg)
+	apiHandler.Router.HandleFunc("/ssh/policies", sshPoliciesHandler.GetAllSSHPolicies).Methods("GET", "OPTIONS")
+	apiHandler.Router.HandleFunc("/ssh/policies", sshPoliciesHandler.CreateSSHPolicy).Methods("POST", "OPTIONS")
+	apiHandler.Router.HandleFunc("/ssh/policies/{id}", sshPoliciesHandler.UpdateSSHPolicy).Methods("PUT", "OPTIONS")
+	apiHandler.Router.HandleFunc("/ssh/policies/{id}", sshPoliciesHandler.GetSSHPolicy).Methods("GET", "OPTIONS")
+	apiHandler.Router.HandleFunc("/ssh/policies/{id}", sshPoliciesHandler.DeleteSSHPolicy).Methods("DELETE", "OPTIONS")
+}
+
+func (apiHandler *apiHandler) addSSHCertificatesEndpoint() {
+	sshCertificatesHandler := NewSSHCertificatesHandler(apiHandler.AccountManager, apiHandler.AuthCfg)
+	apiHandler.Router.HandleFunc("/ssh/certificates", sshCertificatesHandler.IssueSSHCertificate).Methods("POST", "OPTIONS")
+	apiHandler.Router.HandleFunc("/ssh/ca", sshCertificatesHandler.GetSSHCertificateAuthority).Methods("GET", "OPTIONS")
+}
+
 func (apiHandler *apiHandler) addEventsEndpoint() {
 	eventsHandler := NewEventsHandler(apiHandler.AccountManager, apiHandler.AuthCfg)
 	apiHandler.Router.HandleFunc("/events", eventsHandler.GetAllEvents).Methods("GET", "OPTIONS")
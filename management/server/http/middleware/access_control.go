@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"net/http"
+	"regexp"
 
 	"github.com/netbirdio/netbird/management/server/http/util"
 	"github.com/netbirdio/netbird/management/server/status"
@@ -11,6 +12,18 @@ import (
 
 type IsUserAdminFunc func(claims jwtclaims.AuthorizationClaims) (bool, error)
 
+// nonAdminRequests are the modifying requests non admin users are allowed to make. Their handlers check the user permissions
+var nonAdminRequests = []struct {
+	method string
+	path   *regexp.Regexp
+}{
+	// users request SSH certificates limited by the SSH policies of their peers
+	{http.MethodPost, regexp.MustCompile(`^/api/ssh/certificates/?$`)},
+	// users manage their own personal access tokens
+	{http.MethodPost, regexp.MustCompile(`^/api/users/[^/]+/tokens/?$`)},
+	{http.MethodDelete, regexp.MustCompile(`^/api/users/[^/]+/tokens/[^/]+/?$`)},
+}
+
 // AccessControl middleware to restrict to make POST/PUT/DELETE requests by admin only
 type AccessControl struct {
 	isUserAdmin   IsUserAdminFunc
@@ -36,11 +49,16 @@ func (a *AccessControl) Handler(h http.Handler) http.Handler {
 
 		ok, err := a.isUserAdmin(claims)
 		if err != nil {
+			// blocked users are rejected with a permission denied error
+			if e, isStatus := status.FromError(err); isStatus && e.Type() == status.PermissionDenied {
+				util.WriteError(e, w)
+				return
+			}
 			util.WriteError(status.Errorf(status.Unauthorized, "invalid JWT"), w)
 			return
 		}
 
-		if !ok {
+		if !ok && !isNonAdminRequest(r) {
 			switch r.Method {
 			case http.MethodDelete, http.MethodPost, http.MethodPatch, http.MethodPut:
 				util.WriteError(status.Errorf(status.PermissionDenied, "only admin can perform this operation"), w)
@@ -51,3 +69,13 @@ func (a *AccessControl) Handler(h http.Handler) http.Handler {
 		h.ServeHTTP(w, r)
 	})
 }
+
+// isNonAdminRequest checks whether a modifying request is allowed for non admin users
+func isNonAdminRequest(r *http.Request) bool {
+	for _, request := range nonAdminRequests {
+		if r.Method == request.method && request.path.MatchString(r.URL.Path) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/golang-jwt/jwt"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/netbirdio/netbird/management/server/http/util"
+	"github.com/netbirdio/netbird/management/server/jwtclaims"
+	"github.com/netbirdio/netbird/management/server/status"
+)
+
+// patAuthScheme is the scheme of the Authorization header personal access tokens are sent with, e.g. "Token nbp_..."
+const patAuthScheme = "token"
+
+// GetClaimsFromPATFunc returns the claims of the user owning the plain personal access token
+type GetClaimsFromPATFunc func(plainToken string) (jwtclaims.AuthorizationClaims, error)
+
+// AuthMiddleware authenticates requests with a personal access token and passes all other requests to the JWT middleware
+type AuthMiddleware struct {
+	getClaimsFromPAT GetClaimsFromPATFunc
+	jwtMiddleware    *JWTMiddleware
+	audience         string
+	userIDClaim      string
+}
+
+// NewAuthMiddleware instance constructor
+func NewAuthMiddleware(getClaimsFromPAT GetClaimsFromPATFunc, jwtMiddleware *JWTMiddleware, audience, userIDClaim string) *AuthMiddleware {
+	if userIDClaim == "" {
+		userIDClaim = jwtclaims.UserIDClaim
+	}
+	return &AuthMiddleware{
+		getClaimsFromPAT: getClaimsFromPAT,
+		jwtMiddleware:    jwtMiddleware,
+		audience:         audience,
+		userIDClaim:      userIDClaim,
+	}
+}
+
+// Handler method of the middleware which authenticates the request with the personal access token of the
+// Authorization header. The claims of the token owner are put into the request context like the claims of a JWT,
+// so that the following handlers don't distinguish between both
+func (m *AuthMiddleware) Handler(h http.Handler) http.Handler {
+	jwtHandler := m.jwtMiddleware.Handler(h)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		plainToken, ok := patFromAuthHeader(r)
+		if !ok {
+			jwtHandler.ServeHTTP(w, r)
+			return
+		}
+
+		claims, err := m.getClaimsFromPAT(plainToken)
+		if err != nil {
+			log.Debugf("failed authenticating request with a personal access token: %v", err)
+			// blocked users are rejected with a permission denied error
+			if e, isStatus := status.FromError(err); isStatus && e.Type() == status.PermissionDenied {
+				util.WriteError(e, w)
+				return
+			}
+			util.WriteError(status.Errorf(status.Unauthorized, "invalid token"), w)
+			return
+		}
+
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			m.userIDClaim:                               claims.UserId,
+			m.audience + jwtclaims.AccountIDSuffix:      claims.AccountId,
+			m.audience + jwtclaims.DomainIDSuffix:       claims.Domain,
+			m.audience + jwtclaims.DomainCategorySuffix: claims.DomainCategory,
+		})
+		ctx := context.WithValue(r.Context(), jwtclaims.TokenUserProperty, token) //nolint
+		ctx = context.WithValue(ctx, jwtclaims.PATUserProperty, true)             //nolint
+
+		h.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// patFromAuthHeader returns the personal access token of the Authorization header, if the header has the token scheme
+func patFromAuthHeader(r *http.Request) (string, bool) {
+	scheme, token, found := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !found || !strings.EqualFold(scheme, patAuthScheme) {
+		return "", false
+	}
+	return strings.TrimSpace(token), true
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 
 	"github.com/gorilla/mux"
 	log "github.com/sirupsen/logrus"
@@ -276,7 +277,11 @@ func (h *NameserversHandler) GetNameserverGroup(w http.ResponseWriter, r *http.R
 func toServerNSList(apiNSList []api.Nameserver) ([]nbdns.NameServer, error) {
 	var nsList []nbdns.NameServer
 	for _, apiNS := range apiNSList {
-		parsed, err := nbdns.ParseNameServerURL(fmt.Sprintf("%s://%s:%d", apiNS.NsType, apiNS.Ip, apiNS.Port))
+		nsURL := fmt.Sprintf("%s://%s:%d", apiNS.NsType, apiNS.Ip, apiNS.Port)
+		if apiNS.Hostname != nil && *apiNS.Hostname != "" {
+			nsURL = fmt.Sprintf("%s?hostname=%s", nsURL, url.QueryEscape(*apiNS.Hostname))
+		}
+		parsed, err := nbdns.ParseNameServerURL(nsURL)
 		if err != nil {
 			return nil, err
 		}
@@ -294,6 +299,10 @@ func toNameserverGroupResponse(serverNSGroup *nbdns.NameServerGroup) *api.Namese
 			NsType: api.NameserverNsType(ns.NSType.String()),
 			Port:   ns.Port,
 		}
+		if ns.Hostname != "" {
+			hostname := ns.Hostname
+			apiNS.Hostname = &hostname
+		}
 		nsList = append(nsList, apiNS)
 	}
 
@@ -0,0 +1,264 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/netbirdio/netbird/management/server"
+	"github.com/netbirdio/netbird/management/server/http/api"
+	"github.com/netbirdio/netbird/management/server/http/middleware"
+	"github.com/netbirdio/netbird/management/server/jwtclaims"
+	"github.com/netbirdio/netbird/management/server/mock_server"
+	"github.com/netbirdio/netbird/management/server/status"
+)
+
+const (
+	testPATAccountID     = "test_account"
+	testPATAdminID       = "test_admin"
+	testPATServiceUserID = "test_service_user"
+	testPATTokenID       = "test_token"
+	testPATPlainToken    = "nbp_plain"
+	testPATBlockedToken  = "nbp_blocked"
+)
+
+var testingPATAccount = &server.Account{
+	Id:     testPATAccountID,
+	Domain: "hotmail.com",
+	Users: map[string]*server.User{
+		testPATAdminID: server.NewAdminUser(testPATAdminID),
+		testPATServiceUserID: {
+			Id:              testPATServiceUserID,
+			Role:            server.UserRoleUser,
+			IsServiceUser:   true,
+			ServiceUserName: "ci",
+			PATs:            []server.PersonalAccessToken{{ID: testPATTokenID, HashedToken: server.HashPAT(testPATPlainToken)}},
+		},
+	},
+}
+
+func initPATTestData() *PATHandler {
+	return &PATHandler{
+		accountManager: &mock_server.MockAccountManager{
+			GetAccountFromTokenFunc: func(claims jwtclaims.AuthorizationClaims) (*server.Account, *server.User, error) {
+				user, ok := testingPATAccount.Users[claims.UserId]
+				if !ok {
+					return nil, nil, status.Errorf(status.NotFound, "user %s not found", claims.UserId)
+				}
+				return testingPATAccount, user, nil
+			},
+			CreatePATFunc: func(accountID, executingUserID, targetUserID, tokenName string, expiresIn int) (*server.PersonalAccessTokenGenerated, error) {
+				if _, ok := testingPATAccount.Users[targetUserID]; !ok {
+					return nil, status.Errorf(status.NotFound, "user %s not found", targetUserID)
+				}
+				pat, plainToken, err := server.CreateNewPAT(tokenName, expiresIn, executingUserID)
+				if err != nil {
+					return nil, err
+				}
+				return &server.PersonalAccessTokenGenerated{PlainToken: plainToken, PersonalAccessToken: *pat}, nil
+			},
+			DeletePATFunc: func(accountID, executingUserID, targetUserID, tokenID string) error {
+				if tokenID != testPATTokenID {
+					return status.Errorf(status.NotFound, "token %s not found", tokenID)
+				}
+				return nil
+			},
+		},
+		claimsExtractor: jwtclaims.NewClaimsExtractor(
+			jwtclaims.WithFromRequestContext(func(r *http.Request) jwtclaims.AuthorizationClaims {
+				return jwtclaims.AuthorizationClaims{
+					UserId:    testPATAdminID,
+					Domain:    "hotmail.com",
+					AccountId: testPATAccountID,
+				}
+			}),
+		),
+	}
+}
+
+func TestPATHandlers(t *testing.T) {
+	tt := []struct {
+		name           string
+		requestType    string
+		requestPath    string
+		requestBody    io.Reader
+		expectedStatus int
+		expectedBody   bool
+	}{
+		{
+			name:           "Create Token",
+			requestType:    http.MethodPost,
+			requestPath:    "/api/users/" + testPATServiceUserID + "/tokens",
+			requestBody:    bytes.NewBufferString(`{"name":"ci","expires_in":30}`),
+			expectedStatus: http.StatusOK,
+			expectedBody:   true,
+		},
+		{
+			name:           "Create Token For Unknown User",
+			requestType:    http.MethodPost,
+			requestPath:    "/api/users/unknown/tokens",
+			requestBody:    bytes.NewBufferString(`{"name":"ci","expires_in":30}`),
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "Create Token With Invalid Body",
+			requestType:    http.MethodPost,
+			requestPath:    "/api/users/" + testPATServiceUserID + "/tokens",
+			requestBody:    bytes.NewBufferString(`{`),
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Delete Token",
+			requestType:    http.MethodDelete,
+			requestPath:    "/api/users/" + testPATServiceUserID + "/tokens/" + testPATTokenID,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Delete Missing Token",
+			requestType:    http.MethodDelete,
+			requestPath:    "/api/users/" + testPATServiceUserID + "/tokens/missing",
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	p := initPATTestData()
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/users/{userId}/tokens", p.CreateToken).Methods("POST")
+	router.HandleFunc("/api/users/{userId}/tokens/{tokenId}", p.DeleteToken).Methods("DELETE")
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			req := httptest.NewRequest(tc.requestType, tc.requestPath, tc.requestBody)
+
+			router.ServeHTTP(recorder, req)
+
+			res := recorder.Result()
+			defer res.Body.Close()
+
+			content, err := io.ReadAll(res.Body)
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedStatus, recorder.Code, string(content))
+
+			if !tc.expectedBody {
+				return
+			}
+
+			got := &api.PersonalAccessTokenGenerated{}
+			require.NoError(t, json.Unmarshal(content, got))
+			assert.NoError(t, server.ValidatePATFormat(got.PlainToken))
+			assert.Equal(t, "ci", got.PersonalAccessToken.Name)
+			assert.Equal(t, testPATAdminID, got.PersonalAccessToken.CreatedBy)
+			assert.WithinDuration(t, time.Now().UTC().AddDate(0, 0, 30), got.PersonalAccessToken.ExpirationDate, time.Minute)
+		})
+	}
+}
+
+func TestPATAuthentication(t *testing.T) {
+	p := initPATTestData()
+	p.claimsExtractor = jwtclaims.NewClaimsExtractor()
+
+	authMiddleware := middleware.NewAuthMiddleware(
+		func(plainToken string) (jwtclaims.AuthorizationClaims, error) {
+			if plainToken == testPATBlockedToken {
+				return jwtclaims.AuthorizationClaims{}, status.Errorf(status.PermissionDenied, "user is blocked")
+			}
+			for _, user := range testingPATAccount.Users {
+				for _, pat := range user.PATs {
+					if pat.HashedToken == server.HashPAT(plainToken) {
+						return jwtclaims.AuthorizationClaims{UserId: user.Id, AccountId: testingPATAccount.Id}, nil
+					}
+				}
+			}
+			return jwtclaims.AuthorizationClaims{}, status.Errorf(status.Unauthorized, "invalid token")
+		},
+		middleware.New(),
+		"",
+		"",
+	)
+	accessControl := middleware.NewAccessControl("", "", func(claims jwtclaims.AuthorizationClaims) (bool, error) {
+		assert.True(t, claims.AuthenticatedByPAT, "the claims should be marked as authenticated by the token")
+		return testingPATAccount.Users[claims.UserId].IsAdmin(), nil
+	})
+
+	var authenticatedUserID string
+	p.accountManager.(*mock_server.MockAccountManager).DeletePATFunc = func(accountID, executingUserID, targetUserID, tokenID string) error {
+		authenticatedUserID = executingUserID
+		return nil
+	}
+
+	router := mux.NewRouter()
+	router.Use(authMiddleware.Handler, accessControl.Handler)
+	router.HandleFunc("/api/users/{userId}/tokens/{tokenId}", p.DeleteToken).Methods("DELETE")
+	router.HandleFunc("/api/ssh/policies", func(w http.ResponseWriter, r *http.Request) {}).Methods("POST")
+
+	tt := []struct {
+		name           string
+		requestType    string
+		requestPath    string
+		authorization  string
+		expectedStatus int
+	}{
+		{
+			name:           "Service User Deletes Own Token",
+			requestType:    http.MethodDelete,
+			requestPath:    "/api/users/" + testPATServiceUserID + "/tokens/" + testPATTokenID,
+			authorization:  "Token " + testPATPlainToken,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Service User Creates SSH Policy",
+			requestType:    http.MethodPost,
+			requestPath:    "/api/ssh/policies",
+			authorization:  "Token " + testPATPlainToken,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "Unknown Token",
+			requestType:    http.MethodDelete,
+			requestPath:    "/api/users/" + testPATServiceUserID + "/tokens/" + testPATTokenID,
+			authorization:  "Token nbp_unknown",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "Blocked User Token",
+			requestType:    http.MethodDelete,
+			requestPath:    "/api/users/" + testPATServiceUserID + "/tokens/" + testPATTokenID,
+			authorization:  "Token " + testPATBlockedToken,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "No Credentials",
+			requestType:    http.MethodDelete,
+			requestPath:    "/api/users/" + testPATServiceUserID + "/tokens/" + testPATTokenID,
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			authenticatedUserID = ""
+			req := httptest.NewRequest(tc.requestType, tc.requestPath, nil)
+			if tc.authorization != "" {
+				req.Header.Set("Authorization", tc.authorization)
+			}
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			assert.Equal(t, tc.expectedStatus, recorder.Code, recorder.Body.String())
+			if tc.expectedStatus == http.StatusOK {
+				assert.Equal(t, testPATServiceUserID, authenticatedUserID, "the request should be made by the token owner")
+			}
+		})
+	}
+}
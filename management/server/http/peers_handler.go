@@ -3,6 +3,7 @@ package http
 import (
 	"encoding/json"
 	"fmt"
+	"net"
 	"net/http"
 
 	"github.com/gorilla/mux"
@@ -51,6 +52,16 @@ func (h *PeersHandler) updatePeer(account *server.Account, user *server.User, pe
 
 	update := &server.Peer{ID: peerID, SSHEnabled: req.SshEnabled, Name: req.Name,
 		LoginExpirationEnabled: req.LoginExpirationEnabled}
+
+	if req.Ip != nil {
+		ip := net.ParseIP(*req.Ip)
+		if ip == nil {
+			util.WriteError(status.Errorf(status.InvalidArgument, "invalid peer IP %s", *req.Ip), w)
+			return
+		}
+		update.IP = ip
+	}
+
 	peer, err := h.accountManager.UpdatePeer(account.Id, user.Id, update)
 	if err != nil {
 		util.WriteError(err, w)
@@ -99,6 +110,52 @@ func (h *PeersHandler) HandlePeer(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// ApprovePeer approves a peer that is pending an admin approval
+func (h *PeersHandler) ApprovePeer(w http.ResponseWriter, r *http.Request) {
+	claims := h.claimsExtractor.FromRequestContext(r)
+	account, user, err := h.accountManager.GetAccountFromToken(claims)
+	if err != nil {
+		util.WriteError(err, w)
+		return
+	}
+	peerID := mux.Vars(r)["id"]
+	if len(peerID) == 0 {
+		util.WriteError(status.Errorf(status.InvalidArgument, "invalid peer ID"), w)
+		return
+	}
+
+	peer, err := h.accountManager.ApprovePeer(account.Id, user.Id, peerID)
+	if err != nil {
+		util.WriteError(err, w)
+		return
+	}
+
+	util.WriteJSONObject(w, toPeerResponse(peer, account, h.accountManager.GetDNSDomain()))
+}
+
+// RejectPeer rejects and deletes a peer that is pending an admin approval
+func (h *PeersHandler) RejectPeer(w http.ResponseWriter, r *http.Request) {
+	claims := h.claimsExtractor.FromRequestContext(r)
+	account, user, err := h.accountManager.GetAccountFromToken(claims)
+	if err != nil {
+		util.WriteError(err, w)
+		return
+	}
+	peerID := mux.Vars(r)["id"]
+	if len(peerID) == 0 {
+		util.WriteError(status.Errorf(status.InvalidArgument, "invalid peer ID"), w)
+		return
+	}
+
+	err = h.accountManager.RejectPeer(account.Id, user.Id, peerID)
+	if err != nil {
+		util.WriteError(err, w)
+		return
+	}
+
+	util.WriteJSONObject(w, "")
+}
+
 // GetAllPeers returns a list of all peers associated with a provided account
 func (h *PeersHandler) GetAllPeers(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
@@ -110,6 +167,12 @@ func (h *PeersHandler) GetAllPeers(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		statusFilter := r.URL.Query().Get("status")
+		if statusFilter != "" && statusFilter != string(api.GetApiPeersParamsStatusPending) {
+			util.WriteError(status.Errorf(status.InvalidArgument, "invalid peer status filter %s", statusFilter), w)
+			return
+		}
+
 		peers, err := h.accountManager.GetPeers(account.Id, user.Id)
 		if err != nil {
 			util.WriteError(err, w)
@@ -120,6 +183,9 @@ func (h *PeersHandler) GetAllPeers(w http.ResponseWriter, r *http.Request) {
 
 		respBody := []*api.Peer{}
 		for _, peer := range peers {
+			if statusFilter != "" && !peer.ApprovalRequired() {
+				continue
+			}
 			respBody = append(respBody, toPeerResponse(peer, account, dnsDomain))
 		}
 		util.WriteJSONObject(w, respBody)
@@ -156,6 +222,19 @@ func toPeerResponse(peer *server.Peer, account *server.Account, dnsDomain string
 		fqdn = peer.DNSLabel
 	}
 
+	var postureCheckFailures *[]api.PostureCheckFailure
+	if failures := account.GetPeerPostureCheckFailures(peer.ID); len(failures) > 0 {
+		apiFailures := make([]api.PostureCheckFailure, 0, len(failures))
+		for _, failure := range failures {
+			apiFailures = append(apiFailures, api.PostureCheckFailure{
+				Id:     failure.CheckID,
+				Name:   failure.CheckName,
+				Reason: failure.Reason,
+			})
+		}
+		postureCheckFailures = &apiFailures
+	}
+
 	return &api.Peer{
 		Id:                     peer.ID,
 		Name:                   peer.Name,
@@ -173,5 +252,7 @@ func toPeerResponse(peer *server.Peer, account *server.Account, dnsDomain string
 		LoginExpirationEnabled: peer.LoginExpirationEnabled,
 		LastLogin:              peer.LastLogin,
 		LoginExpired:           peer.Status.LoginExpired,
+		ApprovalRequired:       peer.Status.RequiresApproval,
+		PostureCheckFailures:   postureCheckFailures,
 	}
 }
@@ -189,3 +189,79 @@ func TestGetPeers(t *testing.T) {
 		})
 	}
 }
+
+func TestPendingPeers(t *testing.T) {
+	approvedPeer := &server.Peer{
+		ID:     testPeerID,
+		IP:     net.ParseIP("100.64.0.1"),
+		Status: &server.PeerStatus{},
+		Name:   "approved",
+	}
+	pendingPeer := &server.Peer{
+		ID:     "pending_peer",
+		IP:     net.ParseIP("100.64.0.2"),
+		Status: &server.PeerStatus{RequiresApproval: true},
+		Name:   "pending",
+	}
+
+	p := initTestMetaData(approvedPeer, pendingPeer)
+	var approvedID, rejectedID string
+	mock := p.accountManager.(*mock_server.MockAccountManager)
+	mock.ApprovePeerFunc = func(accountID, userID, peerID string) (*server.Peer, error) {
+		approvedID = peerID
+		peer := pendingPeer.Copy()
+		peer.Status = &server.PeerStatus{}
+		return peer, nil
+	}
+	mock.RejectPeerFunc = func(accountID, userID, peerID string) error {
+		rejectedID = peerID
+		return nil
+	}
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/peers", p.GetAllPeers).Methods("GET")
+	router.HandleFunc("/api/peers/{id}/approve", p.ApprovePeer).Methods("POST")
+	router.HandleFunc("/api/peers/{id}/reject", p.RejectPeer).Methods("POST")
+
+	listPeers := func(path string) (int, []*api.Peer) {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		var peers []*api.Peer
+		if recorder.Code == http.StatusOK {
+			if err := json.Unmarshal(recorder.Body.Bytes(), &peers); err != nil {
+				t.Fatalf("Sent content is not in correct json format; %v", err)
+			}
+		}
+		return recorder.Code, peers
+	}
+
+	code, peers := listPeers("/api/peers")
+	assert.Equal(t, code, http.StatusOK)
+	assert.Equal(t, len(peers), 2)
+
+	code, peers = listPeers("/api/peers?status=pending")
+	assert.Equal(t, code, http.StatusOK)
+	if len(peers) != 1 {
+		t.Fatalf("expected 1 pending peer, got %d", len(peers))
+	}
+	assert.Equal(t, peers[0].Id, pendingPeer.ID)
+	assert.Equal(t, peers[0].ApprovalRequired, true)
+
+	code, _ = listPeers("/api/peers?status=unknown")
+	assert.Equal(t, code, http.StatusUnprocessableEntity)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/api/peers/"+pendingPeer.ID+"/approve", nil))
+	assert.Equal(t, recorder.Code, http.StatusOK)
+	assert.Equal(t, approvedID, pendingPeer.ID)
+	got := &api.Peer{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), got); err != nil {
+		t.Fatalf("Sent content is not in correct json format; %v", err)
+	}
+	assert.Equal(t, got.ApprovalRequired, false)
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/api/peers/"+pendingPeer.ID+"/reject", nil))
+	assert.Equal(t, recorder.Code, http.StatusOK)
+	assert.Equal(t, rejectedID, pendingPeer.ID)
+}
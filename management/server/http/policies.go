@@ -85,56 +85,18 @@ func (h *Policies) UpdatePolicy(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if req.Name == "" {
-		util.WriteError(status.Errorf(status.InvalidArgument, "policy name shouldn't be empty"), w)
-		return
-	}
-
-	policy := server.Policy{
-		ID:          policyID,
-		Name:        req.Name,
-		Enabled:     req.Enabled,
-		Description: req.Description,
-		Query:       req.Query,
-	}
-	if req.Rules != nil {
-		for _, r := range req.Rules {
-			pr := server.PolicyRule{
-				Destinations: groupMinimumsToStrings(account, r.Destinations),
-				Sources:      groupMinimumsToStrings(account, r.Sources),
-				Name:         r.Name,
-			}
-			pr.Enabled = r.Enabled
-			if r.Description != nil {
-				pr.Description = *r.Description
-			}
-			if r.Id != nil {
-				pr.ID = *r.Id
-			}
-			switch r.Action {
-			case api.PolicyRuleActionAccept:
-				pr.Action = server.PolicyTrafficActionAccept
-			case api.PolicyRuleActionDrop:
-				pr.Action = server.PolicyTrafficActionDrop
-			default:
-				util.WriteError(status.Errorf(status.InvalidArgument, "unknown action type"), w)
-				return
-			}
-			policy.Rules = append(policy.Rules, &pr)
-		}
-	}
-	if err := policy.UpdateQueryFromRules(); err != nil {
-		log.Errorf("failed to update policy query: %v", err)
+	policy, err := toServerPolicy(account, policyID, req, true)
+	if err != nil {
 		util.WriteError(err, w)
 		return
 	}
 
-	if err = h.accountManager.SavePolicy(account.Id, user.Id, &policy); err != nil {
+	if err = h.accountManager.SavePolicy(account.Id, user.Id, policy); err != nil {
 		util.WriteError(err, w)
 		return
 	}
 
-	util.WriteJSONObject(w, toPolicyResponse(account, &policy))
+	util.WriteJSONObject(w, toPolicyResponse(account, policy))
 }
 
 // CreatePolicy handles policy creation request
@@ -153,44 +115,8 @@ func (h *Policies) CreatePolicy(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if req.Name == "" {
-		util.WriteError(status.Errorf(status.InvalidArgument, "policy name shouldn't be empty"), w)
-		return
-	}
-
-	policy := &server.Policy{
-		ID:          xid.New().String(),
-		Name:        req.Name,
-		Enabled:     req.Enabled,
-		Description: req.Description,
-		Query:       req.Query,
-	}
-
-	if req.Rules != nil {
-		for _, r := range req.Rules {
-			pr := server.PolicyRule{
-				ID:           xid.New().String(),
-				Destinations: groupMinimumsToStrings(account, r.Destinations),
-				Sources:      groupMinimumsToStrings(account, r.Sources),
-				Name:         r.Name,
-			}
-			pr.Enabled = r.Enabled
-			if r.Description != nil {
-				pr.Description = *r.Description
-			}
-			switch r.Action {
-			case api.PolicyRuleActionAccept:
-				pr.Action = server.PolicyTrafficActionAccept
-			case api.PolicyRuleActionDrop:
-				pr.Action = server.PolicyTrafficActionDrop
-			default:
-				util.WriteError(status.Errorf(status.InvalidArgument, "unknown action type"), w)
-				return
-			}
-			policy.Rules = append(policy.Rules, &pr)
-		}
-	}
-	if err := policy.UpdateQueryFromRules(); err != nil {
+	policy, err := toServerPolicy(account, xid.New().String(), req, false)
+	if err != nil {
 		util.WriteError(err, w)
 		return
 	}
@@ -258,6 +184,146 @@ func (h *Policies) GetPolicy(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// EvaluatePolicies handles a request to evaluate the policies between a source and a destination peer or group.
+// A POST request evaluates them together with the candidate policy of the request body without saving it
+func (h *Policies) EvaluatePolicies(w http.ResponseWriter, r *http.Request) {
+	claims := h.claimsExtractor.FromRequestContext(r)
+	account, user, err := h.accountManager.GetAccountFromToken(claims)
+	if err != nil {
+		util.WriteError(err, w)
+		return
+	}
+
+	params := r.URL.Query()
+	query := &server.PolicyEvaluationQuery{
+		SourcePeerID:       params.Get("source"),
+		SourceGroupID:      params.Get("source_group"),
+		DestinationPeerID:  params.Get("destination"),
+		DestinationGroupID: params.Get("destination_group"),
+	}
+
+	if r.Method == http.MethodPost {
+		var req api.PostApiPoliciesEvaluateJSONRequestBody
+		err = json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			util.WriteErrorResponse("couldn't parse JSON request", http.StatusBadRequest, w)
+			return
+		}
+
+		policyID := xid.New().String()
+		if req.PolicyId != nil && *req.PolicyId != "" {
+			policyID = *req.PolicyId
+		}
+
+		query.Policy, err = toServerPolicy(account, policyID, req.Policy, true)
+		if err != nil {
+			util.WriteError(err, w)
+			return
+		}
+	}
+
+	evaluations, err := h.accountManager.EvaluatePolicies(account.Id, user.Id, query)
+	if err != nil {
+		util.WriteError(err, w)
+		return
+	}
+
+	response := make([]*api.PolicyEvaluation, 0, len(evaluations))
+	for _, evaluation := range evaluations {
+		response = append(response, toPolicyEvaluationResponse(evaluation))
+	}
+
+	util.WriteJSONObject(w, response)
+}
+
+// toServerPolicy converts a policy request to a policy with the given ID. Rules keep the IDs of the request
+// if keepRuleIDs is set, new IDs are generated otherwise
+func toServerPolicy(account *server.Account, policyID string, req api.PolicyMinimum, keepRuleIDs bool) (*server.Policy, error) {
+	if req.Name == "" {
+		return nil, status.Errorf(status.InvalidArgument, "policy name shouldn't be empty")
+	}
+
+	policy := &server.Policy{
+		ID:          policyID,
+		Name:        req.Name,
+		Enabled:     req.Enabled,
+		Description: req.Description,
+		Query:       req.Query,
+	}
+	if req.PostureChecks != nil {
+		policy.PostureChecks = *req.PostureChecks
+	}
+	if req.Tests != nil {
+		policy.Tests = *req.Tests
+	}
+
+	for _, r := range req.Rules {
+		pr := server.PolicyRule{
+			ID:           xid.New().String(),
+			Destinations: groupMinimumsToStrings(account, r.Destinations),
+			Sources:      groupMinimumsToStrings(account, r.Sources),
+			Name:         r.Name,
+		}
+		pr.Enabled = r.Enabled
+		if r.Description != nil {
+			pr.Description = *r.Description
+		}
+		if keepRuleIDs && r.Id != nil {
+			pr.ID = *r.Id
+		}
+		switch r.Action {
+		case api.PolicyRuleActionAccept:
+			pr.Action = server.PolicyTrafficActionAccept
+		case api.PolicyRuleActionDrop:
+			pr.Action = server.PolicyTrafficActionDrop
+		default:
+			return nil, status.Errorf(status.InvalidArgument, "unknown action type")
+		}
+		policy.Rules = append(policy.Rules, &pr)
+	}
+
+	// policies without rules keep the custom Rego query of the request
+	if len(policy.Rules) > 0 {
+		if err := policy.UpdateQueryFromRules(); err != nil {
+			log.Errorf("failed to update policy query: %v", err)
+			return nil, err
+		}
+	}
+
+	return policy, nil
+}
+
+func toPolicyEvaluationResponse(evaluation *server.PolicyEvaluation) *api.PolicyEvaluation {
+	response := &api.PolicyEvaluation{
+		SourcePeerId:      evaluation.SourcePeerID,
+		DestinationPeerId: evaluation.DestinationPeerID,
+		Allowed:           evaluation.Allowed,
+		Matches:           make([]api.PolicyEvaluationMatch, 0, len(evaluation.Matches)),
+		FirewallRules:     make([]api.FirewallRule, 0, len(evaluation.FirewallRules)),
+	}
+	for _, m := range evaluation.Matches {
+		match := api.PolicyEvaluationMatch{
+			PolicyId:   m.Policy.ID,
+			PolicyName: m.Policy.Name,
+			RuleIds:    make([]string, 0, len(m.Rules)),
+		}
+		for _, rule := range m.Rules {
+			match.RuleIds = append(match.RuleIds, rule.ID)
+		}
+		response.Matches = append(response.Matches, match)
+	}
+	for _, rule := range evaluation.FirewallRules {
+		response.FirewallRules = append(response.FirewallRules, api.FirewallRule{
+			PeerId:    rule.PeerID,
+			PeerIp:    rule.PeerIP,
+			Direction: api.FirewallRuleDirection(rule.Direction),
+			Action:    rule.Action,
+			Port:      rule.Port,
+		})
+	}
+	return response
+}
+
 func toPolicyResponse(account *server.Account, policy *server.Policy) *api.Policy {
 	cache := make(map[string]api.GroupMinimum)
 	ap := &api.Policy{
@@ -267,6 +333,12 @@ func toPolicyResponse(account *server.Account, policy *server.Policy) *api.Polic
 		Enabled:     policy.Enabled,
 		Query:       policy.Query,
 	}
+	if len(policy.PostureChecks) > 0 {
+		ap.PostureChecks = &policy.PostureChecks
+	}
+	if policy.Tests != "" {
+		ap.Tests = &policy.Tests
+	}
 	if len(policy.Rules) == 0 {
 		return ap
 	}
@@ -0,0 +1,195 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/netbirdio/netbird/management/server"
+	"github.com/netbirdio/netbird/management/server/http/api"
+	"github.com/netbirdio/netbird/management/server/jwtclaims"
+	"github.com/netbirdio/netbird/management/server/mock_server"
+	"github.com/netbirdio/netbird/management/server/status"
+)
+
+func initPoliciesTestData(evaluations []*server.PolicyEvaluation) *Policies {
+	return &Policies{
+		accountManager: &mock_server.MockAccountManager{
+			GetAccountFromTokenFunc: func(claims jwtclaims.AuthorizationClaims) (*server.Account, *server.User, error) {
+				user := server.NewAdminUser("test_user")
+				return &server.Account{
+					Id:    claims.AccountId,
+					Users: map[string]*server.User{user.Id: user},
+				}, user, nil
+			},
+			EvaluatePoliciesFunc: func(accountID, userID string, query *server.PolicyEvaluationQuery) ([]*server.PolicyEvaluation, error) {
+				if query.SourcePeerID == "" && query.SourceGroupID == "" {
+					return nil, status.Errorf(status.InvalidArgument, "either a peer or a group should be provided")
+				}
+				return evaluations, nil
+			},
+		},
+		claimsExtractor: jwtclaims.NewClaimsExtractor(
+			jwtclaims.WithFromRequestContext(func(r *http.Request) jwtclaims.AuthorizationClaims {
+				return jwtclaims.AuthorizationClaims{
+					UserId:    "test_user",
+					Domain:    "hotmail.com",
+					AccountId: "test_id",
+				}
+			}),
+		),
+	}
+}
+
+func TestPolicies_EvaluatePolicies(t *testing.T) {
+	policy := &server.Policy{ID: "policy1", Name: "dev"}
+	evaluations := []*server.PolicyEvaluation{
+		{
+			SourcePeerID:      "peer1",
+			DestinationPeerID: "peer2",
+			Allowed:           true,
+			Matches: []*server.PolicyEvaluationMatch{
+				{
+					Policy: policy,
+					Rules:  []*server.PolicyRule{{ID: "rule1"}},
+				},
+			},
+			FirewallRules: []*server.FirewallRule{
+				{PeerID: "peer2", PeerIP: "10.20.0.2", Direction: "dst", Action: "accept"},
+			},
+		},
+	}
+	handler := initPoliciesTestData(evaluations)
+
+	tt := []struct {
+		name           string
+		requestPath    string
+		expectedStatus int
+		expectedBody   []*api.PolicyEvaluation
+	}{
+		{
+			name:           "Evaluate Peers",
+			requestPath:    "/api/policies/evaluate?source=peer1&destination=peer2",
+			expectedStatus: http.StatusOK,
+			expectedBody: []*api.PolicyEvaluation{
+				{
+					SourcePeerId:      "peer1",
+					DestinationPeerId: "peer2",
+					Allowed:           true,
+					Matches: []api.PolicyEvaluationMatch{
+						{
+							PolicyId:   "policy1",
+							PolicyName: "dev",
+							RuleIds:    []string{"rule1"},
+						},
+					},
+					FirewallRules: []api.FirewallRule{
+						{PeerId: "peer2", PeerIp: "10.20.0.2", Direction: api.FirewallRuleDirectionDst, Action: "accept"},
+					},
+				},
+			},
+		},
+		{
+			name:           "Evaluate Without Source",
+			requestPath:    "/api/policies/evaluate?destination=peer2",
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+	}
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/policies/evaluate", handler.EvaluatePolicies).Methods("GET")
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, tc.requestPath, nil))
+
+			assert.Equal(t, tc.expectedStatus, recorder.Code)
+			if tc.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var got []*api.PolicyEvaluation
+			if err := json.Unmarshal(recorder.Body.Bytes(), &got); err != nil {
+				t.Fatalf("Sent content is not in correct json format; %v", err)
+			}
+			assert.Equal(t, tc.expectedBody, got)
+		})
+	}
+}
+
+func TestPolicies_EvaluatePoliciesWithCandidate(t *testing.T) {
+	handler := initPoliciesTestData(nil)
+
+	var candidate *server.Policy
+	handler.accountManager.(*mock_server.MockAccountManager).EvaluatePoliciesFunc = func(accountID, userID string, query *server.PolicyEvaluationQuery) ([]*server.PolicyEvaluation, error) {
+		candidate = query.Policy
+		return nil, nil
+	}
+
+	tt := []struct {
+		name             string
+		requestBody      string
+		expectedStatus   int
+		expectedPolicyID string
+	}{
+		{
+			name:             "Replace Policy",
+			requestBody:      `{"policy_id":"policy1","policy":{"name":"dev","enabled":false,"query":"","rules":[]}}`,
+			expectedStatus:   http.StatusOK,
+			expectedPolicyID: "policy1",
+		},
+		{
+			name:           "Add Policy",
+			requestBody:    `{"policy":{"name":"dev","enabled":true,"query":"","rules":[]}}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Candidate Without Name",
+			requestBody:    `{"policy":{"name":"","enabled":true,"query":"","rules":[]}}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "Invalid Body",
+			requestBody:    `{`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/policies/evaluate", handler.EvaluatePolicies).Methods("GET", "POST")
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			candidate = nil
+			recorder := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/api/policies/evaluate?source=peer1&destination=peer2", bytes.NewBufferString(tc.requestBody))
+			router.ServeHTTP(recorder, req)
+
+			assert.Equal(t, tc.expectedStatus, recorder.Code, recorder.Body.String())
+			if tc.expectedStatus != http.StatusOK {
+				assert.Nil(t, candidate, "invalid candidates should not be evaluated")
+				return
+			}
+
+			if assert.NotNil(t, candidate, "the candidate should be passed to the evaluation") {
+				assert.Equal(t, "dev", candidate.Name)
+				if tc.expectedPolicyID != "" {
+					assert.Equal(t, tc.expectedPolicyID, candidate.ID)
+				} else {
+					assert.NotEmpty(t, candidate.ID, "a new candidate should get an ID")
+				}
+			}
+		})
+	}
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/policies/evaluate?source=peer1&destination=peer2", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Nil(t, candidate, "a GET request should evaluate the account policies only")
+}
@@ -0,0 +1,217 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/xid"
+
+	"github.com/netbirdio/netbird/management/server"
+	"github.com/netbirdio/netbird/management/server/http/api"
+	"github.com/netbirdio/netbird/management/server/http/util"
+	"github.com/netbirdio/netbird/management/server/jwtclaims"
+	"github.com/netbirdio/netbird/management/server/status"
+)
+
+// PostureChecksHandler is the posture checks handler of the account
+type PostureChecksHandler struct {
+	accountManager  server.AccountManager
+	claimsExtractor *jwtclaims.ClaimsExtractor
+}
+
+// NewPostureChecksHandler returns a new instance of PostureChecksHandler handler
+func NewPostureChecksHandler(accountManager server.AccountManager, authCfg AuthCfg) *PostureChecksHandler {
+	return &PostureChecksHandler{
+		accountManager: accountManager,
+		claimsExtractor: jwtclaims.NewClaimsExtractor(
+			jwtclaims.WithAudience(authCfg.Audience),
+			jwtclaims.WithUserIDClaim(authCfg.UserIDClaim),
+		),
+	}
+}
+
+// GetAllPostureChecks returns the list of posture checks for the account
+func (h *PostureChecksHandler) GetAllPostureChecks(w http.ResponseWriter, r *http.Request) {
+	claims := h.claimsExtractor.FromRequestContext(r)
+	account, user, err := h.accountManager.GetAccountFromToken(claims)
+	if err != nil {
+		util.WriteError(err, w)
+		return
+	}
+
+	checks, err := h.accountManager.ListPostureChecks(account.Id, user.Id)
+	if err != nil {
+		util.WriteError(err, w)
+		return
+	}
+
+	apiChecks := make([]*api.PostureCheck, 0, len(checks))
+	for _, check := range checks {
+		apiChecks = append(apiChecks, toPostureCheckResponse(check))
+	}
+
+	util.WriteJSONObject(w, apiChecks)
+}
+
+// CreatePostureCheck handles posture check creation request
+func (h *PostureChecksHandler) CreatePostureCheck(w http.ResponseWriter, r *http.Request) {
+	claims := h.claimsExtractor.FromRequestContext(r)
+	account, user, err := h.accountManager.GetAccountFromToken(claims)
+	if err != nil {
+		util.WriteError(err, w)
+		return
+	}
+
+	var req api.PostApiPostureChecksJSONRequestBody
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		util.WriteErrorResponse("couldn't parse JSON request", http.StatusBadRequest, w)
+		return
+	}
+
+	check := toServerPostureCheck(xid.New().String(), req)
+
+	err = h.accountManager.SavePostureCheck(account.Id, user.Id, check)
+	if err != nil {
+		util.WriteError(err, w)
+		return
+	}
+
+	util.WriteJSONObject(w, toPostureCheckResponse(check))
+}
+
+// UpdatePostureCheck handles update to a posture check identified by a given ID
+func (h *PostureChecksHandler) UpdatePostureCheck(w http.ResponseWriter, r *http.Request) {
+	claims := h.claimsExtractor.FromRequestContext(r)
+	account, user, err := h.accountManager.GetAccountFromToken(claims)
+	if err != nil {
+		util.WriteError(err, w)
+		return
+	}
+
+	checkID := mux.Vars(r)["id"]
+	if len(checkID) == 0 {
+		util.WriteError(status.Errorf(status.InvalidArgument, "invalid posture check ID"), w)
+		return
+	}
+
+	_, err = h.accountManager.GetPostureCheck(account.Id, checkID, user.Id)
+	if err != nil {
+		util.WriteError(err, w)
+		return
+	}
+
+	var req api.PutApiPostureChecksIdJSONRequestBody
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		util.WriteErrorResponse("couldn't parse JSON request", http.StatusBadRequest, w)
+		return
+	}
+
+	check := toServerPostureCheck(checkID, req)
+
+	err = h.accountManager.SavePostureCheck(account.Id, user.Id, check)
+	if err != nil {
+		util.WriteError(err, w)
+		return
+	}
+
+	util.WriteJSONObject(w, toPostureCheckResponse(check))
+}
+
+// DeletePostureCheck handles posture check deletion request
+func (h *PostureChecksHandler) DeletePostureCheck(w http.ResponseWriter, r *http.Request) {
+	claims := h.claimsExtractor.FromRequestContext(r)
+	account, user, err := h.accountManager.GetAccountFromToken(claims)
+	if err != nil {
+		util.WriteError(err, w)
+		return
+	}
+
+	checkID := mux.Vars(r)["id"]
+	if len(checkID) == 0 {
+		util.WriteError(status.Errorf(status.InvalidArgument, "invalid posture check ID"), w)
+		return
+	}
+
+	err = h.accountManager.DeletePostureCheck(account.Id, checkID, user.Id)
+	if err != nil {
+		util.WriteError(err, w)
+		return
+	}
+
+	util.WriteJSONObject(w, "")
+}
+
+// GetPostureCheck handles a posture check Get request identified by ID
+func (h *PostureChecksHandler) GetPostureCheck(w http.ResponseWriter, r *http.Request) {
+	claims := h.claimsExtractor.FromRequestContext(r)
+	account, user, err := h.accountManager.GetAccountFromToken(claims)
+	if err != nil {
+		util.WriteError(err, w)
+		return
+	}
+
+	checkID := mux.Vars(r)["id"]
+	if len(checkID) == 0 {
+		util.WriteError(status.Errorf(status.InvalidArgument, "invalid posture check ID"), w)
+		return
+	}
+
+	check, err := h.accountManager.GetPostureCheck(account.Id, checkID, user.Id)
+	if err != nil {
+		util.WriteError(err, w)
+		return
+	}
+
+	util.WriteJSONObject(w, toPostureCheckResponse(check))
+}
+
+func toServerPostureCheck(checkID string, req api.PostureCheckRequest) *server.PostureCheck {
+	check := &server.PostureCheck{
+		ID:          checkID,
+		Name:        req.Name,
+		Description: req.Description,
+	}
+	if req.MinVersion != nil {
+		check.MinVersion = *req.MinVersion
+	}
+	if req.AllowedOs != nil {
+		check.AllowedOS = *req.AllowedOs
+	}
+	if req.AllowedPlatforms != nil {
+		check.AllowedPlatforms = *req.AllowedPlatforms
+	}
+	if req.MinKernelVersion != nil {
+		check.MinKernelVersion = *req.MinKernelVersion
+	}
+	if req.HostnamePatterns != nil {
+		check.HostnamePatterns = *req.HostnamePatterns
+	}
+	return check
+}
+
+func toPostureCheckResponse(check *server.PostureCheck) *api.PostureCheck {
+	apiCheck := &api.PostureCheck{
+		Id:          check.ID,
+		Name:        check.Name,
+		Description: check.Description,
+	}
+	if check.MinVersion != "" {
+		apiCheck.MinVersion = &check.MinVersion
+	}
+	if len(check.AllowedOS) > 0 {
+		apiCheck.AllowedOs = &check.AllowedOS
+	}
+	if len(check.AllowedPlatforms) > 0 {
+		apiCheck.AllowedPlatforms = &check.AllowedPlatforms
+	}
+	if check.MinKernelVersion != "" {
+		apiCheck.MinKernelVersion = &check.MinKernelVersion
+	}
+	if len(check.HostnamePatterns) > 0 {
+		apiCheck.HostnamePatterns = &check.HostnamePatterns
+	}
+	return apiCheck
+}
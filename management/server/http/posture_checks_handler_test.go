@@ -0,0 +1,205 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/netbirdio/netbird/management/server"
+	"github.com/netbirdio/netbird/management/server/http/api"
+	"github.com/netbirdio/netbird/management/server/jwtclaims"
+	"github.com/netbirdio/netbird/management/server/mock_server"
+	"github.com/netbirdio/netbird/management/server/status"
+)
+
+const (
+	testPostureChecksAccountID = "test_id"
+	testPostureChecksUserID    = "test_user"
+	existingPostureCheckID     = "existingCheck"
+	notFoundPostureCheckID     = "notFoundCheck"
+)
+
+var baseExistingPostureCheck = &server.PostureCheck{
+	ID:          existingPostureCheckID,
+	Name:        "linux",
+	Description: "only up to date linux peers",
+	MinVersion:  "0.21.0",
+	AllowedOS:   []string{"linux"},
+}
+
+var testingPostureChecksAccount = &server.Account{
+	Id:     testPostureChecksAccountID,
+	Domain: "hotmail.com",
+	Users: map[string]*server.User{
+		testPostureChecksUserID: server.NewAdminUser(testPostureChecksUserID),
+	},
+}
+
+func initPostureChecksTestData() *PostureChecksHandler {
+	return &PostureChecksHandler{
+		accountManager: &mock_server.MockAccountManager{
+			GetPostureCheckFunc: func(_, checkID, _ string) (*server.PostureCheck, error) {
+				if checkID == existingPostureCheckID {
+					return baseExistingPostureCheck.Copy(), nil
+				}
+				return nil, status.Errorf(status.NotFound, "posture check with ID %s not found", checkID)
+			},
+			SavePostureCheckFunc: func(_, _ string, checkToSave *server.PostureCheck) error {
+				if checkToSave.MinVersion == "" && len(checkToSave.AllowedOS) == 0 &&
+					len(checkToSave.AllowedPlatforms) == 0 && checkToSave.MinKernelVersion == "" &&
+					len(checkToSave.HostnamePatterns) == 0 {
+					return status.Errorf(status.InvalidArgument, "posture check should define at least one requirement")
+				}
+				return nil
+			},
+			ListPostureChecksFunc: func(_, _ string) ([]*server.PostureCheck, error) {
+				return []*server.PostureCheck{baseExistingPostureCheck.Copy()}, nil
+			},
+			DeletePostureCheckFunc: func(_, checkID, _ string) error {
+				if checkID == existingPostureCheckID {
+					return nil
+				}
+				return status.Errorf(status.NotFound, "posture check with ID %s not found", checkID)
+			},
+			GetAccountFromTokenFunc: func(_ jwtclaims.AuthorizationClaims) (*server.Account, *server.User, error) {
+				return testingPostureChecksAccount, testingPostureChecksAccount.Users[testPostureChecksUserID], nil
+			},
+		},
+		claimsExtractor: jwtclaims.NewClaimsExtractor(
+			jwtclaims.WithFromRequestContext(func(r *http.Request) jwtclaims.AuthorizationClaims {
+				return jwtclaims.AuthorizationClaims{
+					UserId:    testPostureChecksUserID,
+					Domain:    "hotmail.com",
+					AccountId: testPostureChecksAccountID,
+				}
+			}),
+		),
+	}
+}
+
+func TestPostureChecksHandlers(t *testing.T) {
+	minKernel := "5.15"
+	hostnamePatterns := []string{"prod-*"}
+
+	tt := []struct {
+		name           string
+		requestType    string
+		requestPath    string
+		requestBody    io.Reader
+		expectedStatus int
+		expectedCheck  *api.PostureCheck
+	}{
+		{
+			name:           "Get Existing Check",
+			requestType:    http.MethodGet,
+			requestPath:    "/api/posture-checks/" + existingPostureCheckID,
+			expectedStatus: http.StatusOK,
+			expectedCheck:  toPostureCheckResponse(baseExistingPostureCheck),
+		},
+		{
+			name:           "Get Not Existing Check",
+			requestType:    http.MethodGet,
+			requestPath:    "/api/posture-checks/" + notFoundPostureCheckID,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:        "Create Check",
+			requestType: http.MethodPost,
+			requestPath: "/api/posture-checks",
+			requestBody: bytes.NewBufferString(`{"name":"servers","description":"",` +
+				`"min_kernel_version":"5.15","hostname_patterns":["prod-*"]}`),
+			expectedStatus: http.StatusOK,
+			expectedCheck: &api.PostureCheck{
+				Name:             "servers",
+				MinKernelVersion: &minKernel,
+				HostnamePatterns: &hostnamePatterns,
+			},
+		},
+		{
+			name:           "Create Check Without Requirements",
+			requestType:    http.MethodPost,
+			requestPath:    "/api/posture-checks",
+			requestBody:    bytes.NewBufferString(`{"name":"servers","description":""}`),
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:        "Update Check",
+			requestType: http.MethodPut,
+			requestPath: "/api/posture-checks/" + existingPostureCheckID,
+			requestBody: bytes.NewBufferString(`{"name":"linux","description":"",` +
+				`"hostname_patterns":["prod-*"]}`),
+			expectedStatus: http.StatusOK,
+			expectedCheck: &api.PostureCheck{
+				Id:               existingPostureCheckID,
+				Name:             "linux",
+				HostnamePatterns: &hostnamePatterns,
+			},
+		},
+		{
+			name:        "Update Not Existing Check",
+			requestType: http.MethodPut,
+			requestPath: "/api/posture-checks/" + notFoundPostureCheckID,
+			requestBody: bytes.NewBufferString(`{"name":"linux","description":"",` +
+				`"hostname_patterns":["prod-*"]}`),
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "Delete Check",
+			requestType:    http.MethodDelete,
+			requestPath:    "/api/posture-checks/" + existingPostureCheckID,
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	p := initPostureChecksTestData()
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			req := httptest.NewRequest(tc.requestType, tc.requestPath, tc.requestBody)
+
+			router := mux.NewRouter()
+			router.HandleFunc("/api/posture-checks", p.GetAllPostureChecks).Methods("GET")
+			router.HandleFunc("/api/posture-checks", p.CreatePostureCheck).Methods("POST")
+			router.HandleFunc("/api/posture-checks/{id}", p.GetPostureCheck).Methods("GET")
+			router.HandleFunc("/api/posture-checks/{id}", p.UpdatePostureCheck).Methods("PUT")
+			router.HandleFunc("/api/posture-checks/{id}", p.DeletePostureCheck).Methods("DELETE")
+			router.ServeHTTP(recorder, req)
+
+			res := recorder.Result()
+			defer res.Body.Close()
+
+			content, err := io.ReadAll(res.Body)
+			if err != nil {
+				t.Fatalf("I don't know what I expected; %v", err)
+			}
+
+			if status := recorder.Code; status != tc.expectedStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v, content: %s",
+					status, tc.expectedStatus, string(content))
+			}
+
+			if tc.expectedCheck == nil {
+				return
+			}
+
+			got := &api.PostureCheck{}
+			if err = json.Unmarshal(content, got); err != nil {
+				t.Fatalf("Sent content is not in correct json format; %v", err)
+			}
+
+			// IDs of created checks are generated by the handler
+			if tc.requestType == http.MethodPost {
+				assert.NotEmpty(t, got.Id)
+				got.Id = ""
+			}
+			assert.Equal(t, tc.expectedCheck, got)
+		})
+	}
+}
@@ -64,8 +64,18 @@ func (h *SetupKeysHandler) CreateSetupKey(w http.ResponseWriter, r *http.Request
 		req.AutoGroups = []string{}
 	}
 
+	var ipPool string
+	if req.IpPool != nil {
+		ipPool = *req.IpPool
+	}
+
+	var ephemeral bool
+	if req.Ephemeral != nil {
+		ephemeral = *req.Ephemeral
+	}
+
 	setupKey, err := h.accountManager.CreateSetupKey(account.Id, req.Name, server.SetupKeyType(req.Type), expiresIn,
-		req.AutoGroups, req.UsageLimit, user.Id)
+		req.AutoGroups, req.UsageLimit, ipPool, ephemeral, user.Id)
 	if err != nil {
 		util.WriteError(err, w)
 		return
@@ -137,6 +147,17 @@ func (h *SetupKeysHandler) UpdateSetupKey(w http.ResponseWriter, r *http.Request
 	newKey.Revoked = req.Revoked
 	newKey.Name = req.Name
 	newKey.Id = keyID
+	if req.IpPool != nil {
+		newKey.IPPool = *req.IpPool
+	} else {
+		// keep the current IP pool when the request doesn't change it
+		for _, key := range account.SetupKeys {
+			if key.Id == keyID {
+				newKey.IPPool = key.IPPool
+				break
+			}
+		}
+	}
 
 	newKey, err = h.accountManager.SaveSetupKey(account.Id, newKey, user.Id)
 	if err != nil {
@@ -191,6 +212,11 @@ func toResponseBody(key *server.SetupKey) *api.SetupKey {
 		state = "valid"
 	}
 
+	var ipPool *string
+	if key.IPPool != "" {
+		ipPool = &key.IPPool
+	}
+
 	return &api.SetupKey{
 		Id:         key.Id,
 		Key:        key.Key,
@@ -205,5 +231,7 @@ func toResponseBody(key *server.SetupKey) *api.SetupKey {
 		AutoGroups: key.AutoGroups,
 		UpdatedAt:  key.UpdatedAt,
 		UsageLimit: key.UsageLimit,
+		IpPool:     ipPool,
+		Ephemeral:  key.Ephemeral,
 	}
 }
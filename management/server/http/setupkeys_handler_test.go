@@ -51,7 +51,7 @@ func initSetupKeysTestMetaData(defaultKey *server.SetupKey, newKey *server.Setup
 				}, user, nil
 			},
 			CreateSetupKeyFunc: func(_ string, keyName string, typ server.SetupKeyType, _ time.Duration, _ []string,
-				_ int, _ string,
+				_ int, _ string, _ bool, _ string,
 			) (*server.SetupKey, error) {
 				if keyName == newKey.Name || typ != newKey.Type {
 					return newKey, nil
@@ -230,3 +230,48 @@ func assertKeys(t *testing.T, got *api.SetupKey, expected *api.SetupKey) {
 	assert.Equal(t, got.Revoked, expected.Revoked)
 	assert.ElementsMatch(t, got.AutoGroups, expected.AutoGroups)
 }
+
+func TestUpdateSetupKeyKeepsIPPool(t *testing.T) {
+	defaultSetupKey := server.GenerateDefaultSetupKey()
+	defaultSetupKey.Id = existingSetupKeyID
+	defaultSetupKey.IPPool = "100.64.10.0/24"
+
+	handler := initSetupKeysTestMetaData(defaultSetupKey, defaultSetupKey, defaultSetupKey, server.NewAdminUser("test_user"))
+
+	var savedKey *server.SetupKey
+	handler.accountManager.(*mock_server.MockAccountManager).SaveSetupKeyFunc = func(accountID string, key *server.SetupKey, _ string) (*server.SetupKey, error) {
+		savedKey = key
+		return key, nil
+	}
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/setup-keys/{id}", handler.UpdateSetupKey).Methods("PUT", "OPTIONS")
+
+	tt := []struct {
+		name           string
+		requestBody    string
+		expectedIPPool string
+	}{
+		{
+			name:           "IP Pool Is Kept When Not Provided",
+			requestBody:    `{"name":"renamed","auto_groups":[],"revoked":false}`,
+			expectedIPPool: defaultSetupKey.IPPool,
+		},
+		{
+			name:           "IP Pool Is Cleared When Empty",
+			requestBody:    `{"name":"renamed","auto_groups":[],"revoked":false,"ip_pool":""}`,
+			expectedIPPool: "",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPut, "/api/setup-keys/"+existingSetupKeyID, bytes.NewBufferString(tc.requestBody))
+			router.ServeHTTP(recorder, req)
+
+			assert.Equal(t, http.StatusOK, recorder.Code)
+			assert.Equal(t, tc.expectedIPPool, savedKey.IPPool)
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/netbirdio/netbird/management/server"
+	"github.com/netbirdio/netbird/management/server/http/api"
+	"github.com/netbirdio/netbird/management/server/http/util"
+	"github.com/netbirdio/netbird/management/server/jwtclaims"
+)
+
+// SSHCertificatesHandler is the handler of the SSH certificates issued by the account SSH certificate authority
+type SSHCertificatesHandler struct {
+	accountManager  server.AccountManager
+	claimsExtractor *jwtclaims.ClaimsExtractor
+}
+
+// NewSSHCertificatesHandler returns a new instance of SSHCertificatesHandler handler
+func NewSSHCertificatesHandler(accountManager server.AccountManager, authCfg AuthCfg) *SSHCertificatesHandler {
+	return &SSHCertificatesHandler{
+		accountManager: accountManager,
+		claimsExtractor: jwtclaims.NewClaimsExtractor(
+			jwtclaims.WithAudience(authCfg.Audience),
+			jwtclaims.WithUserIDClaim(authCfg.UserIDClaim),
+		),
+	}
+}
+
+// IssueSSHCertificate signs the SSH public key of the authenticated user
+func (h *SSHCertificatesHandler) IssueSSHCertificate(w http.ResponseWriter, r *http.Request) {
+	claims := h.claimsExtractor.FromRequestContext(r)
+	account, user, err := h.accountManager.GetAccountFromToken(claims)
+	if err != nil {
+		util.WriteError(err, w)
+		return
+	}
+
+	var req api.PostApiSshCertificatesJSONRequestBody
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		util.WriteErrorResponse("couldn't parse JSON request", http.StatusBadRequest, w)
+		return
+	}
+
+	certReq := &server.SSHCertificateRequest{
+		PublicKey:  req.PublicKey,
+		Principals: req.Principals,
+	}
+	if req.ValiditySeconds != nil {
+		if *req.ValiditySeconds <= 0 {
+			util.WriteErrorResponse("validity_seconds should be positive", http.StatusUnprocessableEntity, w)
+			return
+		}
+		certReq.Validity = time.Duration(*req.ValiditySeconds) * time.Second
+	}
+
+	cert, err := h.accountManager.IssueSSHCertificate(account.Id, user.Id, certReq)
+	if err != nil {
+		util.WriteError(err, w)
+		return
+	}
+
+	util.WriteJSONObject(w, toSSHCertificateResponse(cert))
+}
+
+// GetSSHCertificateAuthority returns the public key of the account SSH certificate authority
+func (h *SSHCertificatesHandler) GetSSHCertificateAuthority(w http.ResponseWriter, r *http.Request) {
+	claims := h.claimsExtractor.FromRequestContext(r)
+	account, user, err := h.accountManager.GetAccountFromToken(claims)
+	if err != nil {
+		util.WriteError(err, w)
+		return
+	}
+
+	publicKey, err := h.accountManager.GetSSHCertificateAuthority(account.Id, user.Id)
+	if err != nil {
+		util.WriteError(err, w)
+		return
+	}
+
+	util.WriteJSONObject(w, &api.SSHCertificateAuthority{PublicKey: publicKey})
+}
+
+func toSSHCertificateResponse(cert *server.SSHCertificate) *api.SSHCertificate {
+	return &api.SSHCertificate{
+		Certificate: cert.Certificate,
+		Serial:      strconv.FormatUint(cert.Serial, 10),
+		KeyId:       cert.KeyID,
+		Principals:  cert.Principals,
+		ValidAfter:  cert.ValidAfter,
+		ValidBefore: cert.ValidBefore,
+	}
+}
@@ -0,0 +1,214 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/netbirdio/netbird/management/server"
+	"github.com/netbirdio/netbird/management/server/http/api"
+	"github.com/netbirdio/netbird/management/server/http/middleware"
+	"github.com/netbirdio/netbird/management/server/jwtclaims"
+	"github.com/netbirdio/netbird/management/server/mock_server"
+	"github.com/netbirdio/netbird/management/server/status"
+)
+
+const (
+	testSSHCertificatesAccountID = "test_id"
+	testSSHCertificatesUserID    = "test_user"
+	testSSHCertificateAuthority  = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIJ2mUGB6JzWzXhLOvt0ZW2KsBiDnHTUmWJxbrvm+g0qe"
+)
+
+var testingSSHCertificatesAccount = &server.Account{
+	Id:     testSSHCertificatesAccountID,
+	Domain: "hotmail.com",
+	Users: map[string]*server.User{
+		testSSHCertificatesUserID: server.NewRegularUser(testSSHCertificatesUserID),
+	},
+}
+
+func initSSHCertificatesTestData(validAfter time.Time) *SSHCertificatesHandler {
+	return &SSHCertificatesHandler{
+		accountManager: &mock_server.MockAccountManager{
+			IssueSSHCertificateFunc: func(_, userID string, req *server.SSHCertificateRequest) (*server.SSHCertificate, error) {
+				if len(req.Principals) == 0 {
+					return nil, status.Errorf(status.InvalidArgument, "SSH certificate principals should not be empty")
+				}
+				validity := req.Validity
+				if validity == 0 {
+					validity = server.DefaultSSHCertificateValidity
+				}
+				return &server.SSHCertificate{
+					Certificate: "ssh-ed25519-cert-v01@openssh.com AAAA",
+					Serial:      18446744073709551615,
+					KeyID:       userID,
+					Principals:  req.Principals,
+					ValidAfter:  validAfter,
+					ValidBefore: validAfter.Add(validity),
+				}, nil
+			},
+			GetSSHCertificateAuthorityFunc: func(_, _ string) (string, error) {
+				return testSSHCertificateAuthority, nil
+			},
+			GetAccountFromTokenFunc: func(_ jwtclaims.AuthorizationClaims) (*server.Account, *server.User, error) {
+				return testingSSHCertificatesAccount, testingSSHCertificatesAccount.Users[testSSHCertificatesUserID], nil
+			},
+		},
+		claimsExtractor: jwtclaims.NewClaimsExtractor(
+			jwtclaims.WithFromRequestContext(func(r *http.Request) jwtclaims.AuthorizationClaims {
+				return jwtclaims.AuthorizationClaims{
+					UserId:    testSSHCertificatesUserID,
+					Domain:    "hotmail.com",
+					AccountId: testSSHCertificatesAccountID,
+				}
+			}),
+		),
+	}
+}
+
+func TestSSHCertificatesHandlers(t *testing.T) {
+	validAfter := time.Date(2023, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	tt := []struct {
+		name           string
+		requestType    string
+		requestPath    string
+		requestBody    io.Reader
+		expectedStatus int
+		expectedBody   interface{}
+	}{
+		{
+			name:        "Issue Certificate",
+			requestType: http.MethodPost,
+			requestPath: "/api/ssh/certificates",
+			requestBody: bytes.NewBufferString(`{"public_key":"ssh-ed25519 AAAA","principals":["deploy"],` +
+				`"validity_seconds":600}`),
+			expectedStatus: http.StatusOK,
+			expectedBody: &api.SSHCertificate{
+				Certificate: "ssh-ed25519-cert-v01@openssh.com AAAA",
+				Serial:      "18446744073709551615",
+				KeyId:       testSSHCertificatesUserID,
+				Principals:  []string{"deploy"},
+				ValidAfter:  validAfter,
+				ValidBefore: validAfter.Add(10 * time.Minute),
+			},
+		},
+		{
+			name:           "Issue Certificate With Negative Validity",
+			requestType:    http.MethodPost,
+			requestPath:    "/api/ssh/certificates",
+			requestBody:    bytes.NewBufferString(`{"public_key":"ssh-ed25519 AAAA","principals":["deploy"],"validity_seconds":-1}`),
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "Issue Certificate Without Principals",
+			requestType:    http.MethodPost,
+			requestPath:    "/api/ssh/certificates",
+			requestBody:    bytes.NewBufferString(`{"public_key":"ssh-ed25519 AAAA","principals":[]}`),
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "Get Certificate Authority",
+			requestType:    http.MethodGet,
+			requestPath:    "/api/ssh/ca",
+			expectedStatus: http.StatusOK,
+			expectedBody:   &api.SSHCertificateAuthority{PublicKey: testSSHCertificateAuthority},
+		},
+	}
+
+	h := initSSHCertificatesTestData(validAfter)
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			req := httptest.NewRequest(tc.requestType, tc.requestPath, tc.requestBody)
+
+			router := mux.NewRouter()
+			router.HandleFunc("/api/ssh/certificates", h.IssueSSHCertificate).Methods("POST")
+			router.HandleFunc("/api/ssh/ca", h.GetSSHCertificateAuthority).Methods("GET")
+			router.ServeHTTP(recorder, req)
+
+			res := recorder.Result()
+			defer res.Body.Close()
+
+			content, err := io.ReadAll(res.Body)
+			if err != nil {
+				t.Fatalf("I don't know what I expected; %v", err)
+			}
+
+			if status := recorder.Code; status != tc.expectedStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v, content: %s",
+					status, tc.expectedStatus, string(content))
+			}
+
+			switch expected := tc.expectedBody.(type) {
+			case *api.SSHCertificate:
+				got := &api.SSHCertificate{}
+				if err = json.Unmarshal(content, got); err != nil {
+					t.Fatalf("Sent content is not in correct json format; %v", err)
+				}
+				assert.Equal(t, expected, got)
+			case *api.SSHCertificateAuthority:
+				got := &api.SSHCertificateAuthority{}
+				if err = json.Unmarshal(content, got); err != nil {
+					t.Fatalf("Sent content is not in correct json format; %v", err)
+				}
+				assert.Equal(t, expected, got)
+			}
+		})
+	}
+}
+
+func TestSSHCertificatesHandler_NonAdminThroughAccessControl(t *testing.T) {
+	h := initSSHCertificatesTestData(time.Now().UTC())
+	accessControl := middleware.NewAccessControl("", "", func(claims jwtclaims.AuthorizationClaims) (bool, error) {
+		return testingSSHCertificatesAccount.Users[claims.UserId].IsAdmin(), nil
+	})
+
+	router := mux.NewRouter()
+	router.Use(accessControl.Handler)
+	router.HandleFunc("/api/ssh/certificates", h.IssueSSHCertificate).Methods("POST")
+	router.HandleFunc("/api/ssh/policies", func(w http.ResponseWriter, r *http.Request) {}).Methods("POST")
+
+	tt := []struct {
+		name           string
+		requestPath    string
+		requestBody    string
+		expectedStatus int
+	}{
+		{
+			name:           "Regular User Issues Certificate",
+			requestPath:    "/api/ssh/certificates",
+			requestBody:    `{"public_key":"ssh-ed25519 AAAA","principals":["deploy"]}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Regular User Creates SSH Policy",
+			requestPath:    "/api/ssh/policies",
+			requestBody:    `{}`,
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			token := &jwt.Token{Claims: jwt.MapClaims{jwtclaims.UserIDClaim: testSSHCertificatesUserID}}
+			req := httptest.NewRequest(http.MethodPost, tc.requestPath, bytes.NewBufferString(tc.requestBody))
+			req = req.WithContext(context.WithValue(req.Context(), jwtclaims.TokenUserProperty, token)) //nolint
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			assert.Equal(t, tc.expectedStatus, recorder.Code, recorder.Body.String())
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/xid"
+
+	"github.com/netbirdio/netbird/management/server"
+	"github.com/netbirdio/netbird/management/server/http/api"
+	"github.com/netbirdio/netbird/management/server/http/util"
+	"github.com/netbirdio/netbird/management/server/jwtclaims"
+	"github.com/netbirdio/netbird/management/server/status"
+)
+
+// SSHPoliciesHandler is the SSH access policies handler of the account
+type SSHPoliciesHandler struct {
+	accountManager  server.AccountManager
+	claimsExtractor *jwtclaims.ClaimsExtractor
+}
+
+// NewSSHPoliciesHandler returns a new instance of SSHPoliciesHandler handler
+func NewSSHPoliciesHandler(accountManager server.AccountManager, authCfg AuthCfg) *SSHPoliciesHandler {
+	return &SSHPoliciesHandler{
+		accountManager: accountManager,
+		claimsExtractor: jwtclaims.NewClaimsExtractor(
+			jwtclaims.WithAudience(authCfg.Audience),
+			jwtclaims.WithUserIDClaim(authCfg.UserIDClaim),
+		),
+	}
+}
+
+// GetAllSSHPolicies returns the list of SSH policies for the account
+func (h *SSHPoliciesHandler) GetAllSSHPolicies(w http.ResponseWriter, r *http.Request) {
+	claims := h.claimsExtractor.FromRequestContext(r)
+	account, user, err := h.accountManager.GetAccountFromToken(claims)
+	if err != nil {
+		util.WriteError(err, w)
+		return
+	}
+
+	policies, err := h.accountManager.ListSSHPolicies(account.Id, user.Id)
+	if err != nil {
+		util.WriteError(err, w)
+		return
+	}
+
+	apiPolicies := make([]*api.SSHPolicy, 0, len(policies))
+	for _, policy := range policies {
+		apiPolicies = append(apiPolicies, toSSHPolicyResponse(policy))
+	}
+
+	util.WriteJSONObject(w, apiPolicies)
+}
+
+// CreateSSHPolicy handles SSH policy creation request
+func (h *SSHPoliciesHandler) CreateSSHPolicy(w http.ResponseWriter, r *http.Request) {
+	claims := h.claimsExtractor.FromRequestContext(r)
+	account, user, err := h.accountManager.GetAccountFromToken(claims)
+	if err != nil {
+		util.WriteError(err, w)
+		return
+	}
+
+	var req api.PostApiSshPoliciesJSONRequestBody
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		util.WriteErrorResponse("couldn't parse JSON request", http.StatusBadRequest, w)
+		return
+	}
+
+	policy := toServerSSHPolicy(xid.New().String(), req)
+
+	err = h.accountManager.SaveSSHPolicy(account.Id, user.Id, policy)
+	if err != nil {
+		util.WriteError(err, w)
+		return
+	}
+
+	util.WriteJSONObject(w, toSSHPolicyResponse(policy))
+}
+
+// UpdateSSHPolicy handles update to an SSH policy identified by a given ID
+func (h *SSHPoliciesHandler) UpdateSSHPolicy(w http.ResponseWriter, r *http.Request) {
+	claims := h.claimsExtractor.FromRequestContext(r)
+	account, user, err := h.accountManager.GetAccountFromToken(claims)
+	if err != nil {
+		util.WriteError(err, w)
+		return
+	}
+
+	policyID := mux.Vars(r)["id"]
+	if len(policyID) == 0 {
+		util.WriteError(status.Errorf(status.InvalidArgument, "invalid SSH policy ID"), w)
+		return
+	}
+
+	_, err = h.accountManager.GetSSHPolicy(account.Id, policyID, user.Id)
+	if err != nil {
+		util.WriteError(err, w)
+		return
+	}
+
+	var req api.PutApiSshPoliciesIdJSONRequestBody
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		util.WriteErrorResponse("couldn't parse JSON request", http.StatusBadRequest, w)
+		return
+	}
+
+	policy := toServerSSHPolicy(policyID, req)
+
+	err = h.accountManager.SaveSSHPolicy(account.Id, user.Id, policy)
+	if err != nil {
+		util.WriteError(err, w)
+		return
+	}
+
+	util.WriteJSONObject(w, toSSHPolicyResponse(policy))
+}
+
+// DeleteSSHPolicy handles SSH policy deletion request
+func (h *SSHPoliciesHandler) DeleteSSHPolicy(w http.ResponseWriter, r *http.Request) {
+	claims := h.claimsExtractor.FromRequestContext(r)
+	account, user, err := h.accountManager.GetAccountFromToken(claims)
+	if err != nil {
+		util.WriteError(err, w)
+		return
+	}
+
+	policyID := mux.Vars(r)["id"]
+	if len(policyID) == 0 {
+		util.WriteError(status.Errorf(status.InvalidArgument, "invalid SSH policy ID"), w)
+		return
+	}
+
+	err = h.accountManager.DeleteSSHPolicy(account.Id, policyID, user.Id)
+	if err != nil {
+		util.WriteError(err, w)
+		return
+	}
+
+	util.WriteJSONObject(w, "")
+}
+
+// GetSSHPolicy handles an SSH policy Get request identified by ID
+func (h *SSHPoliciesHandler) GetSSHPolicy(w http.ResponseWriter, r *http.Request) {
+	claims := h.claimsExtractor.FromRequestContext(r)
+	account, user, err := h.accountManager.GetAccountFromToken(claims)
+	if err != nil {
+		util.WriteError(err, w)
+		return
+	}
+
+	policyID := mux.Vars(r)["id"]
+	if len(policyID) == 0 {
+		util.WriteError(status.Errorf(status.InvalidArgument, "invalid SSH policy ID"), w)
+		return
+	}
+
+	policy, err := h.accountManager.GetSSHPolicy(account.Id, policyID, user.Id)
+	if err != nil {
+		util.WriteError(err, w)
+		return
+	}
+
+	util.WriteJSONObject(w, toSSHPolicyResponse(policy))
+}
+
+func toServerSSHPolicy(policyID string, req api.SSHPolicyRequest) *server.SSHPolicy {
+	policy := &server.SSHPolicy{
+		ID:           policyID,
+		Name:         req.Name,
+		Description:  req.Description,
+		Enabled:      req.Enabled,
+		Sources:      req.Sources,
+		Destinations: req.Destinations,
+		LocalUsers:   req.LocalUsers,
+	}
+	if req.AllowPortForwarding != nil {
+		policy.AllowPortForwarding = *req.AllowPortForwarding
+	}
+	return policy
+}
+
+func toSSHPolicyResponse(policy *server.SSHPolicy) *api.SSHPolicy {
+	return &api.SSHPolicy{
+		Id:                  policy.ID,
+		Name:                policy.Name,
+		Description:         policy.Description,
+		Enabled:             policy.Enabled,
+		Sources:             policy.Sources,
+		Destinations:        policy.Destinations,
+		LocalUsers:          policy.LocalUsers,
+		AllowPortForwarding: &policy.AllowPortForwarding,
+	}
+}
@@ -0,0 +1,212 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/netbirdio/netbird/management/server"
+	"github.com/netbirdio/netbird/management/server/http/api"
+	"github.com/netbirdio/netbird/management/server/jwtclaims"
+	"github.com/netbirdio/netbird/management/server/mock_server"
+	"github.com/netbirdio/netbird/management/server/status"
+)
+
+const (
+	testSSHPoliciesAccountID = "test_id"
+	testSSHPoliciesUserID    = "test_user"
+	existingSSHPolicyID      = "existingPolicy"
+	notFoundSSHPolicyID      = "notFoundPolicy"
+)
+
+var baseExistingSSHPolicy = &server.SSHPolicy{
+	ID:           existingSSHPolicyID,
+	Name:         "admins",
+	Description:  "admins can log in as root",
+	Enabled:      true,
+	Sources:      []string{"admins"},
+	Destinations: []string{"servers"},
+	LocalUsers:   []string{"root"},
+}
+
+var testingSSHPoliciesAccount = &server.Account{
+	Id:     testSSHPoliciesAccountID,
+	Domain: "hotmail.com",
+	Users: map[string]*server.User{
+		testSSHPoliciesUserID: server.NewAdminUser(testSSHPoliciesUserID),
+	},
+}
+
+func initSSHPoliciesTestData() *SSHPoliciesHandler {
+	return &SSHPoliciesHandler{
+		accountManager: &mock_server.MockAccountManager{
+			GetSSHPolicyFunc: func(_, policyID, _ string) (*server.SSHPolicy, error) {
+				if policyID == existingSSHPolicyID {
+					return baseExistingSSHPolicy.Copy(), nil
+				}
+				return nil, status.Errorf(status.NotFound, "SSH policy with ID %s not found", policyID)
+			},
+			SaveSSHPolicyFunc: func(_, _ string, policyToSave *server.SSHPolicy) error {
+				if len(policyToSave.LocalUsers) == 0 {
+					return status.Errorf(status.InvalidArgument, "the list of local users should not be empty")
+				}
+				return nil
+			},
+			ListSSHPoliciesFunc: func(_, _ string) ([]*server.SSHPolicy, error) {
+				return []*server.SSHPolicy{baseExistingSSHPolicy.Copy()}, nil
+			},
+			DeleteSSHPolicyFunc: func(_, policyID, _ string) error {
+				if policyID == existingSSHPolicyID {
+					return nil
+				}
+				return status.Errorf(status.NotFound, "SSH policy with ID %s not found", policyID)
+			},
+			GetAccountFromTokenFunc: func(_ jwtclaims.AuthorizationClaims) (*server.Account, *server.User, error) {
+				return testingSSHPoliciesAccount, testingSSHPoliciesAccount.Users[testSSHPoliciesUserID], nil
+			},
+		},
+		claimsExtractor: jwtclaims.NewClaimsExtractor(
+			jwtclaims.WithFromRequestContext(func(r *http.Request) jwtclaims.AuthorizationClaims {
+				return jwtclaims.AuthorizationClaims{
+					UserId:    testSSHPoliciesUserID,
+					Domain:    "hotmail.com",
+					AccountId: testSSHPoliciesAccountID,
+				}
+			}),
+		),
+	}
+}
+
+func TestSSHPoliciesHandlers(t *testing.T) {
+	forwardingEnabled, forwardingDisabled := true, false
+
+	tt := []struct {
+		name           string
+		requestType    string
+		requestPath    string
+		requestBody    io.Reader
+		expectedStatus int
+		expectedPolicy *api.SSHPolicy
+	}{
+		{
+			name:           "Get Existing Policy",
+			requestType:    http.MethodGet,
+			requestPath:    "/api/ssh/policies/" + existingSSHPolicyID,
+			expectedStatus: http.StatusOK,
+			expectedPolicy: toSSHPolicyResponse(baseExistingSSHPolicy),
+		},
+		{
+			name:           "Get Not Existing Policy",
+			requestType:    http.MethodGet,
+			requestPath:    "/api/ssh/policies/" + notFoundSSHPolicyID,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:        "Create Policy",
+			requestType: http.MethodPost,
+			requestPath: "/api/ssh/policies",
+			requestBody: bytes.NewBufferString(`{"name":"deploy","description":"","enabled":true,` +
+				`"sources":["ci"],"destinations":["servers"],"local_users":["deploy"]}`),
+			expectedStatus: http.StatusOK,
+			expectedPolicy: &api.SSHPolicy{
+				Name:                "deploy",
+				Enabled:             true,
+				Sources:             []string{"ci"},
+				Destinations:        []string{"servers"},
+				LocalUsers:          []string{"deploy"},
+				AllowPortForwarding: &forwardingDisabled,
+			},
+		},
+		{
+			name:        "Create Policy Without Local Users",
+			requestType: http.MethodPost,
+			requestPath: "/api/ssh/policies",
+			requestBody: bytes.NewBufferString(`{"name":"deploy","description":"","enabled":true,` +
+				`"sources":["ci"],"destinations":["servers"],"local_users":[]}`),
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:        "Update Policy",
+			requestType: http.MethodPut,
+			requestPath: "/api/ssh/policies/" + existingSSHPolicyID,
+			requestBody: bytes.NewBufferString(`{"name":"admins","description":"","enabled":false,` +
+				`"sources":["admins"],"destinations":["servers"],"local_users":["root","admin"],"allow_port_forwarding":true}`),
+			expectedStatus: http.StatusOK,
+			expectedPolicy: &api.SSHPolicy{
+				Id:                  existingSSHPolicyID,
+				Name:                "admins",
+				Enabled:             false,
+				Sources:             []string{"admins"},
+				Destinations:        []string{"servers"},
+				LocalUsers:          []string{"root", "admin"},
+				AllowPortForwarding: &forwardingEnabled,
+			},
+		},
+		{
+			name:        "Update Not Existing Policy",
+			requestType: http.MethodPut,
+			requestPath: "/api/ssh/policies/" + notFoundSSHPolicyID,
+			requestBody: bytes.NewBufferString(`{"name":"admins","description":"","enabled":true,` +
+				`"sources":["admins"],"destinations":["servers"],"local_users":["root"]}`),
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "Delete Policy",
+			requestType:    http.MethodDelete,
+			requestPath:    "/api/ssh/policies/" + existingSSHPolicyID,
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	p := initSSHPoliciesTestData()
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			req := httptest.NewRequest(tc.requestType, tc.requestPath, tc.requestBody)
+
+			router := mux.NewRouter()
+			router.HandleFunc("/api/ssh/policies", p.GetAllSSHPolicies).Methods("GET")
+			router.HandleFunc("/api/ssh/policies", p.CreateSSHPolicy).Methods("POST")
+			router.HandleFunc("/api/ssh/policies/{id}", p.GetSSHPolicy).Methods("GET")
+			router.HandleFunc("/api/ssh/policies/{id}", p.UpdateSSHPolicy).Methods("PUT")
+			router.HandleFunc("/api/ssh/policies/{id}", p.DeleteSSHPolicy).Methods("DELETE")
+			router.ServeHTTP(recorder, req)
+
+			res := recorder.Result()
+			defer res.Body.Close()
+
+			content, err := io.ReadAll(res.Body)
+			if err != nil {
+				t.Fatalf("I don't know what I expected; %v", err)
+			}
+
+			if status := recorder.Code; status != tc.expectedStatus {
+				t.Fatalf("handler returned wrong status code: got %v want %v, content: %s",
+					status, tc.expectedStatus, string(content))
+			}
+
+			if tc.expectedPolicy == nil {
+				return
+			}
+
+			got := &api.SSHPolicy{}
+			if err = json.Unmarshal(content, got); err != nil {
+				t.Fatalf("Sent content is not in correct json format; %v", err)
+			}
+
+			// IDs of created policies are generated by the handler
+			if tc.requestType == http.MethodPost {
+				assert.NotEmpty(t, got.Id)
+				got.Id = ""
+			}
+			assert.Equal(t, tc.expectedPolicy, got)
+		})
+	}
+}
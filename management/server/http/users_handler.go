@@ -3,6 +3,7 @@ package http
 import (
 	"encoding/json"
 	"net/http"
+	"strconv"
 
 	"github.com/gorilla/mux"
 
@@ -65,10 +66,19 @@ func (h *UsersHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	isBlocked := false
+	if existingUser, ok := account.Users[userID]; ok {
+		isBlocked = existingUser.IsBlocked()
+	}
+	if req.IsBlocked != nil {
+		isBlocked = *req.IsBlocked
+	}
+
 	newUser, err := h.accountManager.SaveUser(account.Id, user.Id, &server.User{
 		Id:         userID,
 		Role:       userRole,
 		AutoGroups: req.AutoGroups,
+		Blocked:    isBlocked,
 	})
 	if err != nil {
 		util.WriteError(err, w)
@@ -103,11 +113,28 @@ func (h *UsersHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	isServiceUser := req.IsServiceUser != nil && *req.IsServiceUser
+
+	email := ""
+	if req.Email != nil {
+		email = *req.Email
+	}
+	if email == "" && !isServiceUser {
+		util.WriteError(status.Errorf(status.InvalidArgument, "email shouldn't be empty"), w)
+		return
+	}
+
+	name := ""
+	if req.Name != nil {
+		name = *req.Name
+	}
+
 	newUser, err := h.accountManager.CreateUser(account.Id, user.Id, &server.UserInfo{
-		Email:      req.Email,
-		Name:       *req.Name,
-		Role:       req.Role,
-		AutoGroups: req.AutoGroups,
+		Email:         email,
+		Name:          name,
+		Role:          req.Role,
+		AutoGroups:    req.AutoGroups,
+		IsServiceUser: isServiceUser,
 	})
 	if err != nil {
 		util.WriteError(err, w)
@@ -118,6 +145,7 @@ func (h *UsersHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 
 // GetAllUsers returns a list of users of the account this user belongs to.
 // It also gathers additional user data (like email and name) from the IDP manager.
+// Service users are returned only if the service_user query parameter is set to true.
 func (h *UsersHandler) GetAllUsers(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		util.WriteErrorResponse("wrong HTTP method", http.StatusMethodNotAllowed, w)
@@ -131,6 +159,15 @@ func (h *UsersHandler) GetAllUsers(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	serviceUsers := false
+	if serviceUserParam := r.URL.Query().Get("service_user"); serviceUserParam != "" {
+		serviceUsers, err = strconv.ParseBool(serviceUserParam)
+		if err != nil {
+			util.WriteError(status.Errorf(status.InvalidArgument, "invalid service_user query parameter"), w)
+			return
+		}
+	}
+
 	data, err := h.accountManager.GetUsersFromAccount(account.Id, user.Id)
 	if err != nil {
 		util.WriteError(err, w)
@@ -139,6 +176,9 @@ func (h *UsersHandler) GetAllUsers(w http.ResponseWriter, r *http.Request) {
 
 	users := make([]*api.User, 0)
 	for _, r := range data {
+		if r.IsServiceUser != serviceUsers {
+			continue
+		}
 		users = append(users, toUserResponse(r, claims.UserId))
 	}
 
@@ -163,12 +203,14 @@ func toUserResponse(user *server.UserInfo, currenUserID string) *api.User {
 
 	isCurrent := user.ID == currenUserID
 	return &api.User{
-		Id:         user.ID,
-		Name:       user.Name,
-		Email:      user.Email,
-		Role:       user.Role,
-		AutoGroups: autoGroups,
-		Status:     userStatus,
-		IsCurrent:  &isCurrent,
+		Id:            user.ID,
+		Name:          user.Name,
+		Email:         user.Email,
+		Role:          user.Role,
+		AutoGroups:    autoGroups,
+		Status:        userStatus,
+		IsCurrent:     &isCurrent,
+		IsBlocked:     user.IsBlocked,
+		IsServiceUser: user.IsServiceUser,
 	}
 }
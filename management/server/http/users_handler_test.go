@@ -1,15 +1,18 @@
 package http
 
 import (
+	"bytes"
 	"encoding/json"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/gorilla/mux"
 	"github.com/magiconair/properties/assert"
 
 	"github.com/netbirdio/netbird/management/server"
+	"github.com/netbirdio/netbird/management/server/http/api"
 	"github.com/netbirdio/netbird/management/server/jwtclaims"
 	"github.com/netbirdio/netbird/management/server/mock_server"
 )
@@ -32,10 +35,11 @@ func initUsers(user ...*server.User) *UsersHandler {
 				users := make([]*server.UserInfo, 0)
 				for _, v := range user {
 					users = append(users, &server.UserInfo{
-						ID:    v.Id,
-						Role:  string(v.Role),
-						Name:  "",
-						Email: "",
+						ID:            v.Id,
+						Role:          string(v.Role),
+						Name:          v.ServiceUserName,
+						Email:         "",
+						IsServiceUser: v.IsServiceUser,
 					})
 				}
 				return users, nil
@@ -103,3 +107,101 @@ func TestGetUsers(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdateUserBlockedStatus(t *testing.T) {
+	users := []*server.User{{Id: "1", Role: "admin"}, {Id: "2", Role: "user", Blocked: true}}
+	userHandler := initUsers(users...)
+
+	var savedUser *server.User
+	userHandler.accountManager.(*mock_server.MockAccountManager).SaveUserFunc = func(accountID, userID string, update *server.User) (*server.UserInfo, error) {
+		savedUser = update
+		userStatus := server.UserStatusActive
+		if update.Blocked {
+			userStatus = server.UserStatusDisabled
+		}
+		return &server.UserInfo{ID: update.Id, Role: string(update.Role), Status: string(userStatus), IsBlocked: update.Blocked}, nil
+	}
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/users/{id}", userHandler.UpdateUser).Methods("PUT")
+
+	tt := []struct {
+		name            string
+		requestBody     string
+		expectedBlocked bool
+		expectedStatus  api.UserStatus
+	}{
+		{
+			name:            "Blocked Status Is Kept When Not Provided",
+			requestBody:     `{"role":"user","auto_groups":[]}`,
+			expectedBlocked: true,
+			expectedStatus:  api.UserStatusDisabled,
+		},
+		{
+			name:            "Unblock User",
+			requestBody:     `{"role":"user","auto_groups":[],"is_blocked":false}`,
+			expectedBlocked: false,
+			expectedStatus:  api.UserStatusActive,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPut, "/api/users/2", bytes.NewBufferString(tc.requestBody)))
+
+			assert.Equal(t, recorder.Code, http.StatusOK)
+			assert.Equal(t, savedUser.Blocked, tc.expectedBlocked)
+
+			got := &api.User{}
+			if err := json.Unmarshal(recorder.Body.Bytes(), got); err != nil {
+				t.Fatalf("Sent content is not in correct json format; %v", err)
+			}
+			assert.Equal(t, got.IsBlocked, tc.expectedBlocked)
+			assert.Equal(t, got.Status, tc.expectedStatus)
+		})
+	}
+}
+
+func TestGetServiceUsers(t *testing.T) {
+	users := []*server.User{
+		{Id: "1", Role: "admin"},
+		{Id: "2", Role: "user", IsServiceUser: true, ServiceUserName: "automation"},
+	}
+	userHandler := initUsers(users...)
+
+	tt := []struct {
+		name                 string
+		requestPath          string
+		expectedStatus       int
+		expectedIDs          []string
+		expectedServiceUsers bool
+	}{
+		{name: "Regular Users", requestPath: "/api/users", expectedStatus: http.StatusOK, expectedIDs: []string{"1"}},
+		{name: "Service Users", requestPath: "/api/users?service_user=true", expectedStatus: http.StatusOK, expectedIDs: []string{"2"}, expectedServiceUsers: true},
+		{name: "Invalid Filter", requestPath: "/api/users?service_user=maybe", expectedStatus: http.StatusUnprocessableEntity},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			userHandler.GetAllUsers(recorder, httptest.NewRequest(http.MethodGet, tc.requestPath, nil))
+
+			assert.Equal(t, recorder.Code, tc.expectedStatus)
+			if tc.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var got []*api.User
+			if err := json.Unmarshal(recorder.Body.Bytes(), &got); err != nil {
+				t.Fatalf("Sent content is not in correct json format; %v", err)
+			}
+			var ids []string
+			for _, u := range got {
+				ids = append(ids, u.Id)
+				assert.Equal(t, u.IsServiceUser, tc.expectedServiceUsers)
+			}
+			assert.Equal(t, ids, tc.expectedIDs)
+		})
+	}
+}
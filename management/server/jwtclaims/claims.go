@@ -1,9 +1,18 @@
 package jwtclaims
 
+import (
+	"github.com/golang-jwt/jwt"
+)
+
 // AuthorizationClaims stores authorization information from JWTs
 type AuthorizationClaims struct {
 	UserId         string
 	AccountId      string
 	Domain         string
 	DomainCategory string
+	// AuthenticatedByPAT indicates that the claims were created from a personal access token instead of a JWT
+	AuthenticatedByPAT bool
+
+	// Raw holds all the claims of the token, e.g. to read the account specific claims like the user groups
+	Raw jwt.MapClaims
 }
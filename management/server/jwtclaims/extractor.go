@@ -14,6 +14,9 @@ const (
 	UserIDClaim          = "sub"
 )
 
+// PATUserProperty is the request context key marking the request as authenticated by a personal access token
+const PATUserProperty = "pat"
+
 // Extract function type
 type ExtractClaims func(r *http.Request) AuthorizationClaims
 
@@ -68,7 +71,9 @@ func NewClaimsExtractor(options ...ClaimsExtractorOption) *ClaimsExtractor {
 // FromToken extracts claims from the token (after auth)
 func (c *ClaimsExtractor) FromToken(token *jwt.Token) AuthorizationClaims {
 	claims := token.Claims.(jwt.MapClaims)
-	jwtClaims := AuthorizationClaims{}
+	jwtClaims := AuthorizationClaims{
+		Raw: claims,
+	}
 	userID, ok := claims[c.userIDClaim].(string)
 	if !ok {
 		return jwtClaims
@@ -95,5 +100,7 @@ func (c *ClaimsExtractor) fromRequestContext(r *http.Request) AuthorizationClaim
 		return AuthorizationClaims{}
 	}
 	token := r.Context().Value(TokenUserProperty).(*jwt.Token)
-	return c.FromToken(token)
+	claims := c.FromToken(token)
+	claims.AuthenticatedByPAT, _ = r.Context().Value(PATUserProperty).(bool)
+	return claims
 }
@@ -11,6 +11,9 @@ import (
 
 func newTestRequestWithJWT(t *testing.T, claims AuthorizationClaims, audiance string) *http.Request {
 	claimMaps := jwt.MapClaims{}
+	for name, value := range claims.Raw {
+		claimMaps[name] = value
+	}
 	if claims.UserId != "" {
 		claimMaps[UserIDClaim] = claims.UserId
 	}
@@ -97,14 +100,29 @@ func TestExtractClaimsFromRequestContext(t *testing.T) {
 		expectedMSG: "extracted claims should match input claims",
 	}
 
-	for _, testCase := range []test{testCase1, testCase2, testCase3, testCase4, testCase5} {
+	testCase6 := test{
+		name:          "Custom Claims Are Kept",
+		inputAudiance: "https://login/",
+		inputAuthorizationClaims: AuthorizationClaims{
+			UserId: "test",
+			Raw:    jwt.MapClaims{"groups": []interface{}{"admins", "devs"}},
+		},
+		testingFunc: require.EqualValues,
+		expectedMSG: "extracted claims should match input claims",
+	}
+
+	for _, testCase := range []test{testCase1, testCase2, testCase3, testCase4, testCase5, testCase6} {
 		t.Run(testCase.name, func(t *testing.T) {
 			request := newTestRequestWithJWT(t, testCase.inputAuthorizationClaims, testCase.inputAudiance)
 
 			extractor := NewClaimsExtractor(WithAudience(testCase.inputAudiance))
 			extractedClaims := extractor.FromRequestContext(request)
 
-			testCase.testingFunc(t, testCase.inputAuthorizationClaims, extractedClaims, testCase.expectedMSG)
+			// all the token claims are expected to be exposed as raw claims
+			expectedClaims := testCase.inputAuthorizationClaims
+			expectedClaims.Raw = request.Context().Value(TokenUserProperty).(*jwt.Token).Claims.(jwt.MapClaims)
+
+			testCase.testingFunc(t, expectedClaims, extractedClaims, testCase.expectedMSG)
 		})
 	}
 }
@@ -17,7 +17,7 @@ type MockAccountManager struct {
 	GetOrCreateAccountByUserFunc func(userId, domain string) (*server.Account, error)
 	GetAccountByUserFunc         func(userId string) (*server.Account, error)
 	CreateSetupKeyFunc           func(accountId string, keyName string, keyType server.SetupKeyType,
-		expiresIn time.Duration, autoGroups []string, usageLimit int, userID string) (*server.SetupKey, error)
+		expiresIn time.Duration, autoGroups []string, usageLimit int, ipPool string, ephemeral bool, userID string) (*server.SetupKey, error)
 	GetSetupKeyFunc                 func(accountID, userID, keyID string) (*server.SetupKey, error)
 	GetAccountByUserOrAccountIdFunc func(userId, accountId, domain string) (*server.Account, error)
 	IsUserAdminFunc                 func(claims jwtclaims.AuthorizationClaims) (bool, error)
@@ -26,6 +26,8 @@ type MockAccountManager struct {
 	GetPeersFunc                    func(accountID, userID string) ([]*server.Peer, error)
 	MarkPeerConnectedFunc           func(peerKey string, connected bool) error
 	DeletePeerFunc                  func(accountID, peerKey, userID string) (*server.Peer, error)
+	ApprovePeerFunc                 func(accountID, userID, peerID string) (*server.Peer, error)
+	RejectPeerFunc                  func(accountID, userID, peerID string) error
 	GetPeerByIPFunc                 func(accountId string, peerIP string) (*server.Peer, error)
 	GetNetworkMapFunc               func(peerKey string) (*server.NetworkMap, error)
 	GetPeerNetworkFunc              func(peerKey string) (*server.Network, error)
@@ -46,6 +48,7 @@ type MockAccountManager struct {
 	SavePolicyFunc                  func(accountID, userID string, policy *server.Policy) error
 	DeletePolicyFunc                func(accountID, policyID, userID string) error
 	ListPoliciesFunc                func(accountID, userID string) ([]*server.Policy, error)
+	EvaluatePoliciesFunc            func(accountID, userID string, query *server.PolicyEvaluationQuery) ([]*server.PolicyEvaluation, error)
 	GetUsersFromAccountFunc         func(accountID, userID string) ([]*server.UserInfo, error)
 	UpdatePeerMetaFunc              func(peerID string, meta server.PeerSystemMeta) error
 	UpdatePeerSSHKeyFunc            func(peerID string, sshKey string) error
@@ -67,6 +70,9 @@ type MockAccountManager struct {
 	ListNameServerGroupsFunc        func(accountID string) ([]*nbdns.NameServerGroup, error)
 	CreateUserFunc                  func(accountID, userID string, key *server.UserInfo) (*server.UserInfo, error)
 	GetAccountFromTokenFunc         func(claims jwtclaims.AuthorizationClaims) (*server.Account, *server.User, error)
+	GetAccountFromPATFunc           func(plainToken string) (*server.Account, *server.User, *server.PersonalAccessToken, error)
+	CreatePATFunc                   func(accountID, executingUserID, targetUserID, tokenName string, expiresIn int) (*server.PersonalAccessTokenGenerated, error)
+	DeletePATFunc                   func(accountID, executingUserID, targetUserID, tokenID string) error
 	GetDNSDomainFunc                func() string
 	GetEventsFunc                   func(accountID, userID string) ([]*activity.Event, error)
 	GetDNSSettingsFunc              func(accountID, userID string) (*server.DNSSettings, error)
@@ -75,6 +81,21 @@ type MockAccountManager struct {
 	UpdateAccountSettingsFunc       func(accountID, userID string, newSettings *server.Settings) (*server.Account, error)
 	LoginPeerFunc                   func(login server.PeerLogin) (*server.Peer, *server.NetworkMap, error)
 	SyncPeerFunc                    func(sync server.PeerSync) (*server.Peer, *server.NetworkMap, error)
+	GetDNSZoneFunc                  func(accountID, zoneID, userID string) (*server.DNSZone, error)
+	SaveDNSZoneFunc                 func(accountID, userID string, zoneToSave *server.DNSZone) error
+	DeleteDNSZoneFunc               func(accountID, zoneID, userID string) error
+	ListDNSZonesFunc                func(accountID, userID string) ([]*server.DNSZone, error)
+	GetSSHPolicyFunc                func(accountID, policyID, userID string) (*server.SSHPolicy, error)
+	SaveSSHPolicyFunc               func(accountID, userID string, policyToSave *server.SSHPolicy) error
+	DeleteSSHPolicyFunc             func(accountID, policyID, userID string) error
+	ListSSHPoliciesFunc             func(accountID, userID string) ([]*server.SSHPolicy, error)
+	GetPostureCheckFunc             func(accountID, checkID, userID string) (*server.PostureCheck, error)
+	SavePostureCheckFunc            func(accountID, userID string, checkToSave *server.PostureCheck) error
+	DeletePostureCheckFunc          func(accountID, checkID, userID string) error
+	ListPostureChecksFunc           func(accountID, userID string) ([]*server.PostureCheck, error)
+	StoreSSHSessionEventFunc        func(peerPubKey string, event *server.SSHSessionEvent) error
+	GetSSHCertificateAuthorityFunc  func(accountID, userID string) (string, error)
+	IssueSSHCertificateFunc         func(accountID, userID string, req *server.SSHCertificateRequest) (*server.SSHCertificate, error)
 }
 
 // GetUsersFromAccount mock implementation of GetUsersFromAccount from server.AccountManager interface
@@ -93,6 +114,22 @@ func (am *MockAccountManager) DeletePeer(accountID, peerID, userID string) (*ser
 	return nil, status.Errorf(codes.Unimplemented, "method DeletePeer is not implemented")
 }
 
+// ApprovePeer mock implementation of ApprovePeer from server.AccountManager interface
+func (am *MockAccountManager) ApprovePeer(accountID, userID, peerID string) (*server.Peer, error) {
+	if am.ApprovePeerFunc != nil {
+		return am.ApprovePeerFunc(accountID, userID, peerID)
+	}
+	return nil, status.Errorf(codes.Unimplemented, "method ApprovePeer is not implemented")
+}
+
+// RejectPeer mock implementation of RejectPeer from server.AccountManager interface
+func (am *MockAccountManager) RejectPeer(accountID, userID, peerID string) error {
+	if am.RejectPeerFunc != nil {
+		return am.RejectPeerFunc(accountID, userID, peerID)
+	}
+	return status.Errorf(codes.Unimplemented, "method RejectPeer is not implemented")
+}
+
 // GetOrCreateAccountByUser mock implementation of GetOrCreateAccountByUser from server.AccountManager interface
 func (am *MockAccountManager) GetOrCreateAccountByUser(
 	userId, domain string,
@@ -122,10 +159,12 @@ func (am *MockAccountManager) CreateSetupKey(
 	expiresIn time.Duration,
 	autoGroups []string,
 	usageLimit int,
+	ipPool string,
+	ephemeral bool,
 	userID string,
 ) (*server.SetupKey, error) {
 	if am.CreateSetupKeyFunc != nil {
-		return am.CreateSetupKeyFunc(accountID, keyName, keyType, expiresIn, autoGroups, usageLimit, userID)
+		return am.CreateSetupKeyFunc(accountID, keyName, keyType, expiresIn, autoGroups, usageLimit, ipPool, ephemeral, userID)
 	}
 	return nil, status.Errorf(codes.Unimplemented, "method CreateSetupKey is not implemented")
 }
@@ -331,6 +370,14 @@ func (am *MockAccountManager) ListPolicies(accountID, userID string) ([]*server.
 	return nil, status.Errorf(codes.Unimplemented, "method ListPolicies is not implemented")
 }
 
+// EvaluatePolicies mock implementation of EvaluatePolicies from server.AccountManager interface
+func (am *MockAccountManager) EvaluatePolicies(accountID, userID string, query *server.PolicyEvaluationQuery) ([]*server.PolicyEvaluation, error) {
+	if am.EvaluatePoliciesFunc != nil {
+		return am.EvaluatePoliciesFunc(accountID, userID, query)
+	}
+	return nil, status.Errorf(codes.Unimplemented, "method EvaluatePolicies is not implemented")
+}
+
 // UpdatePeerMeta mock implementation of UpdatePeerMeta from server.AccountManager interface
 func (am *MockAccountManager) UpdatePeerMeta(peerID string, meta server.PeerSystemMeta) error {
 	if am.UpdatePeerMetaFunc != nil {
@@ -512,6 +559,30 @@ func (am *MockAccountManager) GetAccountFromToken(claims jwtclaims.Authorization
 	return nil, nil, status.Errorf(codes.Unimplemented, "method GetAccountFromToken is not implemented")
 }
 
+// GetAccountFromPAT mocks GetAccountFromPAT of the AccountManager interface
+func (am *MockAccountManager) GetAccountFromPAT(plainToken string) (*server.Account, *server.User, *server.PersonalAccessToken, error) {
+	if am.GetAccountFromPATFunc != nil {
+		return am.GetAccountFromPATFunc(plainToken)
+	}
+	return nil, nil, nil, status.Errorf(codes.Unimplemented, "method GetAccountFromPAT is not implemented")
+}
+
+// CreatePAT mocks CreatePAT of the AccountManager interface
+func (am *MockAccountManager) CreatePAT(accountID, executingUserID, targetUserID, tokenName string, expiresIn int) (*server.PersonalAccessTokenGenerated, error) {
+	if am.CreatePATFunc != nil {
+		return am.CreatePATFunc(accountID, executingUserID, targetUserID, tokenName, expiresIn)
+	}
+	return nil, status.Errorf(codes.Unimplemented, "method CreatePAT is not implemented")
+}
+
+// DeletePAT mocks DeletePAT of the AccountManager interface
+func (am *MockAccountManager) DeletePAT(accountID, executingUserID, targetUserID, tokenID string) error {
+	if am.DeletePATFunc != nil {
+		return am.DeletePATFunc(accountID, executingUserID, targetUserID, tokenID)
+	}
+	return status.Errorf(codes.Unimplemented, "method DeletePAT is not implemented")
+}
+
 // GetPeers mocks GetPeers of the AccountManager interface
 func (am *MockAccountManager) GetPeers(accountID, userID string) ([]*server.Peer, error) {
 	if am.GetAccountFromTokenFunc != nil {
@@ -583,3 +654,123 @@ func (am *MockAccountManager) SyncPeer(sync server.PeerSync) (*server.Peer, *ser
 	}
 	return nil, nil, status.Errorf(codes.Unimplemented, "method SyncPeer is not implemented")
 }
+
+// GetDNSZone mocks GetDNSZone of the AccountManager interface
+func (am *MockAccountManager) GetDNSZone(accountID, zoneID, userID string) (*server.DNSZone, error) {
+	if am.GetDNSZoneFunc != nil {
+		return am.GetDNSZoneFunc(accountID, zoneID, userID)
+	}
+	return nil, status.Errorf(codes.Unimplemented, "method GetDNSZone is not implemented")
+}
+
+// SaveDNSZone mocks SaveDNSZone of the AccountManager interface
+func (am *MockAccountManager) SaveDNSZone(accountID, userID string, zoneToSave *server.DNSZone) error {
+	if am.SaveDNSZoneFunc != nil {
+		return am.SaveDNSZoneFunc(accountID, userID, zoneToSave)
+	}
+	return status.Errorf(codes.Unimplemented, "method SaveDNSZone is not implemented")
+}
+
+// DeleteDNSZone mocks DeleteDNSZone of the AccountManager interface
+func (am *MockAccountManager) DeleteDNSZone(accountID, zoneID, userID string) error {
+	if am.DeleteDNSZoneFunc != nil {
+		return am.DeleteDNSZoneFunc(accountID, zoneID, userID)
+	}
+	return status.Errorf(codes.Unimplemented, "method DeleteDNSZone is not implemented")
+}
+
+// ListDNSZones mocks ListDNSZones of the AccountManager interface
+func (am *MockAccountManager) ListDNSZones(accountID, userID string) ([]*server.DNSZone, error) {
+	if am.ListDNSZonesFunc != nil {
+		return am.ListDNSZonesFunc(accountID, userID)
+	}
+	return nil, status.Errorf(codes.Unimplemented, "method ListDNSZones is not implemented")
+}
+
+// GetSSHPolicy mocks GetSSHPolicy of the AccountManager interface
+func (am *MockAccountManager) GetSSHPolicy(accountID, policyID, userID string) (*server.SSHPolicy, error) {
+	if am.GetSSHPolicyFunc != nil {
+		return am.GetSSHPolicyFunc(accountID, policyID, userID)
+	}
+	return nil, status.Errorf(codes.Unimplemented, "method GetSSHPolicy is not implemented")
+}
+
+// SaveSSHPolicy mocks SaveSSHPolicy of the AccountManager interface
+func (am *MockAccountManager) SaveSSHPolicy(accountID, userID string, policyToSave *server.SSHPolicy) error {
+	if am.SaveSSHPolicyFunc != nil {
+		return am.SaveSSHPolicyFunc(accountID, userID, policyToSave)
+	}
+	return status.Errorf(codes.Unimplemented, "method SaveSSHPolicy is not implemented")
+}
+
+// DeleteSSHPolicy mocks DeleteSSHPolicy of the AccountManager interface
+func (am *MockAccountManager) DeleteSSHPolicy(accountID, policyID, userID string) error {
+	if am.DeleteSSHPolicyFunc != nil {
+		return am.DeleteSSHPolicyFunc(accountID, policyID, userID)
+	}
+	return status.Errorf(codes.Unimplemented, "method DeleteSSHPolicy is not implemented")
+}
+
+// ListSSHPolicies mocks ListSSHPolicies of the AccountManager interface
+func (am *MockAccountManager) ListSSHPolicies(accountID, userID string) ([]*server.SSHPolicy, error) {
+	if am.ListSSHPoliciesFunc != nil {
+		return am.ListSSHPoliciesFunc(accountID, userID)
+	}
+	return nil, status.Errorf(codes.Unimplemented, "method ListSSHPolicies is not implemented")
+}
+
+// GetPostureCheck mocks GetPostureCheck of the AccountManager interface
+func (am *MockAccountManager) GetPostureCheck(accountID, checkID, userID string) (*server.PostureCheck, error) {
+	if am.GetPostureCheckFunc != nil {
+		return am.GetPostureCheckFunc(accountID, checkID, userID)
+	}
+	return nil, status.Errorf(codes.Unimplemented, "method GetPostureCheck is not implemented")
+}
+
+// SavePostureCheck mocks SavePostureCheck of the AccountManager interface
+func (am *MockAccountManager) SavePostureCheck(accountID, userID string, checkToSave *server.PostureCheck) error {
+	if am.SavePostureCheckFunc != nil {
+		return am.SavePostureCheckFunc(accountID, userID, checkToSave)
+	}
+	return status.Errorf(codes.Unimplemented, "method SavePostureCheck is not implemented")
+}
+
+// DeletePostureCheck mocks DeletePostureCheck of the AccountManager interface
+func (am *MockAccountManager) DeletePostureCheck(accountID, checkID, userID string) error {
+	if am.DeletePostureCheckFunc != nil {
+		return am.DeletePostureCheckFunc(accountID, checkID, userID)
+	}
+	return status.Errorf(codes.Unimplemented, "method DeletePostureCheck is not implemented")
+}
+
+// ListPostureChecks mocks ListPostureChecks of the AccountManager interface
+func (am *MockAccountManager) ListPostureChecks(accountID, userID string) ([]*server.PostureCheck, error) {
+	if am.ListPostureChecksFunc != nil {
+		return am.ListPostureChecksFunc(accountID, userID)
+	}
+	return nil, status.Errorf(codes.Unimplemented, "method ListPostureChecks is not implemented")
+}
+
+// StoreSSHSessionEvent mocks StoreSSHSessionEvent of the AccountManager interface
+func (am *MockAccountManager) StoreSSHSessionEvent(peerPubKey string, event *server.SSHSessionEvent) error {
+	if am.StoreSSHSessionEventFunc != nil {
+		return am.StoreSSHSessionEventFunc(peerPubKey, event)
+	}
+	return status.Errorf(codes.Unimplemented, "method StoreSSHSessionEvent is not implemented")
+}
+
+// GetSSHCertificateAuthority mocks GetSSHCertificateAuthority of the AccountManager interface
+func (am *MockAccountManager) GetSSHCertificateAuthority(accountID, userID string) (string, error) {
+	if am.GetSSHCertificateAuthorityFunc != nil {
+		return am.GetSSHCertificateAuthorityFunc(accountID, userID)
+	}
+	return "", status.Errorf(codes.Unimplemented, "method GetSSHCertificateAuthority is not implemented")
+}
+
+// IssueSSHCertificate mocks IssueSSHCertificate of the AccountManager interface
+func (am *MockAccountManager) IssueSSHCertificate(accountID, userID string, req *server.SSHCertificateRequest) (*server.SSHCertificate, error) {
+	if am.IssueSSHCertificateFunc != nil {
+		return am.IssueSSHCertificateFunc(accountID, userID, req)
+	}
+	return nil, status.Errorf(codes.Unimplemented, "method IssueSSHCertificate is not implemented")
+}
@@ -1,6 +1,7 @@
 package server
 
 import (
+	"encoding/binary"
 	"github.com/c-robinson/iplib"
 	nbdns "github.com/netbirdio/netbird/dns"
 	"github.com/netbirdio/netbird/management/server/status"
@@ -28,6 +29,16 @@ type NetworkMap struct {
 	Routes       []*route.Route
 	DNSConfig    nbdns.Config
 	OfflinePeers []*Peer
+	// SSHUsers are the local users remote peers, indexed by peer ID, are allowed to log in as over SSH.
+	// It is nil when the account has no enabled SSH policies and any local user is allowed
+	SSHUsers map[string][]string
+	// SSHPortForwarding are the remote peers, indexed by peer ID, allowed to forward TCP ports over SSH
+	SSHPortForwarding map[string]bool
+	// SSHUserCAKey is the public key of the account SSH certificate authority. Empty if the account has none
+	SSHUserCAKey string
+	// SSHCertificateAccess is the access of the users, indexed by user ID, logging in with an SSH certificate.
+	// It is nil when the account has no SSH certificate authority. Users missing from it are denied
+	SSHCertificateAccess map[string]*SSHUserAccess
 }
 
 type Network struct {
@@ -106,6 +117,101 @@ func AllocatePeerIP(ipNet net.IPNet, takenIps []net.IP) (net.IP, error) {
 	return ips[intn], nil
 }
 
+// AllocatePeerIPFromPool picks an available IP from the pool range of the ipNet.
+// The pool has to be a subnet of the ipNet, e.g. if ipNet=100.30.0.0/16 and pool=100.30.10.0/24
+// then the result would be a random free IP within 100.30.10.0/24
+func AllocatePeerIPFromPool(ipNet net.IPNet, pool net.IPNet, takenIps []net.IP) (net.IP, error) {
+	if !isSubnet(ipNet, pool) {
+		return nil, status.Errorf(status.InvalidArgument, "IP pool %s is not part of the network %s", pool.String(), ipNet.String())
+	}
+
+	takenIPMap := make(map[string]struct{})
+	for _, ip := range takenIps {
+		takenIPMap[ip.String()] = struct{}{}
+	}
+
+	network, broadcast := networkAndBroadcast(ipNet)
+	poolIP := pool.IP.To4().Mask(lastIPv4MaskBytes(pool.Mask))
+	poolOnes, poolBits := pool.Mask.Size()
+	poolSize := uint64(1) << (poolBits - poolOnes)
+	first := uint64(binary.BigEndian.Uint32(poolIP))
+
+	// scan the pool from a random offset instead of listing all of its IPs, as the pool may be as large as the network
+	s := rand.NewSource(time.Now().UnixNano())
+	r := rand.New(s)
+	offset := uint64(r.Int63n(int64(poolSize)))
+	for i := uint64(0); i < poolSize; i++ {
+		ip := make(net.IP, net.IPv4len)
+		binary.BigEndian.PutUint32(ip, uint32(first+(offset+i)%poolSize))
+
+		// the .0 addresses are never allocated, same as in AllocatePeerIP
+		if ip[3] == 0 || ip.Equal(network) || ip.Equal(broadcast) {
+			continue
+		}
+		if _, ok := takenIPMap[ip.String()]; ok {
+			continue
+		}
+		return ip, nil
+	}
+
+	return nil, status.Errorf(status.PreconditionFailed, "failed allocating new IP for the pool %s - pool is out of IPs", pool.String())
+}
+
+// ValidatePeerIP checks whether the requested ip can be assigned to a peer of the ipNet.
+// The IP has to be inside the ipNet, must not be the network or broadcast address and must not be already taken.
+func ValidatePeerIP(ipNet net.IPNet, ip net.IP, takenIps []net.IP) error {
+	ip4 := ip.To4()
+	if ip4 == nil {
+		return status.Errorf(status.InvalidArgument, "IP %s is not a valid IPv4 address", ip.String())
+	}
+
+	if !ipNet.Contains(ip4) {
+		return status.Errorf(status.InvalidArgument, "IP %s is not part of the network %s", ip.String(), ipNet.String())
+	}
+
+	network, broadcast := networkAndBroadcast(ipNet)
+	if ip4.Equal(network) || ip4.Equal(broadcast) {
+		return status.Errorf(status.InvalidArgument, "IP %s is a reserved address of the network %s", ip.String(), ipNet.String())
+	}
+
+	for _, taken := range takenIps {
+		if taken.Equal(ip4) {
+			return status.Errorf(status.AlreadyExists, "IP %s is already assigned to another peer", ip.String())
+		}
+	}
+
+	return nil
+}
+
+// networkAndBroadcast returns the network and the broadcast address of the IPv4 ipNet
+func networkAndBroadcast(ipNet net.IPNet) (net.IP, net.IP) {
+	mask := lastIPv4MaskBytes(ipNet.Mask)
+	network := ipNet.IP.To4().Mask(mask)
+	broadcast := make(net.IP, net.IPv4len)
+	for i := range network {
+		broadcast[i] = network[i] | ^mask[i]
+	}
+	return network, broadcast
+}
+
+// lastIPv4MaskBytes returns the IPv4 mask in its 4 bytes form
+func lastIPv4MaskBytes(mask net.IPMask) net.IPMask {
+	if len(mask) == net.IPv6len {
+		return mask[12:]
+	}
+	return mask
+}
+
+// isSubnet returns true if the subnet is fully contained in the ipNet
+func isSubnet(ipNet net.IPNet, subnet net.IPNet) bool {
+	netOnes, netBits := ipNet.Mask.Size()
+	subOnes, subBits := subnet.Mask.Size()
+	// IPv4 masks can be represented in both 4 and 16 bytes form
+	netOnes -= netBits - net.IPv4len*8
+	subOnes -= subBits - net.IPv4len*8
+	return subOnes >= netOnes && ipNet.Contains(subnet.IP)
+}
+
 // generateIPs generates a list of all possible IPs of the given network excluding IPs specified in the exclusion list
 func generateIPs(ipNet *net.IPNet, exclusions map[string]struct{}) ([]net.IP, int) {
 
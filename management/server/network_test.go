@@ -37,3 +37,77 @@ func TestAllocatePeerIP(t *testing.T) {
 		}
 	}
 }
+
+func TestAllocatePeerIPFromPool(t *testing.T) {
+	ipNet := net.IPNet{IP: net.ParseIP("100.64.0.0"), Mask: net.IPMask{255, 255, 0, 0}}
+	pool := net.IPNet{IP: net.ParseIP("100.64.10.0"), Mask: net.IPMask{255, 255, 255, 252}}
+
+	// the .0 address is never allocated, so only 3 IPs are available in the pool
+	var ips []net.IP
+	for i := 0; i < 3; i++ {
+		ip, err := AllocatePeerIPFromPool(ipNet, pool, ips)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.True(t, pool.Contains(ip), "allocated IP %s should be part of the pool", ip)
+		ips = append(ips, ip)
+	}
+
+	_, err := AllocatePeerIPFromPool(ipNet, pool, ips)
+	assert.Error(t, err, "pool should be exhausted")
+
+	// the network and broadcast addresses of the network are never allocated
+	smallNet := net.IPNet{IP: net.ParseIP("100.64.10.4"), Mask: net.IPMask{255, 255, 255, 252}}
+	ips = nil
+	for i := 0; i < 2; i++ {
+		ip, err := AllocatePeerIPFromPool(smallNet, smallNet, ips)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.NoError(t, ValidatePeerIP(smallNet, ip, ips))
+		ips = append(ips, ip)
+	}
+	_, err = AllocatePeerIPFromPool(smallNet, smallNet, ips)
+	assert.Error(t, err, "pool should be exhausted")
+
+	// the pool may be as large as the whole network
+	largeNet := net.IPNet{IP: net.ParseIP("100.64.0.0"), Mask: net.IPMask{255, 192, 0, 0}}
+	ip, err := AllocatePeerIPFromPool(largeNet, largeNet, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, ValidatePeerIP(largeNet, ip, nil))
+
+	outsidePool := net.IPNet{IP: net.ParseIP("100.65.0.0"), Mask: net.IPMask{255, 255, 255, 0}}
+	_, err = AllocatePeerIPFromPool(ipNet, outsidePool, nil)
+	assert.Error(t, err, "pool outside of the network should be rejected")
+}
+
+func TestValidatePeerIP(t *testing.T) {
+	ipNet := net.IPNet{IP: net.ParseIP("100.64.0.0"), Mask: net.IPMask{255, 255, 0, 0}}
+	taken := []net.IP{net.ParseIP("100.64.0.10")}
+
+	tt := []struct {
+		name    string
+		ip      net.IP
+		wantErr bool
+	}{
+		{name: "Free IP", ip: net.ParseIP("100.64.1.20"), wantErr: false},
+		{name: "Taken IP", ip: net.ParseIP("100.64.0.10"), wantErr: true},
+		{name: "Outside Network", ip: net.ParseIP("100.65.0.10"), wantErr: true},
+		{name: "Network Address", ip: net.ParseIP("100.64.0.0"), wantErr: true},
+		{name: "Broadcast Address", ip: net.ParseIP("100.64.255.255"), wantErr: true},
+		{name: "Host Address Ending With Zero", ip: net.ParseIP("100.64.1.0"), wantErr: false},
+		{name: "Host Address Ending With 255", ip: net.ParseIP("100.64.1.255"), wantErr: false},
+		{name: "IPv6 Address", ip: net.ParseIP("fd00::1"), wantErr: true},
+	}
+
+	for _, c := range tt {
+		t.Run(c.name, func(t *testing.T) {
+			err := ValidatePeerIP(ipNet, c.ip, taken)
+			if c.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
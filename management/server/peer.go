@@ -3,7 +3,7 @@ package server
 import (
 	"fmt"
 	"net"
-	"strings"
+	"reflect"
 	"time"
 
 	"github.com/netbirdio/netbird/management/server/activity"
@@ -34,6 +34,8 @@ type PeerStatus struct {
 	Connected bool
 	// LoginExpired
 	LoginExpired bool
+	// RequiresApproval indicates that the peer is pending an admin approval and has no access to the network until approved
+	RequiresApproval bool
 }
 
 // PeerSync used as a data object between the gRPC API and AccountManager on Sync request.
@@ -63,7 +65,7 @@ type Peer struct {
 	ID string
 	// WireGuard public key
 	Key string
-	// A setup key this peer was registered with
+	// The hash of the setup key this peer was registered with
 	SetupKey string
 	// IP address of the Peer
 	IP net.IP
@@ -87,6 +89,9 @@ type Peer struct {
 	LoginExpirationEnabled bool
 	// LastLogin the time when peer performed last login operation
 	LastLogin time.Time
+	// Ephemeral indicates that the peer was registered with an ephemeral setup key and is deleted once it has been
+	// disconnected for longer than Settings.EphemeralPeersGracePeriod
+	Ephemeral bool
 }
 
 // AddedWithSSOLogin indicates whether this peer has been added with an SSO login by a user.
@@ -110,6 +115,7 @@ func (p *Peer) Copy() *Peer {
 		DNSLabel:               p.DNSLabel,
 		LoginExpirationEnabled: p.LoginExpirationEnabled,
 		LastLogin:              p.LastLogin,
+		Ephemeral:              p.Ephemeral,
 	}
 }
 
@@ -148,6 +154,22 @@ func (p *Peer) LoginExpired(expiresIn time.Duration) (bool, time.Duration) {
 	return timeLeft <= 0, timeLeft
 }
 
+// EphemeralExpired indicates whether an ephemeral peer has been disconnected for longer than the gracePeriod
+// and returns the time left until then
+func (p *Peer) EphemeralExpired(gracePeriod time.Duration) (bool, time.Duration) {
+	if !p.Ephemeral || p.Status == nil || p.Status.Connected {
+		return false, 0
+	}
+	expiresAt := p.Status.LastSeen.Add(gracePeriod)
+	timeLeft := time.Until(expiresAt)
+	return timeLeft <= 0, timeLeft
+}
+
+// ApprovalRequired indicates whether the peer is pending an admin approval
+func (p *Peer) ApprovalRequired() bool {
+	return p.Status != nil && p.Status.RequiresApproval
+}
+
 // FQDN returns peers FQDN combined of the peer's DNS label and the system's DNS domain
 func (p *Peer) FQDN(dnsDomain string) string {
 	if dnsDomain == "" {
@@ -164,9 +186,10 @@ func (p *Peer) EventMeta(dnsDomain string) map[string]any {
 // Copy PeerStatus
 func (p *PeerStatus) Copy() *PeerStatus {
 	return &PeerStatus{
-		LastSeen:     p.LastSeen,
-		Connected:    p.Connected,
-		LoginExpired: p.LoginExpired,
+		LastSeen:         p.LastSeen,
+		Connected:        p.Connected,
+		LoginExpired:     p.LoginExpired,
+		RequiresApproval: p.RequiresApproval,
 	}
 }
 
@@ -263,6 +286,10 @@ func (am *DefaultAccountManager) MarkPeerConnected(peerPubKey string, connected
 		am.checkAndSchedulePeerLoginExpiration(account)
 	}
 
+	if peer.Ephemeral && !connected {
+		am.checkAndScheduleEphemeralPeersDeletion(account)
+	}
+
 	if oldStatus.LoginExpired {
 		// we need to update other peers because when peer login expires all other peers are notified to disconnect from
 		// the expired one. Here we notify them that connection is now allowed again.
@@ -275,7 +302,8 @@ func (am *DefaultAccountManager) MarkPeerConnected(peerPubKey string, connected
 	return nil
 }
 
-// UpdatePeer updates peer. Only Peer.Name, Peer.SSHEnabled, and Peer.LoginExpirationEnabled can be updated.
+// UpdatePeer updates peer. Only Peer.Name, Peer.SSHEnabled, Peer.LoginExpirationEnabled, and Peer.IP can be updated.
+// Peer.IP is only updated when provided (not nil) and has to be a free IP within the account network.
 func (am *DefaultAccountManager) UpdatePeer(accountID, userID string, update *Peer) (*Peer, error) {
 	unlock := am.Store.AcquireAccountLock(accountID)
 	defer unlock()
@@ -314,6 +342,28 @@ func (am *DefaultAccountManager) UpdatePeer(accountID, userID string, update *Pe
 		am.storeEvent(userID, peer.ID, accountID, activity.PeerRenamed, peer.EventMeta(am.GetDNSDomain()))
 	}
 
+	if update.IP != nil && !peer.IP.Equal(update.IP) {
+		var takenIps []net.IP
+		for _, p := range account.Peers {
+			if p.ID != peer.ID {
+				takenIps = append(takenIps, p.IP)
+			}
+		}
+
+		err = ValidatePeerIP(account.Network.Net, update.IP, takenIps)
+		if err != nil {
+			return nil, err
+		}
+
+		oldIP := peer.IP
+		peer.IP = update.IP.To4()
+		account.Network.IncSerial()
+
+		meta := peer.EventMeta(am.GetDNSDomain())
+		meta["old_ip"] = oldIP.String()
+		am.storeEvent(userID, peer.ID, accountID, activity.PeerIPUpdated, meta)
+	}
+
 	if peer.LoginExpirationEnabled != update.LoginExpirationEnabled {
 
 		if !peer.AddedWithSSOLogin() {
@@ -334,6 +384,9 @@ func (am *DefaultAccountManager) UpdatePeer(accountID, userID string, update *Pe
 	}
 
 	account.UpdatePeer(peer)
+	if account.updateDynamicGroups() {
+		account.Network.IncSerial()
+	}
 
 	err = am.Store.SaveAccount(account)
 	if err != nil {
@@ -363,13 +416,108 @@ func (am *DefaultAccountManager) DeletePeer(accountID, peerID, userID string) (*
 		return nil, status.Errorf(status.NotFound, "peer %s not found", peerID)
 	}
 
-	account.DeletePeer(peerID)
+	err = am.deletePeer(account, peer)
+	if err != nil {
+		return nil, err
+	}
+
+	am.storeEvent(userID, peer.ID, account.Id, activity.PeerRemovedByUser, peer.EventMeta(am.GetDNSDomain()))
+	return peer, nil
+}
+
+// ApprovePeer approves a peer that is pending an admin approval and gives it access to the network
+func (am *DefaultAccountManager) ApprovePeer(accountID, userID, peerID string) (*Peer, error) {
+	unlock := am.Store.AcquireAccountLock(accountID)
+	defer unlock()
 
+	account, err := am.Store.GetAccount(accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	peer, err := getPendingPeer(account, userID, peerID)
+	if err != nil {
+		return nil, err
+	}
+
+	newStatus := peer.Status.Copy()
+	newStatus.RequiresApproval = false
+	peer.Status = newStatus
+	account.UpdatePeer(peer)
+
+	account.Network.IncSerial()
 	err = am.Store.SaveAccount(account)
 	if err != nil {
 		return nil, err
 	}
 
+	am.storeEvent(userID, peer.ID, account.Id, activity.PeerApproved, peer.EventMeta(am.GetDNSDomain()))
+
+	err = am.updateAccountPeers(account)
+	if err != nil {
+		return nil, err
+	}
+
+	return peer, nil
+}
+
+// RejectPeer rejects a peer that is pending an admin approval. The rejected peer is deleted from the account
+func (am *DefaultAccountManager) RejectPeer(accountID, userID, peerID string) error {
+	unlock := am.Store.AcquireAccountLock(accountID)
+	defer unlock()
+
+	account, err := am.Store.GetAccount(accountID)
+	if err != nil {
+		return err
+	}
+
+	peer, err := getPendingPeer(account, userID, peerID)
+	if err != nil {
+		return err
+	}
+
+	err = am.deletePeer(account, peer)
+	if err != nil {
+		return err
+	}
+
+	am.storeEvent(userID, peer.ID, account.Id, activity.PeerRejected, peer.EventMeta(am.GetDNSDomain()))
+	return nil
+}
+
+// getPendingPeer returns a peer of the account that is pending an approval checking that the user is an admin
+func getPendingPeer(account *Account, userID, peerID string) (*Peer, error) {
+	user, err := account.FindUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.IsAdmin() {
+		return nil, status.Errorf(status.PermissionDenied, "only admins are allowed to approve or reject peers")
+	}
+
+	peer := account.GetPeer(peerID)
+	if peer == nil {
+		return nil, status.Errorf(status.NotFound, "peer %s not found", peerID)
+	}
+
+	if !peer.ApprovalRequired() {
+		return nil, status.Errorf(status.PreconditionFailed, "peer %s is not pending an approval", peerID)
+	}
+
+	return peer, nil
+}
+
+// deletePeer removes the peer from the account, disconnects it and updates the remaining peers of the account
+func (am *DefaultAccountManager) deletePeer(account *Account, peer *Peer) error {
+	account.DeletePeer(peer.ID)
+	account.updateDynamicGroups()
+
+	err := am.Store.SaveAccount(account)
+	if err != nil {
+		return err
+	}
+
 	err = am.peersUpdateManager.SendUpdate(peer.ID,
 		&UpdateMessage{
 			Update: &proto.SyncResponse{
@@ -385,16 +533,15 @@ func (am *DefaultAccountManager) DeletePeer(accountID, peerID, userID string) (*
 			},
 		})
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	if err := am.updateAccountPeers(account); err != nil {
-		return nil, err
+		return err
 	}
 
-	am.peersUpdateManager.CloseChannel(peerID)
-	am.storeEvent(userID, peer.ID, account.Id, activity.PeerRemovedByUser, peer.EventMeta(am.GetDNSDomain()))
-	return peer, nil
+	am.peersUpdateManager.CloseChannel(peer.ID)
+	return nil
 }
 
 // GetPeerByIP returns peer by its IP
@@ -453,7 +600,6 @@ func (am *DefaultAccountManager) AddPeer(setupKey, userID string, peer *Peer) (*
 		return nil, nil, status.Errorf(status.Unauthenticated, "no peer auth method provided, please use a setup key or interactive SSO login")
 	}
 
-	upperKey := strings.ToUpper(setupKey)
 	var account *Account
 	var err error
 	addedByUser := false
@@ -481,9 +627,17 @@ func (am *DefaultAccountManager) AddPeer(setupKey, userID string, peer *Peer) (*
 		AccountID: account.Id,
 	}
 
+	if addedByUser {
+		if user, ok := account.Users[userID]; ok && user.IsBlocked() {
+			return nil, nil, status.Errorf(status.PermissionDenied, "user %s is blocked", userID)
+		}
+	}
+
+	var ipPool, hashedKey string
+	ephemeral := false
 	if !addedByUser {
 		// validate the setup key if adding with a key
-		sk, err := account.FindSetupKey(upperKey)
+		sk, err := account.findSetupKeyByPlainKey(setupKey)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -493,6 +647,9 @@ func (am *DefaultAccountManager) AddPeer(setupKey, userID string, peer *Peer) (*
 		}
 
 		account.SetupKeys[sk.Key] = sk.IncrementUsage()
+		hashedKey = sk.Key
+		ipPool = sk.IPPool
+		ephemeral = sk.Ephemeral
 		opEvent.InitiatorID = sk.Id
 		opEvent.Activity = activity.PeerAddedWithSetupKey
 	} else {
@@ -510,25 +667,38 @@ func (am *DefaultAccountManager) AddPeer(setupKey, userID string, peer *Peer) (*
 
 	peer.DNSLabel = newLabel
 	network := account.Network
-	nextIp, err := AllocatePeerIP(network.Net, takenIps)
-	if err != nil {
-		return nil, nil, err
+	var nextIp net.IP
+	if ipPool != "" {
+		_, pool, err := net.ParseCIDR(ipPool)
+		if err != nil {
+			return nil, nil, status.Errorf(status.Internal, "invalid setup key IP pool %s", ipPool)
+		}
+		nextIp, err = AllocatePeerIPFromPool(network.Net, *pool, takenIps)
+		if err != nil {
+			return nil, nil, err
+		}
+	} else {
+		nextIp, err = AllocatePeerIP(network.Net, takenIps)
+		if err != nil {
+			return nil, nil, err
+		}
 	}
 
 	newPeer := &Peer{
 		ID:                     xid.New().String(),
 		Key:                    peer.Key,
-		SetupKey:               upperKey,
+		SetupKey:               hashedKey,
 		IP:                     nextIp,
 		Meta:                   peer.Meta,
 		Name:                   peer.Meta.Hostname,
 		DNSLabel:               newLabel,
 		UserID:                 userID,
-		Status:                 &PeerStatus{Connected: false, LastSeen: time.Now()},
+		Status:                 &PeerStatus{Connected: false, LastSeen: time.Now(), RequiresApproval: account.Settings.PeerApprovalEnabled},
 		SSHEnabled:             false,
 		SSHKey:                 peer.SSHKey,
 		LastLogin:              time.Now(),
 		LoginExpirationEnabled: true,
+		Ephemeral:              ephemeral,
 	}
 
 	// add peer to 'All' group
@@ -545,7 +715,7 @@ func (am *DefaultAccountManager) AddPeer(setupKey, userID string, peer *Peer) (*
 			return nil, nil, err
 		}
 	} else {
-		groupsToAdd, err = account.getSetupKeyGroups(upperKey)
+		groupsToAdd, err = account.getSetupKeyGroups(hashedKey)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -553,13 +723,15 @@ func (am *DefaultAccountManager) AddPeer(setupKey, userID string, peer *Peer) (*
 
 	if len(groupsToAdd) > 0 {
 		for _, s := range groupsToAdd {
-			if g, ok := account.Groups[s]; ok && g.Name != "All" {
+			// membership of the dynamic groups is computed from their rules only
+			if g, ok := account.Groups[s]; ok && g.Name != "All" && !g.IsDynamic() {
 				g.Peers = append(g.Peers, newPeer.ID)
 			}
 		}
 	}
 
 	account.Peers[newPeer.ID] = newPeer
+	account.updateDynamicGroups()
 	account.Network.IncSerial()
 	err = am.Store.SaveAccount(account)
 	if err != nil {
@@ -575,7 +747,7 @@ func (am *DefaultAccountManager) AddPeer(setupKey, userID string, peer *Peer) (*
 		return nil, nil, err
 	}
 
-	networkMap := account.GetPeerNetworkMap(peer.ID, am.dnsDomain)
+	networkMap := account.GetPeerNetworkMap(newPeer.ID, am.dnsDomain)
 	return newPeer, networkMap, nil
 }
 
@@ -604,6 +776,10 @@ func (am *DefaultAccountManager) SyncPeer(sync PeerSync) (*Peer, *NetworkMap, er
 		return nil, nil, status.Errorf(status.Unauthenticated, "peer is not registered")
 	}
 
+	if err = checkPeerUserBlocked(peer, account); err != nil {
+		return nil, nil, err
+	}
+
 	if peerLoginExpired(peer, account) {
 		return nil, nil, status.Errorf(status.PermissionDenied, "peer login has expired, please log in once more")
 	}
@@ -643,6 +819,10 @@ func (am *DefaultAccountManager) LoginPeer(login PeerLogin) (*Peer, *NetworkMap,
 		return nil, nil, status.Errorf(status.Unauthenticated, "peer is not registered")
 	}
 
+	if err = checkPeerUserBlocked(peer, account); err != nil {
+		return nil, nil, err
+	}
+
 	updateRemotePeers := false
 	if peerLoginExpired(peer, account) {
 		err = checkAuth(login.UserID, peer)
@@ -655,7 +835,16 @@ func (am *DefaultAccountManager) LoginPeer(login PeerLogin) (*Peer, *NetworkMap,
 		updateRemotePeers = true
 	}
 
+	failedPostureChecks := account.getPeerFailedPostureChecks(peer.ID)
 	peer = updatePeerMeta(peer, login.Meta, account)
+	if !reflect.DeepEqual(failedPostureChecks, account.getPeerFailedPostureChecks(peer.ID)) {
+		// the peer gained or lost access through policies with posture checks
+		updateRemotePeers = true
+	}
+	if account.updateDynamicGroups() {
+		account.Network.IncSerial()
+		updateRemotePeers = true
+	}
 
 	peer, err = am.checkAndUpdatePeerSSHKey(peer, account, login.SSHKey)
 	if err != nil {
@@ -687,6 +876,14 @@ func checkAuth(loginUserID string, peer *Peer) error {
 	return nil
 }
 
+// checkPeerUserBlocked returns a status.PermissionDenied error if the peer was added by a user that is blocked
+func checkPeerUserBlocked(peer *Peer, account *Account) error {
+	if user, ok := account.Users[peer.UserID]; ok && user.IsBlocked() {
+		return status.Errorf(status.PermissionDenied, "user %s of the peer is blocked", peer.UserID)
+	}
+	return nil
+}
+
 func peerLoginExpired(peer *Peer, account *Account) bool {
 	expired, expiresIn := peer.LoginExpired(account.Settings.PeerLoginExpiration)
 	expired = account.Settings.PeerLoginExpirationEnabled && expired
@@ -1,10 +1,16 @@
 package server
 
 import (
+	"fmt"
+	"net"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/netbirdio/netbird/management/server/activity"
 
 	"github.com/rs/xid"
 	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
@@ -481,3 +487,266 @@ func getSetupKey(account *Account, keyType SetupKeyType) *SetupKey {
 	}
 	return setupKey
 }
+
+func TestDefaultAccountManager_UpdatePeerIP(t *testing.T) {
+	manager, err := createManager(t)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	userID := "account_creator"
+	account, err := createAccount(manager, "test_account", userID, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, pool, err := net.ParseCIDR(account.Network.Net.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	poolCIDR := fmt.Sprintf("%s/24", pool.IP.String())
+
+	setupKey, err := manager.CreateSetupKey(account.Id, "pool-key", SetupKeyReusable, time.Hour, []string{},
+		SetupKeyUnlimitedUsage, poolCIDR, false, userID)
+	require.NoError(t, err, "unable to create setup key with IP pool")
+
+	_, err = manager.CreateSetupKey(account.Id, "invalid-pool-key", SetupKeyReusable, time.Hour, []string{},
+		SetupKeyUnlimitedUsage, "10.0.0.0/24", false, userID)
+	require.Error(t, err, "setup key IP pool outside of the account network should be rejected")
+
+	var peers []*Peer
+	for i := 0; i < 2; i++ {
+		key, err := wgtypes.GeneratePrivateKey()
+		require.NoError(t, err)
+		peer, _, err := manager.AddPeer(setupKey.Key, "", &Peer{
+			Key:  key.PublicKey().String(),
+			Meta: PeerSystemMeta{Hostname: fmt.Sprintf("test-peer-%d", i)},
+		})
+		require.NoError(t, err, "unable to add peer")
+		_, poolNet, _ := net.ParseCIDR(poolCIDR)
+		assert.True(t, poolNet.Contains(peer.IP), "peer IP %s should be allocated from the setup key pool", peer.IP)
+		peers = append(peers, peer)
+	}
+
+	// conflicting IP
+	update := peers[0].Copy()
+	update.IP = peers[1].IP
+	_, err = manager.UpdatePeer(account.Id, userID, update)
+	require.Error(t, err, "updating peer with an IP of another peer should fail")
+
+	// IP outside the account network
+	update.IP = net.ParseIP("10.0.0.1")
+	_, err = manager.UpdatePeer(account.Id, userID, update)
+	require.Error(t, err, "updating peer with an IP outside the network should fail")
+
+	requestedIP := make(net.IP, net.IPv4len)
+	copy(requestedIP, pool.IP.To4())
+	requestedIP[2] = 200
+	requestedIP[3] = 10
+	update.IP = requestedIP
+	updated, err := manager.UpdatePeer(account.Id, userID, update)
+	require.NoError(t, err, "unable to update peer IP")
+	assert.True(t, updated.IP.Equal(requestedIP))
+
+	account, err = manager.Store.GetAccount(account.Id)
+	require.NoError(t, err)
+	assert.True(t, account.Peers[peers[0].ID].IP.Equal(requestedIP))
+
+	ev := getEvent(t, account.Id, manager, activity.PeerIPUpdated)
+	assert.Equal(t, peers[0].ID, ev.TargetID)
+}
+
+func TestDefaultAccountManager_MarkPeerConnected_EphemeralPeer(t *testing.T) {
+	manager, err := createManager(t)
+	require.NoError(t, err, "unable to create account manager")
+	account, err := manager.GetAccountByUserOrAccountID(userID, "", "")
+	require.NoError(t, err, "unable to create an account")
+
+	setupKey, err := manager.CreateSetupKey(account.Id, "ephemeral-key", SetupKeyReusable, time.Hour, []string{},
+		SetupKeyUnlimitedUsage, "", true, userID)
+	require.NoError(t, err, "unable to create ephemeral setup key")
+	assert.True(t, setupKey.Ephemeral)
+
+	key, err := wgtypes.GeneratePrivateKey()
+	require.NoError(t, err)
+	peer, _, err := manager.AddPeer(setupKey.Key, "", &Peer{
+		Key:  key.PublicKey().String(),
+		Meta: PeerSystemMeta{Hostname: "ephemeral-peer"},
+	})
+	require.NoError(t, err, "unable to add peer")
+	assert.True(t, peer.Ephemeral, "peer added with an ephemeral setup key should be ephemeral")
+
+	err = manager.MarkPeerConnected(key.PublicKey().String(), true)
+	require.NoError(t, err, "unable to mark peer connected")
+
+	var deletionJob func() (time.Duration, bool)
+	wg := &sync.WaitGroup{}
+	wg.Add(2)
+	manager.ephemeralPeers = &MockScheduler{
+		CancelFunc: func(IDs []string) {
+			wg.Done()
+		},
+		ScheduleFunc: func(in time.Duration, ID string, job func() (nextRunIn time.Duration, reschedule bool)) {
+			assert.Equal(t, account.Id, ID)
+			assert.LessOrEqual(t, in, DefaultEphemeralPeersGracePeriod)
+			deletionJob = job
+			wg.Done()
+		},
+	}
+
+	// disconnecting an ephemeral peer should schedule its deletion
+	err = manager.MarkPeerConnected(key.PublicKey().String(), false)
+	require.NoError(t, err, "unable to mark peer disconnected")
+	if waitTimeout(wg, time.Second) {
+		t.Fatal("timeout while waiting for the deletion to be scheduled")
+	}
+
+	// the job keeps the peer while it is within the grace period
+	nextRun, reschedule := deletionJob()
+	assert.True(t, reschedule)
+	assert.Greater(t, nextRun, time.Duration(0))
+	account, err = manager.Store.GetAccount(account.Id)
+	require.NoError(t, err)
+	require.Contains(t, account.Peers, peer.ID)
+
+	// move the last seen time beyond the grace period
+	err = manager.Store.SavePeerStatus(account.Id, peer.ID, PeerStatus{
+		Connected: false,
+		LastSeen:  time.Now().Add(-DefaultEphemeralPeersGracePeriod - time.Minute),
+	})
+	require.NoError(t, err)
+
+	_, reschedule = deletionJob()
+	assert.False(t, reschedule, "no ephemeral peers left to delete")
+
+	account, err = manager.Store.GetAccount(account.Id)
+	require.NoError(t, err)
+	assert.NotContains(t, account.Peers, peer.ID)
+	for _, group := range account.Groups {
+		assert.NotContains(t, group.Peers, peer.ID)
+	}
+
+	ev := getEvent(t, account.Id, manager, activity.EphemeralPeerRemoved)
+	assert.Equal(t, peer.ID, ev.TargetID)
+	assert.Equal(t, setupKey.Id, ev.InitiatorID)
+}
+
+func TestBuildManager_ConnectedEphemeralPeer(t *testing.T) {
+	store, err := createStore(t)
+	require.NoError(t, err, "unable to create store")
+
+	account := newAccountWithId("account_id", userID, "")
+	peer := &Peer{
+		ID:        "ephemeral-peer",
+		Key:       "ephemeral-peer-key",
+		IP:        net.IP{100, 64, 0, 1},
+		Meta:      PeerSystemMeta{Hostname: "ephemeral-peer"},
+		Ephemeral: true,
+		// stored as connected when the management was stopped
+		Status: &PeerStatus{Connected: true, LastSeen: time.Now().Add(-DefaultEphemeralPeersGracePeriod - time.Hour)},
+	}
+	account.Peers[peer.ID] = peer
+	account.Groups["ephemeral"] = &Group{
+		ID:    "ephemeral",
+		Name:  "Ephemeral",
+		Peers: []string{peer.ID},
+		Rules: []*GroupRule{{Attribute: GroupRuleAttributeHostname, Operator: GroupRuleOperatorEquals, Value: peer.Meta.Hostname}},
+	}
+	require.NoError(t, store.SaveAccount(account))
+
+	manager, err := BuildManager(store, NewPeersUpdateManager(), nil, "", "netbird.cloud", &activity.InMemoryEventStore{})
+	require.NoError(t, err, "unable to create account manager")
+
+	account, err = manager.Store.GetAccount(account.Id)
+	require.NoError(t, err)
+	peerStatus := account.Peers[peer.ID].Status
+	assert.False(t, peerStatus.Connected, "ephemeral peers should be disconnected on start")
+	assert.WithinDuration(t, time.Now(), peerStatus.LastSeen, time.Minute, "ephemeral peers should get the grace period to reconnect")
+
+	nextRun, ok := account.GetNextEphemeralPeerDeletion()
+	require.True(t, ok, "the deletion of the ephemeral peer should be scheduled")
+	assert.Greater(t, nextRun, time.Duration(0))
+
+	err = manager.Store.SavePeerStatus(account.Id, peer.ID, PeerStatus{
+		Connected: false,
+		LastSeen:  time.Now().Add(-DefaultEphemeralPeersGracePeriod - time.Minute),
+	})
+	require.NoError(t, err)
+
+	_, reschedule := manager.ephemeralPeersDeletionJob(account.Id)()
+	assert.False(t, reschedule, "no ephemeral peers left to delete")
+
+	account, err = manager.Store.GetAccount(account.Id)
+	require.NoError(t, err)
+	assert.NotContains(t, account.Peers, peer.ID)
+	assert.Empty(t, account.Groups["ephemeral"].Peers)
+}
+
+func TestDefaultAccountManager_PeerApproval(t *testing.T) {
+	manager, err := createManager(t)
+	require.NoError(t, err, "unable to create account manager")
+	account, err := manager.GetAccountByUserOrAccountID(userID, "", "")
+	require.NoError(t, err, "unable to create an account")
+
+	addPeer := func(hostname string) *Peer {
+		key, err := wgtypes.GeneratePrivateKey()
+		require.NoError(t, err)
+		peer, _, err := manager.AddPeer("", userID, &Peer{
+			Key:  key.PublicKey().String(),
+			Meta: PeerSystemMeta{Hostname: hostname},
+		})
+		require.NoError(t, err, "unable to add peer")
+		return peer
+	}
+
+	approvedPeer := addPeer("approved-peer")
+	assert.False(t, approvedPeer.ApprovalRequired(), "peers added before enabling approval should not require it")
+
+	_, err = manager.UpdateAccountSettings(account.Id, userID, &Settings{
+		PeerLoginExpiration:        time.Hour,
+		PeerLoginExpirationEnabled: true,
+		PeerApprovalEnabled:        true,
+	})
+	require.NoError(t, err, "unable to enable peer approval")
+
+	pendingPeer := addPeer("pending-peer")
+	assert.True(t, pendingPeer.ApprovalRequired(), "new peers should require approval")
+
+	networkMap, err := manager.GetNetworkMap(pendingPeer.ID)
+	require.NoError(t, err)
+	assert.Empty(t, networkMap.Peers, "pending peer should not see other peers")
+	assert.Empty(t, networkMap.Routes)
+	assert.Empty(t, networkMap.DNSConfig.CustomZones)
+
+	networkMap, err = manager.GetNetworkMap(approvedPeer.ID)
+	require.NoError(t, err)
+	assert.Empty(t, networkMap.Peers, "pending peer should not be visible to other peers")
+
+	approved, err := manager.ApprovePeer(account.Id, userID, pendingPeer.ID)
+	require.NoError(t, err, "unable to approve peer")
+	assert.False(t, approved.ApprovalRequired())
+
+	_, err = manager.ApprovePeer(account.Id, userID, pendingPeer.ID)
+	require.Error(t, err, "approving a peer that is not pending should fail")
+
+	networkMap, err = manager.GetNetworkMap(pendingPeer.ID)
+	require.NoError(t, err)
+	require.Len(t, networkMap.Peers, 1)
+	assert.Equal(t, approvedPeer.ID, networkMap.Peers[0].ID)
+
+	ev := getEvent(t, account.Id, manager, activity.PeerApproved)
+	assert.Equal(t, pendingPeer.ID, ev.TargetID)
+
+	rejectedPeer := addPeer("rejected-peer")
+	require.True(t, rejectedPeer.ApprovalRequired())
+	err = manager.RejectPeer(account.Id, userID, rejectedPeer.ID)
+	require.NoError(t, err, "unable to reject peer")
+
+	account, err = manager.Store.GetAccount(account.Id)
+	require.NoError(t, err)
+	assert.NotContains(t, account.Peers, rejectedPeer.ID, "rejected peer should be deleted")
+
+	ev = getEvent(t, account.Id, manager, activity.PeerRejected)
+	assert.Equal(t, rejectedPeer.ID, ev.TargetID)
+}
@@ -2,8 +2,10 @@ package server
 
 import (
 	"crypto/sha256"
+	b64 "encoding/base64"
 	"fmt"
 	"hash/crc32"
+	"strings"
 	"time"
 
 	"codeberg.org/ac/base62"
@@ -15,9 +17,14 @@ const (
 	// PATPrefix is the globally used, 4 char prefix for personal access tokens
 	PATPrefix    = "nbp_"
 	secretLength = 30
+	// checksumLength is the length of the base62 encoded and padded checksum at the end of the token
+	checksumLength = 6
+	// PATLength is the length of a plain personal access token
+	PATLength = len(PATPrefix) + secretLength + checksumLength
 )
 
-// PersonalAccessToken holds all information about a PAT including a hashed version of it for verification
+// PersonalAccessToken holds all information about a PAT including a hashed version of it for verification.
+// The HashedToken is the base64 encoded SHA-256 hash of the plain token
 type PersonalAccessToken struct {
 	ID             string
 	Description    string
@@ -29,6 +36,18 @@ type PersonalAccessToken struct {
 	LastUsed  time.Time
 }
 
+// PersonalAccessTokenGenerated holds a new PersonalAccessToken together with its plain token
+// which is shown once to the user and never stored
+type PersonalAccessTokenGenerated struct {
+	PlainToken string
+	PersonalAccessToken
+}
+
+// IsExpired returns true if the token has expired
+func (t *PersonalAccessToken) IsExpired() bool {
+	return time.Now().UTC().After(t.ExpirationDate)
+}
+
 // CreateNewPAT will generate a new PersonalAccessToken that can be assigned to a User.
 // Additionally, it will return the token in plain text once, to give to the user and only save a hashed version
 func CreateNewPAT(description string, expirationInDays int, createdBy string) (*PersonalAccessToken, string, error) {
@@ -54,10 +73,34 @@ func generateNewToken() (string, string, error) {
 		return "", "", err
 	}
 
-	checksum := crc32.ChecksumIEEE([]byte(secret))
-	encodedChecksum := base62.Encode(checksum)
-	paddedChecksum := fmt.Sprintf("%06s", encodedChecksum)
-	plainToken := PATPrefix + secret + paddedChecksum
+	plainToken := PATPrefix + secret + tokenChecksum(secret)
+	return HashPAT(plainToken), plainToken, nil
+}
+
+// HashPAT returns the hashed version of a plain personal access token the PersonalAccessToken is stored with
+func HashPAT(plainToken string) string {
 	hashedToken := sha256.Sum256([]byte(plainToken))
-	return string(hashedToken[:]), plainToken, nil
+	return b64.StdEncoding.EncodeToString(hashedToken[:])
+}
+
+// ValidatePATFormat checks the prefix, the length and the checksum of a plain personal access token,
+// so that malformed tokens are rejected without a lookup
+func ValidatePATFormat(plainToken string) error {
+	if len(plainToken) != PATLength {
+		return fmt.Errorf("token has an invalid length")
+	}
+	if !strings.HasPrefix(plainToken, PATPrefix) {
+		return fmt.Errorf("token has an invalid prefix")
+	}
+
+	secret := plainToken[len(PATPrefix) : len(plainToken)-checksumLength]
+	if tokenChecksum(secret) != plainToken[len(plainToken)-checksumLength:] {
+		return fmt.Errorf("token has an invalid checksum")
+	}
+	return nil
+}
+
+func tokenChecksum(secret string) string {
+	checksum := crc32.ChecksumIEEE([]byte(secret))
+	return fmt.Sprintf("%06s", base62.Encode(checksum))
 }
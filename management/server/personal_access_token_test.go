@@ -2,6 +2,7 @@ package server
 
 import (
 	"crypto/sha256"
+	b64 "encoding/base64"
 	"hash/crc32"
 	"strings"
 	"testing"
@@ -13,7 +14,7 @@ import (
 func TestPAT_GenerateToken_Hashing(t *testing.T) {
 	hashedToken, plainToken, _ := generateNewToken()
 	expectedToken := sha256.Sum256([]byte(plainToken))
-	assert.Equal(t, hashedToken, string(expectedToken[:]))
+	assert.Equal(t, hashedToken, b64.StdEncoding.EncodeToString(expectedToken[:]))
 }
 
 func TestPAT_GenerateToken_Prefix(t *testing.T) {
@@ -38,3 +39,21 @@ func TestPAT_GenerateToken_Checksum(t *testing.T) {
 	}
 	assert.Equal(t, expectedChecksum, actualChecksum)
 }
+
+func TestPAT_ValidateFormat(t *testing.T) {
+	_, plainToken, err := generateNewToken()
+	assert.NoError(t, err)
+	assert.NoError(t, ValidatePATFormat(plainToken))
+
+	assert.Error(t, ValidatePATFormat(""), "empty token should be rejected")
+	assert.Error(t, ValidatePATFormat("abc_"+plainToken[len(PATPrefix):]), "token with a wrong prefix should be rejected")
+	assert.Error(t, ValidatePATFormat(plainToken[:len(plainToken)-1]), "token with a wrong length should be rejected")
+
+	tampered := []byte(plainToken)
+	if tampered[len(PATPrefix)] == 'a' {
+		tampered[len(PATPrefix)] = 'b'
+	} else {
+		tampered[len(PATPrefix)] = 'a'
+	}
+	assert.Error(t, ValidatePATFormat(string(tampered)), "token with a wrong checksum should be rejected")
+}
@@ -3,15 +3,20 @@ package server
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
 	_ "embed"
 	"fmt"
 	"html/template"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/netbirdio/netbird/management/server/activity"
 	"github.com/netbirdio/netbird/management/server/status"
 
+	"github.com/open-policy-agent/opa/ast"
 	"github.com/open-policy-agent/opa/rego"
+	"github.com/open-policy-agent/opa/tester"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -43,6 +48,13 @@ var defaultPolicyText string
 // defaultPolicyTemplate is a template for the default policy
 var defaultPolicyTemplate = template.Must(template.New("policy").Parse(defaultPolicyText))
 
+const (
+	// policyModulePackage is the package the policy queries have to be defined in
+	policyModulePackage = "data.netbird"
+	// policyTestsTimeout is the maximum duration of the policy tests run on save
+	policyTestsTimeout = 5 * time.Second
+)
+
 // PolicyRule is the metadata of the policy
 type PolicyRule struct {
 	// ID of the policy rule
@@ -112,6 +124,12 @@ type Policy struct {
 
 	// Rules of the policy
 	Rules []*PolicyRule
+
+	// PostureChecks are IDs of the posture checks peers have to pass to be part of the policy
+	PostureChecks []string
+
+	// Tests are Rego unit tests of the policy query executed when the policy is saved
+	Tests string
 }
 
 // Copy returns a copy of the policy.
@@ -122,13 +140,28 @@ func (p *Policy) Copy() *Policy {
 		Description: p.Description,
 		Enabled:     p.Enabled,
 		Query:       p.Query,
+		Tests:       p.Tests,
 	}
 	for _, r := range p.Rules {
 		c.Rules = append(c.Rules, r.Copy())
 	}
+	if p.PostureChecks != nil {
+		c.PostureChecks = make([]string, len(p.PostureChecks))
+		copy(c.PostureChecks, p.PostureChecks)
+	}
 	return c
 }
 
+// appliesToAnyGroup checks whether any enabled rule of the policy has one of the groups as a source or destination
+func (p *Policy) appliesToAnyGroup(groups lookupMap) bool {
+	for _, r := range p.Rules {
+		if r.Enabled && (isInAnyGroup(groups, r.Sources) || isInAnyGroup(groups, r.Destinations)) {
+			return true
+		}
+	}
+	return false
+}
+
 // EventMeta returns activity event meta related to this policy
 func (p *Policy) EventMeta() map[string]any {
 	return map[string]any{"name": p.Name}
@@ -162,6 +195,91 @@ func (p *Policy) UpdateQueryFromRules() error {
 	return nil
 }
 
+// validatePolicyQuery compiles the Rego query of the policy together with the default policy module and the queries
+// of the policies it is evaluated with, and runs the Rego unit tests of the policy, if any
+func validatePolicyQuery(policy *Policy, policies []*Policy) error {
+	if strings.TrimSpace(policy.Query) == "" {
+		if strings.TrimSpace(policy.Tests) != "" {
+			return status.Errorf(status.InvalidArgument, "policy tests require a policy query")
+		}
+		return nil
+	}
+
+	defaultModule, err := ast.ParseModule("netbird.rego", defaultPolicyModule)
+	if err != nil {
+		return status.Errorf(status.Internal, "failed to parse default policy module: %v", err)
+	}
+
+	queryModule, err := ast.ParseModule("query.rego", policy.Query)
+	if err != nil {
+		return status.Errorf(status.InvalidArgument, "invalid policy query: %v", err)
+	}
+	if queryModule.Package.Path.String() != policyModulePackage {
+		return status.Errorf(status.InvalidArgument, "invalid policy query: %s",
+			queryModule.Package.Location.Format("package should be %s", strings.TrimPrefix(policyModulePackage, "data.")))
+	}
+
+	modules := map[string]*ast.Module{
+		"netbird.rego": defaultModule,
+		"query.rego":   queryModule,
+	}
+	for _, p := range policies {
+		name := fmt.Sprintf("policy-%s.rego", p.ID)
+		module, err := ast.ParseModule(name, p.Query)
+		if err != nil {
+			return status.Errorf(status.InvalidArgument, "invalid query of policy %s: %v", p.Name, err)
+		}
+		modules[name] = module
+	}
+
+	if strings.TrimSpace(policy.Tests) == "" {
+		compiler := ast.NewCompiler()
+		if compiler.Compile(modules); compiler.Failed() {
+			return status.Errorf(status.InvalidArgument, "invalid policy query: %v", compiler.Errors)
+		}
+		return nil
+	}
+
+	testsModule, err := ast.ParseModule("tests.rego", policy.Tests)
+	if err != nil {
+		return status.Errorf(status.InvalidArgument, "invalid policy tests: %v", err)
+	}
+	modules["tests.rego"] = testsModule
+
+	return runPolicyTests(modules)
+}
+
+// runPolicyTests compiles the modules and runs the test rules they contain, returning an error if any test fails
+func runPolicyTests(modules map[string]*ast.Module) error {
+	ctx, cancel := context.WithTimeout(context.TODO(), policyTestsTimeout)
+	defer cancel()
+
+	results, err := tester.NewRunner().SetTimeout(policyTestsTimeout).SetModules(modules).RunTests(ctx, nil)
+	if err != nil {
+		return status.Errorf(status.InvalidArgument, "invalid policy query or tests: %v", err)
+	}
+
+	var failures []string
+	count := 0
+	for result := range results {
+		count++
+		switch {
+		case result.Error != nil:
+			failures = append(failures, result.Location.Format("%s: %v", result.Name, result.Error))
+		case result.Fail:
+			failures = append(failures, result.Location.Format("%s failed", result.Name))
+		}
+	}
+
+	if count == 0 {
+		return status.Errorf(status.InvalidArgument, "policy tests should contain at least one rule prefixed with test_")
+	}
+	if len(failures) > 0 {
+		return status.Errorf(status.InvalidArgument, "policy tests failed: %s", strings.Join(failures, "; "))
+	}
+	return nil
+}
+
 // FirewallRule is a rule of the firewall.
 type FirewallRule struct {
 	// PeerID of the peer
@@ -221,101 +339,376 @@ func (f *FirewallRule) parseFromRegoResult(value interface{}) error {
 	return nil
 }
 
-// getRegoQuery returns a initialized Rego object with default rule.
-func (a *Account) getRegoQuery() (rego.PreparedEvalQuery, error) {
+// getPoliciesEvaluatedWith returns the other enabled policies of the account whose Rego queries getPeersByPolicy
+// evaluates together with the query of the given policy. Policies with posture checks are evaluated on their own
+func (a *Account) getPoliciesEvaluatedWith(policy *Policy) []*Policy {
+	if len(policy.PostureChecks) > 0 {
+		return nil
+	}
+
+	var policies []*Policy
+	for _, p := range a.Policies {
+		if p.ID == policy.ID || !p.Enabled || len(p.PostureChecks) > 0 || strings.TrimSpace(p.Query) == "" {
+			continue
+		}
+		policies = append(policies, p)
+	}
+	return policies
+}
+
+// regoQueryCacheSize is the maximum number of prepared Rego queries kept in the regoQueryCache
+const regoQueryCacheSize = 1000
+
+// regoQueryCache keeps the prepared Rego queries by the content of their policy queries, so that the modules are
+// not compiled again for every peer and every network map. Prepared queries are safe for concurrent use
+var regoQueryCache = struct {
+	mu      sync.Mutex
+	queries map[string]rego.PreparedEvalQuery
+}{queries: make(map[string]rego.PreparedEvalQuery)}
+
+// getRegoQuery returns a initialized Rego object with default rule and the given policies.
+// The prepared query is cached by the content of the policy queries
+func getRegoQuery(policies []*Policy) (rego.PreparedEvalQuery, error) {
+	key := regoQueryCacheKey(policies)
+
+	regoQueryCache.mu.Lock()
+	query, ok := regoQueryCache.queries[key]
+	regoQueryCache.mu.Unlock()
+	if ok {
+		return query, nil
+	}
+
 	queries := []func(*rego.Rego){
 		rego.Query("data.netbird.all"),
 		rego.Module("netbird", defaultPolicyModule),
 	}
-	for i, p := range a.Policies {
-		if !p.Enabled {
-			continue
-		}
+	for i, p := range policies {
 		queries = append(queries, rego.Module(fmt.Sprintf("netbird-%d", i), p.Query))
 	}
-	return rego.New(queries...).PrepareForEval(context.TODO())
+	query, err := rego.New(queries...).PrepareForEval(context.TODO())
+	if err != nil {
+		return query, err
+	}
+
+	regoQueryCache.mu.Lock()
+	defer regoQueryCache.mu.Unlock()
+	if len(regoQueryCache.queries) >= regoQueryCacheSize {
+		regoQueryCache.queries = make(map[string]rego.PreparedEvalQuery)
+	}
+	regoQueryCache.queries[key] = query
+	return query, nil
+}
+
+// regoQueryCacheKey returns the hash of the ordered policy queries
+func regoQueryCacheKey(policies []*Policy) string {
+	hash := sha256.New()
+	for _, p := range policies {
+		_, _ = fmt.Fprintf(hash, "%d:%s", len(p.Query), p.Query)
+	}
+	return string(hash.Sum(nil))
 }
 
 // getPeersByPolicy returns all peers that given peer has access to.
 func (a *Account) getPeersByPolicy(peerID string) ([]*Peer, []*FirewallRule) {
-	input := map[string]interface{}{
-		"peer_id": peerID,
-		"peers":   a.Peers,
-		"groups":  a.Groups,
+	peers := a.getPeersWithNetworkAccess()
+	if _, ok := peers[peerID]; !ok {
+		return nil, nil
 	}
 
-	query, err := a.getRegoQuery()
-	if err != nil {
-		log.WithError(err).Error("get Rego query")
-		return nil, nil
+	var rules []*FirewallRule
+	added := make(map[string]struct{})
+	for _, group := range a.getPolicyEvaluationGroups(peers) {
+		if _, ok := group.peers[peerID]; !ok {
+			continue
+		}
+		policyRules := a.evalPolicies(peerID, group.policies, group.peers)
+		addPeersFromRules(peerID, policyRules, added)
+		rules = append(rules, policyRules...)
 	}
 
-	evalResult, err := query.Eval(
-		context.TODO(),
-		rego.EvalInput(input),
-	)
-	if err != nil {
-		log.WithError(err).Error("eval Rego query")
-		return nil, nil
+	aclPeers := make([]*Peer, 0, len(added))
+	for id := range added {
+		aclPeers = append(aclPeers, a.Peers[id])
 	}
+	return aclPeers, rules
+}
 
-	if len(evalResult) == 0 || len(evalResult[0].Expressions) == 0 {
-		log.Trace("empty Rego query eval result")
-		return nil, nil
+// policyEvaluationGroup is a set of policies whose Rego queries are evaluated together for the peers they apply to
+type policyEvaluationGroup struct {
+	policies []*Policy
+	peers    map[string]*Peer
+}
+
+// getPolicyEvaluationGroups groups the enabled policies of the account the way their Rego queries are evaluated.
+// Policies with posture checks are evaluated one by one with the peers passing their checks only, so that the peers
+// failing the checks are excluded from these policies but not from the others. The other policies are evaluated
+// together with all the given peers
+func (a *Account) getPolicyEvaluationGroups(peers map[string]*Peer) []*policyEvaluationGroup {
+	var groups []*policyEvaluationGroup
+	var policies []*Policy
+	for _, policy := range a.Policies {
+		if !policy.Enabled {
+			continue
+		}
+		if len(policy.PostureChecks) == 0 {
+			policies = append(policies, policy)
+			continue
+		}
+		groups = append(groups, &policyEvaluationGroup{
+			policies: []*Policy{policy},
+			peers:    a.getPostureCompliantPeers(policy, peers),
+		})
 	}
-	expressions, ok := evalResult[0].Expressions[0].Value.([]interface{})
-	if !ok {
-		return nil, nil
+	if len(policies) > 0 {
+		groups = append(groups, &policyEvaluationGroup{policies: policies, peers: peers})
 	}
+	return groups
+}
 
-	dst := make(map[string]struct{})
-	src := make(map[string]struct{})
-	peers := make([]*Peer, 0, len(expressions))
-	rules := make([]*FirewallRule, 0, len(expressions))
-	for _, v := range expressions {
-		rule := &FirewallRule{}
-		if err := rule.parseFromRegoResult(v); err != nil {
-			log.WithError(err).Error("parse Rego query eval result")
+// getPeersWithNetworkAccess returns the peers that can be part of policies.
+// Peers pending an approval and peers of blocked users are not part of any policy.
+func (a *Account) getPeersWithNetworkAccess() map[string]*Peer {
+	peers := make(map[string]*Peer, len(a.Peers))
+	for id, peer := range a.Peers {
+		if a.hasNetworkAccess(peer) {
+			peers[id] = peer
+		}
+	}
+	return peers
+}
+
+// PolicyEvaluationQuery selects the source and destination peers of a policy evaluation.
+// Each side is either a peer or a group, in which case all peers of the group are evaluated.
+type PolicyEvaluationQuery struct {
+	SourcePeerID       string
+	SourceGroupID      string
+	DestinationPeerID  string
+	DestinationGroupID string
+	// Policy is an optional candidate policy evaluated in place of the account policy with the same ID,
+	// or in addition to the account policies if there is none
+	Policy *Policy
+}
+
+// PolicyEvaluation is the result of the policies evaluation between a source and a destination peer
+type PolicyEvaluation struct {
+	SourcePeerID      string
+	DestinationPeerID string
+	// Allowed indicates whether the peers have access to each other
+	Allowed bool
+	// Matches are the policies granting the access
+	Matches []*PolicyEvaluationMatch
+	// FirewallRules the source peer receives for the destination peer
+	FirewallRules []*FirewallRule
+}
+
+// PolicyEvaluationMatch is a policy granting access between two peers
+type PolicyEvaluationMatch struct {
+	Policy *Policy
+	// Rules of the policy the peers belong to, empty when access is granted by a custom Rego query
+	Rules []*PolicyRule
+}
+
+// evaluatePolicies evaluates the enabled policies of the account for every pair of the source and destination peers
+// in the same way as getPeersByPolicy does, but tracks which policy grants the access.
+// The policies evaluated together grant the access if their rules connect the peers, or, for custom Rego queries,
+// if the policy grants the access when it is evaluated on its own
+func (a *Account) evaluatePolicies(sources, destinations []string) []*PolicyEvaluation {
+	type groupResult struct {
+		group *policyEvaluationGroup
+		rules []*FirewallRule
+		peers map[string]struct{}
+	}
+
+	peers := a.getPeersWithNetworkAccess()
+	groups := a.getPolicyEvaluationGroups(peers)
+	queries := make([]*rego.PreparedEvalQuery, len(groups))
+	for i, group := range groups {
+		query, err := getRegoQuery(group.policies)
+		if err != nil {
+			log.WithError(err).Error("get Rego query")
 			continue
 		}
-		rules = append(rules, rule)
-		switch rule.Direction {
-		case "dst":
-			if _, ok := dst[rule.PeerID]; ok {
+		queries[i] = &query
+	}
+
+	evaluations := make([]*PolicyEvaluation, 0, len(sources)*len(destinations))
+	for _, sourceID := range sources {
+		var results []*groupResult
+		for i, group := range groups {
+			if _, ok := group.peers[sourceID]; !ok || queries[i] == nil {
 				continue
 			}
-			dst[rule.PeerID] = struct{}{}
-		case "src":
-			if _, ok := src[rule.PeerID]; ok {
+			result := &groupResult{
+				group: group,
+				rules: a.evalRegoQuery(*queries[i], sourceID, group.peers),
+				peers: make(map[string]struct{}),
+			}
+			addPeersFromRules(sourceID, result.rules, result.peers)
+			results = append(results, result)
+		}
+
+		// peers the custom Rego query of a policy grants the source access to, by policy ID
+		policyPeers := make(map[string]map[string]struct{})
+		getPolicyPeers := func(policy *Policy) map[string]struct{} {
+			if added, ok := policyPeers[policy.ID]; ok {
+				return added
+			}
+			added := make(map[string]struct{})
+			addPeersFromRules(sourceID, a.evalPolicies(sourceID, []*Policy{policy}, peers), added)
+			policyPeers[policy.ID] = added
+			return added
+		}
+
+		for _, destinationID := range destinations {
+			if destinationID == sourceID {
 				continue
 			}
+			evaluation := &PolicyEvaluation{
+				SourcePeerID:      sourceID,
+				DestinationPeerID: destinationID,
+			}
+			for _, result := range results {
+				if _, ok := result.peers[destinationID]; !ok {
+					continue
+				}
+				for _, rule := range result.rules {
+					if rule.PeerID == destinationID {
+						evaluation.FirewallRules = append(evaluation.FirewallRules, rule)
+					}
+				}
+				for _, policy := range result.group.policies {
+					rules := a.getMatchingPolicyRules(policy, sourceID, destinationID)
+					if len(rules) == 0 && len(result.group.policies) > 1 {
+						if _, ok := getPolicyPeers(policy)[destinationID]; !ok {
+							continue
+						}
+					}
+					evaluation.Matches = append(evaluation.Matches, &PolicyEvaluationMatch{Policy: policy, Rules: rules})
+				}
+			}
+			evaluation.Allowed = len(evaluation.FirewallRules) > 0
+			evaluations = append(evaluations, evaluation)
+		}
+	}
+	return evaluations
+}
+
+// getMatchingPolicyRules returns the enabled rules of the policy connecting the peers in any direction
+func (a *Account) getMatchingPolicyRules(policy *Policy, sourceID, destinationID string) []*PolicyRule {
+	sourceGroups := a.getPeerGroups(sourceID)
+	destinationGroups := a.getPeerGroups(destinationID)
+
+	var rules []*PolicyRule
+	for _, rule := range policy.Rules {
+		if !rule.Enabled {
+			continue
+		}
+		if (isInAnyGroup(sourceGroups, rule.Sources) && isInAnyGroup(destinationGroups, rule.Destinations)) ||
+			(isInAnyGroup(sourceGroups, rule.Destinations) && isInAnyGroup(destinationGroups, rule.Sources)) {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// getPolicyEvaluationPeers returns the ID of the peer or the IDs of the peers of the group
+func (a *Account) getPolicyEvaluationPeers(peerID, groupID string) ([]string, error) {
+	if peerID != "" && groupID != "" {
+		return nil, status.Errorf(status.InvalidArgument, "either a peer or a group should be provided, not both")
+	}
+	if peerID != "" {
+		if _, ok := a.Peers[peerID]; !ok {
+			return nil, status.Errorf(status.NotFound, "peer with ID %s not found", peerID)
+		}
+		return []string{peerID}, nil
+	}
+	if groupID != "" {
+		group, ok := a.Groups[groupID]
+		if !ok {
+			return nil, status.Errorf(status.NotFound, "group with ID %s not found", groupID)
+		}
+		return group.Peers, nil
+	}
+	return nil, status.Errorf(status.InvalidArgument, "either a peer or a group should be provided")
+}
+
+// addPeersFromRules adds the IDs of the peers the given peer has access to according to the rules to the added set
+func addPeersFromRules(peerID string, rules []*FirewallRule, added map[string]struct{}) {
+	dst := make(map[string]struct{})
+	src := make(map[string]struct{})
+	for _, rule := range rules {
+		switch rule.Direction {
+		case "dst":
+			dst[rule.PeerID] = struct{}{}
+		case "src":
 			src[rule.PeerID] = struct{}{}
 		default:
 			log.WithField("direction", rule.Direction).Error("invalid direction")
-			continue
 		}
 	}
 
-	added := make(map[string]struct{})
 	if _, ok := src[peerID]; ok {
 		for id := range dst {
-			if _, ok := added[id]; !ok && id != peerID {
+			if id != peerID {
 				added[id] = struct{}{}
 			}
 		}
 	}
 	if _, ok := dst[peerID]; ok {
 		for id := range src {
-			if _, ok := added[id]; !ok && id != peerID {
+			if id != peerID {
 				added[id] = struct{}{}
 			}
 		}
 	}
+}
 
-	for id := range added {
-		peers = append(peers, a.Peers[id])
+// evalPolicies evaluates the Rego queries of the policies for the peer and returns the resulting firewall rules
+func (a *Account) evalPolicies(peerID string, policies []*Policy, peers map[string]*Peer) []*FirewallRule {
+	query, err := getRegoQuery(policies)
+	if err != nil {
+		log.WithError(err).Error("get Rego query")
+		return nil
+	}
+	return a.evalRegoQuery(query, peerID, peers)
+}
+
+// evalRegoQuery evaluates the prepared Rego query for the peer and returns the resulting firewall rules
+func (a *Account) evalRegoQuery(query rego.PreparedEvalQuery, peerID string, peers map[string]*Peer) []*FirewallRule {
+	input := map[string]interface{}{
+		"peer_id": peerID,
+		"peers":   peers,
+		"groups":  a.Groups,
+	}
+
+	evalResult, err := query.Eval(
+		context.TODO(),
+		rego.EvalInput(input),
+	)
+	if err != nil {
+		log.WithError(err).Error("eval Rego query")
+		return nil
+	}
+
+	if len(evalResult) == 0 || len(evalResult[0].Expressions) == 0 {
+		log.Trace("empty Rego query eval result")
+		return nil
 	}
-	return peers, rules
+	expressions, ok := evalResult[0].Expressions[0].Value.([]interface{})
+	if !ok {
+		return nil
+	}
+
+	rules := make([]*FirewallRule, 0, len(expressions))
+	for _, v := range expressions {
+		rule := &FirewallRule{}
+		if err := rule.parseFromRegoResult(v); err != nil {
+			log.WithError(err).Error("parse Rego query eval result")
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	return rules
 }
 
 // GetPolicy from the store
@@ -346,6 +739,52 @@ func (am *DefaultAccountManager) GetPolicy(accountID, policyID, userID string) (
 	return nil, status.Errorf(status.NotFound, "policy with ID %s not found", policyID)
 }
 
+// EvaluatePolicies evaluates the account policies between the source and destination of the query.
+// A candidate policy of the query is evaluated on a copy of the account which is never saved
+func (am *DefaultAccountManager) EvaluatePolicies(accountID, userID string, query *PolicyEvaluationQuery) ([]*PolicyEvaluation, error) {
+	unlock := am.Store.AcquireAccountLock(accountID)
+	defer unlock()
+
+	account, err := am.Store.GetAccount(accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := account.FindUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.IsAdmin() {
+		return nil, status.Errorf(status.PermissionDenied, "only admins are allowed to evaluate policies")
+	}
+
+	if query.Policy != nil {
+		if err = validatePolicyPostureChecks(query.Policy, account); err != nil {
+			return nil, err
+		}
+
+		if err = validatePolicyQuery(query.Policy, account.getPoliciesEvaluatedWith(query.Policy)); err != nil {
+			return nil, err
+		}
+
+		account = account.Copy()
+		am.savePolicy(account, query.Policy)
+	}
+
+	sources, err := account.getPolicyEvaluationPeers(query.SourcePeerID, query.SourceGroupID)
+	if err != nil {
+		return nil, err
+	}
+
+	destinations, err := account.getPolicyEvaluationPeers(query.DestinationPeerID, query.DestinationGroupID)
+	if err != nil {
+		return nil, err
+	}
+
+	return account.evaluatePolicies(sources, destinations), nil
+}
+
 // SavePolicy in the store
 func (am *DefaultAccountManager) SavePolicy(accountID, userID string, policy *Policy) error {
 	unlock := am.Store.AcquireAccountLock(accountID)
@@ -356,6 +795,14 @@ func (am *DefaultAccountManager) SavePolicy(accountID, userID string, policy *Po
 		return err
 	}
 
+	if err = validatePolicyPostureChecks(policy, account); err != nil {
+		return err
+	}
+
+	if err = validatePolicyQuery(policy, account.getPoliciesEvaluatedWith(policy)); err != nil {
+		return err
+	}
+
 	exists := am.savePolicy(account, policy)
 
 	account.Network.IncSerial()
@@ -4,7 +4,12 @@ import (
 	"net"
 	"testing"
 
+	"github.com/open-policy-agent/opa/rego"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
+
+	"github.com/netbirdio/netbird/management/server/status"
 )
 
 func TestAccount_getPeersByPolicy(t *testing.T) {
@@ -65,3 +70,359 @@ func TestAccount_getPeersByPolicy(t *testing.T) {
 		assert.Equal(t, firewallRules[i], epectedFirewallRules[i])
 	}
 }
+
+func TestAccount_evaluatePolicies(t *testing.T) {
+	account := &Account{
+		Peers: map[string]*Peer{
+			"peer1": {
+				ID: "peer1",
+				IP: net.IPv4(10, 20, 0, 1),
+			},
+			"peer2": {
+				ID: "peer2",
+				IP: net.IPv4(10, 20, 0, 2),
+			},
+			"peer3": {
+				ID: "peer3",
+				IP: net.IPv4(10, 20, 0, 3),
+			},
+		},
+		Groups: map[string]*Group{
+			"gid1": {
+				ID:    "gid1",
+				Name:  "dev",
+				Peers: []string{"peer1", "peer2"},
+			},
+			"gid2": {
+				ID:    "gid2",
+				Name:  "prod",
+				Peers: []string{"peer3"},
+			},
+		},
+	}
+
+	policy := &Policy{
+		ID:      "policy1",
+		Name:    "dev",
+		Enabled: true,
+		Rules: []*PolicyRule{
+			{
+				ID:           "rule1",
+				Name:         "dev",
+				Enabled:      true,
+				Action:       PolicyTrafficActionAccept,
+				Sources:      []string{"gid1"},
+				Destinations: []string{"gid1"},
+			},
+		},
+	}
+	assert.NoError(t, policy.UpdateQueryFromRules())
+	account.Policies = append(account.Policies, policy)
+
+	t.Run("peers with access", func(t *testing.T) {
+		sources, err := account.getPolicyEvaluationPeers("peer1", "")
+		assert.NoError(t, err)
+		destinations, err := account.getPolicyEvaluationPeers("peer2", "")
+		assert.NoError(t, err)
+
+		evaluations := account.evaluatePolicies(sources, destinations)
+		assert.Len(t, evaluations, 1)
+		assert.True(t, evaluations[0].Allowed)
+		assert.Len(t, evaluations[0].Matches, 1)
+
+		match := evaluations[0].Matches[0]
+		assert.Equal(t, policy, match.Policy)
+		assert.Equal(t, []*PolicyRule{policy.Rules[0]}, match.Rules)
+		assert.ElementsMatch(t, []*FirewallRule{
+			{PeerID: "peer2", PeerIP: "10.20.0.2", Direction: "dst", Action: "accept", Port: ""},
+			{PeerID: "peer2", PeerIP: "10.20.0.2", Direction: "src", Action: "accept", Port: ""},
+		}, evaluations[0].FirewallRules)
+	})
+
+	t.Run("policies evaluated together", func(t *testing.T) {
+		custom := &Policy{
+			ID:      "custom",
+			Name:    "custom",
+			Enabled: true,
+			Query: `package netbird
+
+all[rule] {
+	is_peer_in_any_group(["gid1","gid2"])
+	rule := array.concat(
+		rules_from_groups(["gid2"], "dst", "accept", ""),
+		rules_from_groups(["gid1"], "src", "accept", ""),
+	)[_]
+}`,
+		}
+		account.Policies = append(account.Policies, custom)
+		defer func() { account.Policies = account.Policies[:1] }()
+
+		evaluations := account.evaluatePolicies([]string{"peer1"}, []string{"peer2", "peer3"})
+		require.Len(t, evaluations, 2)
+
+		require.True(t, evaluations[0].Allowed)
+		require.Len(t, evaluations[0].Matches, 1, "the custom query doesn't grant access to peer2")
+		assert.Equal(t, policy, evaluations[0].Matches[0].Policy)
+
+		require.True(t, evaluations[1].Allowed)
+		require.Len(t, evaluations[1].Matches, 1)
+		assert.Equal(t, custom, evaluations[1].Matches[0].Policy)
+		assert.Empty(t, evaluations[1].Matches[0].Rules, "custom queries have no matching rules")
+		assert.Equal(t, []*FirewallRule{
+			{PeerID: "peer3", PeerIP: "10.20.0.3", Direction: "dst", Action: "accept", Port: ""},
+		}, evaluations[1].FirewallRules)
+
+		peers, _ := account.getPeersByPolicy("peer1")
+		assert.ElementsMatch(t, []*Peer{account.Peers["peer2"], account.Peers["peer3"]}, peers,
+			"the evaluation should grant the access of the network map")
+	})
+
+	t.Run("groups without access", func(t *testing.T) {
+		sources, err := account.getPolicyEvaluationPeers("", "gid1")
+		assert.NoError(t, err)
+		destinations, err := account.getPolicyEvaluationPeers("", "gid2")
+		assert.NoError(t, err)
+
+		evaluations := account.evaluatePolicies(sources, destinations)
+		assert.Len(t, evaluations, 2)
+		for _, evaluation := range evaluations {
+			assert.Equal(t, "peer3", evaluation.DestinationPeerID)
+			assert.False(t, evaluation.Allowed)
+			assert.Empty(t, evaluation.Matches)
+		}
+	})
+
+	t.Run("disabled policy", func(t *testing.T) {
+		policy.Enabled = false
+		defer func() { policy.Enabled = true }()
+
+		evaluations := account.evaluatePolicies([]string{"peer1"}, []string{"peer2"})
+		assert.Len(t, evaluations, 1)
+		assert.False(t, evaluations[0].Allowed)
+	})
+
+	t.Run("invalid query", func(t *testing.T) {
+		_, err := account.getPolicyEvaluationPeers("", "")
+		assert.Error(t, err)
+		_, err = account.getPolicyEvaluationPeers("peer1", "gid1")
+		assert.Error(t, err)
+		_, err = account.getPolicyEvaluationPeers("unknown", "")
+		assert.Error(t, err)
+	})
+}
+
+func TestGetRegoQuery_Cache(t *testing.T) {
+	regoQueryCache.mu.Lock()
+	regoQueryCache.queries = make(map[string]rego.PreparedEvalQuery)
+	regoQueryCache.mu.Unlock()
+	cacheLen := func() int {
+		regoQueryCache.mu.Lock()
+		defer regoQueryCache.mu.Unlock()
+		return len(regoQueryCache.queries)
+	}
+
+	policy, err := RuleToPolicy(&Rule{ID: "default", Name: "default", Source: []string{"gid1"}, Destination: []string{"gid1"}})
+	require.NoError(t, err)
+
+	_, err = getRegoQuery([]*Policy{policy})
+	require.NoError(t, err)
+	_, err = getRegoQuery([]*Policy{policy.Copy()})
+	require.NoError(t, err)
+	assert.Equal(t, 1, cacheLen(), "policies with the same queries should share the prepared query")
+
+	other := policy.Copy()
+	other.Rules[0].Destinations = []string{"gid2"}
+	require.NoError(t, other.UpdateQueryFromRules())
+	_, err = getRegoQuery([]*Policy{other})
+	require.NoError(t, err)
+	_, err = getRegoQuery([]*Policy{policy, other})
+	require.NoError(t, err)
+	assert.Equal(t, 3, cacheLen(), "policies with other queries should be prepared again")
+
+	invalid := policy.Copy()
+	invalid.Query = "package netbird\n\ninvalid"
+	_, err = getRegoQuery([]*Policy{invalid})
+	require.Error(t, err)
+	assert.Equal(t, 3, cacheLen(), "invalid queries should not be cached")
+}
+
+func TestValidatePolicyQuery(t *testing.T) {
+	policy := &Policy{
+		Rules: []*PolicyRule{
+			{
+				ID:           "rule1",
+				Enabled:      true,
+				Action:       PolicyTrafficActionAccept,
+				Sources:      []string{"gid1"},
+				Destinations: []string{"gid2"},
+			},
+		},
+	}
+	require.NoError(t, policy.UpdateQueryFromRules())
+
+	passingTests := `package netbird
+
+import future.keywords.in
+
+test_source_peer_gets_destination_peers {
+	rules := all with input as {
+		"peer_id": "peer1",
+		"peers": {"peer1": {"ID": "peer1", "IP": "10.20.0.1"}, "peer2": {"ID": "peer2", "IP": "10.20.0.2"}},
+		"groups": {"gid1": {"ID": "gid1", "Peers": ["peer1"]}, "gid2": {"ID": "gid2", "Peers": ["peer2"]}},
+	}
+	{"ID": "peer2", "IP": "10.20.0.2", "Direction": "dst", "Action": "accept", "Port": ""} in rules
+}
+`
+	failingTests := `package netbird
+
+test_no_rules {
+	count(all) == 0 with input as {
+		"peer_id": "peer1",
+		"peers": {"peer1": {"ID": "peer1", "IP": "10.20.0.1"}},
+		"groups": {"gid1": {"ID": "gid1", "Peers": ["peer1"]}},
+	}
+}
+`
+
+	tt := []struct {
+		name          string
+		query         string
+		tests         string
+		expectedError string
+	}{
+		{name: "empty query", query: ""},
+		{name: "query generated from rules", query: policy.Query},
+		{name: "syntax error", query: "package netbird\n\nall[rule] {\n\trule := \n}", expectedError: "query.rego:5"},
+		{name: "wrong package", query: "package other\n\nall[rule] {\n\trule := 1\n}", expectedError: "query.rego:1: package should be netbird"},
+		{name: "undefined function", query: "package netbird\n\nall[rule] {\n\trule := undefined_rules()[_]\n}", expectedError: "query.rego:4"},
+		{name: "passing tests", query: policy.Query, tests: passingTests},
+		{name: "failing tests", query: policy.Query, tests: failingTests, expectedError: "tests.rego:3: test_no_rules failed"},
+		{name: "tests without test rules", query: policy.Query, tests: "package netbird\n\nhelper := 1\n", expectedError: "at least one rule"},
+		{name: "tests without query", tests: passingTests, expectedError: "require a policy query"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validatePolicyQuery(&Policy{Query: tc.query, Tests: tc.tests}, nil)
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.expectedError)
+			sErr, ok := status.FromError(err)
+			require.True(t, ok)
+			assert.Equal(t, status.InvalidArgument, sErr.Type())
+		})
+	}
+}
+
+func TestDefaultAccountManager_SavePolicyWithInvalidQuery(t *testing.T) {
+	manager, err := createManager(t)
+	require.NoError(t, err, "unable to create account manager")
+	account, err := manager.GetAccountByUserOrAccountID(userID, "", "")
+	require.NoError(t, err, "unable to create an account")
+
+	policy := &Policy{ID: "policy", Name: "policy", Enabled: true, Query: "package netbird\n\nall[rule] {"}
+	err = manager.SavePolicy(account.Id, userID, policy)
+	require.Error(t, err, "policy with an invalid query should be rejected")
+
+	_, err = manager.GetPolicy(account.Id, policy.ID, userID)
+	assert.Error(t, err, "rejected policy should not be saved")
+}
+
+func TestDefaultAccountManager_SavePolicyWithConflictingQuery(t *testing.T) {
+	manager, err := createManager(t)
+	require.NoError(t, err, "unable to create account manager")
+	account, err := manager.GetAccountByUserOrAccountID(userID, "", "")
+	require.NoError(t, err, "unable to create an account")
+
+	query := "package netbird\n\ndefault allowed = false\n"
+	stored := &Policy{ID: "stored", Name: "stored", Enabled: true, Query: query}
+	require.NoError(t, manager.SavePolicy(account.Id, userID, stored), "unable to save policy")
+	require.NoError(t, manager.SavePolicy(account.Id, userID, stored), "the policy should not conflict with itself")
+
+	conflicting := &Policy{ID: "conflicting", Name: "conflicting", Enabled: true, Query: query}
+	err = manager.SavePolicy(account.Id, userID, conflicting)
+	require.Error(t, err, "a policy conflicting with another enabled policy should be rejected")
+	sErr, ok := status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, status.InvalidArgument, sErr.Type())
+	_, err = manager.GetPolicy(account.Id, conflicting.ID, userID)
+	assert.Error(t, err, "rejected policy should not be saved")
+
+	_, err = manager.EvaluatePolicies(account.Id, userID, &PolicyEvaluationQuery{Policy: conflicting})
+	require.Error(t, err, "a conflicting candidate should not be evaluated")
+
+	check := &PostureCheck{ID: "linux-only", Name: "Linux only", AllowedOS: []string{"linux"}}
+	require.NoError(t, manager.SavePostureCheck(account.Id, userID, check), "unable to save posture check")
+	conflicting.PostureChecks = []string{check.ID}
+	require.NoError(t, manager.SavePolicy(account.Id, userID, conflicting), "policies with posture checks are evaluated on their own")
+
+	conflicting.PostureChecks = nil
+	stored.Enabled = false
+	require.NoError(t, manager.SavePolicy(account.Id, userID, stored), "unable to disable policy")
+	require.NoError(t, manager.SavePolicy(account.Id, userID, conflicting), "disabled policies should not conflict")
+}
+
+func TestDefaultAccountManager_EvaluatePoliciesWithCandidate(t *testing.T) {
+	manager, err := createManager(t)
+	require.NoError(t, err, "unable to create account manager")
+	account, err := manager.GetAccountByUserOrAccountID(userID, "", "")
+	require.NoError(t, err, "unable to create an account")
+
+	addPeer := func(hostname string) *Peer {
+		key, err := wgtypes.GeneratePrivateKey()
+		require.NoError(t, err)
+		peer, _, err := manager.AddPeer("", userID, &Peer{Key: key.PublicKey().String(), Meta: PeerSystemMeta{Hostname: hostname}})
+		require.NoError(t, err, "unable to add peer")
+		return peer
+	}
+	peer1 := addPeer("peer-1")
+	peer2 := addPeer("peer-2")
+
+	account, err = manager.Store.GetAccount(account.Id)
+	require.NoError(t, err)
+	require.Len(t, account.Policies, 1, "the account should have the default policy")
+	defaultPolicy := account.Policies[0]
+
+	evaluate := func(candidate *Policy) ([]*PolicyEvaluation, error) {
+		return manager.EvaluatePolicies(account.Id, userID, &PolicyEvaluationQuery{
+			SourcePeerID:      peer1.ID,
+			DestinationPeerID: peer2.ID,
+			Policy:            candidate,
+		})
+	}
+
+	evaluations, err := evaluate(nil)
+	require.NoError(t, err)
+	require.Len(t, evaluations, 1)
+	assert.True(t, evaluations[0].Allowed, "the default policy should allow the access")
+
+	disabled := defaultPolicy.Copy()
+	disabled.Enabled = false
+	evaluations, err = evaluate(disabled)
+	require.NoError(t, err)
+	require.Len(t, evaluations, 1)
+	assert.False(t, evaluations[0].Allowed, "the candidate should replace the policy with the same ID")
+
+	added := defaultPolicy.Copy()
+	added.ID = "candidate"
+	evaluations, err = evaluate(added)
+	require.NoError(t, err)
+	require.Len(t, evaluations, 1)
+	require.Len(t, evaluations[0].Matches, 2, "the candidate should be added to the account policies")
+	assert.Equal(t, "candidate", evaluations[0].Matches[1].Policy.ID)
+
+	invalid := &Policy{ID: "invalid", Name: "invalid", Enabled: true, Query: "package netbird\n\nall {"}
+	_, err = evaluate(invalid)
+	require.Error(t, err, "an invalid candidate should be rejected")
+	errStatus, ok := status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, status.InvalidArgument, errStatus.Type())
+
+	account, err = manager.Store.GetAccount(account.Id)
+	require.NoError(t, err)
+	require.Len(t, account.Policies, 1, "candidates should not be saved")
+	assert.True(t, account.Policies[0].Enabled, "candidates should not change the saved policies")
+}
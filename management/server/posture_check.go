@@ -0,0 +1,404 @@
+package server
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hashicorp/go-version"
+
+	nbdns "github.com/netbirdio/netbird/dns"
+	"github.com/netbirdio/netbird/management/server/activity"
+	"github.com/netbirdio/netbird/management/server/status"
+)
+
+// PostureCheck defines requirements on the system of a peer, reported with PeerSystemMeta.
+// Peers that don't pass a check are excluded from the policies referring to it.
+// Empty requirements are not checked
+type PostureCheck struct {
+	// ID of the posture check
+	ID string
+	// Name of the posture check visible in the UI
+	Name string
+	// Description of the posture check visible in the UI
+	Description string
+	// MinVersion is the minimum NetBird version peers have to run
+	MinVersion string
+	// AllowedOS is a list of operating systems peers have to run, matched against PeerSystemMeta.GoOS or PeerSystemMeta.OS
+	AllowedOS []string
+	// AllowedPlatforms is a list of platforms (architectures) peers have to run on, matched against PeerSystemMeta.Platform
+	AllowedPlatforms []string
+	// MinKernelVersion is the minimum kernel version peers have to run, compared to PeerSystemMeta.Core
+	MinKernelVersion string
+	// HostnamePatterns is a list of shell patterns one of which the peer hostname has to match
+	HostnamePatterns []string
+}
+
+// PostureCheckFailure describes a posture check a peer doesn't pass
+type PostureCheckFailure struct {
+	// CheckID is the ID of the failed posture check
+	CheckID string
+	// CheckName is the name of the failed posture check
+	CheckName string
+	// Reason why the peer doesn't pass the check
+	Reason string
+}
+
+// Copy returns a copy of the posture check
+func (c *PostureCheck) Copy() *PostureCheck {
+	check := &PostureCheck{
+		ID:               c.ID,
+		Name:             c.Name,
+		Description:      c.Description,
+		MinVersion:       c.MinVersion,
+		AllowedOS:        make([]string, len(c.AllowedOS)),
+		AllowedPlatforms: make([]string, len(c.AllowedPlatforms)),
+		MinKernelVersion: c.MinKernelVersion,
+		HostnamePatterns: make([]string, len(c.HostnamePatterns)),
+	}
+	copy(check.AllowedOS, c.AllowedOS)
+	copy(check.AllowedPlatforms, c.AllowedPlatforms)
+	copy(check.HostnamePatterns, c.HostnamePatterns)
+	return check
+}
+
+// EventMeta returns activity event meta related to the posture check
+func (c *PostureCheck) EventMeta() map[string]any {
+	return map[string]any{"name": c.Name}
+}
+
+// Check returns an error describing the first requirement the peer system doesn't meet or nil if the peer passes the check
+func (c *PostureCheck) Check(meta PeerSystemMeta) error {
+	if c.MinVersion != "" {
+		err := checkMinVersion("NetBird", meta.WtVersion, c.MinVersion)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(c.AllowedOS) > 0 && !containsFold(c.AllowedOS, meta.GoOS) && !containsFold(c.AllowedOS, meta.OS) {
+		return fmt.Errorf("operating system %s is not allowed", meta.OS)
+	}
+
+	if len(c.AllowedPlatforms) > 0 && !containsFold(c.AllowedPlatforms, meta.Platform) {
+		return fmt.Errorf("platform %s is not allowed", meta.Platform)
+	}
+
+	if c.MinKernelVersion != "" {
+		err := checkMinVersion("kernel", meta.Core, c.MinKernelVersion)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(c.HostnamePatterns) > 0 && !matchesAnyPattern(c.HostnamePatterns, meta.Hostname) {
+		return fmt.Errorf("hostname %s doesn't match any of the allowed patterns", meta.Hostname)
+	}
+
+	return nil
+}
+
+// checkMinVersion compares the release segments of the versions ignoring pre-release and build suffixes,
+// e.g., the kernel version 5.15.0-76-generic is considered 5.15.0
+func checkMinVersion(name, current, minimum string) error {
+	currentVersion, err := version.NewVersion(current)
+	if err != nil {
+		return fmt.Errorf("unable to parse %s version %q", name, current)
+	}
+
+	minVersion, err := version.NewVersion(minimum)
+	if err != nil {
+		return fmt.Errorf("unable to parse minimum %s version %q", name, minimum)
+	}
+
+	if currentVersion.Core().LessThan(minVersion.Core()) {
+		return fmt.Errorf("%s version %s is lower than the required %s", name, current, minimum)
+	}
+
+	return nil
+}
+
+func containsFold(list []string, s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesAnyPattern(patterns []string, s string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(strings.ToLower(pattern), strings.ToLower(s)); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// GetPostureCheck validates a user role and returns the posture check with the provided ID
+func (am *DefaultAccountManager) GetPostureCheck(accountID, checkID, userID string) (*PostureCheck, error) {
+	unlock := am.Store.AcquireAccountLock(accountID)
+	defer unlock()
+
+	account, err := am.Store.GetAccount(accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := account.FindUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.IsAdmin() {
+		return nil, status.Errorf(status.PermissionDenied, "only admins are allowed to view posture checks")
+	}
+
+	check, ok := account.PostureChecks[checkID]
+	if !ok {
+		return nil, status.Errorf(status.NotFound, "posture check with ID %s not found", checkID)
+	}
+
+	return check.Copy(), nil
+}
+
+// SavePostureCheck validates a user role and creates or updates a posture check
+func (am *DefaultAccountManager) SavePostureCheck(accountID, userID string, checkToSave *PostureCheck) error {
+	unlock := am.Store.AcquireAccountLock(accountID)
+	defer unlock()
+
+	if checkToSave == nil {
+		return status.Errorf(status.InvalidArgument, "posture check provided is nil")
+	}
+
+	account, err := am.Store.GetAccount(accountID)
+	if err != nil {
+		return err
+	}
+
+	user, err := account.FindUser(userID)
+	if err != nil {
+		return err
+	}
+
+	if !user.IsAdmin() {
+		return status.Errorf(status.PermissionDenied, "only admins are allowed to update posture checks")
+	}
+
+	err = validatePostureCheck(checkToSave)
+	if err != nil {
+		return err
+	}
+
+	if account.PostureChecks == nil {
+		account.PostureChecks = make(map[string]*PostureCheck)
+	}
+
+	_, exists := account.PostureChecks[checkToSave.ID]
+	account.PostureChecks[checkToSave.ID] = checkToSave.Copy()
+
+	account.Network.IncSerial()
+	if err = am.Store.SaveAccount(account); err != nil {
+		return err
+	}
+
+	action := activity.PostureCheckCreated
+	if exists {
+		action = activity.PostureCheckUpdated
+	}
+	am.storeEvent(userID, checkToSave.ID, accountID, action, checkToSave.EventMeta())
+
+	return am.updateAccountPeers(account)
+}
+
+// DeletePostureCheck validates a user role and deletes the posture check with the provided ID.
+// Posture checks that policies refer to can't be deleted
+func (am *DefaultAccountManager) DeletePostureCheck(accountID, checkID, userID string) error {
+	unlock := am.Store.AcquireAccountLock(accountID)
+	defer unlock()
+
+	account, err := am.Store.GetAccount(accountID)
+	if err != nil {
+		return err
+	}
+
+	user, err := account.FindUser(userID)
+	if err != nil {
+		return err
+	}
+
+	if !user.IsAdmin() {
+		return status.Errorf(status.PermissionDenied, "only admins are allowed to delete posture checks")
+	}
+
+	check, ok := account.PostureChecks[checkID]
+	if !ok {
+		return status.Errorf(status.NotFound, "posture check with ID %s not found", checkID)
+	}
+
+	for _, policy := range account.Policies {
+		for _, id := range policy.PostureChecks {
+			if id == checkID {
+				return status.Errorf(status.PreconditionFailed, "posture check %s is used by policy %s", check.Name, policy.Name)
+			}
+		}
+	}
+
+	delete(account.PostureChecks, checkID)
+
+	if err = am.Store.SaveAccount(account); err != nil {
+		return err
+	}
+
+	am.storeEvent(userID, check.ID, accountID, activity.PostureCheckDeleted, check.EventMeta())
+
+	return nil
+}
+
+// ListPostureChecks validates a user role and returns the posture checks of the account
+func (am *DefaultAccountManager) ListPostureChecks(accountID, userID string) ([]*PostureCheck, error) {
+	unlock := am.Store.AcquireAccountLock(accountID)
+	defer unlock()
+
+	account, err := am.Store.GetAccount(accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := account.FindUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.IsAdmin() {
+		return nil, status.Errorf(status.PermissionDenied, "only admins are allowed to view posture checks")
+	}
+
+	checks := make([]*PostureCheck, 0, len(account.PostureChecks))
+	for _, check := range account.PostureChecks {
+		checks = append(checks, check.Copy())
+	}
+
+	return checks, nil
+}
+
+// GetPeerPostureCheckFailures returns the posture checks the peer doesn't pass of the enabled policies
+// that have any group of the peer in their rules
+func (a *Account) GetPeerPostureCheckFailures(peerID string) []PostureCheckFailure {
+	peer, ok := a.Peers[peerID]
+	if !ok {
+		return nil
+	}
+
+	peerGroups := a.getPeerGroups(peerID)
+	checked := make(lookupMap)
+	var failures []PostureCheckFailure
+	for _, policy := range a.Policies {
+		if !policy.Enabled || !policy.appliesToAnyGroup(peerGroups) {
+			continue
+		}
+		for _, checkID := range policy.PostureChecks {
+			if _, ok := checked[checkID]; ok {
+				continue
+			}
+			checked[checkID] = struct{}{}
+
+			if err := a.checkPeerPosture(peer, checkID); err != nil {
+				failure := PostureCheckFailure{CheckID: checkID, Reason: err.Error()}
+				if check, ok := a.PostureChecks[checkID]; ok {
+					failure.CheckName = check.Name
+				}
+				failures = append(failures, failure)
+			}
+		}
+	}
+
+	return failures
+}
+
+// getPeerFailedPostureChecks returns the IDs of the posture checks the peer doesn't pass
+func (a *Account) getPeerFailedPostureChecks(peerID string) lookupMap {
+	failed := make(lookupMap)
+	for _, failure := range a.GetPeerPostureCheckFailures(peerID) {
+		failed[failure.CheckID] = struct{}{}
+	}
+	return failed
+}
+
+// checkPeerPosture checks the peer against the posture check with the given ID.
+// Missing posture checks are failed so that a policy never grants more access than intended
+func (a *Account) checkPeerPosture(peer *Peer, checkID string) error {
+	check, ok := a.PostureChecks[checkID]
+	if !ok {
+		return fmt.Errorf("posture check %s not found", checkID)
+	}
+	return check.Check(peer.Meta)
+}
+
+// getPostureCompliantPeers returns the peers passing all the posture checks of the policy
+func (a *Account) getPostureCompliantPeers(policy *Policy, peers map[string]*Peer) map[string]*Peer {
+	compliant := make(map[string]*Peer, len(peers))
+	for id, peer := range peers {
+		passed := true
+		for _, checkID := range policy.PostureChecks {
+			if a.checkPeerPosture(peer, checkID) != nil {
+				passed = false
+				break
+			}
+		}
+		if passed {
+			compliant[id] = peer
+		}
+	}
+	return compliant
+}
+
+func validatePostureCheck(check *PostureCheck) error {
+	if check.ID == "" {
+		return status.Errorf(status.InvalidArgument, "posture check ID should not be empty")
+	}
+
+	if utf8.RuneCountInString(check.Name) > nbdns.MaxGroupNameChar || check.Name == "" {
+		return status.Errorf(status.InvalidArgument, "posture check name should be between 1 and %d", nbdns.MaxGroupNameChar)
+	}
+
+	if check.MinVersion == "" && check.MinKernelVersion == "" && len(check.AllowedOS) == 0 &&
+		len(check.AllowedPlatforms) == 0 && len(check.HostnamePatterns) == 0 {
+		return status.Errorf(status.InvalidArgument, "posture check should define at least one requirement")
+	}
+
+	if check.MinVersion != "" {
+		if _, err := version.NewVersion(check.MinVersion); err != nil {
+			return status.Errorf(status.InvalidArgument, "invalid minimum NetBird version %q", check.MinVersion)
+		}
+	}
+
+	if check.MinKernelVersion != "" {
+		if _, err := version.NewVersion(check.MinKernelVersion); err != nil {
+			return status.Errorf(status.InvalidArgument, "invalid minimum kernel version %q", check.MinKernelVersion)
+		}
+	}
+
+	for _, pattern := range check.HostnamePatterns {
+		if _, err := path.Match(pattern, ""); err != nil || pattern == "" {
+			return status.Errorf(status.InvalidArgument, "invalid hostname pattern %q", pattern)
+		}
+	}
+
+	return nil
+}
+
+// validatePolicyPostureChecks checks that the posture checks referred to by the policy exist
+func validatePolicyPostureChecks(policy *Policy, account *Account) error {
+	for _, checkID := range policy.PostureChecks {
+		if _, ok := account.PostureChecks[checkID]; !ok {
+			return status.Errorf(status.InvalidArgument, "posture check with ID %s not found", checkID)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,264 @@
+package server
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
+)
+
+func TestPostureCheck_Check(t *testing.T) {
+	meta := PeerSystemMeta{
+		Hostname:  "prod-db-1",
+		GoOS:      "linux",
+		Core:      "5.15.0-76-generic",
+		Platform:  "x86_64",
+		OS:        "Ubuntu",
+		WtVersion: "0.21.3",
+	}
+
+	testCases := []struct {
+		name       string
+		check      *PostureCheck
+		shouldFail bool
+	}{
+		{
+			name:  "Passes Min Version",
+			check: &PostureCheck{MinVersion: "0.21.0"},
+		},
+		{
+			name:       "Fails Min Version",
+			check:      &PostureCheck{MinVersion: "0.22.0"},
+			shouldFail: true,
+		},
+		{
+			name:  "Passes Allowed OS Type",
+			check: &PostureCheck{AllowedOS: []string{"darwin", "Linux"}},
+		},
+		{
+			name:  "Passes Allowed OS Name",
+			check: &PostureCheck{AllowedOS: []string{"ubuntu"}},
+		},
+		{
+			name:       "Fails Allowed OS",
+			check:      &PostureCheck{AllowedOS: []string{"windows"}},
+			shouldFail: true,
+		},
+		{
+			name:       "Fails Allowed Platforms",
+			check:      &PostureCheck{AllowedPlatforms: []string{"arm64"}},
+			shouldFail: true,
+		},
+		{
+			name:  "Passes Min Kernel Version Ignoring Suffix",
+			check: &PostureCheck{MinKernelVersion: "5.15"},
+		},
+		{
+			name:       "Fails Min Kernel Version",
+			check:      &PostureCheck{MinKernelVersion: "6.1"},
+			shouldFail: true,
+		},
+		{
+			name:  "Passes Hostname Pattern",
+			check: &PostureCheck{HostnamePatterns: []string{"dev-*", "prod-*"}},
+		},
+		{
+			name:       "Fails Hostname Pattern",
+			check:      &PostureCheck{HostnamePatterns: []string{"dev-*"}},
+			shouldFail: true,
+		},
+		{
+			name:       "Fails On Any Requirement",
+			check:      &PostureCheck{MinVersion: "0.20.0", AllowedOS: []string{"linux"}, HostnamePatterns: []string{"dev-*"}},
+			shouldFail: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := testCase.check.Check(meta)
+			if testCase.shouldFail {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+
+	err := (&PostureCheck{MinVersion: "0.21.0"}).Check(PeerSystemMeta{WtVersion: "development"})
+	require.Error(t, err, "unparsable versions should fail the check")
+}
+
+func TestAccount_getPeersByPolicy_PostureChecks(t *testing.T) {
+	account := &Account{
+		Peers: map[string]*Peer{
+			"peer1": {ID: "peer1", IP: net.IPv4(10, 20, 0, 1), Meta: PeerSystemMeta{GoOS: "linux"}},
+			"peer2": {ID: "peer2", IP: net.IPv4(10, 20, 0, 2), Meta: PeerSystemMeta{GoOS: "linux"}},
+			"peer3": {ID: "peer3", IP: net.IPv4(10, 20, 0, 3), Meta: PeerSystemMeta{GoOS: "windows"}},
+		},
+		Groups: map[string]*Group{
+			"all": {ID: "all", Name: "All", Peers: []string{"peer1", "peer2", "peer3"}},
+			"ops": {ID: "ops", Name: "ops", Peers: []string{"peer1", "peer3"}},
+		},
+		PostureChecks: map[string]*PostureCheck{
+			"linux-only": {ID: "linux-only", Name: "Linux only", AllowedOS: []string{"linux"}},
+		},
+	}
+
+	restricted := &Policy{
+		ID: "restricted", Name: "restricted", Enabled: true, PostureChecks: []string{"linux-only"},
+		Rules: []*PolicyRule{{ID: "restricted", Enabled: true, Sources: []string{"all"}, Destinations: []string{"all"}}},
+	}
+	require.NoError(t, restricted.UpdateQueryFromRules())
+	account.Policies = []*Policy{restricted}
+
+	peers, _ := account.getPeersByPolicy("peer1")
+	assert.Len(t, peers, 1)
+	assert.Contains(t, peers, account.Peers["peer2"])
+
+	peers, _ = account.getPeersByPolicy("peer3")
+	assert.Empty(t, peers, "non-compliant peer should be excluded from the policy")
+
+	failures := account.GetPeerPostureCheckFailures("peer3")
+	require.Len(t, failures, 1)
+	assert.Equal(t, "linux-only", failures[0].CheckID)
+	assert.Equal(t, "Linux only", failures[0].CheckName)
+	assert.Empty(t, account.GetPeerPostureCheckFailures("peer1"))
+
+	// a policy without posture checks still applies to the non-compliant peer
+	unrestricted := &Policy{
+		ID: "unrestricted", Name: "unrestricted", Enabled: true,
+		Rules: []*PolicyRule{{ID: "unrestricted", Enabled: true, Sources: []string{"ops"}, Destinations: []string{"ops"}}},
+	}
+	require.NoError(t, unrestricted.UpdateQueryFromRules())
+	account.Policies = append(account.Policies, unrestricted)
+
+	peers, _ = account.getPeersByPolicy("peer3")
+	assert.Len(t, peers, 1)
+	assert.Contains(t, peers, account.Peers["peer1"])
+
+	peers, _ = account.getPeersByPolicy("peer1")
+	assert.Len(t, peers, 2)
+
+	peers, _ = account.getPeersByPolicy("peer2")
+	assert.Len(t, peers, 1, "peer3 should not reach peer2 through the unrestricted policy")
+	assert.Contains(t, peers, account.Peers["peer1"])
+}
+
+func TestDefaultAccountManager_PostureChecks(t *testing.T) {
+	manager, err := createManager(t)
+	require.NoError(t, err, "unable to create account manager")
+	account, err := manager.GetAccountByUserOrAccountID(userID, "", "")
+	require.NoError(t, err, "unable to create an account")
+
+	invalidChecks := []*PostureCheck{
+		{ID: "check", Name: "no requirements"},
+		{ID: "check", AllowedOS: []string{"linux"}},
+		{ID: "check", Name: "bad version", MinVersion: "latest"},
+		{ID: "check", Name: "bad pattern", HostnamePatterns: []string{"prod-["}},
+	}
+	for _, check := range invalidChecks {
+		err = manager.SavePostureCheck(account.Id, userID, check)
+		require.Error(t, err, "posture check %+v should be rejected", check)
+	}
+
+	check := &PostureCheck{ID: "check", Name: "min version", MinVersion: "0.21.0"}
+	err = manager.SavePostureCheck(account.Id, userID, check)
+	require.NoError(t, err, "unable to save posture check")
+
+	saved, err := manager.GetPostureCheck(account.Id, check.ID, userID)
+	require.NoError(t, err)
+	assert.Equal(t, check.MinVersion, saved.MinVersion)
+
+	policy := &Policy{ID: "policy", Name: "policy", Enabled: true, PostureChecks: []string{"missing"}}
+	err = manager.SavePolicy(account.Id, userID, policy)
+	require.Error(t, err, "policy referring to a missing posture check should be rejected")
+
+	policy.PostureChecks = []string{check.ID}
+	err = manager.SavePolicy(account.Id, userID, policy)
+	require.NoError(t, err, "unable to save policy with posture check")
+
+	err = manager.DeletePostureCheck(account.Id, check.ID, userID)
+	require.Error(t, err, "posture check used by a policy should not be deleted")
+
+	err = manager.DeletePolicy(account.Id, policy.ID, userID)
+	require.NoError(t, err)
+
+	err = manager.DeletePostureCheck(account.Id, check.ID, userID)
+	require.NoError(t, err, "unable to delete posture check")
+
+	checks, err := manager.ListPostureChecks(account.Id, userID)
+	require.NoError(t, err)
+	assert.Empty(t, checks)
+}
+
+func TestDefaultAccountManager_LoginPeerPostureChange(t *testing.T) {
+	manager, err := createManager(t)
+	require.NoError(t, err, "unable to create account manager")
+	account, err := manager.GetAccountByUserOrAccountID(userID, "", "")
+	require.NoError(t, err, "unable to create an account")
+
+	addPeer := func(hostname string) *Peer {
+		key, err := wgtypes.GeneratePrivateKey()
+		require.NoError(t, err)
+		peer, _, err := manager.AddPeer("", userID, &Peer{
+			Key:  key.PublicKey().String(),
+			Meta: PeerSystemMeta{Hostname: hostname, GoOS: "linux"},
+		})
+		require.NoError(t, err, "unable to add peer")
+		return peer
+	}
+	peer1 := addPeer("peer-1")
+	peer2 := addPeer("peer-2")
+
+	check := &PostureCheck{ID: "linux-only", Name: "Linux only", AllowedOS: []string{"linux"}}
+	require.NoError(t, manager.SavePostureCheck(account.Id, userID, check), "unable to save posture check")
+
+	account, err = manager.Store.GetAccount(account.Id)
+	require.NoError(t, err)
+	allGroup, err := account.GetGroupAll()
+	require.NoError(t, err)
+	for _, policy := range account.Policies {
+		require.NoError(t, manager.DeletePolicy(account.Id, policy.ID, userID))
+	}
+
+	policy := &Policy{
+		ID: "restricted", Name: "restricted", Enabled: true, PostureChecks: []string{check.ID},
+		Rules: []*PolicyRule{{
+			ID: "restricted", Name: "restricted", Enabled: true, Action: PolicyTrafficActionAccept,
+			Sources: []string{allGroup.ID}, Destinations: []string{allGroup.ID},
+		}},
+	}
+	require.NoError(t, policy.UpdateQueryFromRules())
+	require.NoError(t, manager.SavePolicy(account.Id, userID, policy), "unable to save policy")
+
+	updates := manager.peersUpdateManager.CreateChannel(peer2.ID)
+
+	_, _, err = manager.LoginPeer(PeerLogin{
+		WireGuardPubKey: peer1.Key,
+		Meta:            PeerSystemMeta{Hostname: "peer-1", GoOS: "windows"},
+	})
+	require.NoError(t, err, "unable to login peer")
+
+	select {
+	case update := <-updates:
+		assert.Empty(t, update.Update.NetworkMap.RemotePeers, "the non-compliant peer should be removed from the network map")
+	case <-time.After(time.Second):
+		t.Fatal("peers should receive an update once the posture compliance of a peer changes")
+	}
+
+	_, _, err = manager.LoginPeer(PeerLogin{
+		WireGuardPubKey: peer1.Key,
+		Meta:            PeerSystemMeta{Hostname: "peer-1", GoOS: "windows"},
+	})
+	require.NoError(t, err, "unable to login peer")
+
+	select {
+	case <-updates:
+		t.Fatal("peers shouldn't receive an update when the posture compliance doesn't change")
+	default:
+	}
+}
@@ -1,11 +1,16 @@
 package server
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
 	"github.com/google/uuid"
 	"github.com/netbirdio/netbird/management/server/activity"
 	"github.com/netbirdio/netbird/management/server/status"
 	log "github.com/sirupsen/logrus"
 	"hash/fnv"
+	"net"
 	"strconv"
 	"strings"
 	"time"
@@ -24,6 +29,11 @@ const (
 	DefaultSetupKeyName = "Default key"
 	// SetupKeyUnlimitedUsage indicates an unlimited usage of a setup key
 	SetupKeyUnlimitedUsage = 0
+
+	// setupKeyPrefixLength is the number of characters of a setup key that are kept visible to identify it
+	setupKeyPrefixLength = 5
+	// setupKeyLength is the length of a plaintext setup key (an upper case UUID)
+	setupKeyLength = 36
 )
 
 const (
@@ -66,8 +76,15 @@ type SetupKeyType string
 
 // SetupKey represents a pre-authorized key used to register machines (peers)
 type SetupKey struct {
-	Id        string
-	Key       string
+	Id string
+	// Key is the salted hash of the setup key, see HashSetupKey. It holds the plaintext key only when the key is
+	// generated and until it is saved to the Store
+	Key string
+	// KeySalt is the random salt the Key is hashed with. It is empty as long as the Key holds the plaintext key
+	KeySalt string
+	// KeyPrefix is the visible beginning of the plaintext key used to identify the key.
+	// It is empty as long as the Key holds the plaintext key
+	KeyPrefix string
 	Name      string
 	Type      SetupKeyType
 	CreatedAt time.Time
@@ -84,6 +101,12 @@ type SetupKey struct {
 	// UsageLimit indicates the number of times this key can be used to enroll a machine.
 	// The value of 0 indicates the unlimited usage.
 	UsageLimit int
+	// IPPool is an optional CIDR range within the account network (e.g. 100.64.10.0/24).
+	// Peers registered with this key get their IP allocated from this range. Empty value means the whole network.
+	IPPool string
+	// Ephemeral indicates that peers registered with this key are deleted once they have been disconnected
+	// for longer than Settings.EphemeralPeersGracePeriod
+	Ephemeral bool
 }
 
 // Copy copies SetupKey to a new object
@@ -96,6 +119,8 @@ func (key *SetupKey) Copy() *SetupKey {
 	return &SetupKey{
 		Id:         key.Id,
 		Key:        key.Key,
+		KeySalt:    key.KeySalt,
+		KeyPrefix:  key.KeyPrefix,
 		Name:       key.Name,
 		Type:       key.Type,
 		CreatedAt:  key.CreatedAt,
@@ -106,6 +131,8 @@ func (key *SetupKey) Copy() *SetupKey {
 		LastUsed:   key.LastUsed,
 		AutoGroups: autoGroups,
 		UsageLimit: key.UsageLimit,
+		IPPool:     key.IPPool,
+		Ephemeral:  key.Ephemeral,
 	}
 }
 
@@ -118,14 +145,126 @@ func (key *SetupKey) EventMeta() map[string]any {
 // E.g., "831F6*******************************"
 func (key *SetupKey) HiddenCopy(length int) *SetupKey {
 	k := key.Copy()
-	prefix := k.Key[0:5]
-	if length > utf8.RuneCountInString(key.Key) {
-		length = utf8.RuneCountInString(key.Key) - len(prefix)
+	prefix := k.KeyPrefix
+	if prefix == "" {
+		prefix = plaintextKeyPrefix(k.Key)
+	}
+	if length > setupKeyLength {
+		length = setupKeyLength - utf8.RuneCountInString(prefix)
 	}
 	k.Key = prefix + strings.Repeat("*", length)
 	return k
 }
 
+// IsHashed is true if the Key holds the hash of the setup key instead of the plaintext key
+func (key *SetupKey) IsHashed() bool {
+	return key.KeyPrefix != ""
+}
+
+// hashed returns a copy of the key with the plaintext Key replaced by its hash with a new random salt and the KeyPrefix set.
+// Keys that are hashed already are copied as they are
+func (key *SetupKey) hashed() (*SetupKey, error) {
+	k := key.Copy()
+	if k.IsHashed() {
+		return k, nil
+	}
+	salt, err := NewSetupKeySalt()
+	if err != nil {
+		return nil, err
+	}
+	k.KeyPrefix = plaintextKeyPrefix(k.Key)
+	k.KeySalt = salt
+	k.Key = HashSetupKey(salt, k.Key)
+	return k, nil
+}
+
+// matches returns true if the key is the provided plaintext setup key
+func (key *SetupKey) matches(plainKey string) bool {
+	if !key.IsHashed() {
+		return strings.EqualFold(key.Key, plainKey)
+	}
+	if !strings.EqualFold(key.KeyPrefix, plaintextKeyPrefix(plainKey)) {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(key.Key), []byte(HashSetupKey(key.KeySalt, plainKey))) == 1
+}
+
+func plaintextKeyPrefix(key string) string {
+	if utf8.RuneCountInString(key) < setupKeyPrefixLength {
+		return key
+	}
+	return string([]rune(key)[:setupKeyPrefixLength])
+}
+
+// hashSetupKeys replaces the plaintext setup keys of the account with their salted hashes.
+// Peers that reference a plaintext setup key they were registered with are updated to reference the hash
+func hashSetupKeys(account *Account) error {
+	hashedKeys := make(map[string]*SetupKey)
+	for id, key := range account.SetupKeys {
+		if key.IsHashed() {
+			continue
+		}
+		hashedKey, err := key.hashed()
+		if err != nil {
+			return err
+		}
+		hashedKeys[id] = hashedKey
+	}
+
+	for id, hashedKey := range hashedKeys {
+		delete(account.SetupKeys, id)
+		account.SetupKeys[hashedKey.Key] = hashedKey
+	}
+
+	for _, peer := range account.Peers {
+		if peer.SetupKey == "" {
+			continue
+		}
+		if hashedKey, ok := hashedKeys[strings.ToUpper(peer.SetupKey)]; ok {
+			peer.SetupKey = hashedKey.Key
+		}
+	}
+
+	return nil
+}
+
+// setLegacySetupKeySalt sets the salt of the account setup keys that were hashed with the salt shared by all keys
+// of the Store before every key got its own salt
+func setLegacySetupKeySalt(account *Account, salt string) {
+	for _, key := range account.SetupKeys {
+		if key.IsHashed() && key.KeySalt == "" {
+			key.KeySalt = salt
+		}
+	}
+}
+
+// findSetupKeyByPlainKey looks for a SetupKey by the plaintext key in the Account or returns error if it wasn't found.
+func (a *Account) findSetupKeyByPlainKey(plainKey string) (*SetupKey, error) {
+	for _, key := range a.SetupKeys {
+		if key.matches(plainKey) {
+			return key, nil
+		}
+	}
+
+	return nil, status.Errorf(status.NotFound, "setup key not found")
+}
+
+// HashSetupKey returns the hash a setup key is stored as: the base64 encoded SHA-256 of the salt and the upper case key
+func HashSetupKey(salt, key string) string {
+	sum := sha256.Sum256([]byte(salt + strings.ToUpper(key)))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// NewSetupKeySalt generates a new random salt for hashing a setup key
+func NewSetupKeySalt() (string, error) {
+	salt := make([]byte, 32)
+	_, err := rand.Read(salt)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(salt), nil
+}
+
 // IncrementUsage makes a copy of a key, increments the UsedTimes by 1 and sets LastUsed to now
 func (key *SetupKey) IncrementUsage() *SetupKey {
 	c := key.Copy()
@@ -187,6 +326,25 @@ func GenerateDefaultSetupKey() *SetupKey {
 		SetupKeyUnlimitedUsage)
 }
 
+// validateSetupKeyIPPool checks that the ipPool is either empty or a valid CIDR range inside the account network
+func validateSetupKeyIPPool(network *Network, ipPool string) error {
+	if ipPool == "" {
+		return nil
+	}
+
+	_, pool, err := net.ParseCIDR(ipPool)
+	if err != nil {
+		return status.Errorf(status.InvalidArgument, "invalid setup key IP pool %s", ipPool)
+	}
+
+	if !isSubnet(network.Net, *pool) {
+		return status.Errorf(status.InvalidArgument, "setup key IP pool %s is not part of the account network %s",
+			ipPool, network.Net.String())
+	}
+
+	return nil
+}
+
 func Hash(s string) uint32 {
 	h := fnv.New32a()
 	_, err := h.Write([]byte(s))
@@ -198,8 +356,11 @@ func Hash(s string) uint32 {
 
 // CreateSetupKey generates a new setup key with a given name, type, list of groups IDs to auto-assign to peers registered with this key,
 // and adds it to the specified account. A list of autoGroups IDs can be empty.
+// The ipPool is an optional CIDR range within the account network that peers registered with this key get their IPs from.
+// Peers registered with an ephemeral key are deleted automatically after being disconnected for the grace period of the account.
+// The returned key holds the plaintext key. It is the only time the plaintext key is available as only its hash is stored.
 func (am *DefaultAccountManager) CreateSetupKey(accountID string, keyName string, keyType SetupKeyType,
-	expiresIn time.Duration, autoGroups []string, usageLimit int, userID string) (*SetupKey, error) {
+	expiresIn time.Duration, autoGroups []string, usageLimit int, ipPool string, ephemeral bool, userID string) (*SetupKey, error) {
 	unlock := am.Store.AcquireAccountLock(accountID)
 	defer unlock()
 
@@ -219,7 +380,14 @@ func (am *DefaultAccountManager) CreateSetupKey(accountID string, keyName string
 		}
 	}
 
+	err = validateSetupKeyIPPool(account.Network, ipPool)
+	if err != nil {
+		return nil, err
+	}
+
 	setupKey := GenerateSetupKey(keyName, keyType, keyDuration, autoGroups, usageLimit)
+	setupKey.IPPool = ipPool
+	setupKey.Ephemeral = ephemeral
 	account.SetupKeys[setupKey.Key] = setupKey
 	err = am.Store.SaveAccount(account)
 	if err != nil {
@@ -244,7 +412,7 @@ func (am *DefaultAccountManager) CreateSetupKey(accountID string, keyName string
 // SaveSetupKey saves the provided SetupKey to the database overriding the existing one.
 // Due to the unique nature of a SetupKey certain properties must not be overwritten
 // (e.g. the key itself, creation date, ID, etc).
-// These properties are overwritten: Name, AutoGroups, Revoked. The rest is copied from the existing key.
+// These properties are overwritten: Name, AutoGroups, Revoked, IPPool. The rest is copied from the existing key.
 func (am *DefaultAccountManager) SaveSetupKey(accountID string, keyToSave *SetupKey, userID string) (*SetupKey, error) {
 	unlock := am.Store.AcquireAccountLock(accountID)
 	defer unlock()
@@ -269,14 +437,23 @@ func (am *DefaultAccountManager) SaveSetupKey(accountID string, keyToSave *Setup
 		return nil, status.Errorf(status.NotFound, "setup key not found")
 	}
 
-	// only auto groups, revoked status, and name can be updated for now
+	err = validateSetupKeyIPPool(account.Network, keyToSave.IPPool)
+	if err != nil {
+		return nil, err
+	}
+
+	// only auto groups, revoked status, IP pool, and name can be updated for now
 	newKey := oldKey.Copy()
 	newKey.Name = keyToSave.Name
 	newKey.AutoGroups = keyToSave.AutoGroups
 	newKey.Revoked = keyToSave.Revoked
+	newKey.IPPool = keyToSave.IPPool
 	newKey.UpdatedAt = time.Now()
 
 	account.SetupKeys[newKey.Key] = newKey
+	if account.updateDynamicGroups() {
+		account.Network.IncSerial()
+	}
 
 	if err = am.Store.SaveAccount(account); err != nil {
 		return nil, err
@@ -311,7 +488,7 @@ func (am *DefaultAccountManager) SaveSetupKey(accountID string, keyToSave *Setup
 		}
 	}()
 
-	return newKey, am.updateAccountPeers(account)
+	return newKey.HiddenCopy(999), am.updateAccountPeers(account)
 }
 
 // ListSetupKeys returns a list of all setup keys of the account
@@ -323,20 +500,15 @@ func (am *DefaultAccountManager) ListSetupKeys(accountID, userID string) ([]*Set
 		return nil, err
 	}
 
-	user, err := account.FindUser(userID)
+	_, err = account.FindUser(userID)
 	if err != nil {
 		return nil, err
 	}
 
+	// the plaintext keys are not stored, only their prefix can be shown
 	keys := make([]*SetupKey, 0, len(account.SetupKeys))
 	for _, key := range account.SetupKeys {
-		var k *SetupKey
-		if !user.IsAdmin() {
-			k = key.HiddenCopy(999)
-		} else {
-			k = key.Copy()
-		}
-		keys = append(keys, k)
+		keys = append(keys, key.HiddenCopy(999))
 	}
 
 	return keys, nil
@@ -352,7 +524,7 @@ func (am *DefaultAccountManager) GetSetupKey(accountID, userID, keyID string) (*
 		return nil, err
 	}
 
-	user, err := account.FindUser(userID)
+	_, err = account.FindUser(userID)
 	if err != nil {
 		return nil, err
 	}
@@ -373,9 +545,5 @@ func (am *DefaultAccountManager) GetSetupKey(accountID, userID, keyID string) (*
 		foundKey.UpdatedAt = foundKey.CreatedAt
 	}
 
-	if !user.IsAdmin() {
-		foundKey = foundKey.HiddenCopy(999)
-	}
-
-	return foundKey, nil
+	return foundKey.HiddenCopy(999), nil
 }
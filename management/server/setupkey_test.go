@@ -5,7 +5,9 @@ import (
 	"github.com/google/uuid"
 	"github.com/netbirdio/netbird/management/server/activity"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 )
@@ -35,7 +37,7 @@ func TestDefaultAccountManager_SaveSetupKey(t *testing.T) {
 	keyName := "my-test-key"
 
 	key, err := manager.CreateSetupKey(account.Id, keyName, SetupKeyReusable, expiresIn, []string{},
-		SetupKeyUnlimitedUsage, userID)
+		SetupKeyUnlimitedUsage, "", false, userID)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -53,6 +55,10 @@ func TestDefaultAccountManager_SaveSetupKey(t *testing.T) {
 		t.Fatal(err)
 	}
 
+	// only the prefix of the plaintext key can be returned after the key has been created
+	assert.Equal(t, key.HiddenCopy(999).Key, newKey.Key)
+	newKey.Key = key.Key
+
 	assertKey(t, newKey, newKeyName, revoked, "reusable", 0, key.CreatedAt, key.ExpiresAt,
 		key.Id, time.Now(), autoGroups)
 
@@ -134,7 +140,7 @@ func TestDefaultAccountManager_CreateSetupKey(t *testing.T) {
 	for _, tCase := range []testCase{testCase1, testCase2} {
 		t.Run(tCase.name, func(t *testing.T) {
 			key, err := manager.CreateSetupKey(account.Id, tCase.expectedKeyName, SetupKeyReusable, expiresIn,
-				tCase.expectedGroups, SetupKeyUnlimitedUsage, userID)
+				tCase.expectedGroups, SetupKeyUnlimitedUsage, "", false, userID)
 
 			if tCase.expectedFailure {
 				if err == nil {
@@ -287,3 +293,29 @@ func TestSetupKey_Copy(t *testing.T) {
 		key.UpdatedAt, key.AutoGroups)
 
 }
+
+func TestSetupKey_Hashed(t *testing.T) {
+	key := GenerateSetupKey("key name", SetupKeyOneOff, time.Hour, []string{}, SetupKeyUnlimitedUsage)
+	assert.False(t, key.IsHashed())
+
+	hashedKey, err := key.hashed()
+	require.NoError(t, err)
+	assert.True(t, hashedKey.IsHashed())
+	assert.Equal(t, key.Key[:5], hashedKey.KeyPrefix)
+	assert.NotEmpty(t, hashedKey.KeySalt)
+	assert.Equal(t, HashSetupKey(hashedKey.KeySalt, key.Key), hashedKey.Key)
+	assert.Equal(t, key.HiddenCopy(999).Key, hashedKey.HiddenCopy(999).Key)
+	hashedAgain, err := hashedKey.hashed()
+	require.NoError(t, err)
+	assert.Equal(t, hashedKey, hashedAgain, "expected a hashed key not to be hashed again")
+	assert.True(t, hashedKey.matches(strings.ToLower(key.Key)))
+	assert.False(t, hashedKey.matches(hashedKey.Key))
+
+	otherHashedKey, err := key.hashed()
+	require.NoError(t, err)
+	assert.NotEqual(t, hashedKey.KeySalt, otherHashedKey.KeySalt, "expected a random salt per key")
+	assert.NotEqual(t, hashedKey.Key, otherHashedKey.Key)
+
+	assert.Equal(t, HashSetupKey("salt", key.Key), HashSetupKey("salt", strings.ToLower(key.Key)))
+	assert.NotEqual(t, HashSetupKey("salt", key.Key), HashSetupKey("other salt", key.Key))
+}
@@ -0,0 +1,380 @@
+package server
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/pem"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+
+	"github.com/netbirdio/netbird/management/server/activity"
+	"github.com/netbirdio/netbird/management/server/status"
+)
+
+const (
+	// DefaultSSHCertificateValidity is the validity of the SSH certificates when the request doesn't set one
+	DefaultSSHCertificateValidity = time.Hour
+	// MaxSSHCertificateValidity is the longest validity of the SSH certificates
+	MaxSSHCertificateValidity = 24 * time.Hour
+	// sshCertificateClockSkew is subtracted from the start of the certificate validity to tolerate clock differences
+	sshCertificateClockSkew = time.Minute
+	// sshCertificatePortForwardingExtension permits port forwarding on OpenSSH servers trusting the authority
+	sshCertificatePortForwardingExtension = "permit-port-forwarding"
+)
+
+// SSHCertificateAuthority of an account signs short-lived SSH user certificates of the account users.
+// The peers trust certificates signed by it in addition to the SSH keys of the remote peers
+type SSHCertificateAuthority struct {
+	// PrivateKey is the PEM encoded private key of the authority
+	PrivateKey string
+	// PublicKey is the public key of the authority in the authorized_keys format
+	PublicKey string
+	CreatedAt time.Time
+}
+
+// Copy returns a copy of the SSH certificate authority
+func (ca *SSHCertificateAuthority) Copy() *SSHCertificateAuthority {
+	return &SSHCertificateAuthority{
+		PrivateKey: ca.PrivateKey,
+		PublicKey:  ca.PublicKey,
+		CreatedAt:  ca.CreatedAt,
+	}
+}
+
+// newSSHCertificateAuthority generates a new ED25519 SSH certificate authority
+func newSSHCertificateAuthority() (*SSHCertificateAuthority, error) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	encodedKey, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	sshPublicKey, err := ssh.NewPublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SSHCertificateAuthority{
+		PrivateKey: string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: encodedKey})),
+		PublicKey:  strings.TrimSpace(string(ssh.MarshalAuthorizedKey(sshPublicKey))),
+		CreatedAt:  time.Now().UTC(),
+	}, nil
+}
+
+// SSHCertificateRequest is a request of a user to sign its SSH public key
+type SSHCertificateRequest struct {
+	// PublicKey is the user public key in the authorized_keys format
+	PublicKey string
+	// Principals are the local users the certificate allows to log in as
+	Principals []string
+	// Validity of the certificate. DefaultSSHCertificateValidity is used when not set
+	Validity time.Duration
+}
+
+// SSHCertificate is a short-lived SSH user certificate signed by the account SSH certificate authority
+type SSHCertificate struct {
+	// Certificate in the authorized_keys format, to be used as the certificate file of an SSH client
+	Certificate string
+	Serial      uint64
+	// KeyID of the certificate, the ID of the user it has been issued to
+	KeyID       string
+	Principals  []string
+	ValidAfter  time.Time
+	ValidBefore time.Time
+}
+
+// SSHUserAccess is what a NetBird user logging in with an SSH certificate is allowed to do on a peer
+type SSHUserAccess struct {
+	// LocalUsers are the local users of the peer the user is allowed to log in as
+	LocalUsers []string
+	// PortForwarding allows the user to forward TCP ports through the SSH server of the peer
+	PortForwarding bool
+}
+
+// GetSSHCertificateAuthority returns the public key of the account SSH certificate authority in the authorized_keys
+// format. The authority is created with the first issued certificate, until then NotFound is returned
+func (am *DefaultAccountManager) GetSSHCertificateAuthority(accountID, userID string) (string, error) {
+	unlock := am.Store.AcquireAccountLock(accountID)
+	defer unlock()
+
+	account, err := am.Store.GetAccount(accountID)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = account.FindUser(userID)
+	if err != nil {
+		return "", err
+	}
+
+	if account.SSHCertificateAuthority == nil {
+		return "", status.Errorf(status.NotFound, "account doesn't have an SSH certificate authority yet")
+	}
+
+	return account.SSHCertificateAuthority.PublicKey, nil
+}
+
+// IssueSSHCertificate signs the SSH public key of a user with the account SSH certificate authority.
+// When the account has SSH policies, the principals are limited to the local users the policies of the user peers allow.
+// Otherwise only admins are allowed to request certificates
+func (am *DefaultAccountManager) IssueSSHCertificate(accountID, userID string, req *SSHCertificateRequest) (*SSHCertificate, error) {
+	unlock := am.Store.AcquireAccountLock(accountID)
+	defer unlock()
+
+	if req == nil {
+		return nil, status.Errorf(status.InvalidArgument, "SSH certificate request provided is nil")
+	}
+
+	account, err := am.Store.GetAccount(accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := account.FindUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(req.PublicKey))
+	if err != nil {
+		return nil, status.Errorf(status.InvalidArgument, "invalid SSH public key: %v", err)
+	}
+	if _, ok := publicKey.(*ssh.Certificate); ok {
+		return nil, status.Errorf(status.InvalidArgument, "SSH public key should not be a certificate")
+	}
+
+	validity := req.Validity
+	if validity == 0 {
+		validity = DefaultSSHCertificateValidity
+	}
+	if validity < 0 || validity > MaxSSHCertificateValidity {
+		return nil, status.Errorf(status.InvalidArgument, "SSH certificate validity should be between 1s and %s",
+			MaxSSHCertificateValidity)
+	}
+
+	principals, err := validatePrincipals(req.Principals)
+	if err != nil {
+		return nil, err
+	}
+
+	portForwarding := user.IsAdmin()
+	if account.hasEnabledSSHPolicies() {
+		access := account.getUserSSHAccess(userID)
+		for _, principal := range principals {
+			if !containsString(access.LocalUsers, principal) {
+				return nil, status.Errorf(status.PermissionDenied, "SSH policies don't allow logging in as local user %s",
+					principal)
+			}
+		}
+		portForwarding = access.PortForwarding
+	} else if !user.IsAdmin() {
+		return nil, status.Errorf(status.PermissionDenied,
+			"only admins are allowed to request SSH certificates when the account has no SSH policies")
+	}
+
+	ca, err := am.getOrCreateSSHCertificateAuthority(account)
+	if err != nil {
+		return nil, err
+	}
+
+	signer, err := ssh.ParsePrivateKey([]byte(ca.PrivateKey))
+	if err != nil {
+		return nil, status.Errorf(status.Internal, "failed parsing SSH certificate authority key: %v", err)
+	}
+
+	serial := make([]byte, 8)
+	_, err = rand.Read(serial)
+	if err != nil {
+		return nil, status.Errorf(status.Internal, "failed generating SSH certificate serial: %v", err)
+	}
+
+	now := time.Now().UTC()
+	validAfter := now.Add(-sshCertificateClockSkew).Truncate(time.Second)
+	validBefore := now.Add(validity).Truncate(time.Second)
+
+	extensions := map[string]string{"permit-pty": ""}
+	if portForwarding {
+		extensions[sshCertificatePortForwardingExtension] = ""
+	}
+
+	cert := &ssh.Certificate{
+		Key:             publicKey,
+		Serial:          binary.BigEndian.Uint64(serial),
+		CertType:        ssh.UserCert,
+		KeyId:           userID,
+		ValidPrincipals: principals,
+		ValidAfter:      uint64(validAfter.Unix()),
+		ValidBefore:     uint64(validBefore.Unix()),
+		Permissions:     ssh.Permissions{Extensions: extensions},
+	}
+
+	err = cert.SignCert(rand.Reader, signer)
+	if err != nil {
+		return nil, status.Errorf(status.Internal, "failed signing SSH certificate: %v", err)
+	}
+
+	am.storeEvent(userID, userID, accountID, activity.SSHCertificateIssued, map[string]any{
+		"principals":   strings.Join(principals, ","),
+		"serial":       cert.Serial,
+		"fingerprint":  ssh.FingerprintSHA256(publicKey),
+		"valid_before": validBefore.Format(time.RFC3339),
+	})
+
+	return &SSHCertificate{
+		Certificate: strings.TrimSpace(string(ssh.MarshalAuthorizedKey(cert))),
+		Serial:      cert.Serial,
+		KeyID:       cert.KeyId,
+		Principals:  principals,
+		ValidAfter:  validAfter,
+		ValidBefore: validBefore,
+	}, nil
+}
+
+// getOrCreateSSHCertificateAuthority returns the SSH certificate authority of the account.
+// A new authority is stored and distributed to the peers if the account doesn't have one yet. Must be called with the
+// account lock held
+func (am *DefaultAccountManager) getOrCreateSSHCertificateAuthority(account *Account) (*SSHCertificateAuthority, error) {
+	if account.SSHCertificateAuthority != nil {
+		return account.SSHCertificateAuthority, nil
+	}
+
+	ca, err := newSSHCertificateAuthority()
+	if err != nil {
+		return nil, status.Errorf(status.Internal, "failed generating SSH certificate authority: %v", err)
+	}
+	account.SSHCertificateAuthority = ca
+
+	account.Network.IncSerial()
+	err = am.Store.SaveAccount(account)
+	if err != nil {
+		return nil, err
+	}
+
+	err = am.updateAccountPeers(account)
+	if err != nil {
+		return nil, err
+	}
+
+	return ca, nil
+}
+
+// getUserSSHAccess returns the local users and the port forwarding permission the enabled SSH policies grant
+// to the peers of the given user on any peer
+func (a *Account) getUserSSHAccess(userID string) *SSHUserAccess {
+	localUsers := make(lookupMap)
+	access := &SSHUserAccess{}
+	for _, policy := range a.SSHPolicies {
+		if !policy.Enabled || !a.hasUserPeerInGroups(userID, policy.Sources) {
+			continue
+		}
+		for _, localUser := range policy.LocalUsers {
+			localUsers[localUser] = struct{}{}
+		}
+		access.PortForwarding = access.PortForwarding || policy.AllowPortForwarding
+	}
+
+	access.LocalUsers = sortedKeys(localUsers)
+	return access
+}
+
+// getPeerSSHCertificateAccess returns the access of the NetBird users, indexed by user ID, logging in with an SSH
+// certificate on the given peer. Users are granted the access of the SSH policies of their peers.
+// When the account has no enabled SSH policies only the admins are granted access, as any local user of the certificate
+// principals. Blocked users are never granted access. Returns nil if the account has no SSH certificate authority
+func (a *Account) getPeerSSHCertificateAccess(peerID string) map[string]*SSHUserAccess {
+	if a.SSHCertificateAuthority == nil {
+		return nil
+	}
+
+	access := make(map[string]*SSHUserAccess)
+	if !a.hasEnabledSSHPolicies() {
+		for _, user := range a.Users {
+			if user.IsAdmin() && !user.IsBlocked() {
+				access[user.Id] = &SSHUserAccess{PortForwarding: true}
+			}
+		}
+		return access
+	}
+
+	localUsers := make(map[string]lookupMap)
+	a.forEachPeerSSHPolicySource(peerID, func(policy *SSHPolicy, sourceID string) {
+		source, ok := a.Peers[sourceID]
+		if !ok || source.UserID == "" {
+			return
+		}
+		if access[source.UserID] == nil {
+			access[source.UserID] = &SSHUserAccess{}
+			localUsers[source.UserID] = make(lookupMap)
+		}
+		for _, localUser := range policy.LocalUsers {
+			localUsers[source.UserID][localUser] = struct{}{}
+		}
+		access[source.UserID].PortForwarding = access[source.UserID].PortForwarding || policy.AllowPortForwarding
+	})
+
+	for userID, users := range localUsers {
+		access[userID].LocalUsers = sortedKeys(users)
+	}
+
+	return access
+}
+
+// hasUserPeerInGroups checks whether any peer of the user belongs to one of the groups
+func (a *Account) hasUserPeerInGroups(userID string, groups []string) bool {
+	for _, groupID := range groups {
+		group, ok := a.Groups[groupID]
+		if !ok {
+			continue
+		}
+		for _, peerID := range group.Peers {
+			peer, ok := a.Peers[peerID]
+			if ok && peer.UserID == userID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func validatePrincipals(principals []string) ([]string, error) {
+	if len(principals) == 0 {
+		return nil, status.Errorf(status.InvalidArgument, "SSH certificate principals should not be empty")
+	}
+
+	unique := make(lookupMap)
+	for _, principal := range principals {
+		if !isValidLocalUserName(principal) {
+			return nil, status.Errorf(status.InvalidArgument, "invalid SSH certificate principal %q", principal)
+		}
+		unique[principal] = struct{}{}
+	}
+
+	return sortedKeys(unique), nil
+}
+
+func sortedKeys(m lookupMap) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
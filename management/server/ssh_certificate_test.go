@@ -0,0 +1,252 @@
+package server
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/netbirdio/netbird/management/server/status"
+)
+
+const sshCertRegularUserID = "sshCertRegularUser"
+
+func initTestSSHCertificateAccount(t *testing.T) (*DefaultAccountManager, *Account, string) {
+	t.Helper()
+
+	am, err := createNSManager(t)
+	require.NoError(t, err, "failed to create account manager")
+
+	_, err = initTestNSAccount(t, am)
+	require.NoError(t, err, "failed to init testing account")
+
+	account, err := am.Store.GetAccount("testingAcc")
+	require.NoError(t, err)
+
+	serverPeer, err := account.FindPeerByPubKey(nsGroupPeer1Key)
+	require.NoError(t, err)
+	userPeer, err := account.FindPeerByPubKey(nsGroupPeer2Key)
+	require.NoError(t, err)
+
+	// the regular user owns the second peer which is allowed to log in on the first one
+	account.Users[sshCertRegularUserID] = NewRegularUser(sshCertRegularUserID)
+	account.Peers[userPeer.ID].UserID = sshCertRegularUserID
+	account.Groups[group1ID].Peers = []string{userPeer.ID}
+	account.Groups[group2ID].Peers = []string{serverPeer.ID}
+	require.NoError(t, am.Store.SaveAccount(account))
+
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	signer, err := ssh.NewSignerFromKey(privateKey)
+	require.NoError(t, err)
+	publicKey := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(signer.PublicKey())))
+
+	return am, account, publicKey
+}
+
+func TestIssueSSHCertificate(t *testing.T) {
+	am, account, publicKey := initTestSSHCertificateAccount(t)
+
+	_, err := am.GetSSHCertificateAuthority(account.Id, sshCertRegularUserID)
+	require.Error(t, err, "the authority should not be created by reading it")
+	sErr, ok := status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, status.NotFound, sErr.Type())
+	stored, err := am.Store.GetAccount(account.Id)
+	require.NoError(t, err)
+	assert.Nil(t, stored.SSHCertificateAuthority)
+
+	_, err = am.IssueSSHCertificate(account.Id, sshCertRegularUserID, &SSHCertificateRequest{
+		PublicKey:  publicKey,
+		Principals: []string{"deploy"},
+	})
+	require.Error(t, err, "regular users shouldn't get certificates without SSH policies")
+	sErr, ok = status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, status.PermissionDenied, sErr.Type())
+
+	issued, err := am.IssueSSHCertificate(account.Id, userID, &SSHCertificateRequest{
+		PublicKey:  publicKey,
+		Principals: []string{"root", "deploy", "root"},
+		Validity:   10 * time.Minute,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"deploy", "root"}, issued.Principals)
+	assert.Equal(t, userID, issued.KeyID)
+	assert.WithinDuration(t, time.Now().Add(10*time.Minute), issued.ValidBefore, 5*time.Second)
+
+	parsed, _, _, _, err := ssh.ParseAuthorizedKey([]byte(issued.Certificate))
+	require.NoError(t, err)
+	cert, ok := parsed.(*ssh.Certificate)
+	require.True(t, ok, "expecting an SSH certificate")
+	assert.Equal(t, uint32(ssh.UserCert), cert.CertType)
+	assert.Equal(t, issued.Serial, cert.Serial)
+	assert.Contains(t, cert.Permissions.Extensions, sshCertificatePortForwardingExtension,
+		"admins should be allowed to forward ports without SSH policies")
+
+	caPublicKey, err := am.GetSSHCertificateAuthority(account.Id, userID)
+	require.NoError(t, err)
+	caKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(caPublicKey))
+	require.NoError(t, err)
+	assert.Equal(t, caKey.Marshal(), cert.SignatureKey.Marshal(), "certificate should be signed by the account authority")
+
+	checker := &ssh.CertChecker{}
+	require.NoError(t, checker.CheckCert("root", cert))
+
+	// the authority should be distributed to the peers
+	account, err = am.Store.GetAccount(account.Id)
+	require.NoError(t, err)
+	serverPeer, err := account.FindPeerByPubKey(nsGroupPeer1Key)
+	require.NoError(t, err)
+	assert.Equal(t, caPublicKey, account.GetPeerNetworkMap(serverPeer.ID, "netbird.cloud").SSHUserCAKey)
+}
+
+func TestIssueSSHCertificateWithSSHPolicies(t *testing.T) {
+	am, account, publicKey := initTestSSHCertificateAccount(t)
+
+	policy := &SSHPolicy{
+		ID: "policy1", Name: "deploy", Enabled: true,
+		Sources: []string{group1ID}, Destinations: []string{group2ID}, LocalUsers: []string{"deploy"},
+	}
+	require.NoError(t, am.SaveSSHPolicy(account.Id, userID, policy))
+
+	issued, err := am.IssueSSHCertificate(account.Id, sshCertRegularUserID, &SSHCertificateRequest{
+		PublicKey:  publicKey,
+		Principals: []string{"deploy"},
+	})
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(DefaultSSHCertificateValidity), issued.ValidBefore, 5*time.Second)
+
+	parsed, _, _, _, err := ssh.ParseAuthorizedKey([]byte(issued.Certificate))
+	require.NoError(t, err)
+	assert.NotContains(t, parsed.(*ssh.Certificate).Permissions.Extensions, sshCertificatePortForwardingExtension)
+
+	_, err = am.IssueSSHCertificate(account.Id, sshCertRegularUserID, &SSHCertificateRequest{
+		PublicKey:  publicKey,
+		Principals: []string{"root"},
+	})
+	require.Error(t, err, "principals should be limited to the local users of the SSH policies")
+
+	testCases := []struct {
+		name string
+		req  *SSHCertificateRequest
+	}{
+		{
+			name: "Should Fail On Invalid Public Key",
+			req:  &SSHCertificateRequest{PublicKey: "ssh-ed25519 invalid", Principals: []string{"deploy"}},
+		},
+		{
+			name: "Should Fail On Empty Principals",
+			req:  &SSHCertificateRequest{PublicKey: publicKey},
+		},
+		{
+			name: "Should Fail On Invalid Principal",
+			req:  &SSHCertificateRequest{PublicKey: publicKey, Principals: []string{"bad user"}},
+		},
+		{
+			name: "Should Fail On Too Long Validity",
+			req: &SSHCertificateRequest{
+				PublicKey: publicKey, Principals: []string{"deploy"}, Validity: MaxSSHCertificateValidity + time.Second,
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := am.IssueSSHCertificate(account.Id, sshCertRegularUserID, testCase.req)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestGetPeerSSHCertificateAccess(t *testing.T) {
+	account := newAccountWithId("testingAcc", userID, "example.com")
+	account.Peers = map[string]*Peer{
+		"laptop": {ID: "laptop", Key: "laptopKey", UserID: "alice"},
+		"ci":     {ID: "ci", Key: "ciKey"},
+		"server": {ID: "server", Key: "serverKey"},
+	}
+	account.Groups = map[string]*Group{
+		"laptops": {ID: "laptops", Name: "laptops", Peers: []string{"laptop"}},
+		"ci":      {ID: "ci", Name: "ci", Peers: []string{"ci"}},
+		"servers": {ID: "servers", Name: "servers", Peers: []string{"server"}},
+	}
+
+	require.Nil(t, account.getPeerSSHCertificateAccess("server"), "there is no access without an authority")
+
+	ca, err := newSSHCertificateAuthority()
+	require.NoError(t, err)
+	account.SSHCertificateAuthority = ca
+	account.Users["alice"] = NewRegularUser("alice")
+	account.Users["bob"] = NewAdminUser("bob")
+	account.Users["bob"].Blocked = true
+
+	require.Equal(t, map[string]*SSHUserAccess{
+		userID: {PortForwarding: true},
+	}, account.getPeerSSHCertificateAccess("server"), "only the admins that aren't blocked should have access without SSH policies")
+
+	account.SSHPolicies = map[string]*SSHPolicy{
+		"laptops": {
+			ID: "laptops", Name: "laptops", Enabled: true, AllowPortForwarding: true,
+			Sources: []string{"laptops"}, Destinations: []string{"servers"}, LocalUsers: []string{"root", "deploy"},
+		},
+		"ci": {
+			ID: "ci", Name: "ci", Enabled: true,
+			Sources: []string{"ci"}, Destinations: []string{"servers"}, LocalUsers: []string{"deploy"},
+		},
+	}
+
+	require.Equal(t, map[string]*SSHUserAccess{
+		"alice": {LocalUsers: []string{"deploy", "root"}, PortForwarding: true},
+	}, account.getPeerSSHCertificateAccess("server"), "only peers added by users should grant certificate access")
+
+	access := account.getPeerSSHCertificateAccess("laptop")
+	require.NotNil(t, access, "the access should not be nil when there is an authority")
+	require.Empty(t, access)
+
+	account.Users["alice"].Blocked = true
+	access = account.getPeerSSHCertificateAccess("server")
+	require.NotNil(t, access, "the access should not be nil when there is an authority")
+	require.Empty(t, access, "blocked users should not have access")
+	require.Empty(t, account.getPeerSSHUsers("server")["laptop"], "the peers of blocked users should not have access")
+}
+
+func TestSSHCertificateAccessOfBlockedUser(t *testing.T) {
+	am, account, publicKey := initTestSSHCertificateAccount(t)
+
+	policy := &SSHPolicy{
+		ID: "policy1", Name: "deploy", Enabled: true,
+		Sources: []string{group1ID}, Destinations: []string{group2ID}, LocalUsers: []string{"deploy"},
+	}
+	require.NoError(t, am.SaveSSHPolicy(account.Id, userID, policy))
+
+	issued, err := am.IssueSSHCertificate(account.Id, sshCertRegularUserID, &SSHCertificateRequest{
+		PublicKey:  publicKey,
+		Principals: []string{"deploy"},
+	})
+	require.NoError(t, err)
+
+	serverPeer, err := account.FindPeerByPubKey(nsGroupPeer1Key)
+	require.NoError(t, err)
+	getAccess := func() map[string]*SSHUserAccess {
+		account, err := am.Store.GetAccount(account.Id)
+		require.NoError(t, err)
+		access := account.GetPeerNetworkMap(serverPeer.ID, "netbird.cloud").SSHCertificateAccess
+		require.NotNil(t, access, "the access should not be nil when there is an authority")
+		return access
+	}
+	require.Contains(t, getAccess(), issued.KeyID, "the certificate owner should have access")
+
+	_, err = am.SaveUser(account.Id, userID, &User{Id: sshCertRegularUserID, Role: UserRoleUser, Blocked: true})
+	require.NoError(t, err)
+	assert.NotContains(t, getAccess(), issued.KeyID, "the certificate should stop working once its owner is blocked")
+
+	_, err = am.SaveUser(account.Id, userID, &User{Id: sshCertRegularUserID, Role: UserRoleUser})
+	require.NoError(t, err)
+	assert.Contains(t, getAccess(), issued.KeyID, "the certificate should work again once its owner is unblocked")
+}
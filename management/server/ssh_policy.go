@@ -0,0 +1,325 @@
+package server
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	nbdns "github.com/netbirdio/netbird/dns"
+	"github.com/netbirdio/netbird/management/server/activity"
+	"github.com/netbirdio/netbird/management/server/status"
+)
+
+// maxLocalUserNameLength is the maximum length of a local user name in an SSH policy
+const maxLocalUserNameLength = 256
+
+// SSHPolicy allows peers of the source groups to log in over SSH as one of the local users
+// on the peers of the destination groups
+type SSHPolicy struct {
+	// ID of the policy
+	ID string
+	// Name of the policy visible in the UI
+	Name string
+	// Description of the policy visible in the UI
+	Description string
+	// Enabled status of the policy
+	Enabled bool
+	// Sources are the groups of peers allowed to log in
+	Sources []string
+	// Destinations are the groups of peers running the SSH server the policy applies to
+	Destinations []string
+	// LocalUsers are the local users of the destination peers the source peers are allowed to log in as
+	LocalUsers []string
+	// AllowPortForwarding allows the source peers to forward TCP ports through the SSH server of the destination peers
+	AllowPortForwarding bool
+}
+
+// Copy returns a copy of the SSH policy
+func (p *SSHPolicy) Copy() *SSHPolicy {
+	policy := &SSHPolicy{
+		ID:                  p.ID,
+		Name:                p.Name,
+		Description:         p.Description,
+		Enabled:             p.Enabled,
+		Sources:             make([]string, len(p.Sources)),
+		Destinations:        make([]string, len(p.Destinations)),
+		LocalUsers:          make([]string, len(p.LocalUsers)),
+		AllowPortForwarding: p.AllowPortForwarding,
+	}
+	copy(policy.Sources, p.Sources)
+	copy(policy.Destinations, p.Destinations)
+	copy(policy.LocalUsers, p.LocalUsers)
+	return policy
+}
+
+// EventMeta returns activity event meta related to the SSH policy
+func (p *SSHPolicy) EventMeta() map[string]any {
+	return map[string]any{"name": p.Name, "local_users": strings.Join(p.LocalUsers, ",")}
+}
+
+// GetSSHPolicy validates a user role and returns the SSH policy with the provided ID
+func (am *DefaultAccountManager) GetSSHPolicy(accountID, policyID, userID string) (*SSHPolicy, error) {
+	unlock := am.Store.AcquireAccountLock(accountID)
+	defer unlock()
+
+	account, err := am.Store.GetAccount(accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := account.FindUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.IsAdmin() {
+		return nil, status.Errorf(status.PermissionDenied, "only admins are allowed to view SSH policies")
+	}
+
+	policy, ok := account.SSHPolicies[policyID]
+	if !ok {
+		return nil, status.Errorf(status.NotFound, "SSH policy with ID %s not found", policyID)
+	}
+
+	return policy.Copy(), nil
+}
+
+// SaveSSHPolicy validates a user role and creates or updates an SSH policy
+func (am *DefaultAccountManager) SaveSSHPolicy(accountID, userID string, policyToSave *SSHPolicy) error {
+	unlock := am.Store.AcquireAccountLock(accountID)
+	defer unlock()
+
+	if policyToSave == nil {
+		return status.Errorf(status.InvalidArgument, "SSH policy provided is nil")
+	}
+
+	account, err := am.Store.GetAccount(accountID)
+	if err != nil {
+		return err
+	}
+
+	user, err := account.FindUser(userID)
+	if err != nil {
+		return err
+	}
+
+	if !user.IsAdmin() {
+		return status.Errorf(status.PermissionDenied, "only admins are allowed to update SSH policies")
+	}
+
+	err = validateSSHPolicy(policyToSave, account)
+	if err != nil {
+		return err
+	}
+
+	if account.SSHPolicies == nil {
+		account.SSHPolicies = make(map[string]*SSHPolicy)
+	}
+
+	_, exists := account.SSHPolicies[policyToSave.ID]
+	account.SSHPolicies[policyToSave.ID] = policyToSave.Copy()
+
+	account.Network.IncSerial()
+	if err = am.Store.SaveAccount(account); err != nil {
+		return err
+	}
+
+	action := activity.SSHPolicyCreated
+	if exists {
+		action = activity.SSHPolicyUpdated
+	}
+	am.storeEvent(userID, policyToSave.ID, accountID, action, policyToSave.EventMeta())
+
+	return am.updateAccountPeers(account)
+}
+
+// DeleteSSHPolicy validates a user role and deletes the SSH policy with the provided ID
+func (am *DefaultAccountManager) DeleteSSHPolicy(accountID, policyID, userID string) error {
+	unlock := am.Store.AcquireAccountLock(accountID)
+	defer unlock()
+
+	account, err := am.Store.GetAccount(accountID)
+	if err != nil {
+		return err
+	}
+
+	user, err := account.FindUser(userID)
+	if err != nil {
+		return err
+	}
+
+	if !user.IsAdmin() {
+		return status.Errorf(status.PermissionDenied, "only admins are allowed to delete SSH policies")
+	}
+
+	policy, ok := account.SSHPolicies[policyID]
+	if !ok {
+		return status.Errorf(status.NotFound, "SSH policy with ID %s not found", policyID)
+	}
+	delete(account.SSHPolicies, policyID)
+
+	account.Network.IncSerial()
+	if err = am.Store.SaveAccount(account); err != nil {
+		return err
+	}
+
+	am.storeEvent(userID, policy.ID, accountID, activity.SSHPolicyDeleted, policy.EventMeta())
+
+	return am.updateAccountPeers(account)
+}
+
+// ListSSHPolicies validates a user role and returns the SSH policies of the account
+func (am *DefaultAccountManager) ListSSHPolicies(accountID, userID string) ([]*SSHPolicy, error) {
+	unlock := am.Store.AcquireAccountLock(accountID)
+	defer unlock()
+
+	account, err := am.Store.GetAccount(accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := account.FindUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.IsAdmin() {
+		return nil, status.Errorf(status.PermissionDenied, "only admins are allowed to view SSH policies")
+	}
+
+	policies := make([]*SSHPolicy, 0, len(account.SSHPolicies))
+	for _, policy := range account.SSHPolicies {
+		policies = append(policies, policy.Copy())
+	}
+
+	return policies, nil
+}
+
+// getPeerSSHUsers returns the local users each remote peer, indexed by peer ID, is allowed to log in as on the given peer.
+// When the account has no enabled SSH policies it returns nil, which means that SSH logins aren't restricted
+func (a *Account) getPeerSSHUsers(peerID string) map[string][]string {
+	if !a.hasEnabledSSHPolicies() {
+		return nil
+	}
+
+	allowed := make(map[string]lookupMap)
+	a.forEachPeerSSHPolicySource(peerID, func(policy *SSHPolicy, sourceID string) {
+		if allowed[sourceID] == nil {
+			allowed[sourceID] = make(lookupMap)
+		}
+		for _, localUser := range policy.LocalUsers {
+			allowed[sourceID][localUser] = struct{}{}
+		}
+	})
+
+	sshUsers := make(map[string][]string, len(allowed))
+	for sourceID, users := range allowed {
+		sshUsers[sourceID] = sortedKeys(users)
+	}
+
+	return sshUsers
+}
+
+// getPeerSSHPortForwarding returns the remote peers, indexed by peer ID, allowed to forward TCP ports
+// through the SSH server of the given peer
+func (a *Account) getPeerSSHPortForwarding(peerID string) map[string]bool {
+	forwarding := make(map[string]bool)
+	a.forEachPeerSSHPolicySource(peerID, func(policy *SSHPolicy, sourceID string) {
+		if policy.AllowPortForwarding {
+			forwarding[sourceID] = true
+		}
+	})
+
+	return forwarding
+}
+
+func (a *Account) hasEnabledSSHPolicies() bool {
+	for _, policy := range a.SSHPolicies {
+		if policy.Enabled {
+			return true
+		}
+	}
+	return false
+}
+
+// forEachPeerSSHPolicySource calls fn for every source peer of the enabled SSH policies applying to the given peer.
+// Source peers without network access, e.g. the peers of blocked users, are skipped
+func (a *Account) forEachPeerSSHPolicySource(peerID string, fn func(policy *SSHPolicy, sourceID string)) {
+	peerGroups := a.getPeerGroups(peerID)
+	for _, policy := range a.SSHPolicies {
+		if !policy.Enabled || !isInAnyGroup(peerGroups, policy.Destinations) {
+			continue
+		}
+		for _, groupID := range policy.Sources {
+			group, ok := a.Groups[groupID]
+			if !ok {
+				continue
+			}
+			for _, sourceID := range group.Peers {
+				if sourceID == peerID {
+					continue
+				}
+				if source := a.GetPeer(sourceID); source == nil || !a.hasNetworkAccess(source) {
+					continue
+				}
+				fn(policy, sourceID)
+			}
+		}
+	}
+}
+
+func isInAnyGroup(groups lookupMap, list []string) bool {
+	for _, groupID := range list {
+		if _, found := groups[groupID]; found {
+			return true
+		}
+	}
+	return false
+}
+
+func validateSSHPolicy(policy *SSHPolicy, account *Account) error {
+	if policy.ID == "" {
+		return status.Errorf(status.InvalidArgument, "SSH policy ID should not be empty")
+	}
+
+	if utf8.RuneCountInString(policy.Name) > nbdns.MaxGroupNameChar || policy.Name == "" {
+		return status.Errorf(status.InvalidArgument, "SSH policy name should be between 1 and %d", nbdns.MaxGroupNameChar)
+	}
+
+	err := validateGroups(policy.Sources, account.Groups)
+	if err != nil {
+		return err
+	}
+
+	err = validateGroups(policy.Destinations, account.Groups)
+	if err != nil {
+		return err
+	}
+
+	if len(policy.LocalUsers) == 0 {
+		return status.Errorf(status.InvalidArgument, "the list of local users should not be empty")
+	}
+
+	for _, localUser := range policy.LocalUsers {
+		if !isValidLocalUserName(localUser) {
+			return status.Errorf(status.InvalidArgument, "invalid local user name %q", localUser)
+		}
+	}
+
+	return nil
+}
+
+// isValidLocalUserName checks that the name can be used as a login name on Unix and Windows hosts
+func isValidLocalUserName(name string) bool {
+	if name == "" || len(name) > maxLocalUserNameLength || strings.HasPrefix(name, "-") {
+		return false
+	}
+
+	for _, r := range name {
+		if unicode.IsSpace(r) || unicode.IsControl(r) || strings.ContainsRune(`/:"`, r) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,172 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSaveSSHPolicy(t *testing.T) {
+	testCases := []struct {
+		name       string
+		policy     *SSHPolicy
+		shouldFail bool
+	}{
+		{
+			name: "Should Save Valid Policy",
+			policy: &SSHPolicy{
+				ID: "policy1", Name: "deploy", Enabled: true,
+				Sources: []string{group1ID}, Destinations: []string{group2ID}, LocalUsers: []string{"deploy"},
+			},
+		},
+		{
+			name: "Should Fail On Empty Name",
+			policy: &SSHPolicy{
+				ID: "policy1", Sources: []string{group1ID}, Destinations: []string{group2ID}, LocalUsers: []string{"deploy"},
+			},
+			shouldFail: true,
+		},
+		{
+			name: "Should Fail On Unknown Source Group",
+			policy: &SSHPolicy{
+				ID: "policy1", Name: "deploy", Sources: []string{"missingGroup"}, Destinations: []string{group2ID},
+				LocalUsers: []string{"deploy"},
+			},
+			shouldFail: true,
+		},
+		{
+			name: "Should Fail On Empty Destinations",
+			policy: &SSHPolicy{
+				ID: "policy1", Name: "deploy", Sources: []string{group1ID}, LocalUsers: []string{"deploy"},
+			},
+			shouldFail: true,
+		},
+		{
+			name: "Should Fail On Empty Local Users",
+			policy: &SSHPolicy{
+				ID: "policy1", Name: "deploy", Sources: []string{group1ID}, Destinations: []string{group2ID},
+			},
+			shouldFail: true,
+		},
+		{
+			name: "Should Fail On Invalid Local User",
+			policy: &SSHPolicy{
+				ID: "policy1", Name: "deploy", Sources: []string{group1ID}, Destinations: []string{group2ID},
+				LocalUsers: []string{"bad user"},
+			},
+			shouldFail: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			am, err := createNSManager(t)
+			require.NoError(t, err, "failed to create account manager")
+
+			account, err := initTestNSAccount(t, am)
+			require.NoError(t, err, "failed to init testing account")
+
+			err = am.SaveSSHPolicy(account.Id, userID, testCase.policy)
+			if testCase.shouldFail {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			savedPolicy, err := am.GetSSHPolicy(account.Id, testCase.policy.ID, userID)
+			require.NoError(t, err)
+			require.Equal(t, testCase.policy, savedPolicy)
+		})
+	}
+}
+
+func TestDeleteSSHPolicy(t *testing.T) {
+	am, err := createNSManager(t)
+	require.NoError(t, err, "failed to create account manager")
+
+	account, err := initTestNSAccount(t, am)
+	require.NoError(t, err, "failed to init testing account")
+
+	policy := &SSHPolicy{
+		ID: "policy1", Name: "deploy", Enabled: true,
+		Sources: []string{group1ID}, Destinations: []string{group2ID}, LocalUsers: []string{"deploy"},
+	}
+	require.NoError(t, am.SaveSSHPolicy(account.Id, userID, policy))
+
+	require.NoError(t, am.DeleteSSHPolicy(account.Id, policy.ID, userID))
+
+	_, err = am.GetSSHPolicy(account.Id, policy.ID, userID)
+	require.Error(t, err, "policy shouldn't be found after delete")
+
+	require.Error(t, am.DeleteSSHPolicy(account.Id, policy.ID, userID), "deleting a missing policy should fail")
+}
+
+func TestGetPeerSSHUsers(t *testing.T) {
+	account := newAccountWithId("testingAcc", userID, "example.com")
+	account.Peers = map[string]*Peer{
+		"admin":  {ID: "admin", Key: "adminKey"},
+		"ci":     {ID: "ci", Key: "ciKey"},
+		"server": {ID: "server", Key: "serverKey"},
+	}
+	account.Groups = map[string]*Group{
+		"admins":  {ID: "admins", Name: "admins", Peers: []string{"admin"}},
+		"ci":      {ID: "ci", Name: "ci", Peers: []string{"ci", "admin"}},
+		"servers": {ID: "servers", Name: "servers", Peers: []string{"server"}},
+	}
+
+	require.Nil(t, account.getPeerSSHUsers("server"), "SSH logins shouldn't be restricted without SSH policies")
+
+	account.SSHPolicies = map[string]*SSHPolicy{
+		"root": {
+			ID: "root", Name: "root", Enabled: true,
+			Sources: []string{"admins"}, Destinations: []string{"servers"}, LocalUsers: []string{"root", "deploy"},
+		},
+		"deploy": {
+			ID: "deploy", Name: "deploy", Enabled: true,
+			Sources: []string{"ci"}, Destinations: []string{"servers"}, LocalUsers: []string{"deploy"},
+		},
+		"disabled": {
+			ID: "disabled", Name: "disabled", Enabled: false,
+			Sources: []string{"ci"}, Destinations: []string{"servers"}, LocalUsers: []string{"root"},
+		},
+	}
+
+	require.Equal(t, map[string][]string{
+		"admin": {"deploy", "root"},
+		"ci":    {"deploy"},
+	}, account.getPeerSSHUsers("server"))
+
+	require.Empty(t, account.getPeerSSHUsers("admin"), "peers outside of the destination groups shouldn't allow logins")
+	require.NotNil(t, account.getPeerSSHUsers("admin"), "SSH logins should be restricted when the account has SSH policies")
+}
+
+func TestGetPeerSSHPortForwarding(t *testing.T) {
+	account := newAccountWithId("testingAcc", userID, "example.com")
+	account.Peers = map[string]*Peer{
+		"admin":  {ID: "admin", Key: "adminKey"},
+		"ci":     {ID: "ci", Key: "ciKey"},
+		"server": {ID: "server", Key: "serverKey"},
+	}
+	account.Groups = map[string]*Group{
+		"admins":  {ID: "admins", Name: "admins", Peers: []string{"admin"}},
+		"ci":      {ID: "ci", Name: "ci", Peers: []string{"ci"}},
+		"servers": {ID: "servers", Name: "servers", Peers: []string{"server"}},
+	}
+	account.SSHPolicies = map[string]*SSHPolicy{
+		"admins": {
+			ID: "admins", Name: "admins", Enabled: true, AllowPortForwarding: true,
+			Sources: []string{"admins"}, Destinations: []string{"servers"}, LocalUsers: []string{"root"},
+		},
+		"ci": {
+			ID: "ci", Name: "ci", Enabled: true,
+			Sources: []string{"ci"}, Destinations: []string{"servers"}, LocalUsers: []string{"deploy"},
+		},
+		"disabled": {
+			ID: "disabled", Name: "disabled", Enabled: false, AllowPortForwarding: true,
+			Sources: []string{"ci"}, Destinations: []string{"servers"}, LocalUsers: []string{"deploy"},
+		},
+	}
+
+	require.Equal(t, map[string]bool{"admin": true}, account.getPeerSSHPortForwarding("server"))
+	require.Empty(t, account.getPeerSSHPortForwarding("admin"), "peers outside of the destination groups shouldn't allow port forwarding")
+}
@@ -0,0 +1,86 @@
+package server
+
+import (
+	"time"
+
+	"github.com/netbirdio/netbird/management/server/activity"
+)
+
+// SSHSessionEventType is the type of an SSH session audit event
+type SSHSessionEventType int
+
+const (
+	// SSHSessionStart indicates that a session has been opened
+	SSHSessionStart SSHSessionEventType = iota
+	// SSHSessionEnd indicates that a session has been closed
+	SSHSessionEnd
+)
+
+// SSHSessionEvent is an audit event of a session handled by the embedded SSH server of a peer
+type SSHSessionEvent struct {
+	Type SSHSessionEventType
+	// SessionID is the ID of the SSH session
+	SessionID string
+	// RemotePeerKey is the WireGuard public key of the peer that opened the session.
+	// Empty if the session was authenticated with an SSH certificate
+	RemotePeerKey string
+	// RemoteAddr is the address the session was opened from
+	RemoteAddr string
+	// LocalUser is the local user the remote peer logged in as
+	LocalUser string
+	// Command of a non-interactive session, empty for interactive shells
+	Command   string
+	StartedAt time.Time
+	// Duration of the session. Only set for SSHSessionEnd events
+	Duration time.Duration
+	// UserID is the ID of the user of the SSH certificate the session was authenticated with
+	UserID string
+}
+
+// StoreSSHSessionEvent stores an activity event of a session handled by the SSH server of the peer with the given key.
+// The remote peer, or the user of the SSH certificate, is the initiator of the event and the peer running the SSH
+// server is the target
+func (am *DefaultAccountManager) StoreSSHSessionEvent(peerPubKey string, event *SSHSessionEvent) error {
+	account, err := am.Store.GetAccountByPeerPubKey(peerPubKey)
+	if err != nil {
+		return err
+	}
+
+	peer, err := account.FindPeerByPubKey(peerPubKey)
+	if err != nil {
+		return err
+	}
+
+	meta := map[string]any{
+		"session_id":  event.SessionID,
+		"peer":        peer.Name,
+		"local_user":  event.LocalUser,
+		"remote_addr": event.RemoteAddr,
+		"started_at":  event.StartedAt.UTC().Format(time.RFC3339),
+	}
+	if event.Command != "" {
+		meta["command"] = event.Command
+	}
+
+	// the remote peer might have been deleted while the session was open
+	initiatorID := event.RemotePeerKey
+	remotePeer, err := account.FindPeerByPubKey(event.RemotePeerKey)
+	if err == nil {
+		initiatorID = remotePeer.ID
+		meta["remote_peer"] = remotePeer.Name
+	}
+	if event.UserID != "" {
+		initiatorID = event.UserID
+		meta["user_id"] = event.UserID
+	}
+
+	action := activity.PeerSSHSessionStarted
+	if event.Type == SSHSessionEnd {
+		action = activity.PeerSSHSessionEnded
+		meta["duration"] = event.Duration.String()
+	}
+
+	am.storeEvent(initiatorID, peer.ID, account.Id, action, meta)
+
+	return nil
+}
@@ -0,0 +1,60 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/netbirdio/netbird/management/server/activity"
+)
+
+func TestStoreSSHSessionEvent(t *testing.T) {
+	am, err := createNSManager(t)
+	require.NoError(t, err, "failed to create account manager")
+
+	_, err = initTestNSAccount(t, am)
+	require.NoError(t, err, "failed to init testing account")
+
+	account, err := am.Store.GetAccount("testingAcc")
+	require.NoError(t, err)
+
+	serverPeer, err := account.FindPeerByPubKey(nsGroupPeer1Key)
+	require.NoError(t, err)
+	remotePeer, err := account.FindPeerByPubKey(nsGroupPeer2Key)
+	require.NoError(t, err)
+
+	err = am.StoreSSHSessionEvent(nsGroupPeer1Key, &SSHSessionEvent{
+		Type:          SSHSessionEnd,
+		SessionID:     "session1",
+		RemotePeerKey: nsGroupPeer2Key,
+		RemoteAddr:    "100.64.0.2:52000",
+		LocalUser:     "deploy",
+		StartedAt:     time.Now().Add(-time.Minute),
+		Duration:      time.Minute,
+	})
+	require.NoError(t, err)
+
+	var event *activity.Event
+	require.Eventually(t, func() bool {
+		events, err := am.eventStore.Get(account.Id, 0, 10, true)
+		if err != nil {
+			return false
+		}
+		for _, e := range events {
+			if e.Activity == activity.PeerSSHSessionEnded {
+				event = e
+				return true
+			}
+		}
+		return false
+	}, time.Second, 10*time.Millisecond, "expecting SSH session event to be stored")
+
+	require.Equal(t, remotePeer.ID, event.InitiatorID, "remote peer should initiate the event")
+	require.Equal(t, serverPeer.ID, event.TargetID, "peer running the SSH server should be the target")
+	require.Equal(t, "deploy", event.Meta["local_user"])
+	require.Equal(t, time.Minute.String(), event.Meta["duration"])
+
+	err = am.StoreSSHSessionEvent("unknownPeerKey", &SSHSessionEvent{SessionID: "session2"})
+	require.Error(t, err, "events of unknown peers should be rejected")
+}
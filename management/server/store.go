@@ -6,8 +6,12 @@ type Store interface {
 	GetAccountByUser(userID string) (*Account, error)
 	GetAccountByPeerPubKey(peerKey string) (*Account, error)
 	GetAccountByPeerID(peerID string) (*Account, error)
-	GetAccountBySetupKey(setupKey string) (*Account, error) //todo use key hash later
+	// GetAccountBySetupKey returns the account of the plaintext setup key
+	GetAccountBySetupKey(setupKey string) (*Account, error)
 	GetAccountByPrivateDomain(domain string) (*Account, error)
+	// GetAccountByHashedToken returns the account of the user owning the personal access token with the hash, see HashPAT
+	GetAccountByHashedToken(hashedToken string) (*Account, error)
+	// SaveAccount saves the account. Setup keys that hold a plaintext key must be stored hashed
 	SaveAccount(account *Account) error
 	GetInstallationID() string
 	SaveInstallationID(ID string) error
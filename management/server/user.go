@@ -6,6 +6,9 @@ import (
 
 	log "github.com/sirupsen/logrus"
 
+	"github.com/rs/xid"
+
+	"github.com/netbirdio/netbird/management/proto"
 	"github.com/netbirdio/netbird/management/server/activity"
 	"github.com/netbirdio/netbird/management/server/idp"
 	"github.com/netbirdio/netbird/management/server/jwtclaims"
@@ -46,7 +49,15 @@ type User struct {
 	Role UserRole
 	// AutoGroups is a list of Group IDs to auto-assign to peers registered by this user
 	AutoGroups []string
-	PATs       []PersonalAccessToken
+	// JWTGroups is the list of the AutoGroups that were assigned to the user from the JWT groups claim
+	JWTGroups []string
+	PATs      []PersonalAccessToken
+	// Blocked indicates that the user has been blocked by an admin and has no access to the system and the network
+	Blocked bool
+	// IsServiceUser indicates that the user was created in management for automation and has no IdP identity
+	IsServiceUser bool
+	// ServiceUserName is the name of a service user. Names of other users come from the IdP
+	ServiceUserName string
 }
 
 // IsAdmin returns true if user is an admin, false otherwise
@@ -54,6 +65,11 @@ func (u *User) IsAdmin() bool {
 	return u.Role == UserRoleAdmin
 }
 
+// IsBlocked returns true if the user is blocked, false otherwise
+func (u *User) IsBlocked() bool {
+	return u.Blocked
+}
+
 // toUserInfo converts a User object to a UserInfo object.
 func (u *User) toUserInfo(userData *idp.UserData) (*UserInfo, error) {
 	autoGroups := u.AutoGroups
@@ -62,13 +78,19 @@ func (u *User) toUserInfo(userData *idp.UserData) (*UserInfo, error) {
 	}
 
 	if userData == nil {
+		userStatus := UserStatusActive
+		if u.IsBlocked() {
+			userStatus = UserStatusDisabled
+		}
 		return &UserInfo{
-			ID:         u.Id,
-			Email:      "",
-			Name:       "",
-			Role:       string(u.Role),
-			AutoGroups: u.AutoGroups,
-			Status:     string(UserStatusActive),
+			ID:            u.Id,
+			Email:         "",
+			Name:          u.ServiceUserName,
+			Role:          string(u.Role),
+			AutoGroups:    u.AutoGroups,
+			Status:        string(userStatus),
+			IsBlocked:     u.IsBlocked(),
+			IsServiceUser: u.IsServiceUser,
 		}, nil
 	}
 	if userData.ID != u.Id {
@@ -79,6 +101,9 @@ func (u *User) toUserInfo(userData *idp.UserData) (*UserInfo, error) {
 	if userData.AppMetadata.WTPendingInvite != nil && *userData.AppMetadata.WTPendingInvite {
 		userStatus = UserStatusInvited
 	}
+	if u.IsBlocked() {
+		userStatus = UserStatusDisabled
+	}
 
 	return &UserInfo{
 		ID:         u.Id,
@@ -87,6 +112,7 @@ func (u *User) toUserInfo(userData *idp.UserData) (*UserInfo, error) {
 		Role:       string(u.Role),
 		AutoGroups: autoGroups,
 		Status:     string(userStatus),
+		IsBlocked:  u.IsBlocked(),
 	}, nil
 }
 
@@ -94,13 +120,22 @@ func (u *User) toUserInfo(userData *idp.UserData) (*UserInfo, error) {
 func (u *User) Copy() *User {
 	autoGroups := make([]string, len(u.AutoGroups))
 	copy(autoGroups, u.AutoGroups)
+	var jwtGroups []string
+	if u.JWTGroups != nil {
+		jwtGroups = make([]string, len(u.JWTGroups))
+		copy(jwtGroups, u.JWTGroups)
+	}
 	pats := make([]PersonalAccessToken, len(u.PATs))
 	copy(pats, u.PATs)
 	return &User{
-		Id:         u.Id,
-		Role:       u.Role,
-		AutoGroups: autoGroups,
-		PATs:       pats,
+		Id:              u.Id,
+		Role:            u.Role,
+		AutoGroups:      autoGroups,
+		JWTGroups:       jwtGroups,
+		PATs:            pats,
+		Blocked:         u.Blocked,
+		IsServiceUser:   u.IsServiceUser,
+		ServiceUserName: u.ServiceUserName,
 	}
 }
 
@@ -124,7 +159,12 @@ func NewAdminUser(id string) *User {
 }
 
 // CreateUser creates a new user under the given account. Effectively this is a user invite.
+// If UserInfo.IsServiceUser is set, a service user is created in management only without inviting it to the IdP.
 func (am *DefaultAccountManager) CreateUser(accountID, userID string, invite *UserInfo) (*UserInfo, error) {
+	if invite != nil && invite.IsServiceUser {
+		return am.createServiceUser(accountID, userID, invite)
+	}
+
 	unlock := am.Store.AcquireAccountLock(accountID)
 	defer unlock()
 
@@ -189,8 +229,58 @@ func (am *DefaultAccountManager) CreateUser(accountID, userID string, invite *Us
 
 }
 
+// createServiceUser creates a new service user under the given account. Service users have no IdP identity.
+func (am *DefaultAccountManager) createServiceUser(accountID, userID string, serviceUser *UserInfo) (*UserInfo, error) {
+	unlock := am.Store.AcquireAccountLock(accountID)
+	defer unlock()
+
+	if serviceUser.Name == "" {
+		return nil, status.Errorf(status.InvalidArgument, "service user name shouldn't be empty")
+	}
+
+	role := StrRoleToUserRole(serviceUser.Role)
+	if role == UserRoleUnknown {
+		return nil, status.Errorf(status.InvalidArgument, "unknown user role %s", serviceUser.Role)
+	}
+
+	account, err := am.Store.GetAccount(accountID)
+	if err != nil {
+		return nil, status.Errorf(status.NotFound, "account %s doesn't exist", accountID)
+	}
+
+	for _, groupID := range serviceUser.AutoGroups {
+		if _, ok := account.Groups[groupID]; !ok {
+			return nil, status.Errorf(status.InvalidArgument, "provided group ID %s doesn't exist", groupID)
+		}
+	}
+
+	autoGroups := serviceUser.AutoGroups
+	if autoGroups == nil {
+		autoGroups = []string{}
+	}
+
+	newUser := &User{
+		Id:              xid.New().String(),
+		Role:            role,
+		AutoGroups:      autoGroups,
+		IsServiceUser:   true,
+		ServiceUserName: serviceUser.Name,
+	}
+	account.Users[newUser.Id] = newUser
+
+	err = am.Store.SaveAccount(account)
+	if err != nil {
+		return nil, err
+	}
+
+	am.storeEvent(userID, newUser.Id, accountID, activity.ServiceUserCreated, map[string]any{"name": newUser.ServiceUserName})
+
+	return newUser.toUserInfo(nil)
+}
+
 // SaveUser saves updates a given user. If the user doesn't exit it will throw status.NotFound error.
-// Only User.AutoGroups field is allowed to be updated for now.
+// Only User.AutoGroups, User.Role, and User.Blocked fields are allowed to be updated for now.
+// Blocking a user disconnects all the user's peers and revokes their access to the network until the user is unblocked.
 func (am *DefaultAccountManager) SaveUser(accountID, userID string, update *User) (*UserInfo, error) {
 	unlock := am.Store.AcquireAccountLock(accountID)
 	defer unlock()
@@ -216,22 +306,56 @@ func (am *DefaultAccountManager) SaveUser(accountID, userID string, update *User
 		return nil, status.Errorf(status.NotFound, "update not found")
 	}
 
+	if update.Blocked && userID == update.Id {
+		return nil, status.Errorf(status.PermissionDenied, "users can't block themselves")
+	}
+
 	// only auto groups, revoked status, and name can be updated for now
 	newUser := oldUser.Copy()
 	newUser.AutoGroups = update.AutoGroups
+	// groups removed from the user manually are no longer managed by the JWT groups claim
+	newUser.JWTGroups = intersection(newUser.JWTGroups, update.AutoGroups)
 	newUser.Role = update.Role
+	newUser.Blocked = update.Blocked
 
 	account.Users[newUser.Id] = newUser
 
+	blockedStatusUpdated := oldUser.Blocked != newUser.Blocked
+	if blockedStatusUpdated {
+		account.updateDynamicGroups()
+		account.Network.IncSerial()
+	}
+
 	if err = am.Store.SaveAccount(account); err != nil {
 		return nil, err
 	}
 
+	if blockedStatusUpdated {
+		if newUser.IsBlocked() {
+			err = am.disconnectUserPeers(account, newUser.Id)
+			if err != nil {
+				return nil, err
+			}
+		}
+		err = am.updateAccountPeers(account)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	defer func() {
 		if oldUser.Role != newUser.Role {
 			am.storeEvent(userID, oldUser.Id, accountID, activity.UserRoleUpdated, map[string]any{"role": newUser.Role})
 		}
 
+		if blockedStatusUpdated {
+			event := activity.UserBlocked
+			if !newUser.IsBlocked() {
+				event = activity.UserUnblocked
+			}
+			am.storeEvent(userID, oldUser.Id, accountID, event, nil)
+		}
+
 		removedGroups := difference(oldUser.AutoGroups, update.AutoGroups)
 		addedGroups := difference(newUser.AutoGroups, oldUser.AutoGroups)
 		for _, g := range removedGroups {
@@ -256,7 +380,7 @@ func (am *DefaultAccountManager) SaveUser(accountID, userID string, update *User
 		}
 	}()
 
-	if !isNil(am.idpManager) {
+	if !isNil(am.idpManager) && !newUser.IsServiceUser {
 		userData, err := am.lookupUserInCache(newUser.Id, account)
 		if err != nil {
 			return nil, err
@@ -269,6 +393,39 @@ func (am *DefaultAccountManager) SaveUser(accountID, userID string, update *User
 	return newUser.toUserInfo(nil)
 }
 
+// disconnectUserPeers sends an empty network map to all the peers of the user and closes their update channels
+func (am *DefaultAccountManager) disconnectUserPeers(account *Account, userID string) error {
+	peers, err := account.FindUserPeers(userID)
+	if err != nil {
+		return err
+	}
+
+	peerIDs := make([]string, 0, len(peers))
+	for _, peer := range peers {
+		peerIDs = append(peerIDs, peer.ID)
+		err = am.peersUpdateManager.SendUpdate(peer.ID,
+			&UpdateMessage{
+				Update: &proto.SyncResponse{
+					// fill those field for backward compatibility
+					RemotePeers:        []*proto.RemotePeerConfig{},
+					RemotePeersIsEmpty: true,
+					// new field
+					NetworkMap: &proto.NetworkMap{
+						Serial:             account.Network.CurrentSerial(),
+						RemotePeers:        []*proto.RemotePeerConfig{},
+						RemotePeersIsEmpty: true,
+					},
+				},
+			})
+		if err != nil {
+			return err
+		}
+	}
+
+	am.peersUpdateManager.CloseChannels(peerIDs)
+	return nil
+}
+
 // GetOrCreateAccountByUser returns an existing account for a given user id or creates a new one if doesn't exist
 func (am *DefaultAccountManager) GetOrCreateAccountByUser(userID, domain string) (*Account, error) {
 	unlock := am.Store.AcquireGlobalLock()
@@ -310,6 +467,9 @@ func (am *DefaultAccountManager) GetOrCreateAccountByUser(userID, domain string)
 func (am *DefaultAccountManager) IsUserAdmin(claims jwtclaims.AuthorizationClaims) (bool, error) {
 	account, _, err := am.GetAccountFromToken(claims)
 	if err != nil {
+		if _, ok := status.FromError(err); ok {
+			return false, err
+		}
 		return false, fmt.Errorf("get account: %v", err)
 	}
 
@@ -338,7 +498,10 @@ func (am *DefaultAccountManager) GetUsersFromAccount(accountID, userID string) (
 	if !isNil(am.idpManager) {
 		users := make(map[string]struct{}, len(account.Users))
 		for _, user := range account.Users {
-			users[user.Id] = struct{}{}
+			// service users don't exist in the IdP
+			if !user.IsServiceUser {
+				users[user.Id] = struct{}{}
+			}
 		}
 		queriedUsers, err = am.lookupCache(users, accountID)
 		if err != nil {
@@ -379,5 +542,150 @@ func (am *DefaultAccountManager) GetUsersFromAccount(accountID, userID string) (
 		}
 	}
 
+	for _, accountUser := range account.Users {
+		if !accountUser.IsServiceUser || (!user.IsAdmin() && user.Id != accountUser.Id) {
+			continue
+		}
+		info, err := accountUser.toUserInfo(nil)
+		if err != nil {
+			return nil, err
+		}
+		userInfos = append(userInfos, info)
+	}
+
 	return userInfos, nil
 }
+
+// CreatePAT creates a new personal access token for the target user and returns it together with its plain token.
+// Users can create tokens for themselves, admins additionally for the service users of the account
+func (am *DefaultAccountManager) CreatePAT(accountID, executingUserID, targetUserID, tokenName string, expiresIn int) (*PersonalAccessTokenGenerated, error) {
+	unlock := am.Store.AcquireAccountLock(accountID)
+	defer unlock()
+
+	if tokenName == "" {
+		return nil, status.Errorf(status.InvalidArgument, "token name shouldn't be empty")
+	}
+
+	if expiresIn < 1 || expiresIn > 365 {
+		return nil, status.Errorf(status.InvalidArgument, "token expiration should be between 1 and 365 days")
+	}
+
+	account, err := am.Store.GetAccount(accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	targetUser, err := getPATTargetUser(account, executingUserID, targetUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	pat, plainToken, err := CreateNewPAT(tokenName, expiresIn, executingUserID)
+	if err != nil {
+		return nil, status.Errorf(status.Internal, "failed to create personal access token: %v", err)
+	}
+
+	targetUser.PATs = append(targetUser.PATs, *pat)
+
+	err = am.Store.SaveAccount(account)
+	if err != nil {
+		return nil, err
+	}
+
+	am.storeEvent(executingUserID, targetUserID, accountID, activity.PersonalAccessTokenCreated, map[string]any{"name": pat.Description})
+
+	return &PersonalAccessTokenGenerated{PlainToken: plainToken, PersonalAccessToken: *pat}, nil
+}
+
+// DeletePAT deletes a personal access token of the target user
+func (am *DefaultAccountManager) DeletePAT(accountID, executingUserID, targetUserID, tokenID string) error {
+	unlock := am.Store.AcquireAccountLock(accountID)
+	defer unlock()
+
+	account, err := am.Store.GetAccount(accountID)
+	if err != nil {
+		return err
+	}
+
+	targetUser, err := getPATTargetUser(account, executingUserID, targetUserID)
+	if err != nil {
+		return err
+	}
+
+	var deleted *PersonalAccessToken
+	pats := make([]PersonalAccessToken, 0, len(targetUser.PATs))
+	for _, pat := range targetUser.PATs {
+		if pat.ID == tokenID {
+			deleted = &pat
+			continue
+		}
+		pats = append(pats, pat)
+	}
+	if deleted == nil {
+		return status.Errorf(status.NotFound, "personal access token %s not found", tokenID)
+	}
+	targetUser.PATs = pats
+
+	err = am.Store.SaveAccount(account)
+	if err != nil {
+		return err
+	}
+
+	am.storeEvent(executingUserID, targetUserID, accountID, activity.PersonalAccessTokenDeleted, map[string]any{"name": deleted.Description})
+
+	return nil
+}
+
+// getPATTargetUser returns the user whose personal access tokens the executing user manages
+func getPATTargetUser(account *Account, executingUserID, targetUserID string) (*User, error) {
+	executingUser, ok := account.Users[executingUserID]
+	if !ok {
+		return nil, status.Errorf(status.NotFound, "user %s not found", executingUserID)
+	}
+
+	targetUser, ok := account.Users[targetUserID]
+	if !ok {
+		return nil, status.Errorf(status.NotFound, "user %s not found", targetUserID)
+	}
+
+	if executingUserID != targetUserID && !(executingUser.IsAdmin() && targetUser.IsServiceUser) {
+		return nil, status.Errorf(status.PermissionDenied, "only admins can manage the tokens of service users, users can manage their own tokens")
+	}
+
+	return targetUser, nil
+}
+
+// GetAccountFromPAT returns the account and the user owning the plain personal access token together with the token.
+// Malformed, unknown and expired tokens are rejected
+func (am *DefaultAccountManager) GetAccountFromPAT(plainToken string) (*Account, *User, *PersonalAccessToken, error) {
+	if err := ValidatePATFormat(plainToken); err != nil {
+		return nil, nil, nil, status.Errorf(status.Unauthorized, "invalid token: %v", err)
+	}
+
+	hashedToken := HashPAT(plainToken)
+	account, err := am.Store.GetAccountByHashedToken(hashedToken)
+	if err != nil {
+		if e, ok := status.FromError(err); ok && e.Type() == status.NotFound {
+			return nil, nil, nil, status.Errorf(status.Unauthorized, "invalid token")
+		}
+		return nil, nil, nil, err
+	}
+
+	for _, user := range account.Users {
+		for _, pat := range user.PATs {
+			if pat.HashedToken != hashedToken {
+				continue
+			}
+			if pat.IsExpired() {
+				return nil, nil, nil, status.Errorf(status.Unauthorized, "token expired")
+			}
+			if user.IsBlocked() {
+				return nil, nil, nil, status.Errorf(status.PermissionDenied, "user %s is blocked", user.Id)
+			}
+			return account, user, &pat, nil
+		}
+	}
+
+	// the token has been deleted since it was indexed
+	return nil, nil, nil, status.Errorf(status.Unauthorized, "invalid token")
+}
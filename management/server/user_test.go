@@ -0,0 +1,214 @@
+package server
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
+
+	"github.com/netbirdio/netbird/management/server/activity"
+	"github.com/netbirdio/netbird/management/server/jwtclaims"
+	"github.com/netbirdio/netbird/management/server/status"
+)
+
+func TestDefaultAccountManager_BlockUser(t *testing.T) {
+	manager, err := createManager(t)
+	require.NoError(t, err, "unable to create account manager")
+	account, err := manager.GetAccountByUserOrAccountID(userID, "", "")
+	require.NoError(t, err, "unable to create an account")
+
+	regularUserID := "regular_user"
+	account.Users[regularUserID] = NewRegularUser(regularUserID)
+	err = manager.Store.SaveAccount(account)
+	require.NoError(t, err, "unable to save account")
+
+	addPeer := func(ownerID string) (*Peer, error) {
+		key, err := wgtypes.GeneratePrivateKey()
+		require.NoError(t, err)
+		peer, _, err := manager.AddPeer("", ownerID, &Peer{Key: key.PublicKey().String(), Meta: PeerSystemMeta{Hostname: ownerID}})
+		return peer, err
+	}
+
+	adminPeer, err := addPeer(userID)
+	require.NoError(t, err, "unable to add peer")
+	regularPeer, err := addPeer(regularUserID)
+	require.NoError(t, err, "unable to add peer")
+
+	pat, err := manager.CreatePAT(account.Id, regularUserID, regularUserID, "own", 30)
+	require.NoError(t, err, "unable to create token")
+
+	updates := manager.peersUpdateManager.CreateChannel(regularPeer.ID)
+
+	_, err = manager.SaveUser(account.Id, userID, &User{Id: userID, Role: UserRoleAdmin, Blocked: true})
+	require.Error(t, err, "users should not be able to block themselves")
+
+	userInfo, err := manager.SaveUser(account.Id, userID, &User{Id: regularUserID, Role: UserRoleUser, Blocked: true})
+	require.NoError(t, err, "unable to block user")
+	assert.True(t, userInfo.IsBlocked)
+	assert.Equal(t, string(UserStatusDisabled), userInfo.Status)
+
+	update, ok := <-updates
+	require.True(t, ok, "blocked user peers should receive an update")
+	assert.Empty(t, update.Update.NetworkMap.RemotePeers, "blocked user peers should receive an empty network map")
+	_, ok = <-updates
+	assert.False(t, ok, "blocked user peers should be disconnected")
+
+	networkMap, err := manager.GetNetworkMap(adminPeer.ID)
+	require.NoError(t, err)
+	assert.Empty(t, networkMap.Peers, "blocked user peers should not be visible to other peers")
+
+	_, _, err = manager.GetAccountFromToken(jwtclaims.AuthorizationClaims{UserId: regularUserID, AccountId: account.Id})
+	require.Error(t, err, "blocked user JWT should be rejected")
+	errStatus, ok := status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, status.PermissionDenied, errStatus.Type())
+
+	_, _, _, err = manager.GetAccountFromPAT(pat.PlainToken)
+	require.Error(t, err, "blocked user token should be rejected")
+	errStatus, ok = status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, status.PermissionDenied, errStatus.Type())
+
+	_, _, err = manager.SyncPeer(PeerSync{WireGuardPubKey: regularPeer.Key})
+	require.Error(t, err, "blocked user peers should not be able to sync")
+
+	_, err = addPeer(regularUserID)
+	require.Error(t, err, "blocked user should not be able to add peers")
+
+	_, err = manager.SaveUser(account.Id, userID, &User{Id: regularUserID, Role: UserRoleUser})
+	require.NoError(t, err, "unable to unblock user")
+
+	networkMap, err = manager.GetNetworkMap(adminPeer.ID)
+	require.NoError(t, err)
+	require.Len(t, networkMap.Peers, 1, "unblocked user peers should get the access back")
+	assert.Equal(t, regularPeer.ID, networkMap.Peers[0].ID)
+
+	_, _, err = manager.SyncPeer(PeerSync{WireGuardPubKey: regularPeer.Key})
+	require.NoError(t, err, "unblocked user peers should be able to sync")
+
+	_, _, _, err = manager.GetAccountFromPAT(pat.PlainToken)
+	require.NoError(t, err, "unblocked user token should be accepted")
+
+	ev := getEvent(t, account.Id, manager, activity.UserBlocked)
+	assert.Equal(t, regularUserID, ev.TargetID)
+	ev = getEvent(t, account.Id, manager, activity.UserUnblocked)
+	assert.Equal(t, regularUserID, ev.TargetID)
+}
+
+func TestDefaultAccountManager_CreateServiceUser(t *testing.T) {
+	manager, err := createManager(t)
+	require.NoError(t, err, "unable to create account manager")
+	account, err := manager.GetAccountByUserOrAccountID(userID, "", "")
+	require.NoError(t, err, "unable to create an account")
+
+	_, err = manager.CreateUser(account.Id, userID, &UserInfo{Role: "user", IsServiceUser: true})
+	require.Error(t, err, "service user without a name should be rejected")
+
+	_, err = manager.CreateUser(account.Id, userID, &UserInfo{Name: "automation", Role: "owner", IsServiceUser: true})
+	require.Error(t, err, "service user with an unknown role should be rejected")
+
+	userInfo, err := manager.CreateUser(account.Id, userID, &UserInfo{Name: "automation", Role: "admin", IsServiceUser: true})
+	require.NoError(t, err, "unable to create service user without an IdP manager")
+	assert.True(t, userInfo.IsServiceUser)
+	assert.Equal(t, "automation", userInfo.Name)
+	assert.Equal(t, string(UserRoleAdmin), userInfo.Role)
+
+	account, err = manager.Store.GetAccount(account.Id)
+	require.NoError(t, err)
+	serviceUser := account.Users[userInfo.ID]
+	require.NotNil(t, serviceUser, "service user should be stored in the account")
+	assert.True(t, serviceUser.IsServiceUser)
+
+	_, _, err = manager.GetAccountFromToken(jwtclaims.AuthorizationClaims{UserId: serviceUser.Id, AccountId: account.Id})
+	require.Error(t, err, "service users should not authenticate with a JWT")
+
+	users, err := manager.GetUsersFromAccount(account.Id, userID)
+	require.NoError(t, err)
+	var serviceUsers []*UserInfo
+	for _, user := range users {
+		if user.IsServiceUser {
+			serviceUsers = append(serviceUsers, user)
+		}
+	}
+	require.Len(t, serviceUsers, 1)
+	assert.Equal(t, "automation", serviceUsers[0].Name)
+
+	ev := getEvent(t, account.Id, manager, activity.ServiceUserCreated)
+	assert.Equal(t, serviceUser.Id, ev.TargetID)
+}
+
+func TestDefaultAccountManager_PAT(t *testing.T) {
+	manager, err := createManager(t)
+	require.NoError(t, err, "unable to create account manager")
+	account, err := manager.GetAccountByUserOrAccountID(userID, "", "")
+	require.NoError(t, err, "unable to create an account")
+
+	regularUserID := "regular_user"
+	account.Users[regularUserID] = NewRegularUser(regularUserID)
+	err = manager.Store.SaveAccount(account)
+	require.NoError(t, err, "unable to save account")
+
+	serviceUser, err := manager.CreateUser(account.Id, userID, &UserInfo{Name: "ci", Role: string(UserRoleUser), IsServiceUser: true})
+	require.NoError(t, err, "unable to create service user")
+
+	_, err = manager.CreatePAT(account.Id, userID, serviceUser.ID, "", 30)
+	require.Error(t, err, "tokens without a name should be rejected")
+	_, err = manager.CreatePAT(account.Id, userID, serviceUser.ID, "ci", 0)
+	require.Error(t, err, "tokens without an expiration should be rejected")
+	_, err = manager.CreatePAT(account.Id, regularUserID, serviceUser.ID, "ci", 30)
+	require.Error(t, err, "regular users should not create tokens for service users")
+	_, err = manager.CreatePAT(account.Id, userID, regularUserID, "ci", 30)
+	require.Error(t, err, "admins should not create tokens for other regular users")
+
+	pat, err := manager.CreatePAT(account.Id, userID, serviceUser.ID, "ci", 30)
+	require.NoError(t, err, "unable to create token")
+	assert.Equal(t, HashPAT(pat.PlainToken), pat.HashedToken)
+
+	// the hashed token has to survive the persistence of the store
+	store, err := NewFileStore(filepath.Dir(manager.Store.(*FileStore).storeFile))
+	require.NoError(t, err)
+	manager.Store = store
+
+	patAccount, patUser, foundPAT, err := manager.GetAccountFromPAT(pat.PlainToken)
+	require.NoError(t, err, "unable to authenticate with the token")
+	assert.Equal(t, account.Id, patAccount.Id)
+	assert.Equal(t, serviceUser.ID, patUser.Id)
+	assert.Equal(t, pat.ID, foundPAT.ID)
+
+	claims := jwtclaims.AuthorizationClaims{UserId: serviceUser.ID, AccountId: account.Id, AuthenticatedByPAT: true}
+	_, user, err := manager.GetAccountFromToken(claims)
+	require.NoError(t, err, "service users should be authenticated by their tokens")
+	assert.Equal(t, serviceUser.ID, user.Id)
+
+	claims.AuthenticatedByPAT = false
+	_, _, err = manager.GetAccountFromToken(claims)
+	require.Error(t, err, "service users should not be authenticated by a JWT")
+
+	_, _, _, err = manager.GetAccountFromPAT(PATPrefix + "malformed")
+	require.Error(t, err, "malformed tokens should be rejected")
+
+	ownPAT, err := manager.CreatePAT(account.Id, regularUserID, regularUserID, "own", 1)
+	require.NoError(t, err, "users should create tokens for themselves")
+
+	account, err = manager.Store.GetAccount(account.Id)
+	require.NoError(t, err)
+	account.Users[regularUserID].PATs[0].ExpirationDate = time.Now().UTC().Add(-time.Minute)
+	require.NoError(t, manager.Store.SaveAccount(account))
+	_, _, _, err = manager.GetAccountFromPAT(ownPAT.PlainToken)
+	require.Error(t, err, "expired tokens should be rejected")
+
+	err = manager.DeletePAT(account.Id, userID, serviceUser.ID, "missing")
+	require.Error(t, err, "deleting a missing token should fail")
+
+	err = manager.DeletePAT(account.Id, userID, serviceUser.ID, pat.ID)
+	require.NoError(t, err, "unable to delete token")
+
+	_, _, _, err = manager.GetAccountFromPAT(pat.PlainToken)
+	require.Error(t, err, "deleted tokens should be rejected")
+	errStatus, ok := status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, status.Unauthorized, errStatus.Type())
+}